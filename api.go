@@ -0,0 +1,462 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SetOfProtocols contains a set of protocols allowed by the "protocols" node.
+var SetOfProtocols = map[string]struct{}{
+	"HTTP": {}, "HTTPS": {},
+}
+
+// API is the RAML 1.0 API root document.
+type API struct {
+	ID                string
+	Title             string
+	Description       string
+	Version           string
+	BaseURI           string
+	BaseURIParameters *orderedmap.OrderedMap[string, Property]
+	Protocols         []string
+	MediaType         []string
+	Documentation     []*DocumentationItem
+	SecuredBy         []*SecurityRequirement
+	Resources         *orderedmap.OrderedMap[string, *Resource]
+
+	AnnotationTypes *orderedmap.OrderedMap[string, *BaseShape]
+	ResourceTypes   *orderedmap.OrderedMap[string, *ResourceType]
+	Traits          *orderedmap.OrderedMap[string, *Trait]
+	SecuritySchemes *orderedmap.OrderedMap[string, *SecurityScheme]
+	Types           *orderedmap.OrderedMap[string, *BaseShape]
+	Uses            *orderedmap.OrderedMap[string, *LibraryLink]
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Location string
+	raml     *RAML
+
+	// resourceNodes keeps key-value pairs of root resources until libraries from "uses" are parsed,
+	// since resource types and traits may be declared in libraries.
+	resourceNodes []*yaml.Node
+}
+
+// DocumentationItem is a single item of the "documentation" node.
+type DocumentationItem struct {
+	Title   string
+	Content string
+
+	Location string
+	stacktrace.Position
+}
+
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+func (a *API) GetReferenceType(refName string) (*BaseShape, error) {
+	return getReference(a.Types, a.Uses, refName, "reference", libraryTypes)
+}
+
+// GetReferenceAnnotationType returns a reference annotation type by name,
+// implementing the ReferenceAnnotationTypeGetter interface
+func (a *API) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
+	return getReference(a.AnnotationTypes, a.Uses, refName, "reference", libraryAnnotationTypes)
+}
+
+// GetReferenceResourceType returns a resource type by name, implementing the ReferenceResourceTypeGetter interface
+func (a *API) GetReferenceResourceType(refName string) (*ResourceType, error) {
+	return getReference(a.ResourceTypes, a.Uses, refName, "resource type", libraryResourceTypes)
+}
+
+// GetReferenceTrait returns a trait by name, implementing the ReferenceTraitGetter interface
+func (a *API) GetReferenceTrait(refName string) (*Trait, error) {
+	return getReference(a.Traits, a.Uses, refName, "trait", libraryTraits)
+}
+
+// GetReferenceSecurityScheme returns a security scheme by name,
+// implementing the ReferenceSecuritySchemeGetter interface
+func (a *API) GetReferenceSecurityScheme(refName string) (*SecurityScheme, error) {
+	return getReference(a.SecuritySchemes, a.Uses, refName, "security scheme", librarySecuritySchemes)
+}
+
+func (a *API) GetLocation() string {
+	return a.Location
+}
+
+func (a *API) unmarshalUses(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+
+	if valueNode.Kind != yaml.MappingNode {
+		return StacktraceNew("uses must be map", a.Location, WithNodePosition(valueNode))
+	}
+
+	a.Uses = orderedmap.New[string, *LibraryLink](len(valueNode.Content) / 2)
+	// Map nodes come in pairs in order [key, value]
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		path := valueNode.Content[j+1]
+		a.Uses.Set(name, &LibraryLink{
+			Value:    path.Value,
+			Location: a.Location,
+			Position: stacktrace.Position{Line: path.Line, Column: path.Column},
+		})
+	}
+	return nil
+}
+
+func (a *API) unmarshalTypes(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+
+	if valueNode.Kind != yaml.MappingNode {
+		return StacktraceNew("types must be map", a.Location, WithNodePosition(valueNode))
+	}
+
+	// Map nodes come in pairs in order [key, value]
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		shape, err := a.raml.makeNewShapeYAML(data, name, a.Location)
+		if err != nil {
+			return StacktraceNewWrapped("parse types: make shape", err, a.Location, WithNodePosition(data))
+		}
+		a.Types.Set(name, shape)
+		a.raml.PutTypeIntoFragment(name, a.Location, shape)
+	}
+	return nil
+}
+
+func (a *API) unmarshalAnnotationTypes(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+
+	if valueNode.Kind != yaml.MappingNode {
+		return StacktraceNew("annotation types must be map", a.Location, WithNodePosition(valueNode))
+	}
+
+	// Map nodes come in pairs in order [key, value]
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		shape, err := a.raml.makeAnnotationTypeShape(data, name, a.Location)
+		if err != nil {
+			return StacktraceNewWrapped("parse annotation types: make shape", err, a.Location, WithNodePosition(data))
+		}
+		a.AnnotationTypes.Set(name, shape)
+		a.raml.PutAnnotationTypeIntoFragment(name, a.Location, shape)
+	}
+
+	return nil
+}
+
+func (a *API) unmarshalResources() error {
+	for j := 0; j != len(a.resourceNodes); j += 2 {
+		node := a.resourceNodes[j]
+		valueNode := a.resourceNodes[j+1]
+		res, err := a.raml.makeResource(node.Value, valueNode, a.Location, nil, a.MediaType)
+		if err != nil {
+			return StacktraceNewWrapped("make resource", err, a.Location, WithNodePosition(node),
+				stacktrace.WithInfo("resource", node.Value))
+		}
+		a.Resources.Set(res.URI, res)
+	}
+	if err := a.raml.resolveSecurityRequirements(a.SecuredBy); err != nil {
+		return fmt.Errorf("resolve security requirements: %w", err)
+	}
+	if err := a.raml.applySecuredBy(a.Resources, a.SecuredBy); err != nil {
+		return fmt.Errorf("apply securedBy: %w", err)
+	}
+	return nil
+}
+
+func (a *API) unmarshalDocumentation(valueNode *yaml.Node) error {
+	if valueNode.Kind != yaml.SequenceNode {
+		return StacktraceNew("documentation must be sequence", a.Location, WithNodePosition(valueNode))
+	}
+	a.Documentation = make([]*DocumentationItem, 0, len(valueNode.Content))
+	for _, itemNode := range valueNode.Content {
+		if itemNode.Tag == TagInclude {
+			item, err := a.raml.parseDocumentationItem(a.raml.includedLocation(itemNode, a.Location))
+			if err != nil {
+				return StacktraceNewWrapped("parse documentation item", err, a.Location, WithNodePosition(itemNode))
+			}
+			a.Documentation = append(a.Documentation, item)
+			continue
+		}
+		item, err := a.raml.makeDocumentationItem(itemNode, a.Location)
+		if err != nil {
+			return StacktraceNewWrapped("make documentation item", err, a.Location, WithNodePosition(itemNode))
+		}
+		a.Documentation = append(a.Documentation, item)
+	}
+	return nil
+}
+
+// decodeValueNode decodes a root-level node of the API document.
+func (a *API) decodeValueNode(node, valueNode *yaml.Node) error {
+	switch node.Value {
+	case "title":
+		if err := valueNode.Decode(&a.Title); err != nil {
+			return StacktraceNewWrapped("decode title", err, a.Location, WithNodePosition(valueNode))
+		}
+	case FacetDescription:
+		if err := valueNode.Decode(&a.Description); err != nil {
+			return StacktraceNewWrapped("decode description", err, a.Location, WithNodePosition(valueNode))
+		}
+	case "version":
+		if err := valueNode.Decode(&a.Version); err != nil {
+			return StacktraceNewWrapped("decode version", err, a.Location, WithNodePosition(valueNode))
+		}
+	case "baseUri":
+		if err := valueNode.Decode(&a.BaseURI); err != nil {
+			return StacktraceNewWrapped("decode baseUri", err, a.Location, WithNodePosition(valueNode))
+		}
+	case "baseUriParameters":
+		params, err := a.raml.makeParameters(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("make base uri parameters: %w", err)
+		}
+		a.BaseURIParameters = params
+	case "securedBy":
+		reqs, err := a.raml.decodeSecuredBy(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("decode securedBy: %w", err)
+		}
+		a.SecuredBy = reqs
+	case "protocols":
+		protocols, err := decodeProtocols(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("decode protocols: %w", err)
+		}
+		a.Protocols = protocols
+	case "mediaType":
+		mediaTypes, err := decodeStringOrSequence(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("decode media type: %w", err)
+		}
+		a.MediaType = mediaTypes
+	case "documentation":
+		if err := a.unmarshalDocumentation(valueNode); err != nil {
+			return fmt.Errorf("unmarshal documentation: %w", err)
+		}
+	case "uses":
+		if err := a.unmarshalUses(valueNode); err != nil {
+			return fmt.Errorf("unmarshal uses: %w", err)
+		}
+	// NOTE: "schemas" is a deprecated synonym of "types".
+	case "types", "schemas":
+		if err := a.unmarshalTypes(valueNode); err != nil {
+			return fmt.Errorf("unmarshal types: %w", err)
+		}
+	case "annotationTypes":
+		if err := a.unmarshalAnnotationTypes(valueNode); err != nil {
+			return fmt.Errorf("unmarshal annotation types: %w", err)
+		}
+	case "resourceTypes":
+		resourceTypes, err := a.raml.makeResourceTypes(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("make resource types: %w", err)
+		}
+		a.ResourceTypes = resourceTypes
+	case "traits":
+		traits, err := a.raml.makeTraits(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("make traits: %w", err)
+		}
+		a.Traits = traits
+	case "securitySchemes":
+		securitySchemes, err := a.raml.makeSecuritySchemes(valueNode, a.Location)
+		if err != nil {
+			return fmt.Errorf("make security schemes: %w", err)
+		}
+		a.SecuritySchemes = securitySchemes
+	default:
+		if IsCustomDomainExtensionNode(node.Value) {
+			name, de, err := a.raml.unmarshalCustomDomainExtension(a.Location, node, valueNode)
+			if err != nil {
+				return StacktraceNewWrapped("unmarshal custom domain extension", err, a.Location,
+					WithNodePosition(valueNode))
+			}
+			a.CustomDomainProperties.Set(name, de)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML unmarshals an API from a yaml.Node, implementing the yaml.Unmarshaler interface
+func (a *API) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return StacktraceNew("must be map", a.Location, WithNodePosition(value))
+	}
+
+	// Resources are decoded in a separate stage because they depend on the default media type
+	// and on resource types and traits that may be declared in libraries.
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		if IsResourceNode(node.Value) {
+			a.resourceNodes = append(a.resourceNodes, node, valueNode)
+			continue
+		}
+		if err := a.decodeValueNode(node, valueNode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RAML) MakeAPI(path string) *API {
+	return &API{
+		BaseURIParameters:      orderedmap.New[string, Property](0),
+		Resources:              orderedmap.New[string, *Resource](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Uses:                   orderedmap.New[string, *LibraryLink](0),
+		Types:                  orderedmap.New[string, *BaseShape](0),
+		AnnotationTypes:        orderedmap.New[string, *BaseShape](0),
+		ResourceTypes:          orderedmap.New[string, *ResourceType](0),
+		Traits:                 orderedmap.New[string, *Trait](0),
+		SecuritySchemes:        orderedmap.New[string, *SecurityScheme](0),
+
+		Location: path,
+		raml:     r,
+	}
+}
+
+// makeDocumentationItem creates a documentation item from the given value node.
+func (r *RAML) makeDocumentationItem(value *yaml.Node, location string) (*DocumentationItem, error) {
+	location = r.mergedLocation(value, location)
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("documentation item must be map", location, WithNodePosition(value))
+	}
+	item := &DocumentationItem{
+		Location: location,
+		Position: stacktrace.Position{Line: value.Line, Column: value.Column},
+	}
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		switch node.Value {
+		case "title":
+			if err := valueNode.Decode(&item.Title); err != nil {
+				return nil, StacktraceNewWrapped("decode title", err, location, WithNodePosition(valueNode))
+			}
+		case "content":
+			if valueNode.Tag == TagInclude {
+				content, err := r.readIncludedContent(valueNode, location)
+				if err != nil {
+					return nil, StacktraceNewWrapped("read included content", err, location, WithNodePosition(valueNode))
+				}
+				item.Content = content
+				continue
+			}
+			if err := valueNode.Decode(&item.Content); err != nil {
+				return nil, StacktraceNewWrapped("decode content", err, location, WithNodePosition(valueNode))
+			}
+		default:
+			return nil, StacktraceNew("unknown documentation item node", location, WithNodePosition(node),
+				stacktrace.WithInfo("node", node.Value))
+		}
+	}
+	if item.Title == "" {
+		return nil, StacktraceNew("documentation item title is required", location, WithNodePosition(value))
+	}
+	if item.Content == "" {
+		return nil, StacktraceNew("documentation item content is required", location, WithNodePosition(value))
+	}
+	return item, nil
+}
+
+// readIncludedContent reads the included file as a raw string.
+func (r *RAML) readIncludedContent(node *yaml.Node, location string) (string, error) {
+	fragmentPath := r.includedLocation(node, location)
+	f, err := r.readRawFile(fragmentPath)
+	if err != nil {
+		return "", StacktraceNewWrapped("read raw file", err, location, WithNodePosition(node),
+			stacktrace.WithInfo("path", fragmentPath))
+	}
+	defer func(f io.ReadCloser) {
+		err = f.Close()
+		if err != nil {
+			log.Fatal(fmt.Errorf("close file error: %w", err))
+		}
+	}(f)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", StacktraceNewWrapped("read all", err, location, WithNodePosition(node),
+			stacktrace.WithInfo("path", fragmentPath))
+	}
+	return string(data), nil
+}
+
+// makeParameters creates named parameters (URI, query parameters and headers) from the given value node.
+// Parameters share the same syntax with object properties.
+func (r *RAML) makeParameters(
+	valueNode *yaml.Node,
+	location string,
+) (*orderedmap.OrderedMap[string, Property], error) {
+	if valueNode.Tag == TagNull {
+		return orderedmap.New[string, Property](0), nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("parameters must be map", location, WithNodePosition(valueNode))
+	}
+	params := orderedmap.New[string, Property](len(valueNode.Content) / 2)
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		nodeName := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+
+		// Parameters may originate from resource types and traits declared in other fragments.
+		dataLocation := r.templateLocation(data, location)
+		propertyName, hasImplicitOptional := r.chompImplicitOptional(nodeName)
+		property, err := r.makeProperty(nodeName, propertyName, data, dataLocation, hasImplicitOptional)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make parameter", err, dataLocation, WithNodePosition(data),
+				stacktrace.WithInfo("parameter", nodeName))
+		}
+		params.Set(property.Name, property)
+	}
+	return params, nil
+}
+
+// decodeProtocols decodes and validates the "protocols" node.
+func decodeProtocols(valueNode *yaml.Node, location string) ([]string, error) {
+	protocols, err := decodeStringOrSequence(valueNode, location)
+	if err != nil {
+		return nil, fmt.Errorf("decode string or sequence: %w", err)
+	}
+	for i, p := range protocols {
+		// Protocols are case-insensitive according to the specification.
+		p = strings.ToUpper(p)
+		if _, ok := SetOfProtocols[p]; !ok {
+			return nil, StacktraceNew("invalid protocol", location, WithNodePosition(valueNode),
+				stacktrace.WithInfo("protocol", protocols[i]))
+		}
+		protocols[i] = p
+	}
+	return protocols, nil
+}
+
+// decodeStringOrSequence decodes a node that may be either a single string or a sequence of strings.
+func decodeStringOrSequence(valueNode *yaml.Node, location string) ([]string, error) {
+	switch valueNode.Kind {
+	case yaml.ScalarNode:
+		return []string{valueNode.Value}, nil
+	case yaml.SequenceNode:
+		var values []string
+		if err := valueNode.Decode(&values); err != nil {
+			return nil, StacktraceNewWrapped("decode sequence", err, location, WithNodePosition(valueNode))
+		}
+		return values, nil
+	default:
+		return nil, StacktraceNew("must be string or sequence", location, WithNodePosition(valueNode))
+	}
+}
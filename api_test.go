@@ -0,0 +1,138 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_ParseFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		want    func(t *testing.T, got *API)
+	}{
+		{
+			name: "positive: all root nodes",
+			content: `#%RAML 1.0
+title: Sample API
+description: Sample description
+version: v1
+baseUri: https://{region}.example.com/{version}
+baseUriParameters:
+  region:
+    enum: [eu, us]
+protocols: [http, HTTPS]
+mediaType: application/json
+documentation:
+  - title: Overview
+    content: Some content
+annotationTypes:
+  deprecated: boolean
+(deprecated): true
+types:
+  User:
+    properties:
+      name: string
+`,
+			want: func(t *testing.T, got *API) {
+				require.Equal(t, "Sample API", got.Title)
+				require.Equal(t, "Sample description", got.Description)
+				require.Equal(t, "v1", got.Version)
+				require.Equal(t, "https://{region}.example.com/{version}", got.BaseURI)
+				require.Equal(t, []string{"HTTP", "HTTPS"}, got.Protocols)
+				require.Equal(t, []string{"application/json"}, got.MediaType)
+				require.Len(t, got.Documentation, 1)
+				require.Equal(t, "Overview", got.Documentation[0].Title)
+				_, ok := got.BaseURIParameters.Get("region")
+				require.True(t, ok)
+				_, ok = got.Types.Get("User")
+				require.True(t, ok)
+				_, ok = got.AnnotationTypes.Get("deprecated")
+				require.True(t, ok)
+				_, ok = got.CustomDomainProperties.Get("deprecated")
+				require.True(t, ok)
+			},
+		},
+		{
+			name: "positive: media type sequence",
+			content: `#%RAML 1.0
+title: Sample API
+mediaType: [application/json, application/xml]
+`,
+			want: func(t *testing.T, got *API) {
+				require.Equal(t, []string{"application/json", "application/xml"}, got.MediaType)
+			},
+		},
+		{
+			name: "negative: missing title",
+			content: `#%RAML 1.0
+version: v1
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid protocol",
+			content: `#%RAML 1.0
+title: Sample API
+protocols: [FTP]
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: documentation must be sequence",
+			content: `#%RAML 1.0
+title: Sample API
+documentation: text
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: documentation item without content",
+			content: `#%RAML 1.0
+title: Sample API
+documentation:
+  - title: Overview
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid base uri parameter",
+			content: `#%RAML 1.0
+title: Sample API
+baseUriParameters:
+  region:
+    type: string
+    minLength: 5
+    maxLength: 2
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: unresolved type",
+			content: `#%RAML 1.0
+title: Sample API
+types:
+  User: Unknown
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFromStringCtx(context.Background(), tt.content, "api.raml", "",
+				OptWithUnwrap(), OptWithValidate())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want != nil {
+				api, ok := got.EntryPoint().(*API)
+				require.True(t, ok)
+				tt.want(t, api)
+			}
+		})
+	}
+}
@@ -0,0 +1,897 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Sections of documents that contain declarations.
+const (
+	SectionTypes           = "types"
+	SectionAnnotationTypes = "annotationTypes"
+	SectionResourceTypes   = "resourceTypes"
+	SectionTraits          = "traits"
+	SectionSecuritySchemes = "securitySchemes"
+)
+
+var bundleSections = []string{
+	SectionAnnotationTypes, SectionSecuritySchemes, SectionResourceTypes, SectionTraits, SectionTypes,
+}
+
+// bundleReferenceRegexp matches references in type expressions. Template parameters are matched as a part of
+// the reference, so references that contain parameters can be skipped.
+var bundleReferenceRegexp = regexp.MustCompile(`(?:<<[^>]*>>|[\w.\-])+`)
+
+// Bundler bundles a RAML document with its libraries and included files into a single self-contained document.
+//
+// Bundling is performed on YAML level, so the bundled document keeps the structure of the source documents.
+// Included fragments and files are inlined in place. Declarations of libraries are hoisted into the bundled
+// document under names prefixed with the namespace of the library, e.g. "common.User" becomes "common_User",
+// and references to them are rewritten. Data types included as a parent type ("type: !include user.raml")
+// are declared as separate types since the "type" facet cannot contain a type declaration.
+//
+// The bundler relies on libraries and fragments parsed by the RAML, so the entry point must be parsed from a file first.
+// API, Library and DataType documents can be bundled. Overlays and extensions are not supported.
+type Bundler struct {
+	raml *RAML
+
+	kind FragmentKind
+	root *yaml.Node
+	// namespaces contains namespaces of hoisted libraries by locations.
+	namespaces map[string]*bundleNamespace
+	// pending contains namespaces whose declarations are not hoisted yet.
+	pending []*bundleNamespace
+	// hoisted contains hoisted declarations by sections.
+	hoisted map[string]*yaml.Node
+	// names contains names declared in the bundled document by sections.
+	names map[string]map[string]struct{}
+	// includedTypes contains names of types declared for data types included as a parent type by locations.
+	includedTypes map[string]string
+}
+
+// bundleNamespace is a library whose declarations are hoisted into the bundled document.
+type bundleNamespace struct {
+	prefix string
+	lib    *Library
+}
+
+// name returns the name of the hoisted declaration.
+func (ns *bundleNamespace) name(name string) string {
+	return ns.prefix + "_" + name
+}
+
+// declares reports whether the library declares the name in the section.
+func (ns *bundleNamespace) declares(section string, name string) bool {
+	var ok bool
+	switch section {
+	case SectionTypes:
+		_, ok = ns.lib.Types.Get(name)
+	case SectionAnnotationTypes:
+		_, ok = ns.lib.AnnotationTypes.Get(name)
+	case SectionResourceTypes:
+		_, ok = ns.lib.ResourceTypes.Get(name)
+	case SectionTraits:
+		_, ok = ns.lib.Traits.Get(name)
+	case SectionSecuritySchemes:
+		_, ok = ns.lib.SecuritySchemes.Get(name)
+	}
+	return ok
+}
+
+// bundleScope is the scope of the document the bundled nodes belong to.
+type bundleScope struct {
+	location string
+	// uses contains namespaces of used libraries by aliases.
+	uses map[string]*bundleNamespace
+	// local is the namespace of the library the nodes belong to. Nil for other documents.
+	local *bundleNamespace
+}
+
+// reference returns the bundled name of the declaration referenced in the section.
+// Unknown references, e.g. built-in types, are returned as is.
+func (s *bundleScope) reference(section string, ref string) string {
+	if alias, name, found := CutReferenceName(ref); found {
+		if ns, ok := s.uses[alias]; ok && ns.declares(section, name) {
+			return ns.name(name)
+		}
+		return ref
+	}
+	if s.local != nil && s.local.declares(section, ref) {
+		return s.local.name(ref)
+	}
+	return ref
+}
+
+// typeExpression returns the type expression with bundled names of the referenced types.
+func (s *bundleScope) typeExpression(expr string) string {
+	// JSON and XML schemas are not type expressions.
+	if strings.HasPrefix(expr, "{") || (strings.HasPrefix(expr, "<") && !strings.HasPrefix(expr, "<<")) {
+		return expr
+	}
+	return bundleReferenceRegexp.ReplaceAllStringFunc(expr, func(ref string) string {
+		if strings.Contains(ref, "<<") {
+			return ref
+		}
+		return s.reference(SectionTypes, ref)
+	})
+}
+
+// NewBundler creates a bundler of the entry point parsed by r.
+func NewBundler(r *RAML) *Bundler {
+	return &Bundler{
+		raml:          r,
+		namespaces:    make(map[string]*bundleNamespace),
+		hoisted:       make(map[string]*yaml.Node),
+		names:         make(map[string]map[string]struct{}),
+		includedTypes: make(map[string]string),
+	}
+}
+
+// Bundle returns the entry point bundled into a single document.
+func (b *Bundler) Bundle() ([]byte, error) {
+	entry := b.raml.EntryPoint()
+	if entry == nil {
+		return nil, fmt.Errorf("entry point is not parsed")
+	}
+	var uses *orderedmap.OrderedMap[string, *LibraryLink]
+	switch f := entry.(type) {
+	case *API:
+		uses = f.Uses
+	case *Library:
+		uses = f.Uses
+	case *DataType:
+		uses = f.Uses
+	default:
+		return nil, fmt.Errorf("entry point of type %T cannot be bundled", entry)
+	}
+	location := entry.GetLocation()
+	head, root, err := b.readDocument(location)
+	if err != nil {
+		return nil, err
+	}
+	if b.kind, err = IdentifyFragment(head); err != nil {
+		return nil, StacktraceNewWrapped("identify fragment", err, location)
+	}
+	if b.kind != FragmentAPI && b.kind != FragmentLibrary && b.kind != FragmentDataType {
+		return nil, StacktraceNew("fragment cannot be bundled", location, stacktrace.WithInfo("head", head))
+	}
+	b.root = root
+	b.collectNames()
+
+	removeMappingKey(root, "uses")
+	s, err := b.scope(location, uses, nil)
+	if err != nil {
+		return nil, err
+	}
+	if b.kind == FragmentDataType {
+		err = b.typeDeclaration(root, s)
+	} else {
+		err = b.document(root, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(b.pending) > 0 {
+		ns := b.pending[0]
+		b.pending = b.pending[1:]
+		if err = b.hoist(ns); err != nil {
+			return nil, fmt.Errorf("hoist library %s: %w", ns.lib.Location, err)
+		}
+	}
+	b.mergeHoisted()
+
+	var buf bytes.Buffer
+	if err = (&Encoder{w: &buf}).writeDocument(head, root); err != nil {
+		return nil, fmt.Errorf("write document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// collectNames collects names declared by the bundled document itself.
+func (b *Bundler) collectNames() {
+	for _, section := range bundleSections {
+		b.names[section] = make(map[string]struct{})
+	}
+	for i := 0; i+1 < len(b.root.Content); i += 2 {
+		section := b.root.Content[i].Value
+		if section == "schemas" {
+			section = SectionTypes
+		}
+		names, ok := b.names[section]
+		if !ok {
+			continue
+		}
+		node := b.root.Content[i+1]
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			names[node.Content[j].Value] = struct{}{}
+		}
+	}
+}
+
+// scope creates the scope of the document at the location. Libraries used by the document are hoisted.
+func (b *Bundler) scope(
+	location string,
+	uses *orderedmap.OrderedMap[string, *LibraryLink],
+	local *bundleNamespace,
+) (*bundleScope, error) {
+	s := &bundleScope{location: location, uses: make(map[string]*bundleNamespace), local: local}
+	if uses == nil {
+		return s, nil
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		ns, err := b.namespace(pair.Key, pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		s.uses[pair.Key] = ns
+	}
+	return s, nil
+}
+
+// namespace returns the namespace of the used library. Declarations of new namespaces are hoisted later.
+func (b *Bundler) namespace(alias string, link *LibraryLink) (*bundleNamespace, error) {
+	if link.Link == nil {
+		return nil, StacktraceNew("library is not parsed", link.Location, stacktrace.WithPosition(&link.Position),
+			stacktrace.WithInfo("library", link.Value))
+	}
+	if ns, ok := b.namespaces[link.Link.Location]; ok {
+		return ns, nil
+	}
+	if b.kind == FragmentDataType {
+		return nil, StacktraceNew("libraries cannot be hoisted into data type", link.Location,
+			stacktrace.WithPosition(&link.Position), stacktrace.WithInfo("library", link.Value))
+	}
+	ns := &bundleNamespace{prefix: alias, lib: link.Link}
+	for i := 2; b.collides(ns); i++ {
+		ns.prefix = alias + strconv.Itoa(i)
+	}
+	b.namespaces[link.Link.Location] = ns
+	b.pending = append(b.pending, ns)
+	return ns, nil
+}
+
+// collides reports whether the prefix of the namespace is used by another namespace
+// or any of the hoisted names is already declared.
+func (b *Bundler) collides(ns *bundleNamespace) bool {
+	for _, other := range b.namespaces {
+		if other.prefix == ns.prefix {
+			return true
+		}
+	}
+	for _, section := range bundleSections {
+		for name := range b.names[section] {
+			if prefix, rest, found := strings.Cut(name, "_"); found && prefix == ns.prefix && ns.declares(section, rest) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hoist declares the declarations of the library in the bundled document.
+func (b *Bundler) hoist(ns *bundleNamespace) error {
+	_, root, err := b.readDocument(ns.lib.Location)
+	if err != nil {
+		return err
+	}
+	s, err := b.scope(ns.lib.Location, ns.lib.Uses, ns)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		section := root.Content[i].Value
+		if section == "schemas" {
+			section = SectionTypes
+		}
+		walk := b.declarationWalker(section)
+		if walk == nil {
+			continue
+		}
+		node := root.Content[i+1]
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			name, value := node.Content[j].Value, node.Content[j+1]
+			if err = walk(value, s); err != nil {
+				return fmt.Errorf("bundle %s %s: %w", section, name, err)
+			}
+			if err = b.declare(section, ns.name(name), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// declare declares the hoisted declaration in the section of the bundled document.
+func (b *Bundler) declare(section string, name string, node *yaml.Node) error {
+	if _, ok := b.names[section][name]; ok {
+		return fmt.Errorf("%s %s is already declared", section, name)
+	}
+	b.names[section][name] = struct{}{}
+	hoisted, ok := b.hoisted[section]
+	if !ok {
+		hoisted = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		b.hoisted[section] = hoisted
+	}
+	appendYAMLPair(hoisted, name, node)
+	return nil
+}
+
+// mergeHoisted merges hoisted declarations into the sections of the bundled document.
+// New sections are inserted before resources.
+func (b *Bundler) mergeHoisted() {
+	for _, section := range bundleSections {
+		hoisted, ok := b.hoisted[section]
+		if !ok {
+			continue
+		}
+		if node := findMappingValue(b.root, section); node != nil {
+			if node.Kind != yaml.MappingNode {
+				*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			node.Content = append(node.Content, hoisted.Content...)
+			continue
+		}
+		i := 0
+		for i < len(b.root.Content) && !IsResourceNode(b.root.Content[i].Value) {
+			i += 2
+		}
+		content := append([]*yaml.Node{yamlString(section), hoisted}, b.root.Content[i:]...)
+		b.root.Content = append(b.root.Content[:i], content...)
+	}
+}
+
+// declarationWalker returns the function that bundles declarations of the section.
+func (b *Bundler) declarationWalker(section string) func(*yaml.Node, *bundleScope) error {
+	switch section {
+	case SectionTypes:
+		return b.typeDeclaration
+	case SectionAnnotationTypes:
+		return b.annotationType
+	case SectionResourceTypes:
+		return b.resourceType
+	case SectionTraits:
+		return b.trait
+	case SectionSecuritySchemes:
+		return b.securityScheme
+	}
+	return nil
+}
+
+// document bundles the root of API or Library in-place.
+func (b *Bundler) document(node *yaml.Node, s *bundleScope) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch section := key.Value; section {
+		case SectionTypes, "schemas", SectionAnnotationTypes, SectionResourceTypes, SectionTraits,
+			SectionSecuritySchemes:
+			if section == "schemas" {
+				section = SectionTypes
+			}
+			err = b.declarations(value, s, b.declarationWalker(section))
+		case "baseUriParameters":
+			err = b.parameters(value, s)
+		case "securedBy":
+			b.securedBy(value, s)
+		case "documentation":
+			err = b.documentation(value, s)
+		default:
+			switch {
+			case IsResourceNode(key.Value):
+				err = b.resource(value, s)
+			case IsCustomDomainExtensionNode(key.Value):
+				err = b.annotation(key, value, s)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+func (b *Bundler) declarations(node *yaml.Node, s *bundleScope, walk func(*yaml.Node, *bundleScope) error) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := walk(node.Content[i+1], s); err != nil {
+			return fmt.Errorf("bundle %s: %w", node.Content[i].Value, err)
+		}
+	}
+	return nil
+}
+
+// typeDeclaration bundles the type declaration in-place.
+func (b *Bundler) typeDeclaration(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeType(node, s)
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == TagStr || node.Tag == "" {
+			node.Value = s.typeExpression(node.Value)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			item.Value = s.typeExpression(item.Value)
+		}
+	case yaml.MappingNode:
+		return b.typeFacets(node, s)
+	}
+	return nil
+}
+
+//nolint:gocognit // Facets of type declarations are bundled in one place.
+func (b *Bundler) typeFacets(node *yaml.Node, s *bundleScope) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch key.Value {
+		case FacetType, "schema":
+			if value.Tag == TagInclude {
+				var name string
+				if name, err = b.includedParentType(value, s); err == nil {
+					*value = *yamlString(name)
+				}
+				break
+			}
+			err = b.typeDeclaration(value, s)
+		case FacetItems:
+			err = b.typeDeclaration(value, s)
+		case FacetProperties, FacetFacets:
+			err = b.declarations(value, s, b.typeDeclaration)
+		case FacetExample:
+			err = b.example(value, s)
+		case FacetExamples:
+			err = b.examples(value, s)
+		default:
+			if IsCustomDomainExtensionNode(key.Value) {
+				err = b.annotation(key, value, s)
+			} else {
+				err = b.data(value, s, false)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// includeType replaces the included data type with its declaration and returns the scope of the data type.
+func (b *Bundler) includeType(node *yaml.Node, s *bundleScope) (*bundleScope, error) {
+	location := resolveLocation(s.location, node.Value)
+	// JSON schemas are declared as a string.
+	if filepath.Ext(location) == ".json" {
+		content, err := b.raml.readIncludedContent(node, s.location)
+		if err != nil {
+			return nil, err
+		}
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: strings.TrimSpace(content)}
+		return s, nil
+	}
+	return b.includeFragment(node, s, "usage")
+}
+
+// includedParentType declares the data type included as a parent type and returns the name of the declared type.
+func (b *Bundler) includedParentType(node *yaml.Node, s *bundleScope) (string, error) {
+	location := resolveLocation(s.location, node.Value)
+	if name, ok := b.includedTypes[location]; ok {
+		return name, nil
+	}
+	if b.kind == FragmentDataType {
+		return "", StacktraceNew("included parent types cannot be declared in data type", s.location,
+			WithNodePosition(node), stacktrace.WithInfo("path", node.Value))
+	}
+	base := ramlTypeName(strings.TrimSuffix(filepath.Base(location), filepath.Ext(location)))
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := b.names[SectionTypes][name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	b.includedTypes[location] = name
+
+	decl := *node
+	if err := b.typeDeclaration(&decl, s); err != nil {
+		return "", err
+	}
+	if err := b.declare(SectionTypes, name, &decl); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// includeFragment replaces the included fragment with its root node and returns the scope of the fragment.
+// Libraries used by the fragment are hoisted. Keys that are not allowed in place of the include are removed.
+func (b *Bundler) includeFragment(node *yaml.Node, s *bundleScope, removeKeys ...string) (*bundleScope, error) {
+	location := resolveLocation(s.location, node.Value)
+	_, root, err := b.readDocument(location)
+	if err != nil {
+		return nil, StacktraceNewWrapped("read included fragment", err, s.location, WithNodePosition(node),
+			stacktrace.WithInfo("path", node.Value))
+	}
+	var uses *orderedmap.OrderedMap[string, *LibraryLink]
+	switch f := b.raml.GetFragment(location).(type) {
+	case *DataType:
+		uses = f.Uses
+	case *AnnotationTypeDeclaration:
+		uses = f.Uses
+	case *TraitFragment:
+		uses = f.Uses
+	case *ResourceTypeFragment:
+		uses = f.Uses
+	case *SecuritySchemeFragment:
+		uses = f.Uses
+	}
+	removeMappingKey(root, "uses")
+	for _, key := range removeKeys {
+		removeMappingKey(root, key)
+	}
+	*node = *root
+	return b.scope(location, uses, nil)
+}
+
+// example bundles the example in-place.
+func (b *Bundler) example(node *yaml.Node, s *bundleScope) error {
+	if node.Kind != yaml.MappingNode || findMappingValue(node, ExampleValue) == nil {
+		return b.data(node, s, false)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch {
+		case key.Value == ExampleValue:
+			err = b.data(value, s, false)
+		case IsCustomDomainExtensionNode(key.Value):
+			err = b.annotation(key, value, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// examples bundles the examples in-place.
+func (b *Bundler) examples(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeFragment(node, s)
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	return b.declarations(node, s, b.example)
+}
+
+// data inlines included files of the data node in-place.
+// Nested includes are inlined the same way as the parser reads them.
+func (b *Bundler) data(node *yaml.Node, s *bundleScope, nested bool) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == TagInclude {
+		ext := filepath.Ext(node.Value)
+		if ext == ".yaml" || ext == ".yml" || (!nested && ext == ".json") {
+			location := resolveLocation(s.location, node.Value)
+			root, err := b.readData(location)
+			if err != nil {
+				return StacktraceNewWrapped("read included file", err, s.location, WithNodePosition(node),
+					stacktrace.WithInfo("path", node.Value))
+			}
+			*node = *root
+			return b.data(node, &bundleScope{location: location, uses: s.uses, local: s.local}, true)
+		}
+		content, err := b.raml.readIncludedContent(node, s.location)
+		if err != nil {
+			return err
+		}
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: content}
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := b.data(child, s, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotation bundles the annotation in-place.
+func (b *Bundler) annotation(key *yaml.Node, value *yaml.Node, s *bundleScope) error {
+	name := key.Value[1 : len(key.Value)-1]
+	key.Value = "(" + s.reference(SectionAnnotationTypes, name) + ")"
+	return b.data(value, s, false)
+}
+
+// annotationType bundles the annotation type declaration in-place.
+func (b *Bundler) annotationType(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeFragment(node, s, "usage")
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	return b.typeDeclaration(node, s)
+}
+
+// resourceType bundles the resource type declaration in-place.
+func (b *Bundler) resourceType(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeFragment(node, s)
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	return b.resource(node, s)
+}
+
+// trait bundles the trait declaration in-place.
+func (b *Bundler) trait(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeFragment(node, s)
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	return b.method(node, s)
+}
+
+// securityScheme bundles the security scheme declaration in-place.
+func (b *Bundler) securityScheme(node *yaml.Node, s *bundleScope) error {
+	if node.Tag == TagInclude {
+		fs, err := b.includeFragment(node, s)
+		if err != nil {
+			return err
+		}
+		s = fs
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch {
+		case key.Value == "describedBy":
+			err = b.method(value, s)
+		case IsCustomDomainExtensionNode(key.Value):
+			err = b.annotation(key, value, s)
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// resource bundles the resource or the resource type in-place.
+func (b *Bundler) resource(node *yaml.Node, s *bundleScope) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch strings.TrimSuffix(key.Value, "?") {
+		case "type":
+			b.templateRef(value, s, SectionResourceTypes)
+		case "is":
+			b.templateRefs(value, s)
+		case "securedBy":
+			b.securedBy(value, s)
+		case "uriParameters":
+			err = b.parameters(value, s)
+		default:
+			_, isMethod := SetOfMethods[strings.TrimSuffix(key.Value, "?")]
+			switch {
+			case isMethod:
+				err = b.method(value, s)
+			case IsResourceNode(key.Value):
+				err = b.resource(value, s)
+			case IsCustomDomainExtensionNode(key.Value):
+				err = b.annotation(key, value, s)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// method bundles the method, the trait or the description of the security scheme in-place.
+func (b *Bundler) method(node *yaml.Node, s *bundleScope) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch strings.TrimSuffix(key.Value, "?") {
+		case "is":
+			b.templateRefs(value, s)
+		case "securedBy":
+			b.securedBy(value, s)
+		case "headers", "queryParameters":
+			err = b.parameters(value, s)
+		case "queryString":
+			err = b.typeDeclaration(value, s)
+		case "body":
+			err = b.body(value, s)
+		case "responses":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if err = b.response(value.Content[j+1], s); err != nil {
+					break
+				}
+			}
+		default:
+			if IsCustomDomainExtensionNode(key.Value) {
+				err = b.annotation(key, value, s)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// response bundles the response in-place.
+func (b *Bundler) response(node *yaml.Node, s *bundleScope) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch strings.TrimSuffix(key.Value, "?") {
+		case "headers":
+			err = b.parameters(value, s)
+		case "body":
+			err = b.body(value, s)
+		default:
+			if IsCustomDomainExtensionNode(key.Value) {
+				err = b.annotation(key, value, s)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("bundle %s: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// body bundles the body in-place.
+func (b *Bundler) body(node *yaml.Node, s *bundleScope) error {
+	if !isMediaTypeMap(node) {
+		return b.typeDeclaration(node, s)
+	}
+	return b.declarations(node, s, b.typeDeclaration)
+}
+
+// parameters bundles the parameters in-place.
+func (b *Bundler) parameters(node *yaml.Node, s *bundleScope) error {
+	return b.declarations(node, s, b.typeDeclaration)
+}
+
+// templateRefs bundles references to traits in-place.
+func (b *Bundler) templateRefs(node *yaml.Node, s *bundleScope) {
+	for _, item := range node.Content {
+		b.templateRef(item, s, SectionTraits)
+	}
+}
+
+// templateRef bundles the reference to a resource type or a trait in-place.
+// Parameter values are bundled as type expressions since they usually contain type names.
+func (b *Bundler) templateRef(node *yaml.Node, s *bundleScope, section string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		node.Value = s.reference(section, node.Value)
+	case yaml.MappingNode:
+		if len(node.Content) != 2 {
+			return
+		}
+		node.Content[0].Value = s.reference(section, node.Content[0].Value)
+		params := node.Content[1]
+		for i := 1; i < len(params.Content); i += 2 {
+			if param := params.Content[i]; param.Kind == yaml.ScalarNode && param.Tag == TagStr {
+				param.Value = s.typeExpression(param.Value)
+			}
+		}
+	}
+}
+
+// securedBy bundles references to security schemes in-place.
+func (b *Bundler) securedBy(node *yaml.Node, s *bundleScope) {
+	for _, item := range node.Content {
+		switch {
+		case item.Kind == yaml.ScalarNode && item.Tag != TagNull:
+			item.Value = s.reference(SectionSecuritySchemes, item.Value)
+		case item.Kind == yaml.MappingNode && len(item.Content) == 2:
+			item.Content[0].Value = s.reference(SectionSecuritySchemes, item.Content[0].Value)
+		}
+	}
+}
+
+// documentation bundles the documentation in-place.
+func (b *Bundler) documentation(node *yaml.Node, s *bundleScope) error {
+	for _, item := range node.Content {
+		is := s
+		if item.Tag == TagInclude {
+			fs, err := b.includeFragment(item, s)
+			if err != nil {
+				return err
+			}
+			is = fs
+		}
+		if content := findMappingValue(item, "content"); content != nil && content.Tag == TagInclude {
+			value, err := b.raml.readIncludedContent(content, is.location)
+			if err != nil {
+				return err
+			}
+			*content = yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: value}
+		}
+	}
+	return nil
+}
+
+// readDocument reads the document at the location and returns its head and root node.
+// Comments that precede the root node are removed since they contain the head.
+func (b *Bundler) readDocument(location string) (string, *yaml.Node, error) {
+	f, err := b.raml.openFragment(location)
+	if err != nil {
+		return "", nil, StacktraceNewWrapped("open fragment file", err, location,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	head, err := ReadHead(f)
+	if err != nil && err != io.EOF {
+		return "", nil, StacktraceNewWrapped("read head", err, location,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+	var doc yaml.Node
+	if err = yaml.NewDecoder(f).Decode(&doc); err != nil && err != io.EOF {
+		return "", nil, StacktraceNewWrapped("decode fragment", err, location,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	if len(doc.Content) == 0 {
+		return head, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	root.HeadComment = ""
+	if root.Kind == yaml.MappingNode && len(root.Content) > 0 {
+		root.Content[0].HeadComment = ""
+	}
+	return head, root, nil
+}
+
+// readData reads the data file at the location and returns its root node.
+func (b *Bundler) readData(location string) (*yaml.Node, error) {
+	rdr, err := b.raml.readRawFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("read raw file: %w", err)
+	}
+	defer func(rdr io.ReadCloser) {
+		err = rdr.Close()
+		if err != nil {
+			log.Fatal(fmt.Errorf("close file error: %w", err))
+		}
+	}(rdr)
+	var doc yaml.Node
+	if err = yaml.NewDecoder(rdr).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode data: %w", err)
+	}
+	root := doc.Content[0]
+	root.HeadComment = ""
+	return root, nil
+}
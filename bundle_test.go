@@ -0,0 +1,187 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundler_Bundle(t *testing.T) {
+	files := map[string]string{
+		"common.raml": `#%RAML 1.0 Library
+uses:
+  base: base.raml
+annotationTypes:
+  note: string
+types:
+  Tag:
+    type: base.Name
+    maxLength: 5
+  Pet:
+    (note): pet
+    properties:
+      name: base.Name
+      tags: Tag[]
+traits:
+  paged:
+    queryParameters:
+      page: integer
+resourceTypes:
+  collection:
+    get:
+      responses:
+        200:
+          body:
+            application/json: <<item>>[]
+securitySchemes:
+  token:
+    type: Pass Through
+    describedBy:
+      headers:
+        Authorization: base.Name
+`,
+		"base.raml": `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    minLength: 1
+`,
+		"owner.raml": `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+properties:
+  pet: common.Pet
+example: !include owner.yaml
+`,
+		"owner.yaml":    "pet:\n  name: Rex\n  tags: [good]\n",
+		"error.json":    `{"type": "object", "properties": {"code": {"type": "integer"}}}`,
+		"examples.raml": "#%RAML 1.0 NamedExample\nfirst:\n  name: Tom\n  tags: []\n",
+		"intro.md":      "Introduction.",
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantNot []string
+		wantErr bool
+	}{
+		{
+			name: "positive: API with libraries and includes",
+			content: `#%RAML 1.0
+title: Pets
+uses:
+  common: common.raml
+documentation:
+  - title: Intro
+    content: !include intro.md
+types:
+  Owner: !include owner.raml
+  Error: !include error.json
+  Named:
+    type: !include owner.raml
+    properties:
+      nick: string
+/pets:
+  type: { common.collection: { item: common.Pet } }
+  is: [common.paged]
+  securedBy: [common.token]
+  post:
+    (common.note): create
+    body:
+      application/json:
+        type: common.Pet
+        examples: !include examples.raml
+`,
+			want: []string{
+				"content: Introduction.",
+				"Owner:\n    properties:\n      pet: common_Pet\n    example:\n      pet:\n        name: Rex",
+				"Error: '{\"type\": \"object\"",
+				"Named:\n    type: Owner2\n",
+				"Owner2:\n    properties:\n      pet: common_Pet\n",
+				"type: {common_collection: {item: common_Pet}}",
+				"is: [common_paged]",
+				"securedBy: [common_token]",
+				"(common_note): create",
+				"type: common_Pet\n        examples:\n          first:\n            name: Tom",
+				"annotationTypes:\n  common_note: string\n",
+				"securitySchemes:\n  common_token:",
+				"Authorization: base_Name",
+				"resourceTypes:\n  common_collection:",
+				"traits:\n  common_paged:",
+				"common_Tag:\n    type: base_Name\n",
+				"common_Pet:\n    (common_note): pet\n",
+				"tags: common_Tag[]",
+				"base_Name:\n    type: string\n",
+			},
+			wantNot: []string{"uses:", "!include", "usage:"},
+		},
+		{
+			name: "positive: library with local references",
+			content: `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Owner:
+    properties:
+      pet: common.Pet
+      name: common_Pet
+  common_Pet: string
+`,
+			want: []string{
+				"types:\n  Owner:\n    properties:\n      pet: common2_Pet\n      name: common_Pet\n  common_Pet: string\n",
+				"common2_Pet:",
+				"base_Name:",
+			},
+			wantNot: []string{"uses:"},
+		},
+		{
+			name: "negative: data type with libraries",
+			content: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: common.Pet
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+			entry := filepath.Join(dir, "entry.raml")
+			require.NoError(t, os.WriteFile(entry, []byte(tt.content), 0o600))
+			r, err := ParseFromPath(entry, OptWithValidate())
+			require.NoError(t, err)
+
+			out, err := NewBundler(r).Bundle()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bundle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got := string(out)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Bundle() output does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(got, wantNot) {
+					t.Errorf("Bundle() output contains %q:\n%s", wantNot, got)
+				}
+			}
+
+			// The bundled document must be valid without included files.
+			bundleDir := t.TempDir()
+			_, err = ParseFromString(got, "bundle.raml", bundleDir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err, got)
+		})
+	}
+}
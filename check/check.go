@@ -0,0 +1,149 @@
+// Package check validates data documents, e.g. JSON payloads or YAML files, against types declared in RAML
+// documents.
+//
+// Shapes validate a document up to the first failure, so a result has a single failure except for unions,
+// which report a failure for each member that did not match. Failures are located by JSON paths such as
+// "$.pets[0].name".
+package check
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/acronis/go-stacktrace"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// Failure is a reason why a document does not match the type.
+type Failure struct {
+	// Path is the JSON path of the value that does not match, empty if the document could not be decoded.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Failure) String() string {
+	if f.Path == "" {
+		return f.Message
+	}
+	return f.Path + ": " + f.Message
+}
+
+// Result is the result of checking a document.
+type Result struct {
+	File string `json:"file"`
+	// Line is the line where the document starts in the file, 0 if unknown.
+	Line int `json:"line"`
+	// Index is the index of the document in the file starting from 0.
+	Index    int       `json:"index"`
+	Valid    bool      `json:"valid"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+func (r Result) String() string {
+	status := "valid"
+	if !r.Valid {
+		status = "invalid"
+	}
+	if r.Line == 0 {
+		return fmt.Sprintf("%s: %s", r.File, status)
+	}
+	return fmt.Sprintf("%s:%d: %s", r.File, r.Line, status)
+}
+
+// ResolveType returns the type declared in the entry point of the document by its name. Types of used libraries
+// are referred to by their aliases, e.g. "common.User". The name may be empty for DataType fragments.
+// The document must be parsed with raml.OptWithUnwrap.
+func ResolveType(r *raml.RAML, name string) (*raml.BaseShape, error) {
+	frag := r.EntryPoint()
+	if frag == nil {
+		return nil, fmt.Errorf("entry point not found")
+	}
+	if name == "" {
+		if dt, ok := frag.(*raml.DataType); ok {
+			return dt.Shape, nil
+		}
+		return nil, fmt.Errorf("type name is required for %s", frag.GetLocation())
+	}
+	shape, err := frag.GetReferenceType(name)
+	if err != nil {
+		return nil, fmt.Errorf("resolve type %s: %w", name, err)
+	}
+	return shape, nil
+}
+
+// Validate checks the document of the file against the shape.
+func Validate(shape *raml.BaseShape, file string, doc Document) Result {
+	res := Result{File: file, Line: doc.Line, Index: doc.Index}
+	if doc.Err != nil {
+		res.Failures = []Failure{{Message: doc.Err.Error()}}
+		return res
+	}
+	if err := shape.Validate(doc.Value); err != nil {
+		res.Failures = Failures(err)
+		return res
+	}
+	res.Valid = true
+	return res
+}
+
+// pathPattern matches messages of errors that wrap a failure of a nested value.
+var pathPattern = regexp.MustCompile(`^validate (?:pattern property|property|array item) (\$[^:]*): `)
+
+// Failures returns failures of the error returned by raml.BaseShape.Validate.
+func Failures(err error) []Failure {
+	return collect(err, "$")
+}
+
+// collect follows the chain of the error to the innermost message. The path is updated with each wrapping error
+// that validates a nested value.
+func collect(err error, path string) []Failure {
+	for {
+		if st, ok := err.(*stacktrace.StackTrace); ok {
+			return collectStack(st, path)
+		}
+		if m := pathPattern.FindStringSubmatch(err.Error()); m != nil {
+			path = m[1]
+		}
+		next := errors.Unwrap(err)
+		if next == nil {
+			return []Failure{{Path: path, Message: err.Error()}}
+		}
+		err = next
+	}
+}
+
+// collectStack returns failures of the stack trace. Stack traces are returned by unions, whose members are
+// in the list, and by JSON schemas, whose violations set the path.
+func collectStack(st *stacktrace.StackTrace, path string) []Failure {
+	if len(st.List) > 0 && st.Wrapped == nil && st.Err == nil && !st.Info.Has("path") {
+		var failures []Failure
+		for _, item := range st.List {
+			for _, f := range collectStack(item, path) {
+				f.Message = st.Message + ": " + f.Message
+				failures = append(failures, f)
+			}
+		}
+		return failures
+	}
+
+	var failures []Failure
+	switch {
+	case st.Err != nil:
+		// The underlying error keeps the chain of wrapping errors with paths of nested values.
+		failures = collect(st.Err, path)
+	case st.Wrapped != nil:
+		failures = collectStack(st.Wrapped, path)
+	default:
+		itemPath := path
+		if st.Info.Has("path") {
+			itemPath = st.Info.StringBy("path")
+		}
+		failures = []Failure{{Path: itemPath, Message: st.Message}}
+	}
+	for _, item := range st.List {
+		failures = append(failures, collectStack(item, path)...)
+	}
+	return failures
+}
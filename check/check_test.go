@@ -0,0 +1,154 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+func parse(t *testing.T, dir, name, content string) *raml.RAML {
+	p := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	r, err := raml.ParseFromPath(p, raml.OptWithUnwrap())
+	require.NoError(t, err)
+	return r
+}
+
+const library = `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Cat:
+    properties:
+      name:
+        type: string
+        maxLength: 5
+  Dog:
+    properties:
+      bark: boolean
+  Owner:
+    properties:
+      name: string
+      born: date-only
+      pets: (Cat | Dog)[]
+      address?: common.Address
+      settings?:
+        type: |
+          {"type": "object", "properties": {"theme": {"type": "string"}}}
+`
+
+const common = `#%RAML 1.0 Library
+types:
+  Address:
+    properties:
+      city: string
+`
+
+func TestResolveType(t *testing.T) {
+	dir := t.TempDir()
+	parse(t, dir, "common.raml", common)
+	lib := parse(t, dir, "lib.raml", library)
+	dataType := parse(t, dir, "type.raml", "#%RAML 1.0 DataType\ntype: string\n")
+
+	tests := []struct {
+		name    string
+		r       *raml.RAML
+		typ     string
+		want    string
+		wantErr bool
+	}{
+		{name: "positive: declared type", r: lib, typ: "Owner", want: "Owner"},
+		{name: "positive: type of used library", r: lib, typ: "common.Address", want: "Address"},
+		{name: "positive: data type fragment", r: dataType, typ: "", want: "type.raml"},
+		{name: "negative: unknown type", r: lib, typ: "Unknown", wantErr: true},
+		{name: "negative: unknown library", r: lib, typ: "unknown.Address", wantErr: true},
+		{name: "negative: type name is required", r: lib, typ: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveType(tt.r, tt.typ)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("ResolveType() name = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	parse(t, dir, "common.raml", common)
+	shape, err := ResolveType(parse(t, dir, "lib.raml", library), "Owner")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []Failure
+	}{
+		{
+			name: "positive: valid document",
+			doc: `{"name": "Alice", "born": "2000-01-31", "pets": [{"name": "Tom"}, {"bark": true}],
+"address": {"city": "Paris"}, "settings": {"theme": "dark"}}`,
+		},
+		{
+			name: "negative: missing property",
+			doc:  `{"name": "Alice", "pets": []}`,
+			want: []Failure{{Path: "$", Message: "missing required properties: born"}},
+		},
+		{
+			name: "negative: nested property",
+			doc:  `{"name": "Alice", "born": "2000-01-31", "pets": [], "address": {"city": 1}}`,
+			want: []Failure{{Path: "$.address.city", Message: "invalid type, got float64, expected string"}},
+		},
+		{
+			name: "negative: union members",
+			doc:  `{"name": "Alice", "born": "2000-01-31", "pets": [{"name": "Tom"}, {"name": "Garfield"}]}`,
+			want: []Failure{
+				{Path: "$.pets[1].name", Message: "value does not match any type: length must be less than 5"},
+				{Path: "$.pets[1]", Message: "value does not match any type: missing required properties: bark"},
+			},
+		},
+		{
+			name: "negative: json schema",
+			doc:  `{"name": "Alice", "born": "2000-01-31", "pets": [], "settings": {"theme": 1}}`,
+			want: []Failure{{Path: "$.settings.theme", Message: "invalid type, got integer, expected string"}},
+		},
+		{
+			name: "negative: malformed document",
+			doc:  `{"name": `,
+			want: []Failure{{Message: "decode json: unexpected end of JSON input"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := Decode(strings.NewReader(tt.doc), FormatJSON)
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+			got := Validate(shape, "data.json", docs[0])
+			require.Equal(t, Result{
+				File:     "data.json",
+				Line:     1,
+				Valid:    len(tt.want) == 0,
+				Failures: tt.want,
+			}, got)
+		})
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	r := Result{File: "data.ndjson", Line: 3}
+	require.Equal(t, "data.ndjson:3: invalid", r.String())
+	r.Valid = true
+	require.Equal(t, "data.ndjson:3: valid", r.String())
+	r.Line = 0
+	require.Equal(t, "data.ndjson: valid", r.String())
+}
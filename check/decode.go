@@ -0,0 +1,163 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// Supported input formats.
+const (
+	// FormatJSON is a single JSON document.
+	FormatJSON = "json"
+	// FormatNDJSON is a stream of JSON documents, one per line. Empty lines are skipped.
+	FormatNDJSON = "ndjson"
+	// FormatYAML is a stream of YAML documents separated by "---".
+	FormatYAML = "yaml"
+)
+
+// maxLineSize is the maximum size of a line of NDJSON streams.
+const maxLineSize = 64 << 20
+
+// Document is a data document decoded from an input.
+type Document struct {
+	// Line is the line where the document starts in the input, 0 if unknown.
+	Line int
+	// Index is the index of the document in the input starting from 0.
+	Index int
+	Value any
+	// Err is the error of decoding the document.
+	Err error
+}
+
+// FormatFromPath returns the input format by the extension of the path. JSON is the default format.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+// Decode decodes documents of the input in the format. Documents that cannot be decoded are returned with
+// errors, so that other documents of the input are still checked. Malformed YAML ends the stream.
+func Decode(r io.Reader, format string) ([]Document, error) {
+	switch format {
+	case FormatJSON:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("read input: %w", err)
+		}
+		doc := Document{Line: 1}
+		if err = json.Unmarshal(data, &doc.Value); err != nil {
+			doc.Err = fmt.Errorf("decode json: %w", err)
+		}
+		return []Document{doc}, nil
+	case FormatNDJSON:
+		return decodeNDJSON(r)
+	case FormatYAML:
+		return decodeYAML(r)
+	}
+	return nil, fmt.Errorf("unsupported input format %q", format)
+}
+
+func decodeNDJSON(r io.Reader) ([]Document, error) {
+	var docs []Document
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxLineSize)
+	for line := 1; sc.Scan(); line++ {
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		doc := Document{Line: line, Index: len(docs)}
+		if err := json.Unmarshal(data, &doc.Value); err != nil {
+			doc.Err = fmt.Errorf("decode json: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+	return docs, nil
+}
+
+func decodeYAML(r io.Reader) ([]Document, error) {
+	var docs []Document
+	dec := yaml.NewDecoder(r)
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		doc := Document{Line: node.Line, Index: len(docs)}
+		if len(node.Content) > 0 {
+			// The document node starts at the separator.
+			doc.Line = node.Content[0].Line
+		}
+		if err != nil {
+			doc.Err = fmt.Errorf("decode yaml: %w", err)
+			return append(docs, doc), nil
+		}
+		if doc.Value, err = yamlValue(&node); err != nil {
+			doc.Err = fmt.Errorf("decode yaml: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// yamlValue converts the node to the value as if it was decoded from JSON. Timestamps remain strings,
+// since date and time types validate their string representations.
+func yamlValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.Tag == raml.TagStr || node.Tag == raml.TagTimestamp {
+			return node.Value, nil
+		}
+		var val any
+		if err := node.Decode(&val); err != nil {
+			return nil, fmt.Errorf("decode scalar at line %d: %w", node.Line, err)
+		}
+		return val, nil
+	case yaml.MappingNode:
+		properties := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			val, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			properties[node.Content[i].Value] = val
+		}
+		return properties, nil
+	case yaml.SequenceNode:
+		items := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			val, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = val
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected node kind %d at line %d", node.Kind, node.Line)
+}
@@ -0,0 +1,110 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "data.json", want: FormatJSON},
+		{path: "data.ndjson", want: FormatNDJSON},
+		{path: "data.JSONL", want: FormatNDJSON},
+		{path: "data.yaml", want: FormatYAML},
+		{path: "data.yml", want: FormatYAML},
+		{path: "-", want: FormatJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := FormatFromPath(tt.path); got != tt.want {
+				t.Errorf("FormatFromPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// decoded is a document without the error, which is compared by message.
+type decoded struct {
+	Line  int
+	Index int
+	Value any
+	Err   string
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		want    []decoded
+		wantErr bool
+	}{
+		{
+			name:   "positive: json",
+			format: FormatJSON,
+			input:  `{"name": "Alice", "age": 30, "tags": ["a"]}`,
+			want: []decoded{{Line: 1, Value: map[string]any{
+				"name": "Alice", "age": float64(30), "tags": []any{"a"},
+			}}},
+		},
+		{
+			name:   "positive: ndjson",
+			format: FormatNDJSON,
+			input:  "{\"a\": 1}\n\n{\"a\": \n  [true, null]\n",
+			want: []decoded{
+				{Line: 1, Value: map[string]any{"a": float64(1)}},
+				{Line: 3, Index: 1, Err: "decode json: unexpected end of JSON input"},
+				{Line: 4, Index: 2, Value: []any{true, nil}},
+			},
+		},
+		{
+			name:   "positive: yaml stream",
+			format: FormatYAML,
+			input:  "name: Alice\nborn: 2000-01-31\nage: 30\n---\n- &a x\n- *a\n",
+			want: []decoded{
+				{Line: 1, Value: map[string]any{"name": "Alice", "born": "2000-01-31", "age": 30}},
+				{Line: 5, Index: 1, Value: []any{"x", "x"}},
+			},
+		},
+		{
+			name:   "negative: malformed yaml",
+			format: FormatYAML,
+			input:  "a: 1\n---\na: [\n",
+			want: []decoded{
+				{Line: 1, Value: map[string]any{"a": 1}},
+				{Index: 1, Err: "decode yaml: yaml: line 3: did not find expected node content"},
+			},
+		},
+		{
+			name:    "negative: unsupported format",
+			format:  "xml",
+			input:   "<a/>",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := Decode(strings.NewReader(tt.input), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			got := make([]decoded, len(docs))
+			for i, doc := range docs {
+				got[i] = decoded{Line: doc.Line, Index: doc.Index, Value: doc.Value}
+				if doc.Err != nil {
+					got[i].Err = doc.Err.Error()
+				}
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
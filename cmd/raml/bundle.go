@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+type BundleOptions struct {
+	Output string
+}
+
+type BundleCommand struct {
+	Opts BundleOptions
+	Path string
+}
+
+func NewBundleCmd(opts BundleOptions, path string) *BundleCommand {
+	return &BundleCommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (b BundleCommand) Execute(ctx context.Context) error {
+	r, err := raml.ParseFromPathCtx(ctx, b.Path, raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse raml: %w", err)
+	}
+	out, err := raml.NewBundler(r).Bundle()
+	if err != nil {
+		return fmt.Errorf("bundle raml: %w", err)
+	}
+	// The bundled document is parsed in an empty directory to ensure that it does not depend on other files.
+	dir, err := os.MkdirTemp("", "raml-bundle")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err = raml.ParseFromStringCtx(ctx, string(out), "bundle.raml", dir, raml.OptWithValidate()); err != nil {
+		return fmt.Errorf("validate bundle: %w", err)
+	}
+	if b.Opts.Output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err = os.WriteFile(b.Opts.Output, out, 0o600); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	slog.Info("RAML bundled", slog.String("path", b.Path), slog.String("output", b.Opts.Output))
+	return nil
+}
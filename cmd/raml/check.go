@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-raml/v2/check"
+)
+
+const (
+	CheckFormatText = "text"
+	CheckFormatJSON = "json"
+)
+
+// checkStdin is the input name that reads documents from the standard input.
+const checkStdin = "-"
+
+type CheckOptions struct {
+	Type        string
+	Format      string
+	InputFormat string
+}
+
+type CheckCommand struct {
+	Opts   CheckOptions
+	Inputs []string
+}
+
+func NewCheckCmd(opts CheckOptions, inputs []string) *CheckCommand {
+	return &CheckCommand{
+		Opts:   opts,
+		Inputs: inputs,
+	}
+}
+
+func (c CheckCommand) Execute(ctx context.Context) error {
+	if c.Opts.Format != CheckFormatText && c.Opts.Format != CheckFormatJSON {
+		return fmt.Errorf("unsupported format %q", c.Opts.Format)
+	}
+	path, name, _ := strings.Cut(c.Opts.Type, "#")
+	slog.Debug("Parsing RAML...", slog.String("path", path))
+	r, err := raml.ParseFromPathCtx(ctx, path, raml.OptWithUnwrap())
+	if err != nil {
+		return fmt.Errorf("parse raml %s: %w", path, err)
+	}
+	shape, err := check.ResolveType(r, name)
+	if err != nil {
+		return err
+	}
+
+	files, err := expandInputs(c.Inputs)
+	if err != nil {
+		return err
+	}
+	results := []check.Result{}
+	for _, file := range files {
+		slog.Debug("Checking documents...", slog.String("path", file))
+		docs, errDecode := c.decodeFile(file)
+		if errDecode != nil {
+			return errDecode
+		}
+		for _, doc := range docs {
+			results = append(results, check.Validate(shape, file, doc))
+		}
+	}
+	if err = writeResults(os.Stdout, c.Opts.Format, results); err != nil {
+		return err
+	}
+
+	invalidCount := 0
+	for _, res := range results {
+		if !res.Valid {
+			invalidCount++
+		}
+	}
+	if invalidCount > 0 {
+		return fmt.Errorf("%d of %d documents are invalid", invalidCount, len(results))
+	}
+	return nil
+}
+
+func (c CheckCommand) decodeFile(file string) ([]check.Document, error) {
+	format := c.Opts.InputFormat
+	if format == "" {
+		format = check.FormatFromPath(file)
+	}
+	if file == checkStdin {
+		docs, err := check.Decode(os.Stdin, format)
+		if err != nil {
+			return nil, fmt.Errorf("decode stdin: %w", err)
+		}
+		return docs, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+	docs, err := check.Decode(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", file, err)
+	}
+	return docs, nil
+}
+
+// expandInputs expands glob patterns of the inputs. Each pattern must match at least one file.
+func expandInputs(inputs []string) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		if input == checkStdin {
+			files = append(files, input)
+			continue
+		}
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("expand %s: %w", input, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", input)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func writeResults(w io.Writer, format string, results []check.Result) error {
+	if format == CheckFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("encode results: %w", err)
+		}
+		return nil
+	}
+	for _, res := range results {
+		if _, err := fmt.Fprintln(w, res.String()); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+		for _, f := range res.Failures {
+			if _, err := fmt.Fprintln(w, "  "+f.String()); err != nil {
+				return fmt.Errorf("write result: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-stacktrace/slogex"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+type ConvertOpenAPIOptions struct {
+	Output string
+	Format string
+}
+
+type ConvertOpenAPICommand struct {
+	Opts ConvertOpenAPIOptions
+	Path string
+}
+
+func NewConvertOpenAPICmd(opts ConvertOpenAPIOptions, path string) *ConvertOpenAPICommand {
+	return &ConvertOpenAPICommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (c ConvertOpenAPICommand) Execute(ctx context.Context) error {
+	if c.Opts.Format != FormatJSON && c.Opts.Format != FormatYAML {
+		return fmt.Errorf("unsupported format %q", c.Opts.Format)
+	}
+	r, err := raml.ParseFromPathCtx(ctx, c.Path, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse raml: %w", err)
+	}
+	conv := raml.NewOpenAPIConverter(r)
+	doc, err := conv.Convert()
+	if err != nil {
+		return fmt.Errorf("convert to openapi: %w", err)
+	}
+	for _, w := range conv.Warnings() {
+		slog.Warn("Lossy conversion", slogex.ErrToSlogAttr(w))
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+	if c.Opts.Format == FormatYAML {
+		if out, err = jsonToYAML(out); err != nil {
+			return fmt.Errorf("marshal yaml: %w", err)
+		}
+	} else {
+		out = append(out, '\n')
+	}
+	if c.Opts.Output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err = os.WriteFile(c.Opts.Output, out, 0o600); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	slog.Info("OpenAPI document generated", slog.String("path", c.Path), slog.String("output", c.Opts.Output))
+	return nil
+}
+
+// jsonToYAML converts the JSON document to YAML keeping the order of keys.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetStyle resets the JSON style of the node, so collections are written in block style
+// and strings are quoted only if needed.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-raml/v2/diff"
+)
+
+const (
+	DiffFormatText = "text"
+	DiffFormatJSON = "json"
+)
+
+// ExitCodeBreakingChanges is the exit code of the diff command when breaking changes are found.
+// Other failures, such as parse errors, exit with 1.
+const ExitCodeBreakingChanges = 2
+
+type DiffOptions struct {
+	Format string
+}
+
+type DiffCommand struct {
+	Opts    DiffOptions
+	OldPath string
+	NewPath string
+}
+
+func NewDiffCmd(opts DiffOptions, oldPath, newPath string) *DiffCommand {
+	return &DiffCommand{
+		Opts:    opts,
+		OldPath: oldPath,
+		NewPath: newPath,
+	}
+}
+
+func (d DiffCommand) Execute(ctx context.Context) error {
+	if d.Opts.Format != DiffFormatText && d.Opts.Format != DiffFormatJSON {
+		return fmt.Errorf("unsupported format %q", d.Opts.Format)
+	}
+	slog.Debug("Parsing old RAML...", slog.String("path", d.OldPath))
+	oldRAML, err := raml.ParseFromPathCtx(ctx, d.OldPath, raml.OptWithUnwrap())
+	if err != nil {
+		return fmt.Errorf("parse raml %s: %w", d.OldPath, err)
+	}
+	slog.Debug("Parsing new RAML...", slog.String("path", d.NewPath))
+	newRAML, err := raml.ParseFromPathCtx(ctx, d.NewPath, raml.OptWithUnwrap())
+	if err != nil {
+		return fmt.Errorf("parse raml %s: %w", d.NewPath, err)
+	}
+
+	changes := diff.Compare(oldRAML, newRAML)
+	if wd, errWd := os.Getwd(); errWd == nil {
+		for i := range changes {
+			if rel, errRel := filepath.Rel(wd, changes[i].Location); errRel == nil && filepath.IsLocal(rel) {
+				changes[i].Location = rel
+			}
+		}
+	}
+	if err = writeChanges(os.Stdout, d.Opts.Format, changes); err != nil {
+		return err
+	}
+
+	breakingCount := 0
+	for _, c := range changes {
+		if c.Breaking {
+			breakingCount++
+		}
+	}
+	if breakingCount > 0 {
+		return &ExitCodeError{
+			Code: ExitCodeBreakingChanges,
+			Err:  fmt.Errorf("%d of %d changes are breaking", breakingCount, len(changes)),
+		}
+	}
+	return nil
+}
+
+func writeChanges(w io.Writer, format string, changes []diff.Change) error {
+	if format == DiffFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changes); err != nil {
+			return fmt.Errorf("encode changes: %w", err)
+		}
+		return nil
+	}
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return fmt.Errorf("write change: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+type GenGoOptions struct {
+	Output  string
+	Package string
+}
+
+type GenGoCommand struct {
+	Opts GenGoOptions
+	Path string
+}
+
+func NewGenGoCmd(opts GenGoOptions, path string) *GenGoCommand {
+	return &GenGoCommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (g GenGoCommand) Execute(ctx context.Context) error {
+	r, err := raml.ParseFromPathCtx(ctx, g.Path, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse raml: %w", err)
+	}
+	src, err := raml.NewGoGenerator(r, raml.WithPackageName(g.Opts.Package)).Generate()
+	if err != nil {
+		return fmt.Errorf("generate go: %w", err)
+	}
+	if g.Opts.Output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	if err = os.WriteFile(g.Opts.Output, src, 0o600); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	slog.Info("Go types generated", slog.String("path", g.Path), slog.String("output", g.Opts.Output))
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-stacktrace/slogex"
+)
+
+type ImportOpenAPIOptions struct {
+	Output string
+	Force  bool
+}
+
+type ImportOpenAPICommand struct {
+	Opts ImportOpenAPIOptions
+	Path string
+}
+
+func NewImportOpenAPICmd(opts ImportOpenAPIOptions, path string) *ImportOpenAPICommand {
+	return &ImportOpenAPICommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (c ImportOpenAPICommand) Execute(ctx context.Context) error {
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return fmt.Errorf("read openapi: %w", err)
+	}
+	output, err := filepath.Abs(c.Opts.Output)
+	if err != nil {
+		return fmt.Errorf("resolve output path: %w", err)
+	}
+	im := raml.NewOpenAPIImporter(raml.New(ctx))
+	api, err := im.Import(data, output)
+	if err != nil {
+		return fmt.Errorf("import openapi: %w", err)
+	}
+	for _, w := range im.Warnings() {
+		slog.Warn("Lossy conversion", slogex.ErrToSlogAttr(w))
+	}
+	var apiBuf bytes.Buffer
+	if err = raml.NewEncoder(&apiBuf).EncodeAPI(api); err != nil {
+		return fmt.Errorf("encode api: %w", err)
+	}
+	files := map[string][]byte{
+		filepath.Base(output): apiBuf.Bytes(),
+	}
+	// The types library is used only when the document has types.
+	if lib, ok := api.Uses.Get(raml.OpenAPITypesAlias); ok {
+		var typesBuf bytes.Buffer
+		if err = raml.NewEncoder(&typesBuf).EncodeLibrary(lib.Link); err != nil {
+			return fmt.Errorf("encode types: %w", err)
+		}
+		files[lib.Value] = typesBuf.Bytes()
+	}
+	if !c.Opts.Force {
+		for name := range files {
+			path := filepath.Join(filepath.Dir(output), name)
+			if _, err = os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite", path)
+			}
+		}
+	}
+
+	// Documents are parsed in a temporary directory to ensure that they are valid before they are written.
+	dir, err := os.MkdirTemp("", "raml-import")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	if _, err = raml.ParseFromPathCtx(ctx, filepath.Join(dir, filepath.Base(output)), raml.OptWithValidate()); err != nil {
+		return fmt.Errorf("validate imported api: %w", err)
+	}
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(filepath.Dir(output), name), content, 0o600); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	slog.Info("OpenAPI document imported", slog.String("path", c.Path), slog.String("output", c.Opts.Output))
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-raml/v2/lint"
+)
+
+const (
+	LintFormatText = "text"
+	LintFormatJSON = "json"
+)
+
+type LintOptions struct {
+	Config string
+	Format string
+}
+
+type LintCommand struct {
+	Opts LintOptions
+	Args []string
+}
+
+func NewLintCmd(opts LintOptions, args []string) *LintCommand {
+	return &LintCommand{
+		Opts: opts,
+		Args: args,
+	}
+}
+
+func (l LintCommand) Execute(ctx context.Context) error {
+	if l.Opts.Format != LintFormatText && l.Opts.Format != LintFormatJSON {
+		return fmt.Errorf("unsupported format %q", l.Opts.Format)
+	}
+	configPath := l.Opts.Config
+	if configPath == "" {
+		var err error
+		if configPath, err = lint.FindConfig(filepath.Dir(l.Args[0])); err != nil {
+			return fmt.Errorf("find config: %w", err)
+		}
+	}
+	var config *lint.Config
+	if configPath != "" {
+		var err error
+		if config, err = lint.LoadConfig(configPath); err != nil {
+			return err
+		}
+		slog.Debug("Using lint config", slog.String("path", configPath))
+	}
+	linter, err := lint.New(config)
+	if err != nil {
+		return fmt.Errorf("new linter: %w", err)
+	}
+
+	issues := []lint.Issue{}
+	for _, arg := range l.Args {
+		slog.Debug("Linting RAML...", slog.String("path", arg))
+		r, errParse := raml.ParseFromPathCtx(ctx, arg)
+		if errParse != nil {
+			return fmt.Errorf("parse raml %s: %w", arg, errParse)
+		}
+		issues = append(issues, linter.Lint(r)...)
+	}
+	issues = uniqueIssues(issues)
+	if wd, errWd := os.Getwd(); errWd == nil {
+		for i := range issues {
+			if rel, errRel := filepath.Rel(wd, issues[i].Location); errRel == nil && filepath.IsLocal(rel) {
+				issues[i].Location = rel
+			}
+		}
+	}
+	if err = writeIssues(os.Stdout, l.Opts.Format, issues); err != nil {
+		return err
+	}
+
+	errorsCount := 0
+	for _, issue := range issues {
+		if issue.Severity == lint.SeverityError {
+			errorsCount++
+		}
+	}
+	if errorsCount > 0 {
+		return fmt.Errorf("%d of %d issues are errors", errorsCount, len(issues))
+	}
+	return nil
+}
+
+// uniqueIssues removes duplicates of issues in libraries shared by linted documents.
+func uniqueIssues(issues []lint.Issue) []lint.Issue {
+	seen := make(map[lint.Issue]struct{}, len(issues))
+	res := issues[:0]
+	for _, issue := range issues {
+		if _, ok := seen[issue]; ok {
+			continue
+		}
+		seen[issue] = struct{}{}
+		res = append(res, issue)
+	}
+	return res
+}
+
+func writeIssues(w io.Writer, format string, issues []lint.Issue) error {
+	if format == LintFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return fmt.Errorf("encode issues: %w", err)
+		}
+		return nil
+	}
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, issue.String()); err != nil {
+			return fmt.Errorf("write issue: %w", err)
+		}
+	}
+	return nil
+}
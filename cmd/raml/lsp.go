@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml/v2/lsp"
+)
+
+type LSPOptions struct{}
+
+type LSPCommand struct {
+	Opts LSPOptions
+}
+
+func NewLSPCmd(opts LSPOptions) *LSPCommand {
+	return &LSPCommand{
+		Opts: opts,
+	}
+}
+
+func (l LSPCommand) Execute(ctx context.Context) error {
+	// Stdout is the protocol channel, logs are written to stderr.
+	slog.Debug("Serving language server on stdio...")
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(ctx); err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
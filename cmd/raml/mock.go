@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+const mockShutdownTimeout = 5 * time.Second
+
+type MockOptions struct {
+	Host string
+	Port int
+}
+
+type MockCommand struct {
+	Opts MockOptions
+	Path string
+}
+
+func NewMockCmd(opts MockOptions, path string) *MockCommand {
+	return &MockCommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (m MockCommand) Execute(ctx context.Context) error {
+	r, err := raml.ParseFromPathCtx(ctx, m.Path, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse raml: %w", err)
+	}
+	handler, err := raml.NewMockHandler(r)
+	if err != nil {
+		return fmt.Errorf("new mock handler: %w", err)
+	}
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(m.Opts.Host, strconv.Itoa(m.Opts.Port)),
+		Handler:           logRequests(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), mockShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("Serving mock API...", slog.String("path", m.Path), slog.String("addr", srv.Addr))
+	if err = srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("listen and serve: %w", err)
+	}
+	return nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rec *statusRecorder) WriteHeader(statusCode int) {
+	rec.statusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rec, req)
+		slog.Info("Request served", slog.String("method", req.Method), slog.String("path", req.URL.Path),
+			slog.Int("status", rec.statusCode))
+	})
+}
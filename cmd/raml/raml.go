@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/acronis/go-raml/v2/lint"
 	"github.com/acronis/go-stacktrace"
 	"github.com/acronis/go-stacktrace/slogex"
 	"github.com/spf13/cobra"
@@ -33,6 +34,20 @@ func NewCommandError(err error, msg string) error {
 	return nil
 }
 
+// ExitCodeError is returned by commands that exit with a specific code.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
 type Command interface {
 	Execute(ctx context.Context) error
 }
@@ -68,6 +83,175 @@ func mainFn() int {
 				return InitLoggingAndRun(ctx, verbosity, NewValidateCmd(opts, args))
 			},
 		}
+		cmd.Flags().StringVarP(&opts.Format, "format", "f", ValidateFormatText,
+			"output format: text, json, sarif, junit or checkstyle")
+
+		return cmd
+	}()
+
+	cmdMock := func() *cobra.Command {
+		var opts MockOptions
+		cmd := &cobra.Command{
+			Use:   "mock <path_to_api>.raml",
+			Short: "serve a mock server that responds with examples declared in the api",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewMockCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVar(&opts.Host, "host", "", "host to listen on")
+		cmd.Flags().IntVarP(&opts.Port, "port", "p", 8080, "port to listen on")
+
+		return cmd
+	}()
+
+	cmdGen := func() *cobra.Command {
+		cmd := &cobra.Command{
+			Use:   "gen",
+			Short: "generate code from raml types",
+		}
+
+		var goOpts GenGoOptions
+		cmdGo := &cobra.Command{
+			Use:   "go <path_to_library>.raml",
+			Short: "generate go types",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewGenGoCmd(goOpts, args[0]))
+			},
+		}
+		cmdGo.Flags().StringVarP(&goOpts.Output, "output", "o", "", "output file, stdout by default")
+		cmdGo.Flags().StringVar(&goOpts.Package, "package", "types", "package name of the generated file")
+
+		cmd.AddCommand(cmdGo)
+		return cmd
+	}()
+
+	cmdConvert := func() *cobra.Command {
+		cmd := &cobra.Command{
+			Use:   "convert",
+			Short: "convert raml to other formats",
+		}
+
+		var openAPIOpts ConvertOpenAPIOptions
+		cmdOpenAPI := &cobra.Command{
+			Use:   "openapi <path_to_api>.raml",
+			Short: "convert api to openapi 3.1",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewConvertOpenAPICmd(openAPIOpts, args[0]))
+			},
+		}
+		cmdOpenAPI.Flags().StringVarP(&openAPIOpts.Output, "output", "o", "", "output file, stdout by default")
+		cmdOpenAPI.Flags().StringVarP(&openAPIOpts.Format, "format", "f", FormatJSON, "output format: json or yaml")
+
+		cmd.AddCommand(cmdOpenAPI)
+		return cmd
+	}()
+
+	cmdImport := func() *cobra.Command {
+		cmd := &cobra.Command{
+			Use:   "import",
+			Short: "import raml from other formats",
+		}
+
+		var openAPIOpts ImportOpenAPIOptions
+		cmdOpenAPI := &cobra.Command{
+			Use:   "openapi <path_to_openapi>",
+			Short: "import api from openapi 3.0 or 3.1 in json or yaml",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewImportOpenAPICmd(openAPIOpts, args[0]))
+			},
+		}
+		cmdOpenAPI.Flags().StringVarP(&openAPIOpts.Output, "output", "o", "api.raml",
+			"output api file, the types library <name>-types.raml is written next to it")
+		cmdOpenAPI.Flags().BoolVar(&openAPIOpts.Force, "force", false, "overwrite existing output files")
+
+		cmd.AddCommand(cmdOpenAPI)
+		return cmd
+	}()
+
+	cmdBundle := func() *cobra.Command {
+		var opts BundleOptions
+		cmd := &cobra.Command{
+			Use:   "bundle <path_to_api>.raml",
+			Short: "bundle raml with its libraries and included files into a single file",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewBundleCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "output file, stdout by default")
+
+		return cmd
+	}()
+
+	cmdLint := func() *cobra.Command {
+		var opts LintOptions
+		cmd := &cobra.Command{
+			Use:   "lint <path_to_raml>.raml...",
+			Short: "check raml files against style rules",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewLintCmd(opts, args))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Config, "config", "c", "",
+			"config file, "+lint.ConfigFileName+" in the directory of the first file or its parents by default")
+		cmd.Flags().StringVarP(&opts.Format, "format", "f", LintFormatText, "output format: text or json")
+
+		return cmd
+	}()
+
+	cmdDiff := func() *cobra.Command {
+		var opts DiffOptions
+		cmd := &cobra.Command{
+			Use:   "diff <path_to_old_raml>.raml <path_to_new_raml>.raml",
+			Short: "compare two versions of a raml file and report breaking changes",
+			Long: "Compare two versions of a raml file and report breaking changes.\n\n" +
+				"Exits with 2 if breaking changes are found and with 1 if the command fails, " +
+				"e.g. because a file cannot be parsed.",
+			Args: cobra.ExactArgs(2),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewDiffCmd(opts, args[0], args[1]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Format, "format", "f", DiffFormatText, "output format: text or json")
+
+		return cmd
+	}()
+
+	cmdCheck := func() *cobra.Command {
+		var opts CheckOptions
+		cmd := &cobra.Command{
+			Use:   "check --type <path_to_raml>.raml#<type> <path_to_data>...",
+			Short: "validate json or yaml documents against a raml type",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewCheckCmd(opts, args))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Type, "type", "t", "",
+			"type to check against: path to raml file and type name separated by #")
+		cmd.Flags().StringVarP(&opts.Format, "format", "f", CheckFormatText, "output format: text or json")
+		cmd.Flags().StringVarP(&opts.InputFormat, "input-format", "i", "",
+			"input format: json, ndjson or yaml (detected by file extension by default)")
+		_ = cmd.MarkFlagRequired("type")
+
+		return cmd
+	}()
+
+	cmdLSP := func() *cobra.Command {
+		var opts LSPOptions
+		cmd := &cobra.Command{
+			Use:   "lsp",
+			Short: "serve a language server for raml files on stdio",
+			Args:  cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewLSPCmd(opts))
+			},
+		}
 
 		return cmd
 	}()
@@ -89,6 +273,15 @@ func mainFn() int {
 
 		cmd.AddCommand(
 			cmdValidate,
+			cmdMock,
+			cmdGen,
+			cmdBundle,
+			cmdConvert,
+			cmdImport,
+			cmdLint,
+			cmdDiff,
+			cmdCheck,
+			cmdLSP,
 		)
 		return cmd
 	}()
@@ -104,6 +297,10 @@ func mainFn() int {
 		} else {
 			_ = rootCmd.Usage()
 		}
+		var exitErr *ExitCodeError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
 		return 1
 	}
 
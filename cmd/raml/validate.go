@@ -4,14 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/acronis/go-raml/v2"
+	"github.com/acronis/go-raml/v2/report"
 	"github.com/acronis/go-stacktrace"
 	"github.com/acronis/go-stacktrace/slogex"
 )
 
+const ValidateFormatText = "text"
+
+// Exit codes of the validate command. Parse errors take precedence over validation errors.
+const (
+	ExitCodeValidationErrors = 2
+	ExitCodeParseErrors      = 3
+)
+
 type ValidateOptions struct {
 	EnsureDuplicates bool
+	Format           string
 }
 
 type ValidateCommand struct {
@@ -27,22 +39,70 @@ func NewValidateCmd(opts ValidateOptions, args []string) *ValidateCommand {
 }
 
 func (v ValidateCommand) Execute(ctx context.Context) error {
-	var err error
+	switch v.Opts.Format {
+	case ValidateFormatText, report.FormatJSON, report.FormatSARIF, report.FormatJUnit, report.FormatCheckstyle:
+	default:
+		return fmt.Errorf("unsupported format %q", v.Opts.Format)
+	}
 	var stOpts []stacktrace.TracesOpt
 	if v.Opts.EnsureDuplicates {
 		stOpts = append(stOpts, stacktrace.WithEnsureDuplicates())
 	}
+	results := make([]report.Result, 0, len(v.Args))
+	exitCode := 0
 	for _, arg := range v.Args {
 		slog.Info("Validating RAML...", slog.String("path", arg))
-		_, err = raml.ParseFromPathCtx(ctx, arg, raml.OptWithUnwrap(), raml.OptWithValidate())
+		code, err := validateFile(ctx, arg)
 		if err != nil {
-			slog.Error("RAML is invalid", slogex.ErrToSlogAttr(err, stOpts...))
+			if v.Opts.Format == ValidateFormatText {
+				slog.Error("RAML is invalid", slogex.ErrToSlogAttr(err, stOpts...))
+			}
+			if code > exitCode {
+				exitCode = code
+			}
 		} else {
 			slog.Info("RAML is valid", slog.String("path", arg))
 		}
+		results = append(results, report.Result{File: arg, Problems: report.Problems(err, arg)})
 	}
-	if err != nil {
-		return fmt.Errorf("errors have been found in the RAML files")
+	if v.Opts.Format != ValidateFormatText {
+		relativeProblems(results)
+		if err := report.Write(os.Stdout, v.Opts.Format, results); err != nil {
+			return err
+		}
+	}
+	if exitCode != 0 {
+		return &ExitCodeError{Code: exitCode, Err: fmt.Errorf("errors have been found in the RAML files")}
 	}
 	return nil
 }
+
+// validateFile parses and validates the file separately, so that parse errors are distinguished from validation
+// errors. Returns the exit code for the error.
+func validateFile(ctx context.Context, path string) (int, error) {
+	r, err := raml.ParseFromPathCtx(ctx, path, raml.OptWithUnwrap())
+	if err != nil {
+		return ExitCodeParseErrors, err
+	}
+	if err = r.ValidateShapes(); err != nil {
+		return ExitCodeValidationErrors, raml.StacktraceNewWrapped("validate shapes", err,
+			r.EntryPoint().GetLocation(), stacktrace.WithType(raml.StacktraceTypeValidating))
+	}
+	return 0, nil
+}
+
+// relativeProblems makes files of problems relative to the working directory, as expected by CI systems.
+func relativeProblems(results []report.Result) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	for i := range results {
+		for j := range results[i].Problems {
+			p := &results[i].Problems[j]
+			if rel, errRel := filepath.Rel(wd, p.File); errRel == nil && filepath.IsLocal(rel) {
+				p.File = rel
+			}
+		}
+	}
+}
@@ -121,6 +121,12 @@ func (s *ArrayShape) validate(v interface{}, ctxPath string) error {
 
 // Inherit merges the source shape into the target shape.
 func (s *ArrayShape) inherit(source Shape) (Shape, error) {
+	return s.inheritNested(source, make(map[*BaseShape]struct{}))
+}
+
+// inheritNested merges the source shape into the target shape.
+// Visited contains source shapes on the current inheritance path.
+func (s *ArrayShape) inheritNested(source Shape, visited map[*BaseShape]struct{}) (Shape, error) {
 	ss, ok := source.(*ArrayShape)
 	if !ok {
 		return nil, StacktraceNew("cannot inherit from different type", s.Location,
@@ -130,7 +136,7 @@ func (s *ArrayShape) inherit(source Shape) (Shape, error) {
 	if s.Items == nil {
 		s.Items = ss.Items
 	} else if ss.Items != nil {
-		_, err := s.Items.Inherit(ss.Items)
+		_, err := s.Items.inheritFrom(ss.Items, visited)
 		if err != nil {
 			return nil, StacktraceNewWrapped("merge array items", err, s.Location,
 				stacktrace.WithPosition(&s.Items.Position))
@@ -238,6 +244,9 @@ type ObjectShape struct {
 	*BaseShape
 
 	ObjectFacets
+
+	// aliasedTypeName is the name of the type this shape is an alias of, used as the default discriminator value.
+	aliasedTypeName string
 }
 
 func (s *ObjectShape) unmarshalPatternProperties(
@@ -346,6 +355,12 @@ func (s *ObjectShape) Base() *BaseShape {
 	return s.BaseShape
 }
 
+// GetDiscriminatorValue returns the value of the discriminator property that identifies the object type.
+// If discriminatorValue is not set, the name of the type is returned.
+func (s *ObjectShape) GetDiscriminatorValue() interface{} {
+	return discriminatorValueOf(s.BaseShape)
+}
+
 func (s *ObjectShape) cloneShallow(base *BaseShape) Shape {
 	c := *s
 	c.BaseShape = base
@@ -389,6 +404,10 @@ func (s *ObjectShape) alias(source Shape) (Shape, error) {
 	s.AdditionalProperties = ss.AdditionalProperties
 	s.Discriminator = ss.Discriminator
 	s.DiscriminatorValue = ss.DiscriminatorValue
+	s.aliasedTypeName = ss.aliasedTypeName
+	if s.aliasedTypeName == "" {
+		s.aliasedTypeName = ss.Name
+	}
 	return s, nil
 }
 
@@ -531,7 +550,7 @@ func (s *ObjectShape) inheritMaxProperties(source *ObjectShape) error {
 	return nil
 }
 
-func (s *ObjectShape) inheritProperties(source *ObjectShape) error {
+func (s *ObjectShape) inheritProperties(source *ObjectShape, visited map[*BaseShape]struct{}) error {
 	if s.Properties == nil {
 		s.Properties = source.Properties
 		return nil
@@ -552,7 +571,7 @@ func (s *ObjectShape) inheritProperties(source *ObjectShape) error {
 					stacktrace.WithInfo("target", targetProp.Required),
 					stacktrace.WithType(StacktraceTypeUnwrapping))
 			}
-			_, err := targetProp.Base.Inherit(sourceProp.Base)
+			_, err := targetProp.Base.inheritFrom(sourceProp.Base, visited)
 			if err != nil {
 				return StacktraceNewWrapped("inherit property", err, s.Location,
 					stacktrace.WithPosition(&targetProp.Base.Position),
@@ -566,7 +585,7 @@ func (s *ObjectShape) inheritProperties(source *ObjectShape) error {
 	return nil
 }
 
-func (s *ObjectShape) inheritPatternProperties(source *ObjectShape) error {
+func (s *ObjectShape) inheritPatternProperties(source *ObjectShape, visited map[*BaseShape]struct{}) error {
 	if s.PatternProperties == nil {
 		s.PatternProperties = source.PatternProperties
 		return nil
@@ -575,7 +594,7 @@ func (s *ObjectShape) inheritPatternProperties(source *ObjectShape) error {
 		for pair := source.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
 			k, sourceProp := pair.Key, pair.Value
 			if targetProp, present := s.PatternProperties.Get(k); present {
-				_, err := targetProp.Base.Inherit(sourceProp.Base)
+				_, err := targetProp.Base.inheritFrom(sourceProp.Base, visited)
 				if err != nil {
 					return StacktraceNewWrapped("inherit pattern property", err, s.Location,
 						stacktrace.WithPosition(&targetProp.Base.Position),
@@ -592,6 +611,12 @@ func (s *ObjectShape) inheritPatternProperties(source *ObjectShape) error {
 
 // Inherit merges the source shape into the target shape.
 func (s *ObjectShape) inherit(source Shape) (Shape, error) {
+	return s.inheritNested(source, make(map[*BaseShape]struct{}))
+}
+
+// inheritNested merges the source shape into the target shape.
+// Visited contains source shapes on the current inheritance path.
+func (s *ObjectShape) inheritNested(source Shape, visited map[*BaseShape]struct{}) (Shape, error) {
 	if ss, ok := source.(*RecursiveShape); ok {
 		source = ss.Head.Shape
 	}
@@ -619,11 +644,11 @@ func (s *ObjectShape) inherit(source Shape) (Shape, error) {
 		return nil, fmt.Errorf("inherit maxProperties: %w", err)
 	}
 
-	if err := s.inheritProperties(ss); err != nil {
+	if err := s.inheritProperties(ss, visited); err != nil {
 		return nil, fmt.Errorf("inherit properties: %w", err)
 	}
 
-	if err := s.inheritPatternProperties(ss); err != nil {
+	if err := s.inheritPatternProperties(ss, visited); err != nil {
 		return nil, fmt.Errorf("inherit pattern properties: %w", err)
 	}
 
@@ -845,6 +870,9 @@ func (s *UnionShape) alias(source Shape) (Shape, error) {
 }
 
 func (s *UnionShape) validate(v interface{}, ctxPath string) error {
+	if discriminator, ok := s.Discriminator(); ok {
+		return s.validateDiscriminated(v, ctxPath, discriminator)
+	}
 	st := StacktraceNew("value does not match any type", s.Location,
 		stacktrace.WithPosition(&s.Position))
 	var err error
@@ -869,8 +897,133 @@ func (s *UnionShape) validate(v interface{}, ctxPath string) error {
 	return st
 }
 
+// validateDiscriminated validates the value only against the member selected by the discriminator value.
+func (s *UnionShape) validateDiscriminated(v interface{}, ctxPath string, discriminator string) error {
+	member, err := s.discriminatedMember(v, discriminator)
+	if err != nil {
+		return err
+	}
+	if err = member.Shape.validate(v, ctxPath); err != nil {
+		return StacktraceNewWrapped("validate union member", err, s.Location,
+			stacktrace.WithPosition(&member.Position),
+			stacktrace.WithInfo("discriminator", discriminator),
+			stacktrace.WithInfo("discriminatorValue", discriminatorValueOf(member)))
+	}
+	return nil
+}
+
+// Discriminator returns the discriminator property shared by all union members.
+// Returns false if at least one member is not an object or members have different discriminators.
+func (s *UnionShape) Discriminator() (string, bool) {
+	var discriminator string
+	for _, member := range s.AnyOf {
+		obj, ok := unionMemberObject(member)
+		if !ok || obj.Discriminator == nil {
+			return "", false
+		}
+		if discriminator != "" && discriminator != *obj.Discriminator {
+			return "", false
+		}
+		discriminator = *obj.Discriminator
+	}
+	return discriminator, discriminator != ""
+}
+
+// Member returns the union member the value belongs to.
+// For discriminated unions the member is selected by the discriminator value,
+// otherwise the first member that the value is valid against is returned.
+func (s *UnionShape) Member(v interface{}) (*BaseShape, error) {
+	if discriminator, ok := s.Discriminator(); ok {
+		return s.discriminatedMember(v, discriminator)
+	}
+	for _, item := range s.AnyOf {
+		if err := item.Shape.validate(v, "$"); err == nil {
+			return item, nil
+		}
+	}
+	return nil, StacktraceNew("value does not match any type", s.Location,
+		stacktrace.WithPosition(&s.Position))
+}
+
+func (s *UnionShape) discriminatedMember(v interface{}, discriminator string) (*BaseShape, error) {
+	props, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, StacktraceNew(fmt.Sprintf("invalid type, got %T, expected map[string]interface{}", v),
+			s.Location, stacktrace.WithPosition(&s.Position))
+	}
+	value, ok := props[discriminator]
+	if !ok {
+		return nil, StacktraceNew("discriminator property not found", s.Location,
+			stacktrace.WithPosition(&s.Position),
+			stacktrace.WithInfo("discriminator", discriminator))
+	}
+	allowed := make([]interface{}, 0, len(s.AnyOf))
+	for _, member := range s.AnyOf {
+		memberValue := discriminatorValueOf(member)
+		if isSameDiscriminatorValue(memberValue, value) {
+			return member, nil
+		}
+		allowed = append(allowed, memberValue)
+	}
+	return nil, StacktraceNew("unknown discriminator value", s.Location,
+		stacktrace.WithPosition(&s.Position),
+		stacktrace.WithInfo("discriminator", discriminator),
+		stacktrace.WithInfo("value", value),
+		stacktrace.WithInfo("allowed_values", allowed))
+}
+
+// unionMemberObject returns the object shape of the union member, following recursive references.
+func unionMemberObject(member *BaseShape) (*ObjectShape, bool) {
+	s := member.Shape
+	if rs, ok := s.(*RecursiveShape); ok {
+		s = rs.Head.Shape
+	}
+	obj, ok := s.(*ObjectShape)
+	return obj, ok
+}
+
+// discriminatorValueOf returns the discriminator value of the object type.
+// If discriminatorValue is not set, the name of the type is used.
+func discriminatorValueOf(base *BaseShape) interface{} {
+	if rs, ok := base.Shape.(*RecursiveShape); ok {
+		base = rs.Head
+	}
+	if obj, ok := base.Shape.(*ObjectShape); ok {
+		if obj.DiscriminatorValue != nil {
+			return obj.DiscriminatorValue
+		}
+		if obj.aliasedTypeName != "" {
+			return obj.aliasedTypeName
+		}
+	}
+	// NOTE: References to types with facets are anonymous shapes that inherit from the named type.
+	if base.Name == "" && len(base.Inherits) == 1 {
+		return discriminatorValueOf(base.Inherits[0])
+	}
+	return base.Name
+}
+
+// isSameDiscriminatorValue compares discriminator values, numbers are compared regardless of their Go type.
+func isSameDiscriminatorValue(a, b interface{}) bool {
+	if a == b {
+		return true
+	}
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+	if aIsString || bIsString {
+		return false
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
 // inherit merges the source shape into the target shape.
 func (s *UnionShape) inherit(source Shape) (Shape, error) {
+	return s.inheritNested(source, make(map[*BaseShape]struct{}))
+}
+
+// inheritNested merges the source shape into the target shape.
+// Visited contains source shapes on the current inheritance path.
+func (s *UnionShape) inheritNested(source Shape, visited map[*BaseShape]struct{}) (Shape, error) {
 	ss, ok := source.(*UnionShape)
 	if !ok {
 		return nil, StacktraceNew("cannot inherit from different type", s.Location,
@@ -892,7 +1045,7 @@ func (s *UnionShape) inherit(source Shape) (Shape, error) {
 				cs := targetMember.CloneDetached()
 				// TODO: Probably all copied shapes must change IDs since these are actually new shapes.
 				cs.ID = s.raml.generateShapeID()
-				ms, err := cs.Inherit(sourceMember)
+				ms, err := cs.inheritFrom(sourceMember, visited)
 				if err != nil {
 					// TODO: Collect errors
 					// StacktraceNewWrapped("merge union member", err, s.Location)
@@ -918,6 +1071,21 @@ func (s *UnionShape) check() error {
 				stacktrace.WithPosition(&item.Position))
 		}
 	}
+	if discriminator, ok := s.Discriminator(); ok {
+		seen := make([]interface{}, 0, len(s.AnyOf))
+		for _, item := range s.AnyOf {
+			value := discriminatorValueOf(item)
+			for _, v := range seen {
+				if isSameDiscriminatorValue(v, value) {
+					return StacktraceNew("duplicate discriminator value", s.Location,
+						stacktrace.WithPosition(&item.Position),
+						stacktrace.WithInfo("discriminator", discriminator),
+						stacktrace.WithInfo("value", value))
+				}
+			}
+			seen = append(seen, value)
+		}
+	}
 	return nil
 }
 
@@ -927,6 +1095,8 @@ type JSONShape struct {
 
 	Schema *JSONSchema
 	Raw    string
+
+	validator *jsonSchemaValidator
 }
 
 func (s *JSONShape) Base() *BaseShape {
@@ -945,8 +1115,26 @@ func (s *JSONShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 	return &c
 }
 
-func (s *JSONShape) validate(_ interface{}, _ string) error {
-	// TODO: Implement validation with JSON Schema
+func (s *JSONShape) validate(v interface{}, ctxPath string) error {
+	if s.validator == nil {
+		return nil
+	}
+	violations := s.validator.validate(normalizeJSONSchemaValue(v), ctxPath)
+	var st *stacktrace.StackTrace
+	for _, violation := range violations {
+		se := StacktraceNew(violation.Message, s.Location,
+			stacktrace.WithPosition(&s.Position),
+			stacktrace.WithInfo("path", violation.Path),
+			stacktrace.WithInfo("keyword", violation.Keyword))
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	if st != nil {
+		return st
+	}
 	return nil
 }
 
@@ -968,6 +1156,7 @@ func (s *JSONShape) inherit(source Shape) (Shape, error) {
 	}
 	s.Schema = ss.Schema
 	s.Raw = ss.Raw
+	s.validator = ss.validator
 	return s, nil
 }
 
@@ -981,6 +1170,7 @@ func (s *JSONShape) alias(source Shape) (Shape, error) {
 	}
 	s.Schema = ss.Schema
 	s.Raw = ss.Raw
+	s.validator = ss.validator
 	return s, nil
 }
 
@@ -4,9 +4,11 @@ import (
 	"container/list"
 	"context"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
 )
@@ -2275,7 +2277,7 @@ func TestObjectShape_inheritProperties(t *testing.T) {
 				BaseShape:    tt.fields.BaseShape,
 				ObjectFacets: tt.fields.ObjectFacets,
 			}
-			if err := s.inheritProperties(tt.args.source); (err != nil) != tt.wantErr {
+			if err := s.inheritProperties(tt.args.source, make(map[*BaseShape]struct{})); (err != nil) != tt.wantErr {
 				t.Errorf("inheritProperties() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if tt.want != nil {
@@ -2465,7 +2467,7 @@ func TestObjectShape_inheritPatternProperties(t *testing.T) {
 				BaseShape:    tt.fields.BaseShape,
 				ObjectFacets: tt.fields.ObjectFacets,
 			}
-			if err := s.inheritPatternProperties(tt.args.source); (err != nil) != tt.wantErr {
+			if err := s.inheritPatternProperties(tt.args.source, make(map[*BaseShape]struct{})); (err != nil) != tt.wantErr {
 				t.Errorf("inheritPatternProperties() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if tt.want != nil {
@@ -4669,3 +4671,84 @@ func TestRecursiveShape_alias(t *testing.T) {
 		})
 	}
 }
+
+func TestUnionShape_validateDiscriminated(t *testing.T) {
+	r, err := ParseFromPath("./fixtures/discriminator.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	lib, ok := r.EntryPoint().(*Library)
+	require.True(t, ok)
+	animal, ok := lib.Types.Get("Animal")
+	require.True(t, ok)
+	pets, ok := lib.Types.Get("Pets")
+	require.True(t, ok)
+
+	tests := []struct {
+		name       string
+		shape      *BaseShape
+		value      interface{}
+		wantMember interface{}
+		wantErr    string
+	}{
+		{
+			name:       "positive: discriminator value defaults to type name",
+			shape:      animal,
+			value:      map[string]interface{}{"kind": "Cat", "name": "Tom", "lives": 9},
+			wantMember: "Cat",
+		},
+		{
+			name:       "positive: explicit discriminator value",
+			shape:      animal,
+			value:      map[string]interface{}{"kind": "dog", "name": "Rex", "good": true},
+			wantMember: "dog",
+		},
+		{
+			name:  "positive: array of union",
+			shape: pets,
+			value: []interface{}{
+				map[string]interface{}{"kind": "Cat", "name": "Tom", "lives": 9},
+				map[string]interface{}{"kind": "dog", "name": "Rex", "good": true},
+			},
+		},
+		{
+			name:    "negative: unknown discriminator value",
+			shape:   animal,
+			value:   map[string]interface{}{"kind": "Dog", "name": "Rex", "good": true},
+			wantErr: "unknown discriminator value",
+		},
+		{
+			name:    "negative: missing discriminator",
+			shape:   animal,
+			value:   map[string]interface{}{"name": "Rex"},
+			wantErr: "discriminator property not found",
+		},
+		{
+			name:    "negative: selected member is invalid",
+			shape:   animal,
+			value:   map[string]interface{}{"kind": "dog", "name": "Rex"},
+			wantErr: "missing required properties: good",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.shape.Validate(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				// Only the selected member must be reported.
+				require.LessOrEqual(t, strings.Count(err.Error(), "validate union member"), 1)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantMember == nil {
+				return
+			}
+			union, ok := tt.shape.Shape.(*UnionShape)
+			require.True(t, ok)
+			member, err := union.Member(tt.value)
+			require.NoError(t, err)
+			obj, ok := member.Shape.(*ObjectShape)
+			require.True(t, ok)
+			require.Equal(t, tt.wantMember, obj.GetDiscriminatorValue())
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package diff compares two versions of a RAML document and classifies changes as breaking or non-breaking.
+//
+// Shapes are compared after unwrapping, facet by facet. Whether a changed facet narrows or widens the shape
+// follows the inheritance rules of raml.BaseShape.Inherit: the new shape narrows the old one if it may inherit
+// from the old one, e.g. a tightened maxLength or a removed enum value. Narrowing is breaking for data sent by
+// clients, i.e. declared types, request bodies, parameters and headers, and widening is breaking for data
+// received by clients, i.e. response bodies and headers. Removed types, resources, methods, responses and media
+// types are always breaking.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// Change is a difference between the old and the new version of a document.
+type Change struct {
+	Breaking bool `json:"breaking"`
+	// Path identifies the changed element, e.g. "types.Pet.properties.name" or
+	// "GET /pets responses.200.body.application/json.items".
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Location, Line and Column point to the element in the new version, or in the old version if it was removed.
+	Location string `json:"location"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+func (c Change) String() string {
+	kind := "non-breaking"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s: %s", c.Location, c.Line, c.Column, kind, c.Path, c.Message)
+}
+
+// HasBreaking reports whether any of the changes is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns changes between entry points of the old and the new version of a document, including types
+// of used libraries. Both versions must be parsed with raml.OptWithUnwrap.
+func Compare(oldRAML, newRAML *raml.RAML) []Change {
+	c := &comparer{
+		changes:   []Change{},
+		visited:   make(map[[2]*raml.BaseShape]struct{}),
+		fragments: make(map[[2]string]struct{}),
+	}
+	c.compareFragments("", oldRAML.EntryPoint(), newRAML.EntryPoint())
+	return c.changes
+}
+
+// direction is the direction in which data described by a shape is transferred.
+type direction int
+
+const (
+	// input is data sent by clients. Narrowing of input shapes is breaking.
+	input direction = iota
+	// output is data received by clients. Widening of output shapes is breaking.
+	output
+)
+
+type comparer struct {
+	changes []Change
+	// visited contains pairs of shapes on the current path to stop at recursive shapes.
+	visited map[[2]*raml.BaseShape]struct{}
+	// fragments contains locations of compared fragments to stop at recursive uses of libraries.
+	fragments map[[2]string]struct{}
+}
+
+func (c *comparer) report(breaking bool, path string, location string, pos stacktrace.Position, format string,
+	args ...any) {
+	c.changes = append(c.changes, Change{
+		Breaking: breaking,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Location: location,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	})
+}
+
+func (c *comparer) reportShape(breaking bool, path string, shape *raml.BaseShape, format string, args ...any) {
+	c.report(breaking, path, shape.Location, shape.Position, format, args...)
+}
+
+// narrowed reports a change that narrows the shape.
+func (c *comparer) narrowed(dir direction, path string, shape *raml.BaseShape, format string, args ...any) {
+	c.reportShape(dir == input, path, shape, format, args...)
+}
+
+// widened reports a change that widens the shape.
+func (c *comparer) widened(dir direction, path string, shape *raml.BaseShape, format string, args ...any) {
+	c.reportShape(dir == output, path, shape, format, args...)
+}
+
+func (c *comparer) compareFragments(prefix string, oldFrag, newFrag raml.Fragment) {
+	key := [2]string{oldFrag.GetLocation(), newFrag.GetLocation()}
+	if _, ok := c.fragments[key]; ok {
+		return
+	}
+	c.fragments[key] = struct{}{}
+
+	if oldDT, ok := oldFrag.(*raml.DataType); ok {
+		if newDT, isDT := newFrag.(*raml.DataType); isDT {
+			c.compareShapes(input, "type", oldDT.Shape, newDT.Shape)
+		}
+	}
+	c.compareTypes(prefix, fragmentTypes(oldFrag), fragmentTypes(newFrag))
+
+	oldUses, newUses := fragmentUses(oldFrag), fragmentUses(newFrag)
+	for pair := oldUses.Oldest(); pair != nil; pair = pair.Next() {
+		alias, oldLink := pair.Key, pair.Value
+		newLink, ok := newUses.Get(alias)
+		if !ok {
+			c.report(true, "uses."+prefix+alias, oldLink.Location, oldLink.Position, "library removed")
+			continue
+		}
+		if oldLink.Link != nil && newLink.Link != nil {
+			c.compareFragments(prefix+alias+".", oldLink.Link, newLink.Link)
+		}
+	}
+	for pair := newUses.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldUses.Get(pair.Key); !ok {
+			c.report(false, "uses."+prefix+pair.Key, pair.Value.Location, pair.Value.Position, "library added")
+		}
+	}
+
+	oldAPI, isOldAPI := oldFrag.(*raml.API)
+	newAPI, isNewAPI := newFrag.(*raml.API)
+	if isOldAPI && isNewAPI {
+		c.compareResources(oldAPI, newAPI)
+	}
+}
+
+func (c *comparer) compareTypes(prefix string, oldTypes, newTypes *orderedmap.OrderedMap[string, *raml.BaseShape]) {
+	for pair := oldTypes.Oldest(); pair != nil; pair = pair.Next() {
+		name, oldShape := pair.Key, pair.Value
+		path := "types." + prefix + name
+		newShape, ok := newTypes.Get(name)
+		if !ok {
+			c.reportShape(true, path, oldShape, "type removed")
+			continue
+		}
+		c.compareShapes(input, path, oldShape, newShape)
+	}
+	for pair := newTypes.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldTypes.Get(pair.Key); !ok {
+			c.reportShape(false, "types."+prefix+pair.Key, pair.Value, "type added")
+		}
+	}
+}
+
+func (c *comparer) compareResources(oldAPI, newAPI *raml.API) {
+	oldResources, newResources := flatResources(oldAPI), flatResources(newAPI)
+	for pair := oldResources.Oldest(); pair != nil; pair = pair.Next() {
+		uri, oldRes := pair.Key, pair.Value
+		newRes, ok := newResources.Get(uri)
+		if !ok {
+			// Resources without methods only group nested resources and are not endpoints.
+			if orEmpty(oldRes.Methods).Len() > 0 {
+				c.report(true, uri, oldRes.Location, oldRes.Position, "resource removed")
+			}
+			continue
+		}
+		c.compareResource(uri, oldRes, newRes)
+	}
+	for pair := newResources.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldResources.Get(pair.Key); !ok && orEmpty(pair.Value.Methods).Len() > 0 {
+			c.report(false, pair.Key, pair.Value.Location, pair.Value.Position, "resource added")
+		}
+	}
+}
+
+func (c *comparer) compareResource(uri string, oldRes, newRes *raml.Resource) {
+	c.compareProperties(input, uri+" uriParameters", "URI parameter", oldRes.URIParameters, newRes.URIParameters)
+	for pair := orEmpty(oldRes.Methods).Oldest(); pair != nil; pair = pair.Next() {
+		oldMethod := pair.Value
+		path := strings.ToUpper(pair.Key) + " " + uri
+		newMethod, ok := orEmpty(newRes.Methods).Get(pair.Key)
+		if !ok {
+			c.report(true, path, oldMethod.Location, oldMethod.Position, "method removed")
+			continue
+		}
+		c.compareMethod(path, oldMethod, newMethod)
+	}
+	for pair := orEmpty(newRes.Methods).Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := orEmpty(oldRes.Methods).Get(pair.Key); !ok {
+			c.report(false, strings.ToUpper(pair.Key)+" "+uri, pair.Value.Location, pair.Value.Position,
+				"method added")
+		}
+	}
+}
+
+func (c *comparer) compareMethod(path string, oldMethod, newMethod *raml.Method) {
+	c.compareProperties(input, path+" queryParameters", "query parameter", oldMethod.QueryParameters,
+		newMethod.QueryParameters)
+	if oldMethod.QueryString != nil && newMethod.QueryString != nil {
+		c.compareShapes(input, path+" queryString", oldMethod.QueryString, newMethod.QueryString)
+	}
+	c.compareProperties(input, path+" headers", "header", oldMethod.Headers, newMethod.Headers)
+	c.compareBodies(input, path+" body", oldMethod.Body, newMethod.Body)
+
+	oldResponses, newResponses := orEmpty(oldMethod.Responses), orEmpty(newMethod.Responses)
+	for pair := oldResponses.Oldest(); pair != nil; pair = pair.Next() {
+		code, oldResp := pair.Key, pair.Value
+		respPath := path + " responses." + code
+		newResp, ok := newResponses.Get(code)
+		if !ok {
+			c.report(true, respPath, oldResp.Location, oldResp.Position, "response removed")
+			continue
+		}
+		c.compareProperties(output, respPath+".headers", "header", oldResp.Headers, newResp.Headers)
+		c.compareBodies(output, respPath+".body", oldResp.Body, newResp.Body)
+	}
+	for pair := newResponses.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldResponses.Get(pair.Key); !ok {
+			c.report(false, path+" responses."+pair.Key, pair.Value.Location, pair.Value.Position, "response added")
+		}
+	}
+}
+
+func (c *comparer) compareBodies(dir direction, path string,
+	oldBodies, newBodies *orderedmap.OrderedMap[string, *raml.Body]) {
+	oldBodies, newBodies = orEmpty(oldBodies), orEmpty(newBodies)
+	for pair := oldBodies.Oldest(); pair != nil; pair = pair.Next() {
+		mediaType, oldBody := pair.Key, pair.Value
+		newBody, ok := newBodies.Get(mediaType)
+		if !ok {
+			c.report(true, path+"."+mediaType, oldBody.Location, oldBody.Position, "media type removed")
+			continue
+		}
+		if oldBody.Shape != nil && newBody.Shape != nil {
+			c.compareShapes(dir, path+"."+mediaType, oldBody.Shape, newBody.Shape)
+		}
+	}
+	for pair := newBodies.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldBodies.Get(pair.Key); !ok {
+			c.report(false, path+"."+pair.Key, pair.Value.Location, pair.Value.Position, "media type added")
+		}
+	}
+}
+
+// flatResources returns resources of the API including nested resources by their full URIs.
+func flatResources(api *raml.API) *orderedmap.OrderedMap[string, *raml.Resource] {
+	res := orderedmap.New[string, *raml.Resource]()
+	var walk func(r *raml.Resource)
+	walk = func(r *raml.Resource) {
+		res.Set(r.FullURI(), r)
+		for pair := orEmpty(r.Resources).Oldest(); pair != nil; pair = pair.Next() {
+			walk(pair.Value)
+		}
+	}
+	for pair := orEmpty(api.Resources).Oldest(); pair != nil; pair = pair.Next() {
+		walk(pair.Value)
+	}
+	return res
+}
+
+func fragmentUses(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.LibraryLink] {
+	var uses *orderedmap.OrderedMap[string, *raml.LibraryLink]
+	switch f := frag.(type) {
+	case *raml.API:
+		uses = f.Uses
+	case *raml.Library:
+		uses = f.Uses
+	case *raml.DataType:
+		uses = f.Uses
+	}
+	return orEmpty(uses)
+}
+
+func fragmentTypes(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.BaseShape] {
+	var types *orderedmap.OrderedMap[string, *raml.BaseShape]
+	switch f := frag.(type) {
+	case *raml.API:
+		types = f.Types
+	case *raml.Library:
+		types = f.Types
+	}
+	return orEmpty(types)
+}
+
+// orEmpty returns an empty map if m is nil.
+func orEmpty[V any](m *orderedmap.OrderedMap[string, V]) *orderedmap.OrderedMap[string, V] {
+	if m == nil {
+		return orderedmap.New[string, V]()
+	}
+	return m
+}
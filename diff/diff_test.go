@@ -0,0 +1,243 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+func parse(t *testing.T, dir, name, content string) *raml.RAML {
+	p := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	r, err := raml.ParseFromPath(p, raml.OptWithUnwrap())
+	require.NoError(t, err)
+	return r
+}
+
+type changeKey struct {
+	Breaking bool
+	Path     string
+	Message  string
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []changeKey
+	}{
+		{
+			name: "positive: facets of declared types",
+			old: `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    maxLength: 10
+  Status:
+    type: string
+    enum: [AVAILABLE, SOLD]
+  Count:
+    type: integer
+    minimum: 1
+  Tags:
+    type: array
+    items: string
+  Code:
+    type: string
+    pattern: ^[a-z]+$
+  Removed: string
+`,
+			new: `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    maxLength: 5
+    minLength: 1
+  Status:
+    type: string
+    enum: [AVAILABLE, PENDING]
+  Count:
+    type: integer
+  Tags:
+    type: array
+    items: string
+    uniqueItems: true
+  Code:
+    type: string
+    pattern: ^[a-z0-9]+$
+  Added: string
+`,
+			want: []changeKey{
+				{true, "types.Name", "maxLength narrowed from 10 to 5"},
+				{true, "types.Name", "minLength 1 added"},
+				{true, "types.Status", `enum changed: removed ["SOLD"], added ["PENDING"]`},
+				{false, "types.Count", "minimum 1 removed"},
+				{true, "types.Tags", "uniqueItems narrowed from false to true"},
+				{true, "types.Code", `pattern changed from "^[a-z]+$" to "^[a-z0-9]+$"`},
+				{true, "types.Removed", "type removed"},
+				{false, "types.Added", "type added"},
+			},
+		},
+		{
+			name: "positive: objects and types",
+			old: `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name: string
+      tag?: string
+      age: integer
+      kind: string
+      owner: string
+      nickname: string
+  Status:
+    enum: [A, B]
+`,
+			new: `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    additionalProperties: false
+    properties:
+      name: string
+      tag: string
+      age: string
+      kind: string | nil
+      owner?: string
+      color?: string
+  Status:
+    enum: [A]
+`,
+			want: []changeKey{
+				{true, "types.Pet.properties.tag", "property became required"},
+				{true, "types.Pet.properties.age", "type changed from integer to string"},
+				{false, "types.Pet.properties.kind", "type widened from string to union"},
+				{false, "types.Pet.properties.owner", "property became optional"},
+				{false, "types.Pet.properties.nickname", "required property removed"},
+				{false, "types.Pet.properties.color", "optional property added"},
+				{true, "types.Pet", "additionalProperties changed from true to false"},
+				{true, "types.Status", `enum narrowed: removed ["B"]`},
+			},
+		},
+		{
+			name: "positive: resources",
+			old: `#%RAML 1.0
+title: Pets
+types:
+  Pet:
+    properties:
+      name:
+        type: string
+        maxLength: 10
+/pets:
+  get:
+    queryParameters:
+      limit?: integer
+    responses:
+      200:
+        body:
+          application/json: Pet[]
+  post:
+    body:
+      application/json: Pet
+  /{id}:
+    delete:
+/owners:
+  get:
+`,
+			new: `#%RAML 1.0
+title: Pets
+types:
+  Pet:
+    properties:
+      name:
+        type: string
+        maxLength: 20
+/pets:
+  get:
+    queryParameters:
+      limit: integer
+    responses:
+      200:
+        body:
+          application/json: Pet[]
+      404:
+  post:
+    body:
+      application/json: Pet
+/owners:
+`,
+			want: []changeKey{
+				{false, "types.Pet.properties.name", "maxLength widened from 10 to 20"},
+				{true, "GET /pets queryParameters.limit", "query parameter became required"},
+				{true, "GET /pets responses.200.body.application/json.items.properties.name",
+					"maxLength widened from 10 to 20"},
+				{false, "GET /pets responses.404", "response added"},
+				{false, "POST /pets body.application/json.properties.name", "maxLength widened from 10 to 20"},
+				{true, "/pets/{id}", "resource removed"},
+				{true, "GET /owners", "method removed"},
+			},
+		},
+		{
+			name: "positive: recursive types",
+			old: `#%RAML 1.0 Library
+types:
+  Node:
+    properties:
+      value: string
+      children?: Node[]
+`,
+			new: `#%RAML 1.0 Library
+types:
+  Node:
+    properties:
+      value:
+        type: string
+        minLength: 1
+      children?: Node[]
+`,
+			want: []changeKey{
+				{true, "types.Node.properties.value", "minLength 1 added"},
+			},
+		},
+		{
+			name: "positive: no changes",
+			old:  "#%RAML 1.0\ntitle: Pets\n/pets:\n  get:\n",
+			new:  "#%RAML 1.0\ntitle: Pets\n/pets:\n  get:\n",
+			want: []changeKey{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldRAML := parse(t, dir, "old.raml", tt.old)
+			newRAML := parse(t, dir, "new.raml", tt.new)
+			changes := Compare(oldRAML, newRAML)
+			got := make([]changeKey, 0, len(changes))
+			for _, c := range changes {
+				got = append(got, changeKey{c.Breaking, c.Path, c.Message})
+			}
+			require.Equal(t, tt.want, got)
+			wantBreaking := false
+			for _, c := range tt.want {
+				wantBreaking = wantBreaking || c.Breaking
+			}
+			if HasBreaking(changes) != wantBreaking {
+				t.Errorf("HasBreaking() = %v, want %v", HasBreaking(changes), wantBreaking)
+			}
+		})
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	c := Change{Breaking: true, Path: "types.Pet", Message: "type removed", Location: "old.raml", Line: 3, Column: 5}
+	require.Equal(t, "old.raml:3:5: breaking: types.Pet: type removed", c.String())
+	c.Breaking = false
+	require.Equal(t, "old.raml:3:5: non-breaking: types.Pet: type removed", c.String())
+}
@@ -0,0 +1,342 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// nestedFacets are facets holding nested shapes. They are compared separately from other facets.
+var nestedFacets = map[string]struct{}{
+	"items":                {},
+	"properties":           {},
+	"patternProperties":    {},
+	"anyOf":                {},
+	"additionalProperties": {},
+}
+
+// facetDefaults are values of facets that are not set. Facets without defaults are not constrained if not set.
+var facetDefaults = map[string]any{
+	"uniqueItems": false,
+}
+
+func (c *comparer) compareShapes(dir direction, path string, oldShape, newShape *raml.BaseShape) {
+	_, oldRecursive := oldShape.Shape.(*raml.RecursiveShape)
+	_, newRecursive := newShape.Shape.(*raml.RecursiveShape)
+	if oldRecursive && newRecursive {
+		// Heads of recursions are compared on the path to the recursive shapes.
+		return
+	}
+	oldShape, newShape = recursionHead(oldShape), recursionHead(newShape)
+	key := [2]*raml.BaseShape{oldShape, newShape}
+	if _, ok := c.visited[key]; ok {
+		return
+	}
+	c.visited[key] = struct{}{}
+	defer delete(c.visited, key)
+
+	if oldShape.Type != newShape.Type {
+		c.compareTypeChange(dir, path, oldShape, newShape)
+		return
+	}
+	switch o := oldShape.Shape.(type) {
+	case *raml.ObjectShape:
+		c.compareObjects(dir, path, newShape, o, newShape.Shape.(*raml.ObjectShape))
+	case *raml.ArrayShape:
+		n := newShape.Shape.(*raml.ArrayShape)
+		switch {
+		case o.Items == nil && n.Items != nil:
+			c.narrowed(dir, path, newShape, "items %s added", n.Items.Type)
+		case o.Items != nil && n.Items == nil:
+			c.widened(dir, path, newShape, "items %s removed", o.Items.Type)
+		case o.Items != nil && n.Items != nil:
+			c.compareShapes(dir, path+".items", o.Items, n.Items)
+		}
+	case *raml.UnionShape:
+		c.compareUnions(dir, path, newShape, o, newShape.Shape.(*raml.UnionShape))
+	}
+	c.compareFacets(dir, path, oldShape, newShape)
+}
+
+// recursionHead returns the shape that the recursive shape refers to.
+func recursionHead(shape *raml.BaseShape) *raml.BaseShape {
+	if rs, ok := shape.Shape.(*raml.RecursiveShape); ok && rs.Head != nil {
+		return rs.Head
+	}
+	return shape
+}
+
+func (c *comparer) compareTypeChange(dir direction, path string, oldShape, newShape *raml.BaseShape) {
+	_, oldUnion := oldShape.Shape.(*raml.UnionShape)
+	_, newUnion := newShape.Shape.(*raml.UnionShape)
+	// Inheritance of a union from a shape that is not a union does not check members of the union,
+	// so only the shape that is not a union is checked as a child.
+	narrows := !newUnion && inherits(newShape.CloneDetached(), oldShape)
+	widens := !oldUnion && inherits(oldShape.CloneDetached(), newShape)
+	switch {
+	case narrows && !widens:
+		c.narrowed(dir, path, newShape, "type narrowed from %s to %s", oldShape.Type, newShape.Type)
+	case widens && !narrows:
+		c.widened(dir, path, newShape, "type widened from %s to %s", oldShape.Type, newShape.Type)
+	default:
+		c.reportShape(true, path, newShape, "type changed from %s to %s", oldShape.Type, newShape.Type)
+	}
+}
+
+// inherits reports whether the child may inherit from the parent. The child is modified by inheritance.
+func inherits(child, parent *raml.BaseShape) bool {
+	_, err := child.Inherit(parent)
+	return err == nil
+}
+
+func (c *comparer) compareObjects(dir direction, path string, newShape *raml.BaseShape, o, n *raml.ObjectShape) {
+	c.compareProperties(dir, path+".properties", "property", o.Properties, n.Properties)
+
+	oldPatterns, newPatterns := orEmpty(o.PatternProperties), orEmpty(n.PatternProperties)
+	for pair := oldPatterns.Oldest(); pair != nil; pair = pair.Next() {
+		pattern, oldProp := pair.Key, pair.Value
+		propPath := path + ".patternProperties." + pattern
+		newProp, ok := newPatterns.Get(pattern)
+		if !ok {
+			c.widened(dir, propPath, oldProp.Base, "pattern property removed")
+			continue
+		}
+		c.compareShapes(dir, propPath, oldProp.Base, newProp.Base)
+	}
+	for pair := newPatterns.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := oldPatterns.Get(pair.Key); !ok {
+			c.narrowed(dir, path+".patternProperties."+pair.Key, pair.Value.Base, "pattern property added")
+		}
+	}
+
+	// Inheritance does not restrict additional properties, but they are allowed if not set.
+	oldAdditional := o.AdditionalProperties == nil || *o.AdditionalProperties
+	newAdditional := n.AdditionalProperties == nil || *n.AdditionalProperties
+	switch {
+	case oldAdditional && !newAdditional:
+		c.narrowed(dir, path, newShape, "additionalProperties changed from true to false")
+	case !oldAdditional && newAdditional:
+		c.widened(dir, path, newShape, "additionalProperties changed from false to true")
+	}
+}
+
+// compareProperties compares properties of objects, parameters or headers. Optional properties may be added
+// and removed in any direction, since data without them remains valid.
+func (c *comparer) compareProperties(dir direction, path string, what string,
+	oldProps, newProps *orderedmap.OrderedMap[string, raml.Property]) {
+	oldProps, newProps = orEmpty(oldProps), orEmpty(newProps)
+	for pair := oldProps.Oldest(); pair != nil; pair = pair.Next() {
+		name, oldProp := pair.Key, pair.Value
+		propPath := path + "." + name
+		newProp, ok := newProps.Get(name)
+		switch {
+		case !ok && oldProp.Required:
+			c.widened(dir, propPath, oldProp.Base, "required %s removed", what)
+			continue
+		case !ok:
+			c.reportShape(false, propPath, oldProp.Base, "optional %s removed", what)
+			continue
+		case !oldProp.Required && newProp.Required:
+			c.narrowed(dir, propPath, newProp.Base, "%s became required", what)
+		case oldProp.Required && !newProp.Required:
+			c.widened(dir, propPath, newProp.Base, "%s became optional", what)
+		}
+		c.compareShapes(dir, propPath, oldProp.Base, newProp.Base)
+	}
+	for pair := newProps.Oldest(); pair != nil; pair = pair.Next() {
+		name, newProp := pair.Key, pair.Value
+		if _, ok := oldProps.Get(name); ok {
+			continue
+		}
+		if newProp.Required {
+			c.narrowed(dir, path+"."+name, newProp.Base, "required %s added", what)
+		} else {
+			c.reportShape(false, path+"."+name, newProp.Base, "optional %s added", what)
+		}
+	}
+}
+
+// compareUnions compares members of unions matched by their types in order.
+func (c *comparer) compareUnions(dir direction, path string, newShape *raml.BaseShape, o, n *raml.UnionShape) {
+	matched := make([]bool, len(n.AnyOf))
+	for i, oldMember := range o.AnyOf {
+		found := false
+		for j, newMember := range n.AnyOf {
+			if matched[j] || recursionHead(newMember).Type != recursionHead(oldMember).Type {
+				continue
+			}
+			matched[j], found = true, true
+			c.compareShapes(dir, fmt.Sprintf("%s.anyOf[%d]", path, i), oldMember, newMember)
+			break
+		}
+		if !found {
+			c.narrowed(dir, path, newShape, "union member %s removed", oldMember.Type)
+		}
+	}
+	for j, newMember := range n.AnyOf {
+		if !matched[j] {
+			c.widened(dir, path, newShape, "union member %s added", newMember.Type)
+		}
+	}
+}
+
+// compareFacets compares facets of shapes of the same type, except nested shapes.
+// A changed facet narrows the shape if the new shape may inherit from the old one with only this facet set.
+func (c *comparer) compareFacets(dir direction, path string, oldShape, newShape *raml.BaseShape) {
+	oldFacets, newFacets := facets(oldShape.Shape), facets(newShape.Shape)
+	for i, f := range oldFacets {
+		if _, ok := nestedFacets[f.name]; ok {
+			continue
+		}
+		oldValue, newValue := facetValue(f), facetValue(newFacets[i])
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		switch {
+		case oldValue == nil:
+			c.narrowed(dir, path, newShape, "%s %s added", f.name, formatValue(newValue))
+			continue
+		case newValue == nil:
+			c.widened(dir, path, newShape, "%s %s removed", f.name, formatValue(oldValue))
+			continue
+		}
+		change := fmt.Sprintf(" from %s to %s", formatValue(oldValue), formatValue(newValue))
+		if f.name == raml.FacetEnum {
+			change = ": " + enumChange(oldValue.([]any), newValue.([]any))
+		}
+		narrows := inherits(isolateFacet(newShape, f.name), isolateFacet(oldShape, f.name))
+		widens := inherits(isolateFacet(oldShape, f.name), isolateFacet(newShape, f.name))
+		switch {
+		case narrows && !widens:
+			c.narrowed(dir, path, newShape, "%s narrowed%s", f.name, change)
+		case widens && !narrows:
+			c.widened(dir, path, newShape, "%s widened%s", f.name, change)
+		default:
+			// Inheritance either does not restrict the facet, like pattern, or the values are incompatible.
+			c.reportShape(true, path, newShape, "%s changed%s", f.name, change)
+		}
+	}
+}
+
+func enumChange(oldValues, newValues []any) string {
+	diff := func(a, b []any) []any {
+		var res []any
+		for _, v := range a {
+			found := false
+			for _, w := range b {
+				if reflect.DeepEqual(v, w) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+	var parts []string
+	if removed := diff(oldValues, newValues); len(removed) > 0 {
+		parts = append(parts, "removed "+formatValue(removed))
+	}
+	if added := diff(newValues, oldValues); len(added) > 0 {
+		parts = append(parts, "added "+formatValue(added))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("from %s to %s", formatValue(oldValues), formatValue(newValues))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// facet is a facet of a shape. Value is addressable, so that the facet can be changed.
+type facet struct {
+	name  string
+	value reflect.Value
+}
+
+// facets returns facets of the shape declared in its embedded *Facets structs, e.g. raml.StringFacets.
+// The order of facets is the same for shapes of the same type.
+func facets(shape raml.Shape) []facet {
+	var res []facet
+	var walk func(v reflect.Value, inFacets bool)
+	walk = func(v reflect.Value, inFacets bool) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			switch {
+			case f.Anonymous && f.Type.Kind() == reflect.Struct && strings.HasSuffix(f.Name, "Facets"):
+				walk(v.Field(i), true)
+			case inFacets && f.IsExported():
+				res = append(res, facet{name: strings.ToLower(f.Name[:1]) + f.Name[1:], value: v.Field(i)})
+			}
+		}
+	}
+	walk(reflect.ValueOf(shape).Elem(), false)
+	return res
+}
+
+// facetValue returns a comparable value of the facet or nil if the facet is not set.
+func facetValue(f facet) any {
+	switch v := f.value.Interface().(type) {
+	case raml.Nodes:
+		if v == nil {
+			return nil
+		}
+		values := make([]any, len(v))
+		for i, n := range v {
+			values[i] = n.Value
+		}
+		return values
+	case *regexp.Regexp:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		return json.Number(v.String())
+	}
+	if f.value.Kind() == reflect.Pointer {
+		if f.value.IsNil() {
+			return facetDefaults[f.name]
+		}
+		return f.value.Elem().Interface()
+	}
+	return f.value.Interface()
+}
+
+// isolateFacet returns a detached copy of the shape with only the named facet set.
+// The default value is set if the facet is not set.
+func isolateFacet(shape *raml.BaseShape, name string) *raml.BaseShape {
+	c := shape.CloneDetached()
+	for _, f := range facets(c.Shape) {
+		switch {
+		case f.name != name:
+			f.value.Set(reflect.Zero(f.value.Type()))
+		case f.value.Kind() == reflect.Pointer && f.value.IsNil():
+			if def, ok := facetDefaults[name]; ok {
+				p := reflect.New(f.value.Type().Elem())
+				p.Elem().Set(reflect.ValueOf(def))
+				f.value.Set(p)
+			}
+		}
+	}
+	return c
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,1013 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type EncoderOptions struct {
+	inlineTypes bool
+}
+
+type EncoderOpt interface {
+	apply(*EncoderOptions)
+}
+
+type optInlineTypes struct{}
+
+func (o optInlineTypes) apply(opts *EncoderOptions) { opts.inlineTypes = true }
+
+// WithInlineTypes makes the encoder write unwrapped shapes in place instead of references to named types.
+// Shapes must be unwrapped, i.e. the document must be parsed with OptWithUnwrap.
+//
+// Since RAML unions can only be declared with type expressions and recursion requires a named type,
+// such shapes are declared as separate types of the library. Custom facet values are not written
+// because they can only be applied to subtypes of the types that declare them.
+func WithInlineTypes() EncoderOpt {
+	return optInlineTypes{}
+}
+
+// Encoder writes RAML fragments as RAML YAML documents.
+//
+// By default, references to named types are preserved, so the written documents keep the structure
+// of the source documents. Use WithInlineTypes to write self-contained shapes instead.
+type Encoder struct {
+	w    io.Writer
+	opts EncoderOptions
+
+	// types is the "types" node of the library being encoded.
+	// It is used to declare shapes that cannot be inlined. Nil if the document cannot declare types.
+	types *yaml.Node
+	// declared contains names of types declared in the library being encoded by shape IDs.
+	declared map[int64]string
+	// names contains types declared in the library being encoded by names.
+	names map[string]*BaseShape
+	// libraryTypes contains qualified names of types and annotation types of the used libraries by shape IDs,
+	// e.g. "common.Pet".
+	libraryTypes map[int64]string
+}
+
+// NewEncoder creates an encoder that writes to w.
+func NewEncoder(w io.Writer, opts ...EncoderOpt) *Encoder {
+	e := &Encoder{w: w}
+	for _, opt := range opts {
+		opt.apply(&e.opts)
+	}
+	return e
+}
+
+// EncodeLibrary writes the library as a RAML 1.0 Library document.
+//
+// Types must be resolved, i.e. the library must be parsed or built from resolved shapes.
+// Resource types, traits and security schemes are written as declared.
+func (e *Encoder) EncodeLibrary(l *Library) error {
+	node, err := e.libraryNode(l)
+	if err != nil {
+		return err
+	}
+	return e.writeDocument("#%RAML 1.0 Library", node)
+}
+
+// EncodeAPI writes the API as a RAML 1.0 document.
+//
+// Types must be resolved. Resource types and traits are already applied to resources and methods by the parser,
+// so resources are written expanded and declarations of resource types and traits are not written.
+// Methods are written with their effective security requirements.
+func (e *Encoder) EncodeAPI(api *API) error {
+	node, err := e.apiNode(api)
+	if err != nil {
+		return err
+	}
+	return e.writeDocument("#%RAML 1.0", node)
+}
+
+// EncodeDataType writes the data type as a RAML 1.0 DataType document.
+func (e *Encoder) EncodeDataType(dt *DataType) error {
+	node, err := e.dataTypeNode(dt)
+	if err != nil {
+		return err
+	}
+	return e.writeDocument("#%RAML 1.0 DataType", node)
+}
+
+func (e *Encoder) writeDocument(head string, node *yaml.Node) error {
+	if _, err := io.WriteString(e.w, head+"\n"); err != nil {
+		return fmt.Errorf("write head: %w", err)
+	}
+	if len(node.Content) == 0 {
+		return nil
+	}
+	enc := yaml.NewEncoder(e.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("close yaml encoder: %w", err)
+	}
+	return nil
+}
+
+//nolint:gocognit,gocyclo,cyclop // Declarations of every kind are encoded in one place.
+func (e *Encoder) libraryNode(l *Library) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if l.Usage != "" {
+		appendYAMLPair(node, "usage", yamlString(l.Usage))
+	}
+	if err := e.appendAnnotations(node, l.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	appendYAMLUses(node, l.Uses)
+
+	defer e.beginDeclarations(l.Types, l.Uses)()
+	annotationTypes, err := e.annotationTypesNode(l.AnnotationTypes)
+	if err != nil {
+		return nil, err
+	}
+	if annotationTypes != nil {
+		appendYAMLPair(node, "annotationTypes", annotationTypes)
+	}
+	if err = e.encodeTypes(l.Types); err != nil {
+		return nil, err
+	}
+	// Types declared while encoding inlined shapes are appended to the same node.
+	if len(e.types.Content) > 0 {
+		appendYAMLPair(node, "types", e.types)
+	}
+	if l.ResourceTypes != nil && l.ResourceTypes.Len() > 0 {
+		resourceTypes := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := l.ResourceTypes.Oldest(); pair != nil; pair = pair.Next() {
+			appendYAMLPair(resourceTypes, pair.Key, pair.Value.Node)
+		}
+		appendYAMLPair(node, "resourceTypes", resourceTypes)
+	}
+	if l.Traits != nil && l.Traits.Len() > 0 {
+		traits := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := l.Traits.Oldest(); pair != nil; pair = pair.Next() {
+			appendYAMLPair(traits, pair.Key, pair.Value.Node)
+		}
+		appendYAMLPair(node, "traits", traits)
+	}
+	if l.SecuritySchemes != nil && l.SecuritySchemes.Len() > 0 {
+		securitySchemes := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := l.SecuritySchemes.Oldest(); pair != nil; pair = pair.Next() {
+			appendYAMLPair(securitySchemes, pair.Key, pair.Value.Node)
+		}
+		appendYAMLPair(node, "securitySchemes", securitySchemes)
+	}
+	return node, nil
+}
+
+// beginDeclarations prepares the encoder to encode the document that declares the types and uses the libraries.
+// The returned function resets the state.
+func (e *Encoder) beginDeclarations(types *orderedmap.OrderedMap[string, *BaseShape],
+	uses *orderedmap.OrderedMap[string, *LibraryLink],
+) func() {
+	e.types = &yaml.Node{Kind: yaml.MappingNode}
+	e.declared = make(map[int64]string)
+	e.names = make(map[string]*BaseShape)
+	e.libraryTypes = make(map[int64]string)
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		e.declared[pair.Value.ID] = pair.Key
+		e.names[pair.Key] = pair.Value
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link == nil {
+			continue
+		}
+		for typePair := pair.Value.Link.Types.Oldest(); typePair != nil; typePair = typePair.Next() {
+			e.libraryTypes[typePair.Value.ID] = pair.Key + "." + typePair.Key
+		}
+		for typePair := pair.Value.Link.AnnotationTypes.Oldest(); typePair != nil; typePair = typePair.Next() {
+			e.libraryTypes[typePair.Value.ID] = pair.Key + "." + typePair.Key
+		}
+	}
+	return func() {
+		e.types, e.declared, e.names, e.libraryTypes = nil, nil, nil, nil
+	}
+}
+
+// annotationTypesNode returns the "annotationTypes" node or nil if there are no annotation types.
+func (e *Encoder) annotationTypesNode(annotationTypes *orderedmap.OrderedMap[string, *BaseShape]) (*yaml.Node, error) {
+	if annotationTypes.Len() == 0 {
+		return nil, nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for pair := annotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+		shapeNode, err := e.declarationNode(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("encode annotation type %s: %w", pair.Key, err)
+		}
+		appendYAMLPair(node, pair.Key, shapeNode)
+	}
+	return node, nil
+}
+
+// encodeTypes appends declarations of the types to the "types" node.
+func (e *Encoder) encodeTypes(types *orderedmap.OrderedMap[string, *BaseShape]) error {
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		shapeNode, err := e.declarationNode(pair.Value)
+		if err != nil {
+			return fmt.Errorf("encode type %s: %w", pair.Key, err)
+		}
+		appendYAMLPair(e.types, pair.Key, shapeNode)
+	}
+	return nil
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Root nodes of the API are encoded in one place to keep their order.
+func (e *Encoder) apiNode(api *API) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	appendYAMLPair(node, "title", yamlString(api.Title))
+	if api.Description != "" {
+		appendYAMLPair(node, FacetDescription, yamlString(api.Description))
+	}
+	if api.Version != "" {
+		appendYAMLPair(node, "version", yamlString(api.Version))
+	}
+	if api.BaseURI != "" {
+		appendYAMLPair(node, "baseUri", yamlString(api.BaseURI))
+	}
+
+	defer e.beginDeclarations(api.Types, api.Uses)()
+	baseURIParameters, err := e.parametersNode(api.BaseURIParameters)
+	if err != nil {
+		return nil, fmt.Errorf("encode base uri parameters: %w", err)
+	}
+	if baseURIParameters != nil {
+		appendYAMLPair(node, "baseUriParameters", baseURIParameters)
+	}
+	appendYAMLStrings(node, "protocols", api.Protocols)
+	switch len(api.MediaType) {
+	case 0:
+	case 1:
+		appendYAMLPair(node, "mediaType", yamlString(api.MediaType[0]))
+	default:
+		appendYAMLStrings(node, "mediaType", api.MediaType)
+	}
+	if len(api.Documentation) > 0 {
+		documentation := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range api.Documentation {
+			itemNode := &yaml.Node{Kind: yaml.MappingNode}
+			appendYAMLPair(itemNode, "title", yamlString(item.Title))
+			appendYAMLPair(itemNode, "content", yamlString(item.Content))
+			documentation.Content = append(documentation.Content, itemNode)
+		}
+		appendYAMLPair(node, "documentation", documentation)
+	}
+	if err = e.appendAnnotations(node, api.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	appendYAMLUses(node, api.Uses)
+	if api.SecuritySchemes.Len() > 0 {
+		securitySchemes := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := api.SecuritySchemes.Oldest(); pair != nil; pair = pair.Next() {
+			appendYAMLPair(securitySchemes, pair.Key, pair.Value.Node)
+		}
+		appendYAMLPair(node, "securitySchemes", securitySchemes)
+	}
+	annotationTypes, err := e.annotationTypesNode(api.AnnotationTypes)
+	if err != nil {
+		return nil, err
+	}
+	if annotationTypes != nil {
+		appendYAMLPair(node, "annotationTypes", annotationTypes)
+	}
+	if err = e.encodeTypes(api.Types); err != nil {
+		return nil, err
+	}
+	// Resources are encoded before the "types" node is appended since inlined shapes may declare types.
+	var resources []*yaml.Node
+	for pair := api.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		resourceNode, errResource := e.resourceNode(pair.Value)
+		if errResource != nil {
+			return nil, fmt.Errorf("encode resource %s: %w", pair.Key, errResource)
+		}
+		resources = append(resources, yamlString(pair.Key), resourceNode)
+	}
+	if len(e.types.Content) > 0 {
+		appendYAMLPair(node, "types", e.types)
+	}
+	securedBy, err := securedByNode(api.SecuredBy)
+	if err != nil {
+		return nil, err
+	}
+	if securedBy != nil {
+		appendYAMLPair(node, "securedBy", securedBy)
+	}
+	node.Content = append(node.Content, resources...)
+	return node, nil
+}
+
+func (e *Encoder) resourceNode(res *Resource) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if res.DisplayName != "" && res.DisplayName != res.URI {
+		appendYAMLPair(node, FacetDisplayName, yamlString(res.DisplayName))
+	}
+	if res.Description != "" {
+		appendYAMLPair(node, FacetDescription, yamlString(res.Description))
+	}
+	if err := e.appendAnnotations(node, res.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	uriParameters, err := e.parametersNode(res.URIParameters)
+	if err != nil {
+		return nil, fmt.Errorf("encode uri parameters: %w", err)
+	}
+	if uriParameters != nil {
+		appendYAMLPair(node, "uriParameters", uriParameters)
+	}
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		methodNode, errMethod := e.methodNode(pair.Value)
+		if errMethod != nil {
+			return nil, fmt.Errorf("encode method %s: %w", pair.Key, errMethod)
+		}
+		appendYAMLPair(node, pair.Key, methodNode)
+	}
+	for pair := res.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		resourceNode, errResource := e.resourceNode(pair.Value)
+		if errResource != nil {
+			return nil, fmt.Errorf("encode resource %s: %w", pair.Key, errResource)
+		}
+		appendYAMLPair(node, pair.Key, resourceNode)
+	}
+	if len(node.Content) == 0 {
+		// Resources without methods and nested resources are still valid.
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Value: "null"}, nil
+	}
+	return node, nil
+}
+
+func (e *Encoder) methodNode(m *Method) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if m.DisplayName != "" && m.DisplayName != m.Name {
+		appendYAMLPair(node, FacetDisplayName, yamlString(m.DisplayName))
+	}
+	if m.Description != "" {
+		appendYAMLPair(node, FacetDescription, yamlString(m.Description))
+	}
+	if err := e.appendAnnotations(node, m.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	appendYAMLStrings(node, "protocols", m.Protocols)
+	securedBy, err := securedByNode(m.SecuredBy)
+	if err != nil {
+		return nil, err
+	}
+	if securedBy != nil {
+		appendYAMLPair(node, "securedBy", securedBy)
+	}
+	headers, err := e.parametersNode(m.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("encode headers: %w", err)
+	}
+	if headers != nil {
+		appendYAMLPair(node, "headers", headers)
+	}
+	queryParameters, err := e.parametersNode(m.QueryParameters)
+	if err != nil {
+		return nil, fmt.Errorf("encode query parameters: %w", err)
+	}
+	if queryParameters != nil {
+		appendYAMLPair(node, "queryParameters", queryParameters)
+	}
+	if m.QueryString != nil {
+		queryString, errQuery := e.shapeNode(m.QueryString)
+		if errQuery != nil {
+			return nil, fmt.Errorf("encode query string: %w", errQuery)
+		}
+		appendYAMLPair(node, "queryString", queryString)
+	}
+	body, err := e.bodyNode(m.Body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		appendYAMLPair(node, "body", body)
+	}
+	if m.Responses.Len() > 0 {
+		responses := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+			responseNode, errResponse := e.responseNode(pair.Value)
+			if errResponse != nil {
+				return nil, fmt.Errorf("encode response %s: %w", pair.Key, errResponse)
+			}
+			appendYAMLPair(responses, pair.Key, responseNode)
+		}
+		appendYAMLPair(node, "responses", responses)
+	}
+	if len(node.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Value: "null"}, nil
+	}
+	return node, nil
+}
+
+func (e *Encoder) responseNode(resp *Response) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if resp.Description != "" {
+		appendYAMLPair(node, FacetDescription, yamlString(resp.Description))
+	}
+	if err := e.appendAnnotations(node, resp.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	headers, err := e.parametersNode(resp.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("encode headers: %w", err)
+	}
+	if headers != nil {
+		appendYAMLPair(node, "headers", headers)
+	}
+	body, err := e.bodyNode(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		appendYAMLPair(node, "body", body)
+	}
+	if len(node.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Value: "null"}, nil
+	}
+	return node, nil
+}
+
+// bodyNode returns the "body" node with explicit media types or nil if there are no bodies.
+func (e *Encoder) bodyNode(body *orderedmap.OrderedMap[string, *Body]) (*yaml.Node, error) {
+	if body.Len() == 0 {
+		return nil, nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for pair := body.Oldest(); pair != nil; pair = pair.Next() {
+		shapeNode, err := e.shapeNode(pair.Value.Shape)
+		if err != nil {
+			return nil, fmt.Errorf("encode body %s: %w", pair.Key, err)
+		}
+		appendYAMLPair(node, pair.Key, shapeNode)
+	}
+	return node, nil
+}
+
+// parametersNode returns the node of named parameters or nil if there are no parameters.
+func (e *Encoder) parametersNode(params *orderedmap.OrderedMap[string, Property]) (*yaml.Node, error) {
+	if params.Len() == 0 {
+		return nil, nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		paramNode, err := e.propertyNode(pair.Value.Base, pair.Value.Required)
+		if err != nil {
+			return nil, fmt.Errorf("encode parameter %s: %w", pair.Key, err)
+		}
+		appendYAMLPair(node, pair.Key, paramNode)
+	}
+	return node, nil
+}
+
+// securedByNode returns the "securedBy" node or nil if there are no security requirements.
+// Anonymous access is written as null and parameterized requirements are written as maps.
+func securedByNode(requirements []*SecurityRequirement) (*yaml.Node, error) {
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, req := range requirements {
+		var reqNode *yaml.Node
+		switch {
+		case req.IsAnonymous():
+			reqNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Value: "null"}
+		case len(req.Parameters) > 0:
+			params, err := yamlValue(req.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("encode parameters of %s: %w", req.Name, err)
+			}
+			reqNode = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString(req.Name), params}}
+		default:
+			reqNode = yamlString(req.Name)
+		}
+		node.Content = append(node.Content, reqNode)
+	}
+	return node, nil
+}
+
+func (e *Encoder) dataTypeNode(dt *DataType) (*yaml.Node, error) {
+	if dt.Shape == nil {
+		return nil, fmt.Errorf("data type %s has no shape", dt.Location)
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if dt.Usage != "" {
+		appendYAMLPair(node, "usage", yamlString(dt.Usage))
+	}
+	appendYAMLUses(node, dt.Uses)
+	shapeNode, err := e.declarationNode(dt.Shape)
+	if err != nil {
+		return nil, fmt.Errorf("encode shape: %w", err)
+	}
+	// The shape is declared by the document itself.
+	if shapeNode.Kind == yaml.ScalarNode {
+		appendYAMLPair(node, FacetType, shapeNode)
+	} else {
+		node.Content = append(node.Content, shapeNode.Content...)
+	}
+	return node, nil
+}
+
+// declarationNode returns the declaration of a named type or an annotation type.
+func (e *Encoder) declarationNode(base *BaseShape) (*yaml.Node, error) {
+	if e.opts.inlineTypes && !base.IsUnwrapped() {
+		return nil, fmt.Errorf("shape %s is not unwrapped", base.Name)
+	}
+	return e.shapeNode(base)
+}
+
+// declare declares the shape as a separate type of the library and returns its name.
+func (e *Encoder) declare(base *BaseShape, hint string) (string, error) {
+	if name, ok := e.declared[base.ID]; ok {
+		return name, nil
+	}
+	if e.types == nil {
+		return "", fmt.Errorf("shape %s cannot be inlined and the document cannot declare types", base.Name)
+	}
+	name := ramlTypeName(hint)
+	if name == "" {
+		name = "Type"
+	}
+	unique := name
+	for i := 2; ; i++ {
+		if _, present := e.names[unique]; !present {
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+	e.declared[base.ID] = unique
+	e.names[unique] = base
+	n, err := e.shapeNode(base)
+	if err != nil {
+		return "", fmt.Errorf("encode type %s: %w", unique, err)
+	}
+	appendYAMLPair(e.types, unique, n)
+	return unique, nil
+}
+
+// shapeNode returns the shape declaration.
+// Declarations that consist only of the type are returned in the short form, e.g. "string" or "Person".
+func (e *Encoder) shapeNode(base *BaseShape) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	typeNode, err := e.shapeTypeNode(base)
+	if err != nil {
+		return nil, err
+	}
+	appendYAMLPair(node, FacetType, typeNode)
+	if base.DisplayName != nil {
+		appendYAMLPair(node, FacetDisplayName, yamlString(*base.DisplayName))
+	}
+	if base.Description != nil {
+		appendYAMLPair(node, FacetDescription, yamlString(*base.Description))
+	}
+	// Aliases do not declare facets, they are provided by the referenced type.
+	if base.Alias == nil || e.opts.inlineTypes {
+		if err = e.appendFacets(node, base.Shape); err != nil {
+			return nil, err
+		}
+	}
+	if base.Alias == nil && !e.opts.inlineTypes && base.CustomShapeFacets != nil {
+		for pair := base.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+			v, errValue := yamlValue(pair.Value.Value)
+			if errValue != nil {
+				return nil, fmt.Errorf("encode custom facet %s: %w", pair.Key, errValue)
+			}
+			appendYAMLPair(node, pair.Key, v)
+		}
+	}
+	if base.CustomShapeFacetDefinitions != nil && base.CustomShapeFacetDefinitions.Len() > 0 {
+		facets := &yaml.Node{Kind: yaml.MappingNode}
+		for pair := base.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			propNode, errProp := e.propertyNode(prop.Base, prop.Required)
+			if errProp != nil {
+				return nil, fmt.Errorf("encode facet %s: %w", prop.Name, errProp)
+			}
+			appendYAMLPair(facets, prop.Name, propNode)
+		}
+		appendYAMLPair(node, FacetFacets, facets)
+	}
+	if base.Default != nil {
+		v, errValue := yamlValue(base.Default.Value)
+		if errValue != nil {
+			return nil, fmt.Errorf("encode default: %w", errValue)
+		}
+		appendYAMLPair(node, FacetDefault, v)
+	}
+	if err = e.appendExamples(node, base); err != nil {
+		return nil, err
+	}
+	if err = e.appendAnnotations(node, base.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 2 && typeNode.Kind == yaml.ScalarNode {
+		return typeNode, nil
+	}
+	return node, nil
+}
+
+// shapeTypeNode returns the value of the "type" facet.
+func (e *Encoder) shapeTypeNode(base *BaseShape) (*yaml.Node, error) {
+	if !e.opts.inlineTypes {
+		switch {
+		case base.Alias != nil:
+			return yamlString(e.referenceName(base.TypeLabel, base.Alias)), nil
+		case len(base.Inherits) == 1:
+			return yamlString(e.referenceName(base.TypeLabel, base.Inherits[0])), nil
+		case len(base.Inherits) > 1:
+			seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			for _, parent := range base.Inherits {
+				// Parents are aliases of the named types.
+				ref := parent
+				if parent.Alias != nil {
+					ref = parent.Alias
+				}
+				seq.Content = append(seq.Content, yamlString(e.referenceName(parent.TypeLabel, ref)))
+			}
+			return seq, nil
+		}
+	}
+	switch s := base.Shape.(type) {
+	case *UnionShape:
+		expr, err := e.unionExpression(s)
+		if err != nil {
+			return nil, err
+		}
+		return yamlString(expr), nil
+	case *JSONShape:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: s.Raw, Style: yaml.LiteralStyle}, nil
+	case *RecursiveShape:
+		if !e.opts.inlineTypes {
+			return yamlString(s.Head.Name), nil
+		}
+		name, err := e.reference(s.Head, s.Head.Name)
+		if err != nil {
+			return nil, fmt.Errorf("encode recursive shape: %w", err)
+		}
+		return yamlString(name), nil
+	case *UnknownShape, nil:
+		return nil, fmt.Errorf("shape %s is not resolved", base.Name)
+	}
+	return yamlString(base.Type), nil
+}
+
+// isUnwrappedAlias reports whether the unwrapped shape is an alias of the referenced type.
+// Unwrapped aliases share parents with the referenced type, while shapes that declare their own facets
+// inherit the referenced type.
+func isUnwrappedAlias(base, ref *BaseShape) bool {
+	if len(base.Inherits) != len(ref.Inherits) {
+		return false
+	}
+	for i, parent := range base.Inherits {
+		if parent != ref.Inherits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// referenceName returns the name of the referenced type. Types of the used libraries are qualified
+// with library aliases. Otherwise, the label is preferred since it keeps library prefixes.
+func (e *Encoder) referenceName(label string, ref *BaseShape) string {
+	if name, ok := e.libraryTypes[ref.ID]; ok {
+		return name
+	}
+	if label != "" {
+		return label
+	}
+	return ref.Name
+}
+
+// unionExpression returns the type expression of the union, e.g. "Cat | Dog | nil".
+func (e *Encoder) unionExpression(s *UnionShape) (string, error) {
+	members := make([]string, len(s.AnyOf))
+	for i, member := range s.AnyOf {
+		n, err := e.shapeNode(member)
+		if err != nil {
+			return "", fmt.Errorf("encode union member: %w", err)
+		}
+		if n.Kind != yaml.ScalarNode {
+			if !e.opts.inlineTypes {
+				return "", fmt.Errorf("union member %d must be a type name or a type expression", i)
+			}
+			name, errRef := e.reference(member, s.Base().Name+"Member")
+			if errRef != nil {
+				return "", fmt.Errorf("encode union member %d: %w", i, errRef)
+			}
+			n = yamlString(name)
+		}
+		members[i] = n.Value
+		if strings.Contains(n.Value, "|") {
+			members[i] = "(" + n.Value + ")"
+		}
+	}
+	return strings.Join(members, " | "), nil
+}
+
+// reference returns the name of the type that declares the inlined shape.
+// Aliases of types of the library keep the reference since the referenced types are inlined the same way.
+// Other shapes are declared as separate types.
+func (e *Encoder) reference(base *BaseShape, hint string) (string, error) {
+	if ref, ok := e.names[base.TypeLabel]; ok && isUnwrappedAlias(base, ref) {
+		return base.TypeLabel, nil
+	}
+	if base.TypeLabel != "" {
+		hint = base.TypeLabel
+	}
+	return e.declare(base, hint)
+}
+
+//nolint:gocognit,gocyclo,cyclop // Facets of every shape type are encoded in one place.
+func (e *Encoder) appendFacets(node *yaml.Node, s Shape) error {
+	switch shape := s.(type) {
+	case *ObjectShape:
+		return e.appendObjectFacets(node, shape)
+	case *ArrayShape:
+		if shape.Items != nil {
+			items, err := e.shapeNode(shape.Items)
+			if err != nil {
+				return fmt.Errorf("encode items: %w", err)
+			}
+			appendYAMLPair(node, FacetItems, items)
+		}
+		appendYAMLUint(node, FacetMinItems, shape.MinItems)
+		appendYAMLUint(node, FacetMaxItems, shape.MaxItems)
+		appendYAMLBool(node, FacetUniqueItems, shape.UniqueItems)
+	case *StringShape:
+		if err := appendYAMLEnum(node, shape.Enum); err != nil {
+			return err
+		}
+		if shape.Pattern != nil {
+			appendYAMLPair(node, FacetPattern, yamlString(shape.Pattern.String()))
+		}
+		appendYAMLUint(node, FacetMinLength, shape.MinLength)
+		appendYAMLUint(node, FacetMaxLength, shape.MaxLength)
+	case *IntegerShape:
+		if err := appendYAMLEnum(node, shape.Enum); err != nil {
+			return err
+		}
+		appendYAMLString(node, FacetFormat, shape.Format)
+		if shape.Minimum != nil {
+			appendYAMLPair(node, FacetMinimum, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInt, Value: shape.Minimum.String()})
+		}
+		if shape.Maximum != nil {
+			appendYAMLPair(node, FacetMaximum, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInt, Value: shape.Maximum.String()})
+		}
+		appendYAMLFloat(node, FacetMultipleOf, shape.MultipleOf)
+	case *NumberShape:
+		if err := appendYAMLEnum(node, shape.Enum); err != nil {
+			return err
+		}
+		appendYAMLString(node, FacetFormat, shape.Format)
+		appendYAMLFloat(node, FacetMinimum, shape.Minimum)
+		appendYAMLFloat(node, FacetMaximum, shape.Maximum)
+		appendYAMLFloat(node, FacetMultipleOf, shape.MultipleOf)
+	case *BooleanShape:
+		return appendYAMLEnum(node, shape.Enum)
+	case *DateTimeShape:
+		appendYAMLString(node, FacetFormat, shape.Format)
+	case *FileShape:
+		if len(shape.FileTypes) > 0 {
+			fileTypes := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, ft := range shape.FileTypes {
+				v, err := yamlValue(ft.Value)
+				if err != nil {
+					return fmt.Errorf("encode file type: %w", err)
+				}
+				fileTypes.Content = append(fileTypes.Content, v)
+			}
+			appendYAMLPair(node, FacetFileTypes, fileTypes)
+		}
+		appendYAMLUint(node, FacetMinLength, shape.MinLength)
+		appendYAMLUint(node, FacetMaxLength, shape.MaxLength)
+	}
+	return nil
+}
+
+func (e *Encoder) appendObjectFacets(node *yaml.Node, s *ObjectShape) error {
+	props := &yaml.Node{Kind: yaml.MappingNode}
+	if s.Properties != nil {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			propNode, err := e.propertyNode(prop.Base, prop.Required)
+			if err != nil {
+				return fmt.Errorf("encode property %s: %w", prop.Name, err)
+			}
+			appendYAMLPair(props, prop.Name, propNode)
+		}
+	}
+	if s.PatternProperties != nil {
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			propNode, err := e.shapeNode(prop.Base)
+			if err != nil {
+				return fmt.Errorf("encode pattern property %s: %w", prop.Pattern.String(), err)
+			}
+			appendYAMLPair(props, "/"+prop.Pattern.String()+"/", propNode)
+		}
+	}
+	if len(props.Content) > 0 {
+		appendYAMLPair(node, FacetProperties, props)
+	}
+	appendYAMLBool(node, FacetAdditionalProperties, s.AdditionalProperties)
+	appendYAMLUint(node, FacetMinProperties, s.MinProperties)
+	appendYAMLUint(node, FacetMaxProperties, s.MaxProperties)
+	appendYAMLString(node, FacetDiscriminator, s.Discriminator)
+	if s.DiscriminatorValue != nil {
+		v, err := yamlValue(s.DiscriminatorValue)
+		if err != nil {
+			return fmt.Errorf("encode discriminator value: %w", err)
+		}
+		appendYAMLPair(node, FacetDiscriminatorValue, v)
+	}
+	return nil
+}
+
+// propertyNode returns the declaration of a property or a custom facet.
+// Optional declarations are marked with the "required" facet, so names are written as is.
+func (e *Encoder) propertyNode(base *BaseShape, required bool) (*yaml.Node, error) {
+	node, err := e.shapeNode(base)
+	if err != nil {
+		return nil, err
+	}
+	if !required {
+		if node.Kind == yaml.ScalarNode {
+			node = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString(FacetType), node}}
+		}
+		appendYAMLPair(node, FacetRequired, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"})
+	}
+	return node, nil
+}
+
+func (e *Encoder) appendExamples(node *yaml.Node, base *BaseShape) error {
+	if base.Example != nil {
+		ex, err := e.exampleNode(base.Example)
+		if err != nil {
+			return fmt.Errorf("encode example: %w", err)
+		}
+		appendYAMLPair(node, FacetExample, ex)
+	}
+	if base.Examples == nil || base.Examples.Map == nil || base.Examples.Map.Len() == 0 {
+		return nil
+	}
+	examples := &yaml.Node{Kind: yaml.MappingNode}
+	for pair := base.Examples.Map.Oldest(); pair != nil; pair = pair.Next() {
+		ex, err := e.exampleNode(pair.Value)
+		if err != nil {
+			return fmt.Errorf("encode example %s: %w", pair.Key, err)
+		}
+		appendYAMLPair(examples, pair.Key, ex)
+	}
+	appendYAMLPair(node, FacetExamples, examples)
+	return nil
+}
+
+// exampleNode returns the example value or the example declaration if the example has facets or annotations.
+func (e *Encoder) exampleNode(ex *Example) (*yaml.Node, error) {
+	var value any
+	if ex.Data != nil {
+		value = ex.Data.Value
+	}
+	v, err := yamlValue(value)
+	if err != nil {
+		return nil, err
+	}
+	hasAnnotations := ex.CustomDomainProperties != nil && ex.CustomDomainProperties.Len() > 0
+	if ex.DisplayName == "" && ex.Description == "" && ex.Strict && !hasAnnotations {
+		return v, nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if ex.DisplayName != "" {
+		appendYAMLPair(node, FacetDisplayName, yamlString(ex.DisplayName))
+	}
+	if ex.Description != "" {
+		appendYAMLPair(node, FacetDescription, yamlString(ex.Description))
+	}
+	if err = e.appendAnnotations(node, ex.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	if !ex.Strict {
+		appendYAMLBool(node, FacetStrict, &ex.Strict)
+	}
+	appendYAMLPair(node, ExampleValue, v)
+	return node, nil
+}
+
+func appendYAMLPair(node *yaml.Node, key string, value *yaml.Node) {
+	node.Content = append(node.Content, yamlString(key), value)
+}
+
+func appendYAMLUses(node *yaml.Node, uses *orderedmap.OrderedMap[string, *LibraryLink]) {
+	if uses == nil || uses.Len() == 0 {
+		return
+	}
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		appendYAMLPair(n, pair.Key, yamlString(pair.Value.Value))
+	}
+	appendYAMLPair(node, "uses", n)
+}
+
+// appendAnnotations appends annotations in the "(name): value" form.
+// Annotation types of the used libraries are qualified with library aliases.
+func (e *Encoder) appendAnnotations(
+	node *yaml.Node, annotations *orderedmap.OrderedMap[string, *DomainExtension],
+) error {
+	if annotations == nil {
+		return nil
+	}
+	for pair := annotations.Oldest(); pair != nil; pair = pair.Next() {
+		var value any
+		if pair.Value.Extension != nil {
+			value = pair.Value.Extension.Value
+		}
+		v, err := yamlValue(value)
+		if err != nil {
+			return fmt.Errorf("encode annotation %s: %w", pair.Key, err)
+		}
+		name := pair.Key
+		if pair.Value.DefinedBy != nil {
+			if qualified, ok := e.libraryTypes[pair.Value.DefinedBy.ID]; ok {
+				name = qualified
+			}
+		}
+		appendYAMLPair(node, "("+name+")", v)
+	}
+	return nil
+}
+
+func appendYAMLStrings(node *yaml.Node, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, v := range values {
+		seq.Content = append(seq.Content, yamlString(v))
+	}
+	appendYAMLPair(node, key, seq)
+}
+
+func appendYAMLString(node *yaml.Node, key string, value *string) {
+	if value != nil {
+		appendYAMLPair(node, key, yamlString(*value))
+	}
+}
+
+func appendYAMLUint(node *yaml.Node, key string, value *uint64) {
+	if value != nil {
+		appendYAMLPair(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInt,
+			Value: strconv.FormatUint(*value, 10)})
+	}
+}
+
+func appendYAMLFloat(node *yaml.Node, key string, value *float64) {
+	if value != nil {
+		tag := "!!float"
+		if *value == float64(int64(*value)) {
+			tag = TagInt
+		}
+		appendYAMLPair(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag,
+			Value: strconv.FormatFloat(*value, 'f', -1, 64)})
+	}
+}
+
+func appendYAMLBool(node *yaml.Node, key string, value *bool) {
+	if value != nil {
+		appendYAMLPair(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*value)})
+	}
+}
+
+func appendYAMLEnum(node *yaml.Node, enum Nodes) error {
+	if len(enum) == 0 {
+		return nil
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range enum {
+		v, err := yamlValue(item.Value)
+		if err != nil {
+			return fmt.Errorf("encode enum: %w", err)
+		}
+		seq.Content = append(seq.Content, v)
+	}
+	appendYAMLPair(node, FacetEnum, seq)
+	return nil
+}
+
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: s}
+}
+
+func yamlValue(v any) (*yaml.Node, error) {
+	n := &yaml.Node{}
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,568 @@
+package raml
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_EncodeLibrary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// files contains included files by names.
+		files   map[string]string
+		opts    []EncoderOpt
+		unwrap  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "positive: references and facets",
+			content: `#%RAML 1.0 Library
+usage: Test library.
+types:
+  Status:
+    description: Status of the account.
+    enum: [active, inactive]
+  Code:
+    type: integer
+    minimum: 1
+    maximum: 10
+    multipleOf: 2
+  Pet:
+    discriminator: kind
+    properties:
+      kind: string
+      name?:
+        type: string
+        pattern: ^[A-Z]
+        maxLength: 10
+      /^x-/: any
+  Cat:
+    type: Pet
+    discriminatorValue: cat
+  Dog: Pet
+  Animal: Cat | Dog | nil
+  Pets:
+    type: array
+    items: Animal
+    minItems: 1
+    uniqueItems: true
+  Person:
+    type: object
+    additionalProperties: false
+    properties:
+      status:
+        type: Status
+        default: active
+      pets: Pets
+    examples:
+      first:
+        status: active
+        pets: [null]
+      second:
+        displayName: Second
+        strict: false
+        value:
+          status: inactive
+          pets: [null]
+`,
+			want: `#%RAML 1.0 Library
+usage: Test library.
+types:
+  Status:
+    type: string
+    description: Status of the account.
+    enum:
+      - active
+      - inactive
+  Code:
+    type: integer
+    minimum: 1
+    maximum: 10
+    multipleOf: 2
+  Pet:
+    type: object
+    properties:
+      kind: string
+      name:
+        type: string
+        pattern: ^[A-Z]
+        maxLength: 10
+        required: false
+      /^x-/: any
+    discriminator: kind
+  Cat:
+    type: Pet
+    discriminatorValue: cat
+  Dog: Pet
+  Animal: Cat | Dog | nil
+  Pets:
+    type: array
+    items: Animal
+    minItems: 1
+    uniqueItems: true
+  Person:
+    type: object
+    properties:
+      status:
+        type: Status
+        default: active
+      pets: Pets
+    additionalProperties: false
+    examples:
+      first:
+        pets:
+          - null
+        status: active
+      second:
+        displayName: Second
+        strict: false
+        value:
+          pets:
+            - null
+          status: inactive
+`,
+		},
+		{
+			name: "positive: annotations, custom facets and declarations",
+			content: `#%RAML 1.0 Library
+usage: Test library.
+(note): library
+annotationTypes:
+  note: string
+  level:
+    type: integer
+    minimum: 1
+types:
+  Base:
+    type: object
+    facets:
+      kind: string
+      weight?: integer
+  Thing:
+    type: Base
+    kind: a
+    (note): thing
+    (level): 2
+    example:
+      displayName: First
+      (note): example
+      value: {}
+traits:
+  paged:
+    queryParameters:
+      page: integer
+`,
+			want: `#%RAML 1.0 Library
+usage: Test library.
+(note): library
+annotationTypes:
+  note: string
+  level:
+    type: integer
+    minimum: 1
+types:
+  Base:
+    type: object
+    facets:
+      kind: string
+      weight:
+        type: integer
+        required: false
+  Thing:
+    type: Base
+    kind: a
+    example:
+      displayName: First
+      (note): example
+      value: {}
+    (note): thing
+    (level): 2
+traits:
+  paged:
+    queryParameters:
+      page: integer
+`,
+		},
+		{
+			name: "positive: inline types",
+			content: `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet:
+    properties:
+      kind: string
+      next?: Pet
+  Cat:
+    type: Pet
+    discriminatorValue: cat
+    facets:
+      lives?: integer
+  Animal: Cat | nil
+  Tiger:
+    type: Cat
+    lives: 9
+  Pets:
+    type: array
+    items:
+      type: Pet | Cat
+  Code:
+    type: string
+    minLength: 1
+  Box:
+    properties:
+      content: Pet | Code
+      tag:
+        type: string | Code
+  Label: common.Tag | nil
+`,
+			files: map[string]string{
+				"common.raml": "#%RAML 1.0 Library\ntypes:\n  Tag:\n    type: string\n    maxLength: 5\n",
+			},
+			opts:   []EncoderOpt{WithInlineTypes()},
+			unwrap: true,
+			want: `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet:
+    type: object
+    properties:
+      kind: string
+      next:
+        type: Pet
+        required: false
+  Cat:
+    type: object
+    properties:
+      kind: string
+      next:
+        type: Pet
+        required: false
+    discriminatorValue: cat
+    facets:
+      lives:
+        type: integer
+        required: false
+  Animal: Cat | nil
+  Tiger:
+    type: object
+    properties:
+      kind: string
+      next:
+        type: Pet
+        required: false
+  Pets:
+    type: array
+    items: Pet | Cat
+  Code:
+    type: string
+    minLength: 1
+  Box:
+    type: object
+    properties:
+      content: Pet | Code
+      tag: string | Code
+  CommonTag:
+    type: string
+    maxLength: 5
+  Label: CommonTag | nil
+`,
+		},
+		{
+			name: "negative: inline types require unwrapped shapes",
+			content: `#%RAML 1.0 Library
+types:
+  Code: string
+`,
+			opts:    []EncoderOpt{WithInlineTypes()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+			var opts []ParseOpt
+			if tt.unwrap {
+				opts = append(opts, OptWithUnwrap())
+			}
+			r, err := ParseFromString(tt.content, "library.raml", dir, opts...)
+			require.NoError(t, err)
+			lib, ok := r.EntryPoint().(*Library)
+			require.True(t, ok)
+
+			var buf bytes.Buffer
+			err = NewEncoder(&buf, tt.opts...).EncodeLibrary(lib)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EncodeLibrary() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			require.Equal(t, tt.want, buf.String())
+
+			// Encoded library must be parsed and validated successfully.
+			_, err = ParseFromString(buf.String(), "library.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEncoder_EncodeDataType(t *testing.T) {
+	common := `#%RAML 1.0 Library
+types:
+  Tag:
+    type: string
+    maxLength: 5
+  Pet:
+    properties:
+      name: string
+`
+	tests := []struct {
+		name    string
+		content string
+		opts    []EncoderOpt
+		unwrap  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "positive: references",
+			content: `#%RAML 1.0 DataType
+usage: Test data type.
+uses:
+  common: common.raml
+type: common.Pet
+properties:
+  tags:
+    type: array
+    items: common.Tag
+example:
+  name: Rex
+  tags: [good]
+`,
+			want: `#%RAML 1.0 DataType
+usage: Test data type.
+uses:
+  common: common.raml
+type: common.Pet
+properties:
+  tags:
+    type: array
+    items: common.Tag
+example:
+  name: Rex
+  tags:
+    - good
+`,
+		},
+		{
+			name: "positive: short form",
+			content: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: common.Tag
+`,
+			want: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: common.Tag
+`,
+		},
+		{
+			name: "positive: inline types",
+			content: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: common.Pet
+properties:
+  tag: common.Tag
+`,
+			opts:   []EncoderOpt{WithInlineTypes()},
+			unwrap: true,
+			want: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: object
+properties:
+  tag:
+    type: string
+    maxLength: 5
+  name: string
+`,
+		},
+		{
+			name: "negative: union members cannot be declared",
+			content: `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+type: common.Pet | common.Tag
+`,
+			opts:    []EncoderOpt{WithInlineTypes()},
+			unwrap:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(common), 0o600))
+			var opts []ParseOpt
+			if tt.unwrap {
+				opts = append(opts, OptWithUnwrap())
+			}
+			r, err := ParseFromString(tt.content, "type.raml", dir, opts...)
+			require.NoError(t, err)
+			dt, ok := r.EntryPoint().(*DataType)
+			require.True(t, ok)
+
+			var buf bytes.Buffer
+			err = NewEncoder(&buf, tt.opts...).EncodeDataType(dt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EncodeDataType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			require.Equal(t, tt.want, buf.String())
+
+			// Encoded data type must be parsed and validated successfully.
+			_, err = ParseFromString(buf.String(), "type.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEncoder_EncodeAPI(t *testing.T) {
+	common := `#%RAML 1.0 Library
+types:
+  Pet:
+    properties:
+      name: string
+`
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "positive: resources, methods and library types",
+			content: `#%RAML 1.0
+title: Pets
+version: v1
+baseUri: https://{region}.example.com/{version}
+baseUriParameters:
+  region:
+    enum: [eu, us]
+mediaType: application/json
+uses:
+  common: common.raml
+securitySchemes:
+  basic:
+    type: Basic Authentication
+/pets:
+  displayName: Pets
+  get:
+    securedBy: [basic, null]
+    queryParameters:
+      limit:
+        type: integer
+        required: false
+    responses:
+      200:
+        description: Pets.
+        body:
+          application/json: common.Pet[]
+  /{id}:
+    put:
+      body:
+        application/json: common.Pet
+`,
+			want: `#%RAML 1.0
+title: Pets
+version: v1
+baseUri: https://{region}.example.com/{version}
+baseUriParameters:
+  region:
+    type: string
+    enum:
+      - eu
+      - us
+mediaType: application/json
+uses:
+  common: common.raml
+securitySchemes:
+  basic:
+    type: Basic Authentication
+/pets:
+  displayName: Pets
+  get:
+    securedBy: [basic, null]
+    queryParameters:
+      limit:
+        type: integer
+        required: false
+    responses:
+      "200":
+        description: Pets.
+        body:
+          application/json:
+            type: array
+            items: common.Pet
+  /{id}:
+    put:
+      body:
+        application/json: common.Pet
+`,
+		},
+		{
+			name: "positive: traits are applied",
+			content: `#%RAML 1.0
+title: Pets
+traits:
+  paged:
+    queryParameters:
+      page: integer
+/pets:
+  get:
+    is: [paged]
+`,
+			want: `#%RAML 1.0
+title: Pets
+/pets:
+  get:
+    queryParameters:
+      page: integer
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(common), 0o600))
+			r, err := ParseFromString(tt.content, "api.raml", dir)
+			require.NoError(t, err)
+			api, ok := r.EntryPoint().(*API)
+			require.True(t, ok)
+
+			var buf bytes.Buffer
+			require.NoError(t, NewEncoder(&buf).EncodeAPI(api))
+			require.Equal(t, tt.want, buf.String())
+
+			// Encoded API must be parsed and validated successfully.
+			_, err = ParseFromString(buf.String(), "api.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+		})
+	}
+}
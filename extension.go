@@ -37,7 +37,9 @@ func (r *RAML) unmarshalCustomDomainExtension(location string, keyNode *yaml.Nod
 		Position:  stacktrace.Position{Line: keyNode.Line, Column: keyNode.Column},
 		raml:      r,
 	}
+	r.mu.Lock()
 	r.domainExtensions = append(r.domainExtensions, de)
+	r.mu.Unlock()
 	return name, de, nil
 }
 
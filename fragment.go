@@ -16,6 +16,14 @@ const (
 	FragmentLibrary
 	FragmentDataType
 	FragmentNamedExample
+	FragmentAPI
+	FragmentTrait
+	FragmentResourceType
+	FragmentSecurityScheme
+	FragmentAnnotationTypeDeclaration
+	FragmentDocumentationItem
+	FragmentOverlay
+	FragmentExtension
 )
 
 // CutReferenceName cuts a reference name into two parts: before and after the dot.
@@ -48,10 +56,11 @@ type Library struct {
 	ID              string
 	Usage           string
 	AnnotationTypes *orderedmap.OrderedMap[string, *BaseShape]
-	// TODO: Specific to API fragments. Not supported yet.
-	// ResourceTypes   map[string]interface{} `yaml:"resourceTypes"`
-	Types *orderedmap.OrderedMap[string, *BaseShape]
-	Uses  *orderedmap.OrderedMap[string, *LibraryLink]
+	ResourceTypes   *orderedmap.OrderedMap[string, *ResourceType]
+	Traits          *orderedmap.OrderedMap[string, *Trait]
+	SecuritySchemes *orderedmap.OrderedMap[string, *SecurityScheme]
+	Types           *orderedmap.OrderedMap[string, *BaseShape]
+	Uses            *orderedmap.OrderedMap[string, *LibraryLink]
 
 	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
 
@@ -61,69 +70,29 @@ type Library struct {
 
 // GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
 func (l *Library) GetReferenceType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	//nolint:nestif // Contains simple checks.
-	if !found {
-		rr, ok := l.Types.Get(refName)
-		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", refName)
-		}
-		ref = rr
-	} else {
-		// If reference name has dots, verify if it's a reference to a local type first
-		rr, hasType := l.Types.Get(refName)
-		if !hasType {
-			// If it's not, then check external references
-			lib, ok := l.Uses.Get(before)
-			if !ok {
-				return nil, fmt.Errorf("library \"%s\" not found", before)
-			}
-			rr, ok = lib.Link.Types.Get(after)
-			if !ok {
-				return nil, fmt.Errorf("reference \"%s\" not found", after)
-			}
-		}
-		ref = rr
-	}
-
-	return ref, nil
+	return getReference(l.Types, l.Uses, refName, "reference", libraryTypes)
 }
 
 // GetReferenceAnnotationType returns a reference annotation type by name,
 // implementing the ReferenceAnnotationTypeGetter interface
 func (l *Library) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
+	return getReference(l.AnnotationTypes, l.Uses, refName, "reference", libraryAnnotationTypes)
+}
 
-	var ref *BaseShape
+// GetReferenceResourceType returns a resource type by name, implementing the ReferenceResourceTypeGetter interface
+func (l *Library) GetReferenceResourceType(refName string) (*ResourceType, error) {
+	return getReference(l.ResourceTypes, l.Uses, refName, "resource type", libraryResourceTypes)
+}
 
-	//nolint:nestif // Contains simple checks.
-	if !found {
-		rr, ok := l.AnnotationTypes.Get(refName)
-		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", refName)
-		}
-		ref = rr
-	} else {
-		// If reference name has dots, verify if it's a reference to a local type first
-		rr, isType := l.AnnotationTypes.Get(refName)
-		if !isType {
-			// If it's not, then check external references
-			lib, ok := l.Uses.Get(before)
-			if !ok {
-				return nil, fmt.Errorf("library \"%s\" not found", before)
-			}
-			rr, ok = lib.Link.AnnotationTypes.Get(after)
-			if !ok {
-				return nil, fmt.Errorf("reference \"%s\" not found", after)
-			}
-		}
-		ref = rr
-	}
+// GetReferenceTrait returns a trait by name, implementing the ReferenceTraitGetter interface
+func (l *Library) GetReferenceTrait(refName string) (*Trait, error) {
+	return getReference(l.Traits, l.Uses, refName, "trait", libraryTraits)
+}
 
-	return ref, nil
+// GetReferenceSecurityScheme returns a security scheme by name,
+// implementing the ReferenceSecuritySchemeGetter interface
+func (l *Library) GetReferenceSecurityScheme(refName string) (*SecurityScheme, error) {
+	return getReference(l.SecuritySchemes, l.Uses, refName, "security scheme", librarySecuritySchemes)
 }
 
 func (l *Library) GetLocation() string {
@@ -201,7 +170,7 @@ func (l *Library) unmarshalAnnotationTypes(valueNode *yaml.Node) error {
 	for j := 0; j != len(valueNode.Content); j += 2 {
 		name := valueNode.Content[j].Value
 		data := valueNode.Content[j+1]
-		shape, err := l.raml.makeNewShapeYAML(data, name, l.Location)
+		shape, err := l.raml.makeAnnotationTypeShape(data, name, l.Location)
 		if err != nil {
 			return StacktraceNewWrapped("parse annotation types: make shape", err, l.Location, WithNodePosition(data))
 		}
@@ -234,6 +203,24 @@ func (l *Library) UnmarshalYAML(value *yaml.Node) error {
 			if err := l.unmarshalAnnotationTypes(valueNode); err != nil {
 				return fmt.Errorf("unmarshall annotation types: %w", err)
 			}
+		case "resourceTypes":
+			resourceTypes, err := l.raml.makeResourceTypes(valueNode, l.Location)
+			if err != nil {
+				return fmt.Errorf("make resource types: %w", err)
+			}
+			l.ResourceTypes = resourceTypes
+		case "traits":
+			traits, err := l.raml.makeTraits(valueNode, l.Location)
+			if err != nil {
+				return fmt.Errorf("make traits: %w", err)
+			}
+			l.Traits = traits
+		case "securitySchemes":
+			securitySchemes, err := l.raml.makeSecuritySchemes(valueNode, l.Location)
+			if err != nil {
+				return fmt.Errorf("make security schemes: %w", err)
+			}
+			l.SecuritySchemes = securitySchemes
 		case "usage":
 			if err := valueNode.Decode(&l.Usage); err != nil {
 				return StacktraceNewWrapped("parse usage: value node decode", err, l.Location,
@@ -260,6 +247,9 @@ func (r *RAML) MakeLibrary(path string) *Library {
 		Uses:                   orderedmap.New[string, *LibraryLink](0),
 		Types:                  orderedmap.New[string, *BaseShape](0),
 		AnnotationTypes:        orderedmap.New[string, *BaseShape](0),
+		ResourceTypes:          orderedmap.New[string, *ResourceType](0),
+		Traits:                 orderedmap.New[string, *Trait](0),
+		SecuritySchemes:        orderedmap.New[string, *SecurityScheme](0),
 
 		Location: path,
 		raml:     r,
@@ -279,47 +269,14 @@ type DataType struct {
 
 // GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
 func (dt *DataType) GetReferenceType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	if !found {
-		return nil, fmt.Errorf("invalid reference %s", refName)
-	}
 	// NOTE: DataType does not define local types, only references to library types
-	lib, ok := dt.Uses.Get(before)
-	if !ok {
-		return nil, fmt.Errorf("library \"%s\" not found", before)
-	}
-	ref, ok = lib.Link.Types.Get(after)
-	if !ok {
-		return nil, fmt.Errorf("reference \"%s\" not found", after)
-	}
-
-	return ref, nil
+	return getLibraryReference(dt.Uses, refName, "reference", libraryTypes)
 }
 
 // GetReferenceAnnotationType returns a reference annotation type by name,
 // implementing the ReferenceAnnotationTypeGetter interface
 func (dt *DataType) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	if !found {
-		return nil, fmt.Errorf("invalid reference %s", refName)
-	}
-	// NOTE: DataType does not define local types, only references to library types
-	lib, ok := dt.Uses.Get(before)
-	if !ok {
-		return nil, fmt.Errorf("library \"%s\" not found", before)
-	}
-	ref, ok = lib.Link.AnnotationTypes.Get(after)
-	if !ok {
-		return nil, fmt.Errorf("reference \"%s\" not found", after)
-	}
-
-	return ref, nil
+	return getLibraryReference(dt.Uses, refName, "reference", libraryAnnotationTypes)
 }
 
 func (dt *DataType) GetLocation() string {
@@ -450,3 +407,348 @@ func (ne *NamedExample) UnmarshalYAML(value *yaml.Node) error {
 
 	return nil
 }
+
+// getReference returns a declaration of the fragment by name, or a declaration of a used library if the name
+// is prefixed with its alias and not declared locally. Kind names the declaration in errors, e.g. "trait".
+func getReference[V any](
+	local *orderedmap.OrderedMap[string, V],
+	uses *orderedmap.OrderedMap[string, *LibraryLink],
+	refName string,
+	kind string,
+	declarations func(*Library) *orderedmap.OrderedMap[string, V],
+) (V, error) {
+	// Names with dots may be declared locally, so local declarations are checked first.
+	if ref, ok := local.Get(refName); ok {
+		return ref, nil
+	}
+	if _, _, found := CutReferenceName(refName); !found {
+		var zero V
+		return zero, fmt.Errorf("%s \"%s\" not found", kind, refName)
+	}
+	return getLibraryReference(uses, refName, kind, declarations)
+}
+
+// getLibraryReference returns a declaration referenced via "uses" of the fragment that does not declare it locally.
+// Kind names the declaration in errors, e.g. "trait".
+func getLibraryReference[V any](
+	uses *orderedmap.OrderedMap[string, *LibraryLink],
+	refName string,
+	kind string,
+	declarations func(*Library) *orderedmap.OrderedMap[string, V],
+) (V, error) {
+	var zero V
+	before, after, found := CutReferenceName(refName)
+	if !found {
+		return zero, fmt.Errorf("invalid reference %s", refName)
+	}
+	lib, ok := uses.Get(before)
+	if !ok {
+		return zero, fmt.Errorf("library \"%s\" not found", before)
+	}
+	if lib.Link == nil {
+		return zero, fmt.Errorf("library \"%s\" is not loaded", before)
+	}
+	ref, ok := declarations(lib.Link).Get(after)
+	if !ok {
+		return zero, fmt.Errorf("%s \"%s\" not found", kind, after)
+	}
+	return ref, nil
+}
+
+func libraryTypes(l *Library) *orderedmap.OrderedMap[string, *BaseShape] {
+	return l.Types
+}
+
+func libraryAnnotationTypes(l *Library) *orderedmap.OrderedMap[string, *BaseShape] {
+	return l.AnnotationTypes
+}
+
+func libraryResourceTypes(l *Library) *orderedmap.OrderedMap[string, *ResourceType] {
+	return l.ResourceTypes
+}
+
+func libraryTraits(l *Library) *orderedmap.OrderedMap[string, *Trait] {
+	return l.Traits
+}
+
+func librarySecuritySchemes(l *Library) *orderedmap.OrderedMap[string, *SecurityScheme] {
+	return l.SecuritySchemes
+}
+
+// makeLibraryLinks creates library links from the "uses" node.
+func makeLibraryLinks(valueNode *yaml.Node, location string) (*orderedmap.OrderedMap[string, *LibraryLink], error) {
+	if valueNode.Tag == TagNull {
+		return orderedmap.New[string, *LibraryLink](0), nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("uses must be map", location, WithNodePosition(valueNode))
+	}
+	uses := orderedmap.New[string, *LibraryLink](len(valueNode.Content) / 2)
+	// Map nodes come in pairs in order [key, value]
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		path := valueNode.Content[j+1]
+		uses.Set(name, &LibraryLink{
+			Value:    path.Value,
+			Location: location,
+			Position: stacktrace.Position{Line: path.Line, Column: path.Column},
+		})
+	}
+	return uses, nil
+}
+
+// splitFragmentUses separates the "uses" node from the rest of the fragment nodes.
+func splitFragmentUses(
+	value *yaml.Node,
+	location string,
+) (*orderedmap.OrderedMap[string, *LibraryLink], *yaml.Node, error) {
+	if value.Kind != yaml.MappingNode {
+		return nil, nil, StacktraceNew("must be map", location, WithNodePosition(value))
+	}
+	uses := orderedmap.New[string, *LibraryLink](0)
+	rest := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column}
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		if node.Value == "uses" {
+			links, err := makeLibraryLinks(valueNode, location)
+			if err != nil {
+				return nil, nil, fmt.Errorf("make library links: %w", err)
+			}
+			uses = links
+			continue
+		}
+		rest.Content = append(rest.Content, node, valueNode)
+	}
+	return uses, rest, nil
+}
+
+// TraitFragment is the RAML 1.0 Trait
+type TraitFragment struct {
+	ID    string
+	Uses  *orderedmap.OrderedMap[string, *LibraryLink]
+	Trait *Trait
+
+	Location string
+	raml     *RAML
+}
+
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+func (tf *TraitFragment) GetReferenceType(refName string) (*BaseShape, error) {
+	// NOTE: Trait does not define local types, only references to library types
+	return getLibraryReference(tf.Uses, refName, "reference", libraryTypes)
+}
+
+// GetReferenceAnnotationType returns a reference annotation type by name,
+// implementing the ReferenceAnnotationTypeGetter interface
+func (tf *TraitFragment) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
+	return getLibraryReference(tf.Uses, refName, "reference", libraryAnnotationTypes)
+}
+
+// GetReferenceSecurityScheme returns a security scheme by name,
+// implementing the ReferenceSecuritySchemeGetter interface
+func (tf *TraitFragment) GetReferenceSecurityScheme(refName string) (*SecurityScheme, error) {
+	return getLibraryReference(tf.Uses, refName, "security scheme", librarySecuritySchemes)
+}
+
+func (tf *TraitFragment) GetLocation() string {
+	return tf.Location
+}
+
+// UnmarshalYAML unmarshals a TraitFragment from a yaml.Node, implementing the yaml.Unmarshaler interface
+func (tf *TraitFragment) UnmarshalYAML(value *yaml.Node) error {
+	uses, node, err := splitFragmentUses(value, tf.Location)
+	if err != nil {
+		return fmt.Errorf("split fragment uses: %w", err)
+	}
+	tf.Uses = uses
+	node, usage, err := makeTemplateNode(filepath.Base(tf.Location), node, tf.Location)
+	if err != nil {
+		return fmt.Errorf("make template node: %w", err)
+	}
+	tf.Trait = &Trait{
+		Name:     filepath.Base(tf.Location),
+		Usage:    usage,
+		Node:     node,
+		Location: tf.Location,
+		Position: stacktrace.Position{Line: value.Line, Column: value.Column},
+	}
+	return nil
+}
+
+func (r *RAML) MakeTraitFragment(path string) *TraitFragment {
+	return &TraitFragment{
+		Uses: orderedmap.New[string, *LibraryLink](0),
+
+		Location: path,
+		raml:     r,
+	}
+}
+
+// ResourceTypeFragment is the RAML 1.0 ResourceType
+type ResourceTypeFragment struct {
+	ID           string
+	Uses         *orderedmap.OrderedMap[string, *LibraryLink]
+	ResourceType *ResourceType
+
+	Location string
+	raml     *RAML
+}
+
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+func (rf *ResourceTypeFragment) GetReferenceType(refName string) (*BaseShape, error) {
+	// NOTE: ResourceType does not define local types, only references to library types
+	return getLibraryReference(rf.Uses, refName, "reference", libraryTypes)
+}
+
+// GetReferenceAnnotationType returns a reference annotation type by name,
+// implementing the ReferenceAnnotationTypeGetter interface
+func (rf *ResourceTypeFragment) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
+	return getLibraryReference(rf.Uses, refName, "reference", libraryAnnotationTypes)
+}
+
+// GetReferenceResourceType returns a resource type by name, implementing the ReferenceResourceTypeGetter interface
+func (rf *ResourceTypeFragment) GetReferenceResourceType(refName string) (*ResourceType, error) {
+	return getLibraryReference(rf.Uses, refName, "resource type", libraryResourceTypes)
+}
+
+// GetReferenceTrait returns a trait by name, implementing the ReferenceTraitGetter interface
+func (rf *ResourceTypeFragment) GetReferenceTrait(refName string) (*Trait, error) {
+	return getLibraryReference(rf.Uses, refName, "trait", libraryTraits)
+}
+
+// GetReferenceSecurityScheme returns a security scheme by name,
+// implementing the ReferenceSecuritySchemeGetter interface
+func (rf *ResourceTypeFragment) GetReferenceSecurityScheme(refName string) (*SecurityScheme, error) {
+	return getLibraryReference(rf.Uses, refName, "security scheme", librarySecuritySchemes)
+}
+
+func (rf *ResourceTypeFragment) GetLocation() string {
+	return rf.Location
+}
+
+// UnmarshalYAML unmarshals a ResourceTypeFragment from a yaml.Node, implementing the yaml.Unmarshaler interface
+func (rf *ResourceTypeFragment) UnmarshalYAML(value *yaml.Node) error {
+	uses, node, err := splitFragmentUses(value, rf.Location)
+	if err != nil {
+		return fmt.Errorf("split fragment uses: %w", err)
+	}
+	rf.Uses = uses
+	node, usage, err := makeTemplateNode(filepath.Base(rf.Location), node, rf.Location)
+	if err != nil {
+		return fmt.Errorf("make template node: %w", err)
+	}
+	rf.ResourceType = &ResourceType{
+		Name:     filepath.Base(rf.Location),
+		Usage:    usage,
+		Node:     node,
+		Location: rf.Location,
+		Position: stacktrace.Position{Line: value.Line, Column: value.Column},
+	}
+	return nil
+}
+
+func (r *RAML) MakeResourceTypeFragment(path string) *ResourceTypeFragment {
+	return &ResourceTypeFragment{
+		Uses: orderedmap.New[string, *LibraryLink](0),
+
+		Location: path,
+		raml:     r,
+	}
+}
+
+// SecuritySchemeFragment is the RAML 1.0 SecurityScheme
+type SecuritySchemeFragment struct {
+	ID             string
+	Uses           *orderedmap.OrderedMap[string, *LibraryLink]
+	SecurityScheme *SecurityScheme
+
+	Location string
+	raml     *RAML
+}
+
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+func (sf *SecuritySchemeFragment) GetReferenceType(refName string) (*BaseShape, error) {
+	// NOTE: SecurityScheme does not define local types, only references to library types
+	return getLibraryReference(sf.Uses, refName, "reference", libraryTypes)
+}
+
+// GetReferenceAnnotationType returns a reference annotation type by name,
+// implementing the ReferenceAnnotationTypeGetter interface
+func (sf *SecuritySchemeFragment) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
+	return getLibraryReference(sf.Uses, refName, "reference", libraryAnnotationTypes)
+}
+
+func (sf *SecuritySchemeFragment) GetLocation() string {
+	return sf.Location
+}
+
+// UnmarshalYAML unmarshals a SecuritySchemeFragment from a yaml.Node, implementing the yaml.Unmarshaler interface
+func (sf *SecuritySchemeFragment) UnmarshalYAML(value *yaml.Node) error {
+	uses, node, err := splitFragmentUses(value, sf.Location)
+	if err != nil {
+		return fmt.Errorf("split fragment uses: %w", err)
+	}
+	sf.Uses = uses
+	ss, err := sf.raml.makeSecurityScheme(filepath.Base(sf.Location), node, sf.Location)
+	if err != nil {
+		return fmt.Errorf("make security scheme: %w", err)
+	}
+	sf.SecurityScheme = ss
+	return nil
+}
+
+func (r *RAML) MakeSecuritySchemeFragment(path string) *SecuritySchemeFragment {
+	return &SecuritySchemeFragment{
+		Uses: orderedmap.New[string, *LibraryLink](0),
+
+		Location: path,
+		raml:     r,
+	}
+}
+
+// AnnotationTypeDeclaration is the RAML 1.0 AnnotationTypeDeclaration.
+// It shares the structure with DataType, but the declared shape is used as an annotation type.
+type AnnotationTypeDeclaration struct {
+	DataType
+}
+
+func (r *RAML) MakeAnnotationTypeDeclaration(path string) *AnnotationTypeDeclaration {
+	return &AnnotationTypeDeclaration{DataType: *r.MakeDataType(path)}
+}
+
+// makeAnnotationTypeShape creates an annotation type shape from the given value node.
+// Unlike data types, annotation types may include AnnotationTypeDeclaration fragments.
+func (r *RAML) makeAnnotationTypeShape(v *yaml.Node, name string, location string) (*BaseShape, error) {
+	if v.Kind != yaml.ScalarNode || v.Tag != TagInclude {
+		return r.makeNewShapeYAML(v, name, location)
+	}
+	atd, err := r.parseAnnotationTypeDeclaration(r.includedLocation(v, location))
+	if err != nil {
+		return nil, StacktraceNewWrapped("parse annotation type declaration", err, location, WithNodePosition(v))
+	}
+	base := r.MakeBaseShape(name, location, stacktrace.Position{Line: v.Line, Column: v.Column})
+	base.TypeLabel = v.Value
+	base.Link = &atd.DataType
+	// NOTE: Linked shape is resolved in a separate stage, same as included data types.
+	if _, err = r.MakeConcreteShapeYAML(base, "", nil); err != nil {
+		return nil, StacktraceNewWrapped("make concrete shape", err, location, WithNodePosition(v))
+	}
+	r.unresolvedShapes.PushBack(base)
+	return base, nil
+}
+
+// GetReferenceAnnotationType returns a reference annotation type by name,
+// implementing the ReferenceAnnotationTypeGetter interface
+func (di *DocumentationItem) GetReferenceAnnotationType(_ string) (*BaseShape, error) {
+	return nil, fmt.Errorf("documentation item does not have references")
+}
+
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+func (di *DocumentationItem) GetReferenceType(_ string) (*BaseShape, error) {
+	return nil, fmt.Errorf("documentation item does not have references")
+}
+
+func (di *DocumentationItem) GetLocation() string {
+	return di.Location
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
 )
@@ -1853,3 +1854,105 @@ func TestNamedExample_UnmarshalYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_ParseFragments(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/fragments/api.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+
+	require.Len(t, api.Documentation, 2)
+	require.Equal(t, "Getting Started", api.Documentation[0].Title)
+	require.Equal(t, "Changelog", api.Documentation[1].Title)
+	require.Equal(t, "# Changelog\n\nInitial release.\n", api.Documentation[1].Content)
+
+	scheme, ok := api.SecuritySchemes.Get("oauth_2_0")
+	require.True(t, ok)
+	require.Equal(t, "oauth_2_0", scheme.Name)
+	require.Equal(t, "OAuth 2.0", scheme.Type)
+
+	audit, ok := api.AnnotationTypes.Get("audit")
+	require.True(t, ok)
+	_, ok = audit.Shape.(*ObjectShape)
+	require.True(t, ok)
+
+	songs, ok := api.Resources.Get("/songs")
+	require.True(t, ok)
+	get, ok := songs.Methods.Get("get")
+	require.True(t, ok)
+	require.Equal(t, []string{"searchable"}, get.Is)
+	_, ok = get.QueryParameters.Get("title")
+	require.True(t, ok)
+
+	song, ok := songs.Resources.Get("/{songId}")
+	require.True(t, ok)
+	require.Equal(t, "item", song.Type)
+	_, ok = song.CustomDomainProperties.Get("audit")
+	require.True(t, ok)
+	get, ok = song.Methods.Get("get")
+	require.True(t, ok)
+	// Error is declared in the library used by the resource type fragment and must be resolved in its scope.
+	resp, ok := get.Responses.Get("404")
+	require.True(t, ok)
+	body, ok := resp.Body.Get("application/json")
+	require.True(t, ok)
+	require.Contains(t, body.Shape.Location, "item_resource_type.raml")
+	_, ok = body.Shape.Shape.(*ObjectShape)
+	require.True(t, ok)
+}
+
+func TestRAML_ParseTraitFragment(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/fragments/searchable_trait.raml")
+	require.NoError(t, err)
+	tf, ok := rml.EntryPoint().(*TraitFragment)
+	require.True(t, ok)
+	require.Equal(t, "Applied to searchable collections.", tf.Trait.Usage)
+	_, ok = tf.Uses.Get("common")
+	require.True(t, ok)
+}
+
+func Test_getReference(t *testing.T) {
+	secured := &Trait{Name: "secured"}
+	paged := &Trait{Name: "paged"}
+	dotted := &Trait{Name: "ext.paged"}
+	local := orderedmap.New[string, *Trait](0)
+	local.Set("secured", secured)
+	local.Set("ext.paged", dotted)
+	libTraits := orderedmap.New[string, *Trait](0)
+	libTraits.Set("paged", paged)
+	uses := orderedmap.New[string, *LibraryLink](0)
+	uses.Set("lib", &LibraryLink{Link: &Library{Traits: libTraits}})
+	uses.Set("broken", &LibraryLink{})
+
+	tests := []struct {
+		name    string
+		refName string
+		want    *Trait
+		wantErr string
+	}{
+		{name: "positive: local declaration", refName: "secured", want: secured},
+		{name: "positive: used library", refName: "lib.paged", want: paged},
+		{name: "positive: local declaration with dots", refName: "ext.paged", want: dotted},
+		{name: "negative: unknown local declaration", refName: "unknown", wantErr: `trait "unknown" not found`},
+		{name: "negative: unknown library", refName: "other.paged", wantErr: `library "other" not found`},
+		{name: "negative: unknown library declaration", refName: "lib.unknown", wantErr: `trait "unknown" not found`},
+		{name: "negative: library is not loaded", refName: "broken.paged", wantErr: `library "broken" is not loaded`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getReference(local, uses, tt.refName, "trait", libraryTraits)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Same(t, tt.want, got)
+		})
+	}
+
+	got, err := getLibraryReference(uses, "lib.paged", "trait", libraryTraits)
+	require.NoError(t, err)
+	require.Same(t, paged, got)
+	_, err = getLibraryReference(uses, "secured", "trait", libraryTraits)
+	require.EqualError(t, err, "invalid reference secured")
+}
@@ -0,0 +1,834 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const goGeneratorDefaultPackage = "types"
+
+// commonGoInitialisms contains words that are written in upper case in Go identifiers.
+var commonGoInitialisms = map[string]struct{}{
+	"ACL": {}, "API": {}, "CPU": {}, "CSS": {}, "DNS": {}, "HTML": {}, "HTTP": {}, "HTTPS": {}, "ID": {}, "IP": {},
+	"JSON": {}, "SQL": {}, "TCP": {}, "TLS": {}, "TTL": {}, "UDP": {}, "UI": {}, "URI": {}, "URL": {}, "UUID": {},
+	"XML": {},
+}
+
+type GoGeneratorOptions struct {
+	packageName string
+}
+
+type GoGeneratorOpt interface {
+	apply(*GoGeneratorOptions)
+}
+
+type optPackageName struct{ name string }
+
+func (o optPackageName) apply(c *GoGeneratorOptions) { c.packageName = o.name }
+
+// WithPackageName sets the package name of the generated file. Defaults to "types".
+func WithPackageName(name string) GoGeneratorOpt {
+	return optPackageName{name}
+}
+
+// goDecl is a top-level declaration of the generated file.
+type goDecl struct {
+	code string
+}
+
+// goUnion describes a discriminated union that is generated as an interface.
+type goUnion struct {
+	name          string
+	discriminator string
+	members       []string
+	values        []any
+}
+
+// GoGenerator generates Go types from unwrapped shapes of the entry point.
+// Objects are generated as structs, optional properties as pointers, pattern properties as maps,
+// discriminated unions as interfaces and string enums as typed constants.
+type GoGenerator struct {
+	ShapeVisitor[string]
+
+	raml *RAML
+	// names contains Go type names of named shapes by shape ID.
+	names map[int64]string
+	// taken contains Go type names that are already in use.
+	taken map[string]struct{}
+	// generated contains IDs of shapes whose declarations are already generated.
+	generated map[int64]struct{}
+	// nilable contains Go type names that can be assigned nil.
+	nilable map[string]struct{}
+	// unions contains discriminated unions by Go type name.
+	unions  map[string]*goUnion
+	imports map[string]struct{}
+	decls   []*goDecl
+	// hint is used to name anonymous types, it is the name of the enclosing type and property.
+	hint string
+
+	opts GoGeneratorOptions
+}
+
+func NewGoGenerator(r *RAML, opt ...GoGeneratorOpt) *GoGenerator {
+	g := &GoGenerator{
+		raml: r,
+		opts: GoGeneratorOptions{packageName: goGeneratorDefaultPackage},
+	}
+	for _, o := range opt {
+		o.apply(&g.opts)
+	}
+	return g
+}
+
+// Generate generates Go source code for types of the entry point and of the libraries it uses.
+// The entry point must be a library, an API or a data type fragment parsed with OptWithUnwrap.
+func (g *GoGenerator) Generate() ([]byte, error) {
+	g.names = make(map[int64]string)
+	g.taken = make(map[string]struct{})
+	g.generated = make(map[int64]struct{})
+	g.nilable = make(map[string]struct{})
+	g.unions = make(map[string]*goUnion)
+	g.imports = make(map[string]struct{})
+	g.decls = nil
+
+	types, err := g.collectTypes()
+	if err != nil {
+		return nil, fmt.Errorf("collect types: %w", err)
+	}
+	for _, base := range types {
+		if !base.IsUnwrapped() {
+			return nil, fmt.Errorf("type %s must be unwrapped", base.Name)
+		}
+	}
+	for _, base := range types {
+		g.generateNamed(base)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by raml gen go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", g.opts.packageName)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, strconv.Quote(imp))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	for _, decl := range g.decls {
+		b.WriteString(decl.code)
+		b.WriteString("\n")
+	}
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format source: %w", err)
+	}
+	return src, nil
+}
+
+// collectTypes returns the types of the entry point followed by the types of the used libraries
+// and assigns Go type names to them. Names that collide are prefixed with the library namespace.
+func (g *GoGenerator) collectTypes() ([]*BaseShape, error) {
+	var types []*BaseShape
+	visited := make(map[string]struct{})
+	var addLibraries func(uses *orderedmap.OrderedMap[string, *LibraryLink])
+	addTypes := func(namespace string, m *orderedmap.OrderedMap[string, *BaseShape]) {
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			name := goName(pair.Key)
+			if _, ok := g.taken[name]; ok && namespace != "" {
+				name = goName(namespace) + name
+			}
+			g.names[pair.Value.ID] = g.allocName(name)
+			types = append(types, pair.Value)
+		}
+	}
+	addLibraries = func(uses *orderedmap.OrderedMap[string, *LibraryLink]) {
+		for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+			lib := pair.Value.Link
+			if lib == nil {
+				continue
+			}
+			if _, ok := visited[lib.Location]; ok {
+				continue
+			}
+			visited[lib.Location] = struct{}{}
+			addTypes(pair.Key, lib.Types)
+			addLibraries(lib.Uses)
+		}
+	}
+
+	switch f := g.raml.EntryPoint().(type) {
+	case *Library:
+		visited[f.Location] = struct{}{}
+		addTypes("", f.Types)
+		addLibraries(f.Uses)
+	case *API:
+		visited[f.Location] = struct{}{}
+		addTypes("", f.Types)
+		addLibraries(f.Uses)
+	case *DataType:
+		name := strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+		if f.Shape.Name != "" {
+			name = f.Shape.Name
+		}
+		g.names[f.Shape.ID] = g.allocName(goName(name))
+		types = append(types, f.Shape)
+		addLibraries(f.Uses)
+	default:
+		return nil, fmt.Errorf("entry point must be a library, an api or a data type")
+	}
+	return types, nil
+}
+
+// generateNamed generates the declaration of a named type.
+func (g *GoGenerator) generateNamed(base *BaseShape) {
+	name := g.names[base.ID]
+	// NOTE: Named types that inherit from other types are generated as new types.
+	if len(base.Inherits) == 0 {
+		if ref, ok := g.namedRef(base); ok && ref != name {
+			g.addAlias(name, ref)
+			return
+		}
+	}
+	g.hint = name
+	t := g.Visit(base.Shape)
+	if t != name {
+		g.addAlias(name, t)
+	}
+}
+
+func (g *GoGenerator) addAlias(name, t string) {
+	if g.isNilable(t) {
+		g.nilable[name] = struct{}{}
+	}
+	if u, ok := g.unions[t]; ok {
+		g.unions[name] = u
+	}
+	g.decls = append(g.decls, &goDecl{code: fmt.Sprintf("type %s = %s\n", name, t)})
+}
+
+// namedRef returns the Go type name of the named type that the shape references.
+// Facets that do not change the structure, such as description or validation facets, are ignored.
+// References that add properties or inherit from multiple types are not considered as named references.
+func (g *GoGenerator) namedRef(base *BaseShape) (string, bool) {
+	if name, ok := g.names[base.ID]; ok {
+		return name, true
+	}
+	if base.TypeLabel == "" || len(base.Inherits) > 1 {
+		return "", false
+	}
+	ref, err := g.raml.GetReferencedType(base.TypeLabel, base.Location)
+	if err != nil || ref.ID == base.ID {
+		return "", false
+	}
+	name, ok := g.names[ref.ID]
+	if !ok {
+		return "", false
+	}
+	if obj, isObject := base.Shape.(*ObjectShape); isObject {
+		refObj, isRefObject := ref.Shape.(*ObjectShape)
+		if !isRefObject || obj.Properties.Len() != refObj.Properties.Len() ||
+			obj.PatternProperties.Len() != refObj.PatternProperties.Len() {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// typeOf returns the Go type of the nested shape, hint is used to name anonymous types.
+func (g *GoGenerator) typeOf(base *BaseShape, hint string) string {
+	if base == nil {
+		return "any"
+	}
+	if name, ok := g.namedRef(base); ok {
+		return name
+	}
+	prevHint := g.hint
+	g.hint = hint
+	defer func() { g.hint = prevHint }()
+	return g.Visit(base.Shape)
+}
+
+// ownDescription returns the description of the shape unless it is inherited from the referenced types.
+func (g *GoGenerator) ownDescription(base *BaseShape) *string {
+	if base.Description == nil {
+		return nil
+	}
+	parents := append([]*BaseShape{}, base.Inherits...)
+	if base.Alias != nil {
+		parents = append(parents, base.Alias)
+	}
+	if base.TypeLabel != "" {
+		if ref, err := g.raml.GetReferencedType(base.TypeLabel, base.Location); err == nil && ref.ID != base.ID {
+			parents = append(parents, ref)
+		}
+	}
+	for _, parent := range parents {
+		if parent.Description != nil && *parent.Description == *base.Description {
+			return nil
+		}
+	}
+	return base.Description
+}
+
+// nameOf returns the Go type name of the shape, allocating a new name from the hint for anonymous shapes.
+func (g *GoGenerator) nameOf(base *BaseShape) string {
+	if name, ok := g.names[base.ID]; ok {
+		return name
+	}
+	hint := g.hint
+	if hint == "" {
+		hint = goName(base.Name)
+	}
+	name := g.allocName(hint)
+	g.names[base.ID] = name
+	return name
+}
+
+func (g *GoGenerator) allocName(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := g.taken[candidate]; !ok {
+			break
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+	g.taken[candidate] = struct{}{}
+	return candidate
+}
+
+// startDecl reserves a declaration slot so that declarations of nested types follow their parents.
+func (g *GoGenerator) startDecl(base *BaseShape) (*goDecl, bool) {
+	if _, ok := g.generated[base.ID]; ok {
+		return nil, false
+	}
+	g.generated[base.ID] = struct{}{}
+	decl := &goDecl{}
+	g.decls = append(g.decls, decl)
+	return decl, true
+}
+
+func (g *GoGenerator) isNilable(t string) bool {
+	if strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") || strings.HasPrefix(t, "*") ||
+		t == "any" || t == "json.RawMessage" {
+		return true
+	}
+	_, ok := g.nilable[t]
+	return ok
+}
+
+func (g *GoGenerator) Visit(s Shape) string {
+	switch shapeType := s.(type) {
+	case *ObjectShape:
+		return g.VisitObjectShape(shapeType)
+	case *ArrayShape:
+		return g.VisitArrayShape(shapeType)
+	case *StringShape:
+		return g.VisitStringShape(shapeType)
+	case *NumberShape:
+		return g.VisitNumberShape(shapeType)
+	case *IntegerShape:
+		return g.VisitIntegerShape(shapeType)
+	case *BooleanShape:
+		return g.VisitBooleanShape(shapeType)
+	case *FileShape:
+		return g.VisitFileShape(shapeType)
+	case *UnionShape:
+		return g.VisitUnionShape(shapeType)
+	case *NilShape:
+		return g.VisitNilShape(shapeType)
+	case *AnyShape:
+		return g.VisitAnyShape(shapeType)
+	case *DateTimeShape:
+		return g.VisitDateTimeShape(shapeType)
+	case *DateTimeOnlyShape:
+		return g.VisitDateTimeOnlyShape(shapeType)
+	case *DateOnlyShape:
+		return g.VisitDateOnlyShape(shapeType)
+	case *TimeOnlyShape:
+		return g.VisitTimeOnlyShape(shapeType)
+	case *JSONShape:
+		return g.VisitJSONShape(shapeType)
+	case *RecursiveShape:
+		return g.VisitRecursiveShape(shapeType)
+	default:
+		return "any"
+	}
+}
+
+func (g *GoGenerator) VisitObjectShape(s *ObjectShape) string {
+	if s.Properties == nil || s.Properties.Len() == 0 {
+		switch {
+		case s.PatternProperties != nil && s.PatternProperties.Len() > 0:
+			return "map[string]" + g.patternPropertiesType(s, g.hint)
+		case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+			return "struct{}"
+		default:
+			return "map[string]any"
+		}
+	}
+	name := g.nameOf(s.Base())
+	decl, ok := g.startDecl(s.Base())
+	if !ok {
+		return name
+	}
+
+	var b strings.Builder
+	writeGoDoc(&b, g.ownDescription(s.Base()))
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	var unionFields []goField
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value
+		field := goField{name: goName(prop.Name), jsonName: prop.Name}
+		field.typ = g.typeOf(prop.Base, name+field.name)
+		if !prop.Required && !g.isNilable(field.typ) {
+			field.typ = "*" + field.typ
+		}
+		writeGoDoc(&b, g.ownDescription(prop.Base))
+		tag := prop.Name
+		if !prop.Required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", field.name, field.typ, tag)
+		if _, isUnion := g.unions[strings.TrimPrefix(strings.TrimPrefix(field.typ, "[]"), "map[string]")]; isUnion {
+			unionFields = append(unionFields, field)
+		}
+	}
+	var extra string
+	// Properties that match neither declared nor pattern properties are kept unless additional properties are
+	// disallowed explicitly.
+	unknown := s.AdditionalProperties == nil || *s.AdditionalProperties
+	if s.PatternProperties != nil && s.PatternProperties.Len() > 0 {
+		extra = g.patternPropertiesType(s, name+"AdditionalProperty")
+		b.WriteString("// AdditionalProperties contains properties that match pattern properties.\n")
+		fmt.Fprintf(&b, "AdditionalProperties map[string]%s `json:\"-\"`\n", extra)
+		if unknown {
+			b.WriteString("// UnknownProperties contains properties that match neither declared nor pattern properties.\n")
+			b.WriteString("UnknownProperties map[string]json.RawMessage `json:\"-\"`\n")
+		}
+	}
+	b.WriteString("}\n")
+	if extra != "" {
+		g.writePropertyPatterns(&b, name, s)
+	}
+	if len(unionFields) > 0 || extra != "" {
+		g.imports["encoding/json"] = struct{}{}
+		g.imports["fmt"] = struct{}{}
+		g.writeUnmarshalJSON(&b, name, s, unionFields, extra, unknown)
+	}
+	if extra != "" {
+		g.writeMarshalJSON(&b, name, unknown)
+	}
+	decl.code = b.String()
+	return name
+}
+
+// writePropertyPatterns writes the compiled patterns of pattern properties of the struct.
+func (g *GoGenerator) writePropertyPatterns(b *strings.Builder, name string, s *ObjectShape) {
+	g.imports["regexp"] = struct{}{}
+	fmt.Fprintf(b, "\n// %s contains patterns of %s additional properties.\n", propertyPatternsName(name), name)
+	fmt.Fprintf(b, "var %s = []*regexp.Regexp{\n", propertyPatternsName(name))
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		fmt.Fprintf(b, "regexp.MustCompile(%s),\n", goStringLiteral(pair.Value.Pattern.String()))
+	}
+	b.WriteString("}\n")
+}
+
+// propertyPatternsName returns the unexported name of the variable with patterns of the struct.
+func propertyPatternsName(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// The last upper case letter of an initialism starts the next word, e.g. APIKey is apiKey.
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:]) + "PropertyPatterns"
+}
+
+// goField is a field of a generated struct.
+type goField struct {
+	name     string
+	jsonName string
+	typ      string
+}
+
+// patternPropertiesType returns the common Go type of pattern properties.
+func (g *GoGenerator) patternPropertiesType(s *ObjectShape, hint string) string {
+	var t string
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		pt := g.typeOf(pair.Value.Base, hint)
+		if t != "" && t != pt {
+			return "any"
+		}
+		t = pt
+	}
+	return t
+}
+
+// writeUnmarshalJSON writes UnmarshalJSON that decodes discriminated union fields and pattern properties.
+// Undeclared properties that do not match the patterns are kept as unknown or rejected.
+func (g *GoGenerator) writeUnmarshalJSON(b *strings.Builder, name string, s *ObjectShape, unionFields []goField,
+	extra string, unknown bool) {
+	fmt.Fprintf(b, "\n// UnmarshalJSON decodes %s", name)
+	if len(unionFields) > 0 {
+		b.WriteString(" with discriminated union fields")
+	}
+	b.WriteString(".\n")
+	fmt.Fprintf(b, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+	fmt.Fprintf(b, "type plain %s\n", name)
+	b.WriteString("aux := struct {\n*plain\n")
+	for _, f := range unionFields {
+		rawType := "json.RawMessage"
+		switch {
+		case strings.HasPrefix(f.typ, "[]"):
+			rawType = "[]json.RawMessage"
+		case strings.HasPrefix(f.typ, "map[string]"):
+			rawType = "map[string]json.RawMessage"
+		}
+		fmt.Fprintf(b, "%s %s `json:%q`\n", f.name, rawType, f.jsonName)
+	}
+	b.WriteString("}{plain: (*plain)(v)}\n")
+	b.WriteString("if err := json.Unmarshal(data, &aux); err != nil {\nreturn err\n}\n")
+	for _, f := range unionFields {
+		switch {
+		case strings.HasPrefix(f.typ, "[]"):
+			item := strings.TrimPrefix(f.typ, "[]")
+			fmt.Fprintf(b, "if aux.%s != nil {\n", f.name)
+			fmt.Fprintf(b, "v.%s = make(%s, len(aux.%s))\n", f.name, f.typ, f.name)
+			fmt.Fprintf(b, "for i, raw := range aux.%s {\n", f.name)
+			g.writeDecode(b, item, "raw", fmt.Sprintf("v.%s[i]", f.name), fmt.Sprintf(`"%s[%%d]: %%w", i`, f.jsonName))
+			b.WriteString("}\n}\n")
+		case strings.HasPrefix(f.typ, "map[string]"):
+			item := strings.TrimPrefix(f.typ, "map[string]")
+			fmt.Fprintf(b, "if aux.%s != nil {\n", f.name)
+			fmt.Fprintf(b, "v.%s = make(%s, len(aux.%s))\n", f.name, f.typ, f.name)
+			fmt.Fprintf(b, "for k, raw := range aux.%s {\n", f.name)
+			g.writeDecode(b, item, "raw", fmt.Sprintf("v.%s[k]", f.name), fmt.Sprintf(`"%s.%%s: %%w", k`, f.jsonName))
+			b.WriteString("}\n}\n")
+		default:
+			fmt.Fprintf(b, "if len(aux.%s) != 0 && string(aux.%s) != \"null\" {\n", f.name, f.name)
+			g.writeDecode(b, f.typ, "aux."+f.name, "v."+f.name, fmt.Sprintf(`"%s: %%w"`, f.jsonName))
+			b.WriteString("}\n")
+		}
+	}
+	if extra != "" {
+		b.WriteString("var props map[string]json.RawMessage\n")
+		b.WriteString("if err := json.Unmarshal(data, &props); err != nil {\nreturn err\n}\n")
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			fmt.Fprintf(b, "delete(props, %q)\n", pair.Key)
+		}
+		b.WriteString("v.AdditionalProperties = nil\n")
+		if unknown {
+			b.WriteString("v.UnknownProperties = nil\n")
+		}
+		b.WriteString("for k, raw := range props {\n")
+		b.WriteString("matched := false\n")
+		fmt.Fprintf(b, "for _, re := range %s {\n", propertyPatternsName(name))
+		b.WriteString("if re.MatchString(k) {\nmatched = true\nbreak\n}\n}\n")
+		b.WriteString("if !matched {\n")
+		if unknown {
+			b.WriteString("if v.UnknownProperties == nil {\n")
+			b.WriteString("v.UnknownProperties = make(map[string]json.RawMessage)\n}\n")
+			b.WriteString("v.UnknownProperties[k] = raw\ncontinue\n}\n")
+		} else {
+			b.WriteString("return fmt.Errorf(\"%s: additional property is not allowed\", k)\n}\n")
+		}
+		b.WriteString("if v.AdditionalProperties == nil {\n")
+		fmt.Fprintf(b, "v.AdditionalProperties = make(map[string]%s)\n}\n", extra)
+		g.writeDecode(b, extra, "raw", "v.AdditionalProperties[k]", `"%s: %w", k`)
+		b.WriteString("}\n")
+	}
+	b.WriteString("return nil\n}\n")
+}
+
+// writeDecode writes a statement that decodes raw JSON of type t into dst.
+func (g *GoGenerator) writeDecode(b *strings.Builder, t, raw, dst, errArgs string) {
+	if _, ok := g.unions[t]; ok {
+		fmt.Fprintf(b, "item, err := Unmarshal%s(%s)\n", t, raw)
+	} else {
+		fmt.Fprintf(b, "var item %s\nerr := json.Unmarshal(%s, &item)\n", t, raw)
+	}
+	fmt.Fprintf(b, "if err != nil {\nreturn fmt.Errorf(%s, err)\n}\n", errArgs)
+	fmt.Fprintf(b, "%s = item\n", dst)
+}
+
+// writeMarshalJSON writes MarshalJSON that encodes pattern and unknown properties along with declared properties.
+func (g *GoGenerator) writeMarshalJSON(b *strings.Builder, name string, unknown bool) {
+	fmt.Fprintf(b, "\n// MarshalJSON encodes %s with its additional properties.\n", name)
+	fmt.Fprintf(b, "func (v %s) MarshalJSON() ([]byte, error) {\n", name)
+	fmt.Fprintf(b, "type plain %s\n", name)
+	b.WriteString("data, err := json.Marshal(plain(v))\n")
+	if unknown {
+		b.WriteString("if err != nil || len(v.AdditionalProperties) == 0 && len(v.UnknownProperties) == 0 {\n")
+	} else {
+		b.WriteString("if err != nil || len(v.AdditionalProperties) == 0 {\n")
+	}
+	b.WriteString("return data, err\n}\n")
+	b.WriteString("var props map[string]json.RawMessage\n")
+	b.WriteString("if err = json.Unmarshal(data, &props); err != nil {\nreturn nil, err\n}\n")
+	b.WriteString("for k, p := range v.AdditionalProperties {\n")
+	b.WriteString("raw, errMarshal := json.Marshal(p)\n")
+	b.WriteString("if errMarshal != nil {\nreturn nil, fmt.Errorf(\"%s: %w\", k, errMarshal)\n}\n")
+	b.WriteString("props[k] = raw\n}\n")
+	if unknown {
+		b.WriteString("for k, raw := range v.UnknownProperties {\nprops[k] = raw\n}\n")
+	}
+	b.WriteString("return json.Marshal(props)\n}\n")
+}
+
+func (g *GoGenerator) VisitArrayShape(s *ArrayShape) string {
+	return "[]" + g.typeOf(s.Items, g.hint+"Item")
+}
+
+func (g *GoGenerator) VisitUnionShape(s *UnionShape) string {
+	if discriminator, ok := s.Discriminator(); ok {
+		return g.generateDiscriminatedUnion(s, discriminator)
+	}
+	var t string
+	var hasNil bool
+	for i, member := range s.AnyOf {
+		if _, isNil := member.Shape.(*NilShape); isNil {
+			hasNil = true
+			continue
+		}
+		mt := g.typeOf(member, g.hint+"Member"+strconv.Itoa(i+1))
+		if t != "" && t != mt {
+			return "any"
+		}
+		t = mt
+	}
+	if t == "" {
+		return "any"
+	}
+	if hasNil && !g.isNilable(t) {
+		return "*" + t
+	}
+	return t
+}
+
+// generateDiscriminatedUnion generates an interface implemented by the union members
+// and a function that decodes the member by the discriminator value.
+func (g *GoGenerator) generateDiscriminatedUnion(s *UnionShape, discriminator string) string {
+	name := g.nameOf(s.Base())
+	decl, ok := g.startDecl(s.Base())
+	if !ok {
+		return name
+	}
+	u := &goUnion{name: name, discriminator: discriminator}
+	g.unions[name] = u
+	g.nilable[name] = struct{}{}
+	g.imports["encoding/json"] = struct{}{}
+	g.imports["fmt"] = struct{}{}
+	for i, member := range s.AnyOf {
+		u.members = append(u.members, g.typeOf(member, name+"Member"+strconv.Itoa(i+1)))
+		u.values = append(u.values, discriminatorValueOf(member))
+	}
+
+	var b strings.Builder
+	writeGoDoc(&b, g.ownDescription(s.Base()))
+	fmt.Fprintf(&b, "type %s interface {\nis%s()\n}\n\n", name, name)
+	for _, member := range u.members {
+		fmt.Fprintf(&b, "func (%s) is%s() {}\n", member, name)
+	}
+	fmt.Fprintf(&b, "\n// Unmarshal%s decodes %s by the value of the %q discriminator.\n", name, name, discriminator)
+	fmt.Fprintf(&b, "func Unmarshal%s(data []byte) (%s, error) {\n", name, name)
+	fmt.Fprintf(&b, "var d struct {\nValue json.RawMessage `json:%q`\n}\n", discriminator)
+	b.WriteString("if err := json.Unmarshal(data, &d); err != nil {\nreturn nil, err\n}\n")
+	b.WriteString("switch string(d.Value) {\n")
+	for i, member := range u.members {
+		literal, _ := json.Marshal(u.values[i])
+		fmt.Fprintf(&b, "case %s:\n", goStringLiteral(string(literal)))
+		fmt.Fprintf(&b, "var v %s\nif err := json.Unmarshal(data, &v); err != nil {\nreturn nil, err\n}\nreturn v, nil\n",
+			member)
+	}
+	b.WriteString("default:\n")
+	fmt.Fprintf(&b, "return nil, fmt.Errorf(\"unknown %s discriminator value: %%s\", d.Value)\n}\n}\n", discriminator)
+	decl.code = b.String()
+	return name
+}
+
+func (g *GoGenerator) VisitStringShape(s *StringShape) string {
+	if len(s.Enum) == 0 {
+		return "string"
+	}
+	name := g.nameOf(s.Base())
+	decl, ok := g.startDecl(s.Base())
+	if !ok {
+		return name
+	}
+	var b strings.Builder
+	writeGoDoc(&b, g.ownDescription(s.Base()))
+	fmt.Fprintf(&b, "type %s string\n\nconst (\n", name)
+	consts := make(map[string]struct{})
+	for _, v := range s.Enum {
+		value := fmt.Sprint(v.Value)
+		constName := name + goName(value)
+		if constName == name {
+			constName = name + "Empty"
+		}
+		for i := 2; ; i++ {
+			if _, taken := consts[constName]; !taken {
+				break
+			}
+			constName = name + goName(value) + strconv.Itoa(i)
+		}
+		consts[constName] = struct{}{}
+		fmt.Fprintf(&b, "%s %s = %q\n", constName, name, value)
+	}
+	b.WriteString(")\n")
+	decl.code = b.String()
+	return name
+}
+
+func (g *GoGenerator) VisitNumberShape(s *NumberShape) string {
+	if s.Format != nil && *s.Format == "float" {
+		return "float32"
+	}
+	return "float64"
+}
+
+func (g *GoGenerator) VisitIntegerShape(s *IntegerShape) string {
+	if s.Format == nil {
+		return "int64"
+	}
+	switch SetOfIntegerFormats[*s.Format] {
+	case 0:
+		return "int8"
+	case 1:
+		return "int16"
+	case 2:
+		return "int32"
+	default:
+		return "int64"
+	}
+}
+
+func (g *GoGenerator) VisitBooleanShape(_ *BooleanShape) string {
+	return "bool"
+}
+
+func (g *GoGenerator) VisitFileShape(_ *FileShape) string {
+	return "[]byte"
+}
+
+func (g *GoGenerator) VisitDateTimeShape(s *DateTimeShape) string {
+	if s.Format != nil && *s.Format == DateTimeFormatRFC2616 {
+		return "string"
+	}
+	g.imports["time"] = struct{}{}
+	return "time.Time"
+}
+
+func (g *GoGenerator) VisitDateTimeOnlyShape(_ *DateTimeOnlyShape) string {
+	return "string"
+}
+
+func (g *GoGenerator) VisitDateOnlyShape(_ *DateOnlyShape) string {
+	return "string"
+}
+
+func (g *GoGenerator) VisitTimeOnlyShape(_ *TimeOnlyShape) string {
+	return "string"
+}
+
+func (g *GoGenerator) VisitRecursiveShape(s *RecursiveShape) string {
+	name, ok := g.namedRef(s.Head)
+	if !ok {
+		return "any"
+	}
+	if _, isObject := s.Head.Shape.(*ObjectShape); isObject && !g.isNilable(name) {
+		return "*" + name
+	}
+	return name
+}
+
+func (g *GoGenerator) VisitJSONShape(_ *JSONShape) string {
+	g.imports["encoding/json"] = struct{}{}
+	return "json.RawMessage"
+}
+
+func (g *GoGenerator) VisitAnyShape(_ *AnyShape) string {
+	return "any"
+}
+
+func (g *GoGenerator) VisitNilShape(_ *NilShape) string {
+	return "any"
+}
+
+// goName converts a RAML name to an exported Go identifier, e.g. "user_id" and "userId" become "UserID".
+func goName(s string) string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+			continue
+		case unicode.IsUpper(r) && len(word) > 0 &&
+			(unicode.IsLower(word[len(word)-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	var b strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if _, ok := commonGoInitialisms[upper]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+func goStringLiteral(s string) string {
+	if strings.ContainsAny(s, "`\n") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// writeGoDoc writes the description as a doc comment of the declaration.
+func writeGoDoc(b *strings.Builder, description *string) {
+	if description == nil || *description == "" {
+		return
+	}
+	writeGoComment(b, *description)
+}
+
+func writeGoComment(b *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(strings.TrimRight("// "+line, " "))
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,215 @@
+package raml
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoGenerator_Generate(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		opts     []GoGeneratorOpt
+		want     []string
+		wantNot  []string
+		wantErr  bool
+		unwrap   bool
+		typeName string
+	}{
+		{
+			name:   "positive: library with used library",
+			path:   "./fixtures/gen/library.raml",
+			unwrap: true,
+			want: []string{
+				"package types\n",
+				"type Cat struct {\n\tLives int32  `json:\"lives\"`",
+				"type Animal interface {\n\tisAnimal()\n}",
+				"func (Cat) isAnimal() {}",
+				"case `\"Cat\"`:",
+				"case `\"dog\"`:",
+				"UserID   string  `json:\"userId\"`",
+				"Nickname *string `json:\"nickname,omitempty\"`",
+				"Status     Status            `json:\"status\"`",
+				"Address    *PersonAddress    `json:\"address,omitempty\"`",
+				"Pets       []Animal          `json:\"pets\"`",
+				"Favorite   Animal            `json:\"favorite,omitempty\"`",
+				"MiddleName *string           `json:\"middleName\"`",
+				"Friend     *Person           `json:\"friend,omitempty\"`",
+				"Labels     map[string]string `json:\"labels,omitempty\"`",
+				"AdditionalProperties map[string]string `json:\"-\"`",
+				"UnknownProperties map[string]json.RawMessage `json:\"-\"`",
+				"var personPropertyPatterns = []*regexp.Regexp{\n\tregexp.MustCompile(`^x-`),\n}",
+				"func (v *Person) UnmarshalJSON(data []byte) error {",
+				"func (v Person) MarshalJSON() ([]byte, error) {",
+				"type People = []Person",
+				"type Status string",
+				"StatusOnHold   Status = \"on-hold\"",
+				"CreatedAt time.Time  `json:\"createdAt\"`",
+			},
+			wantNot: []string{
+				// Inherited descriptions must not be repeated.
+				"// A pet owned by a person.\ntype Cat",
+				"// Status of the account.\n\tStatus",
+			},
+		},
+		{
+			name:   "positive: package name",
+			path:   "./fixtures/gen/common.raml",
+			opts:   []GoGeneratorOpt{WithPackageName("dto")},
+			unwrap: true,
+			want:   []string{"package dto\n"},
+		},
+		{
+			name:    "negative: shapes are not unwrapped",
+			path:    "./fixtures/gen/common.raml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Generated code must only cover shapes that pass validation.
+			opts := []ParseOpt{OptWithValidate()}
+			if tt.unwrap {
+				opts = append(opts, OptWithUnwrap())
+			}
+			r, err := ParseFromPath(tt.path, opts...)
+			require.NoError(t, err)
+			got, err := NewGoGenerator(r, tt.opts...).Generate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			src := string(got)
+			// NOTE: Compare ignoring alignment of struct fields.
+			normalized := strings.Join(strings.Fields(src), " ")
+			for _, want := range tt.want {
+				if !strings.Contains(normalized, strings.Join(strings.Fields(want), " ")) {
+					t.Errorf("Generate() = %s, want to contain %s", src, want)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(normalized, strings.Join(strings.Fields(wantNot), " ")) {
+					t.Errorf("Generate() = %s, want not to contain %s", src, wantNot)
+				}
+			}
+			typeCheckGoSource(t, src)
+		})
+	}
+}
+
+// patternPropertiesMain decodes JSON documents of the arguments with the generated types and prints the result.
+const patternPropertiesMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var p Person
+	if err := json.Unmarshal([]byte(os.Args[1]), &p); err != nil {
+		fmt.Println(err)
+		return
+	}
+	data, _ := json.Marshal(p)
+	fmt.Println(p.AdditionalProperties, p.UnknownProperties["other"], string(data))
+	var m Metadata
+	err := json.Unmarshal([]byte(os.Args[2]), &m)
+	fmt.Println(err, m.AdditionalProperties, string(m.UnknownProperties["owner"]))
+}
+`
+
+func TestGoGenerator_PatternProperties(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	r, err := ParseFromPath("./fixtures/gen/library.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	src, err := NewGoGenerator(r, WithPackageName("main")).Generate()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gen\n\ngo 1.20\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), src, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(patternPropertiesMain), 0o600))
+
+	cmd := exec.Command(goBin, "run", ".",
+		`{"userId": "u1", "x-team": "core", "other": 1}`,
+		`{"version": 1, "x-owner": "me", "owner": "you"}`)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	// Only properties that match the pattern are decoded as additional properties, the rest are kept as unknown.
+	require.Equal(t, `map[x-team:core] 1 `+
+		`{"middleName":null,"other":1,"pets":null,"status":"","timestamps":{"createdAt":"0001-01-01T00:00:00Z"},`+
+		`"userId":"u1","x-team":"core"}`+"\n"+
+		`<nil> map[x-owner:me] "you"`+"\n", string(out))
+}
+
+// typeCheckGoSource ensures that the generated source compiles.
+func typeCheckGoSource(t *testing.T, src string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "types.go", src, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	require.NoError(t, err)
+}
+
+func Test_goName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "name", want: "Name"},
+		{name: "userId", want: "UserID"},
+		{name: "user_id", want: "UserID"},
+		{name: "HTTPServer", want: "HTTPServer"},
+		{name: "on-hold", want: "OnHold"},
+		{name: "x-rate-limit", want: "XRateLimit"},
+		{name: "2fa", want: "X2fa"},
+		{name: "api.version", want: "APIVersion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goName(tt.name); got != tt.want {
+				t.Errorf("goName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_propertyPatternsName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Person", want: "personPropertyPatterns"},
+		{name: "APIKey", want: "apiKeyPropertyPatterns"},
+		{name: "ID", want: "idPropertyPatterns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := propertyPatternsName(tt.name); got != tt.want {
+				t.Errorf("propertyPatternsName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,452 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Parts of HTTP messages that are reported in violations.
+const (
+	ViolationInRoute  = "route"
+	ViolationInURI    = "uri"
+	ViolationInQuery  = "query"
+	ViolationInHeader = "header"
+	ViolationInBody   = "body"
+	ViolationInStatus = "status"
+)
+
+// Violation describes a single mismatch between an HTTP message and the API definition.
+type Violation struct {
+	// In is the part of the HTTP message where the violation was found.
+	In string `json:"in"`
+	// Name is the name of the parameter or the media type of the body.
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v *Violation) String() string {
+	if v.Name == "" {
+		return fmt.Sprintf("%s: %s", v.In, v.Message)
+	}
+	return fmt.Sprintf("%s %q: %s", v.In, v.Name, v.Message)
+}
+
+// HTTPValidationError is returned when an HTTP message does not conform to the API definition.
+// It lists every violation found in the message.
+type HTTPValidationError struct {
+	Violations []*Violation
+}
+
+func (e *HTTPValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return "http validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *HTTPValidationError) add(in, name, format string, args ...any) {
+	e.Violations = append(e.Violations, &Violation{In: in, Name: name, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *HTTPValidationError) errorOrNil() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Route is a method of a resource matched by the request.
+type Route struct {
+	Resource *Resource
+	Method   *Method
+	// URIParameters contains raw values of URI parameters extracted from the request path.
+	URIParameters map[string]string
+}
+
+type routeMatcher struct {
+	resource *Resource
+	pattern  *regexp.Regexp
+	names    []string
+	literals int
+}
+
+var uriTemplateParamRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// makeRouteMatcher compiles the resource URI template into a regular expression.
+func makeRouteMatcher(res *Resource, prefix string) (*routeMatcher, error) {
+	template := prefix + res.FullURI()
+	var sb strings.Builder
+	sb.WriteString("^")
+	names := make([]string, 0)
+	literals := 0
+	last := 0
+	for _, loc := range uriTemplateParamRegexp.FindAllStringSubmatchIndex(template, -1) {
+		literal := template[last:loc[0]]
+		literals += len(literal)
+		sb.WriteString(regexp.QuoteMeta(literal))
+		sb.WriteString("([^/]+)")
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	literals += len(template[last:])
+	sb.WriteString(regexp.QuoteMeta(template[last:]))
+	sb.WriteString("/?$")
+	pattern, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("compile uri template %s: %w", template, err)
+	}
+	return &routeMatcher{resource: res, pattern: pattern, names: names, literals: literals}, nil
+}
+
+// HTTPValidator validates HTTP requests and responses against the API definition.
+// The API must be parsed with OptWithUnwrap so that shapes can be used for validation.
+type HTTPValidator struct {
+	api      *API
+	matchers []*routeMatcher
+}
+
+// NewHTTPValidator creates a validator for the given API.
+// Request paths are matched relative to the path of the API base URI.
+func NewHTTPValidator(api *API) (*HTTPValidator, error) {
+	v := &HTTPValidator{api: api}
+	if err := v.addResources(api.Resources, baseURIPath(api)); err != nil {
+		return nil, fmt.Errorf("add resources: %w", err)
+	}
+	// More specific templates take precedence, e.g. "/users/me" over "/users/{userId}".
+	sort.SliceStable(v.matchers, func(i, j int) bool {
+		return v.matchers[i].literals > v.matchers[j].literals
+	})
+	return v, nil
+}
+
+// baseURIPath returns the path of the API base URI with the version substituted.
+func baseURIPath(api *API) string {
+	baseURI := strings.ReplaceAll(api.BaseURI, "{version}", api.Version)
+	if i := strings.Index(baseURI, "://"); i != -1 {
+		baseURI = baseURI[i+3:]
+		j := strings.Index(baseURI, "/")
+		if j == -1 {
+			return ""
+		}
+		baseURI = baseURI[j:]
+	}
+	return strings.TrimSuffix(baseURI, "/")
+}
+
+func (v *HTTPValidator) addResources(resources *orderedmap.OrderedMap[string, *Resource], prefix string) error {
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		m, err := makeRouteMatcher(pair.Value, prefix)
+		if err != nil {
+			return err
+		}
+		v.matchers = append(v.matchers, m)
+		if err = v.addResources(pair.Value.Resources, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindRoute returns the method of the resource that matches the request method and path.
+func (v *HTTPValidator) FindRoute(method string, path string) (*Route, error) {
+	pathMatched := false
+	for _, m := range v.matchers {
+		match := m.pattern.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+		pathMatched = true
+		resMethod, ok := m.resource.Methods.Get(strings.ToLower(method))
+		if !ok {
+			continue
+		}
+		params := make(map[string]string, len(m.names))
+		for i, name := range m.names {
+			value, err := url.PathUnescape(match[i+1])
+			if err != nil {
+				value = match[i+1]
+			}
+			params[name] = value
+		}
+		return &Route{Resource: m.resource, Method: resMethod, URIParameters: params}, nil
+	}
+	verr := &HTTPValidationError{}
+	if pathMatched {
+		verr.add(ViolationInRoute, method, "method is not allowed for %s", path)
+	} else {
+		verr.add(ViolationInRoute, path, "resource not found")
+	}
+	return nil, verr
+}
+
+// ValidateRequest validates the request against the API definition and returns the matched route.
+// The request body is read and replaced, so it can be read again by the caller.
+func (v *HTTPValidator) ValidateRequest(req *http.Request) (*Route, error) {
+	route, err := v.FindRoute(req.Method, req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	verr := &HTTPValidationError{}
+	v.validateURIParameters(route, verr)
+	m := route.Method
+	if m.QueryString != nil {
+		validateQueryString(m.QueryString, req.URL.Query(), verr)
+	} else {
+		validateParameters(m.QueryParameters, req.URL.Query(), ViolationInQuery, verr)
+	}
+	validateParameters(m.Headers, req.Header, ViolationInHeader, verr)
+	if m.Body.Len() > 0 {
+		body, errRead := readBody(&req.Body)
+		if errRead != nil {
+			return route, fmt.Errorf("read request body: %w", errRead)
+		}
+		validateBody(m.Body, req.Header.Get("Content-Type"), body, verr)
+	}
+	return route, verr.errorOrNil()
+}
+
+// ValidateResponse validates the response to the request against the API definition.
+// The response body is read and replaced, so it can be read again by the caller.
+func (v *HTTPValidator) ValidateResponse(req *http.Request, resp *http.Response) error {
+	route, err := v.FindRoute(req.Method, req.URL.Path)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	return ValidateResponse(route.Method, resp.StatusCode, resp.Header, body)
+}
+
+// ValidateResponse validates the response status code, headers and body against the method definition.
+func ValidateResponse(m *Method, statusCode int, header http.Header, body []byte) error {
+	verr := &HTTPValidationError{}
+	code := strconv.Itoa(statusCode)
+	resp, ok := m.Responses.Get(code)
+	if !ok {
+		verr.add(ViolationInStatus, code, "response status code is not declared")
+		return verr
+	}
+	validateParameters(resp.Headers, header, ViolationInHeader, verr)
+	if resp.Body.Len() > 0 {
+		validateBody(resp.Body, header.Get("Content-Type"), body, verr)
+	}
+	return verr.errorOrNil()
+}
+
+// validateURIParameters validates URI parameters declared by the resource and its parents.
+func (v *HTTPValidator) validateURIParameters(route *Route, verr *HTTPValidationError) {
+	declared := make(map[string]Property)
+	for res := route.Resource; res != nil; res = res.Parent {
+		for pair := res.URIParameters.Oldest(); pair != nil; pair = pair.Next() {
+			if _, ok := declared[pair.Key]; !ok {
+				declared[pair.Key] = pair.Value
+			}
+		}
+	}
+	for pair := v.api.BaseURIParameters.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := declared[pair.Key]; !ok {
+			declared[pair.Key] = pair.Value
+		}
+	}
+	names := make([]string, 0, len(route.URIParameters))
+	for name := range route.URIParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := declared[name]
+		if !ok {
+			continue
+		}
+		if err := validateParameterValues(prop.Base, []string{route.URIParameters[name]}); err != nil {
+			verr.add(ViolationInURI, name, "%v", err)
+		}
+	}
+}
+
+// validateParameters validates query parameters or headers against declared parameters.
+func validateParameters(
+	params *orderedmap.OrderedMap[string, Property],
+	values map[string][]string,
+	in string,
+	verr *HTTPValidationError,
+) {
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value
+		var vals []string
+		if in == ViolationInHeader {
+			vals = http.Header(values).Values(prop.Name)
+		} else {
+			vals = values[prop.Name]
+		}
+		if len(vals) == 0 {
+			if prop.Required {
+				verr.add(in, prop.Name, "required parameter is missing")
+			}
+			continue
+		}
+		if err := validateParameterValues(prop.Base, vals); err != nil {
+			verr.add(in, prop.Name, "%v", err)
+		}
+	}
+}
+
+// validateQueryString validates the query string as an object against the query string shape.
+func validateQueryString(shape *BaseShape, values url.Values, verr *HTTPValidationError) {
+	obj := make(map[string]any, len(values))
+	var props *orderedmap.OrderedMap[string, Property]
+	if s, ok := shape.Shape.(*ObjectShape); ok {
+		props = s.Properties
+	}
+	for name, vals := range values {
+		var target *BaseShape
+		if props != nil {
+			if prop, ok := props.Get(name); ok {
+				target = prop.Base
+			}
+		}
+		obj[name] = convertParameterValues(target, vals)
+	}
+	if err := shape.Validate(obj); err != nil {
+		verr.add(ViolationInQuery, "", "%v", err)
+	}
+}
+
+// validateParameterValues validates raw string values of the parameter against its shape.
+func validateParameterValues(shape *BaseShape, vals []string) error {
+	if _, ok := shape.Shape.(*ArrayShape); !ok && len(vals) > 1 {
+		return fmt.Errorf("parameter must have a single value, got %d", len(vals))
+	}
+	return shape.Validate(convertParameterValues(shape, vals))
+}
+
+// convertParameterValues converts raw string values of the parameter to values expected by the shape.
+func convertParameterValues(shape *BaseShape, vals []string) any {
+	if shape == nil {
+		if len(vals) == 1 {
+			return vals[0]
+		}
+		items := make([]any, 0, len(vals))
+		for _, val := range vals {
+			items = append(items, val)
+		}
+		return items
+	}
+	if s, ok := shape.Shape.(*ArrayShape); ok {
+		items := make([]any, 0, len(vals))
+		for _, val := range vals {
+			items = append(items, convertParameterValue(s.Items, val))
+		}
+		return items
+	}
+	return convertParameterValue(shape, vals[0])
+}
+
+// convertParameterValue converts a raw string value to a value expected by the scalar shape.
+// Values that cannot be converted are returned as is, so that validation reports the type mismatch.
+func convertParameterValue(shape *BaseShape, val string) any {
+	if shape == nil {
+		return val
+	}
+	switch s := shape.Shape.(type) {
+	case *IntegerShape:
+		if i, err := strconv.Atoi(val); err == nil {
+			return i
+		}
+	case *NumberShape:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	case *BooleanShape:
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	case *NilShape:
+		if val == "" {
+			return nil
+		}
+	case *UnionShape:
+		// The first member that accepts the converted value wins.
+		for _, member := range s.AnyOf {
+			converted := convertParameterValue(member, val)
+			if member.Validate(converted) == nil {
+				return converted
+			}
+		}
+	}
+	return val
+}
+
+// validateBody validates the body against the shape declared for the content type.
+// Only JSON bodies are decoded and validated, other media types are only checked to be declared.
+func validateBody(
+	bodies *orderedmap.OrderedMap[string, *Body],
+	contentType string,
+	data []byte,
+	verr *HTTPValidationError,
+) {
+	mediaType := contentType
+	if contentType != "" {
+		if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = mt
+		}
+	}
+	if mediaType == "" {
+		if len(data) == 0 {
+			return
+		}
+		verr.add(ViolationInBody, "", "content type is not specified")
+		return
+	}
+	body, ok := bodies.Get(mediaType)
+	if !ok {
+		verr.add(ViolationInBody, mediaType, "media type is not declared")
+		return
+	}
+	if !IsJSONMediaType(mediaType) {
+		return
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		verr.add(ViolationInBody, mediaType, "invalid json: %v", err)
+		return
+	}
+	if err := body.Shape.Validate(value); err != nil {
+		verr.add(ViolationInBody, mediaType, "%v", err)
+	}
+}
+
+// IsJSONMediaType returns true if the media type denotes JSON content.
+func IsJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// readBody reads the body and replaces it with a reader over the read data.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	if err != nil {
+		return nil, err
+	}
+	if err = (*body).Close(); err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,215 @@
+package raml
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPValidator_ValidateRequest(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_http.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+	v, err := NewHTTPValidator(api)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		header         http.Header
+		body           string
+		wantURI        string
+		wantViolations []string
+	}{
+		{
+			name:    "positive: query parameters",
+			method:  http.MethodGet,
+			target:  "/v1/books?limit=10&tag=a&tag=b&available=true",
+			wantURI: "/books",
+		},
+		{
+			name:    "positive: literal segment takes precedence over uri parameter",
+			method:  http.MethodGet,
+			target:  "/v1/books/latest",
+			wantURI: "/latest",
+		},
+		{
+			name:    "positive: uri parameter",
+			method:  http.MethodGet,
+			target:  "/v1/books/42",
+			wantURI: "/{bookId}",
+		},
+		{
+			name:    "positive: body",
+			method:  http.MethodPost,
+			target:  "/v1/books",
+			header:  http.Header{"Content-Type": {"application/json; charset=utf-8"}, "X-Request-Id": {"abc123"}},
+			body:    `{"id": 1, "title": "Dune"}`,
+			wantURI: "/books",
+		},
+		{
+			name:    "positive: query string",
+			method:  http.MethodGet,
+			target:  "/v1/search?q=dune&page=2",
+			wantURI: "/search",
+		},
+		{
+			name:           "negative: resource not found",
+			method:         http.MethodGet,
+			target:         "/v1/authors",
+			wantViolations: []string{`route "/v1/authors": resource not found`},
+		},
+		{
+			name:           "negative: method not allowed",
+			method:         http.MethodDelete,
+			target:         "/v1/books",
+			wantViolations: []string{`route "DELETE": method is not allowed for /v1/books`},
+		},
+		{
+			name:   "negative: every query parameter violation is reported",
+			method: http.MethodGet,
+			target: "/v1/books?limit=1000&available=maybe",
+			wantViolations: []string{
+				`query "limit": value must be less than 100`,
+				`query "available": invalid type, got string, expected bool`,
+			},
+		},
+		{
+			name:           "negative: required query parameter is missing",
+			method:         http.MethodGet,
+			target:         "/v1/books",
+			wantViolations: []string{`query "limit": required parameter is missing`},
+		},
+		{
+			name:           "negative: invalid uri parameter",
+			method:         http.MethodGet,
+			target:         "/v1/books/0",
+			wantViolations: []string{`uri "bookId": value must be greater than 1`},
+		},
+		{
+			name:   "negative: invalid header and body",
+			method: http.MethodPost,
+			target: "/v1/books",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"id": "one", "title": "Dune"}`,
+			wantViolations: []string{
+				`header "X-Request-Id": required parameter is missing`,
+				`body "application/json"`,
+			},
+		},
+		{
+			name:           "negative: undeclared media type",
+			method:         http.MethodPost,
+			target:         "/v1/books",
+			header:         http.Header{"Content-Type": {"application/xml"}, "X-Request-Id": {"abc"}},
+			body:           `<book/>`,
+			wantViolations: []string{`body "application/xml": media type is not declared`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for k, vals := range tt.header {
+				req.Header[k] = vals
+			}
+			route, err := v.ValidateRequest(req)
+			if len(tt.wantViolations) == 0 {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantURI, route.Resource.URI)
+				// Body must be readable after validation.
+				data, errRead := io.ReadAll(req.Body)
+				require.NoError(t, errRead)
+				require.Equal(t, tt.body, string(data))
+				return
+			}
+			var verr *HTTPValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Len(t, verr.Violations, len(tt.wantViolations))
+			for i, want := range tt.wantViolations {
+				if !strings.HasPrefix(verr.Violations[i].String(), want) {
+					t.Errorf("ValidateRequest() violation = %s, want %s", verr.Violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPValidator_ValidateResponse(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_http.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+	v, err := NewHTTPValidator(api)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		target         string
+		status         int
+		header         http.Header
+		body           string
+		wantViolations []string
+	}{
+		{
+			name:   "positive: body and headers",
+			target: "/v1/books?limit=1",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"application/json"}, "X-Total-Count": {"1"}},
+			body:   `[{"id": 1, "title": "Dune", "tags": ["sci-fi"]}]`,
+		},
+		{
+			name:   "positive: response without body",
+			target: "/v1/books/1",
+			status: http.StatusNotFound,
+		},
+		{
+			name:           "negative: undeclared status code",
+			target:         "/v1/books/1",
+			status:         http.StatusInternalServerError,
+			wantViolations: []string{`status "500": response status code is not declared`},
+		},
+		{
+			name:   "negative: invalid header and body",
+			target: "/v1/books?limit=1",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"application/json"}, "X-Total-Count": {"many"}},
+			body:   `{"id": 1}`,
+			wantViolations: []string{
+				`header "X-Total-Count": invalid type, got string, expected int, uint or float64`,
+				`body "application/json": invalid type`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     tt.header,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+			err := v.ValidateResponse(req, resp)
+			if len(tt.wantViolations) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var verr *HTTPValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Len(t, verr.Violations, len(tt.wantViolations))
+			for i, want := range tt.wantViolations {
+				if !strings.HasPrefix(verr.Violations[i].String(), want) {
+					t.Errorf("ValidateResponse() violation = %s, want %s", verr.Violations[i], want)
+				}
+			}
+		})
+	}
+}
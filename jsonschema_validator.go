@@ -0,0 +1,943 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxJSONSchemaRefDepth limits the depth of $ref resolution to detect infinitely recursive schemas.
+const maxJSONSchemaRefDepth = 256
+
+// JSONSchemaViolation describes a value that does not conform to a JSON Schema keyword.
+type JSONSchemaViolation struct {
+	// Path is the location of the value, e.g. "$.items[0].name".
+	Path string
+	// Keyword is the JSON Schema keyword that failed, e.g. "required".
+	Keyword string
+	Message string
+}
+
+// jsonSchemaValidator validates values against a JSON Schema document.
+// Supports draft-07, 2019-09 and 2020-12 keywords. Keywords that only produce annotations
+// (title, description, examples, etc.) and unknown keywords are ignored.
+// Dynamic references ($dynamicRef and $recursiveRef) are not supported and schemas that use them are rejected.
+// Patterns must be compatible with RE2, ECMA-262 features like lookarounds and backreferences are rejected.
+type jsonSchemaValidator struct {
+	root any
+	// legacyRef is set for drafts where keywords next to $ref are ignored (draft-07 and older).
+	legacyRef bool
+	// ids contains schemas by $id and $anchor resolved against the $id of the enclosing schema resource.
+	ids map[string]any
+	// bases contains base URIs of schemas with $ref that are nested in schema resources with $id,
+	// keyed by the pointer of the schema map.
+	bases map[uintptr]string
+	// patterns contains compiled regular expressions of pattern and patternProperties keywords.
+	// The validator is read-only after creation and may be used concurrently.
+	patterns map[string]*regexp.Regexp
+}
+
+// jsonSchemaResult contains violations and evaluated properties and items used by unevaluated* keywords.
+type jsonSchemaResult struct {
+	violations []JSONSchemaViolation
+	props      map[string]struct{}
+	items      int
+	allItems   bool
+}
+
+func (res *jsonSchemaResult) add(path, keyword, format string, args ...any) {
+	res.violations = append(res.violations, JSONSchemaViolation{
+		Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// merge merges evaluated properties and items of a successfully validated subschema.
+func (res *jsonSchemaResult) merge(sub *jsonSchemaResult) {
+	for k := range sub.props {
+		res.props[k] = struct{}{}
+	}
+	if sub.items > res.items {
+		res.items = sub.items
+	}
+	res.allItems = res.allItems || sub.allItems
+}
+
+func (res *jsonSchemaResult) valid() bool {
+	return len(res.violations) == 0
+}
+
+// newJSONSchemaValidator creates a validator for the decoded JSON Schema document.
+// Returns an error if the schema uses unsupported keywords or patterns.
+func newJSONSchemaValidator(doc any) (*jsonSchemaValidator, error) {
+	v := &jsonSchemaValidator{
+		root:     doc,
+		ids:      make(map[string]any),
+		bases:    make(map[uintptr]string),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	if m, ok := doc.(map[string]any); ok {
+		if version, isString := m["$schema"].(string); isString {
+			v.legacyRef = strings.Contains(version, "draft-0")
+		}
+	}
+	if err := v.collectIDs(doc, "", "#"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// newJSONSchemaValidatorFromRaw creates a validator from the raw JSON Schema document.
+func newJSONSchemaValidatorFromRaw(raw string) (*jsonSchemaValidator, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode json schema: %w", err)
+	}
+	return newJSONSchemaValidator(doc)
+}
+
+// Validate validates the value against the JSON Schema.
+// Keywords that are not modeled by JSONSchema, such as $defs or prefixItems, are not taken into account.
+// Use JSONShape to validate against the complete schema document.
+// The schema is reported as a single violation if it uses unsupported keywords or patterns.
+func (js *JSONSchema) Validate(v any) []JSONSchemaViolation {
+	validator, err := newJSONSchemaValidator(js.Map())
+	if err != nil {
+		return []JSONSchemaViolation{{Path: "$", Keyword: "$schema", Message: err.Error()}}
+	}
+	return validator.validate(v, "$")
+}
+
+// collectIDs registers identifiers of the schema and its subschemas and compiles their patterns.
+// The base is the URI of the enclosing schema resource and the pointer is the location of the schema in the document.
+func (v *jsonSchemaValidator) collectIDs(schema any, base string, pointer string) error {
+	switch s := schema.(type) {
+	case map[string]any:
+		for _, key := range []string{"$dynamicRef", "$recursiveRef"} {
+			if _, ok := s[key]; ok {
+				return fmt.Errorf("%s: unsupported keyword %s", pointer, key)
+			}
+		}
+		// draft-07 allows location-independent identifiers in $id, e.g. "#foo".
+		if id, ok := s["$id"].(string); ok && id != "" {
+			resolved := resolveJSONSchemaURI(base, id)
+			if !strings.HasPrefix(id, "#") {
+				base = strings.TrimSuffix(resolved, "#")
+			}
+			v.ids[strings.TrimSuffix(resolved, "#")] = s
+		}
+		for _, key := range []string{"$anchor", "$dynamicAnchor"} {
+			if anchor, ok := s[key].(string); ok && anchor != "" {
+				v.ids[base+"#"+anchor] = s
+			}
+		}
+		if _, ok := s["$ref"]; ok && base != "" {
+			v.bases[reflect.ValueOf(s).Pointer()] = base
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if err := v.compilePattern(pattern); err != nil {
+				return fmt.Errorf("%s/pattern: %w", pointer, err)
+			}
+		}
+		if props, ok := s["patternProperties"].(map[string]any); ok {
+			for pattern := range props {
+				if err := v.compilePattern(pattern); err != nil {
+					return fmt.Errorf("%s/patternProperties: %w", pointer, err)
+				}
+			}
+		}
+		for _, k := range sortedKeys(s) {
+			switch k {
+			case "enum", "const", "examples", "default":
+				continue
+			case "properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "dependencies":
+				// Keys of these keywords are names, not keywords of a schema.
+				if m, ok := s[k].(map[string]any); ok {
+					for _, name := range sortedKeys(m) {
+						if err := v.collectIDs(m[name], base, pointer+"/"+k+"/"+jsonPointerEscape(name)); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+			}
+			if err := v.collectIDs(s[k], base, pointer+"/"+jsonPointerEscape(k)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, sub := range s {
+			if err := v.collectIDs(sub, base, pointer+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveJSONSchemaURI resolves the reference against the base URI. Invalid URIs are returned as is.
+func resolveJSONSchemaURI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	baseURI, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURI, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	resolved := baseURI.ResolveReference(refURI).String()
+	if strings.HasSuffix(ref, "#") {
+		resolved += "#"
+	}
+	return resolved
+}
+
+func jsonPointerEscape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func (v *jsonSchemaValidator) validate(value any, path string) []JSONSchemaViolation {
+	res := v.validateSchema(v.root, value, path, 0)
+	return res.violations
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // JSON Schema has many keywords that apply to the same value.
+func (v *jsonSchemaValidator) validateSchema(schema any, value any, path string, depth int) *jsonSchemaResult {
+	res := &jsonSchemaResult{props: make(map[string]struct{})}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			res.add(path, "false", "value is not allowed")
+		}
+		return res
+	case map[string]any:
+		if ref, ok := s["$ref"].(string); ok {
+			ref = resolveJSONSchemaURI(v.bases[reflect.ValueOf(s).Pointer()], ref)
+			v.validateRef(res, ref, value, path, depth)
+			if v.legacyRef {
+				return res
+			}
+		}
+		v.validateType(res, s, value, path)
+		v.validateEnum(res, s, value, path)
+		v.validateNumber(res, s, value, path)
+		v.validateString(res, s, value, path)
+		v.validateArray(res, s, value, path, depth)
+		v.validateObject(res, s, value, path, depth)
+		v.validateCombinators(res, s, value, path, depth)
+		v.validateUnevaluated(res, s, value, path, depth)
+		return res
+	default:
+		return res
+	}
+}
+
+func (v *jsonSchemaValidator) validateRef(res *jsonSchemaResult, ref string, value any, path string, depth int) {
+	if depth > maxJSONSchemaRefDepth {
+		res.add(path, "$ref", "maximum reference depth exceeded: %s", ref)
+		return
+	}
+	target, err := v.resolveRef(ref)
+	if err != nil {
+		res.add(path, "$ref", "%s", err.Error())
+		return
+	}
+	sub := v.validateSchema(target, value, path, depth+1)
+	res.violations = append(res.violations, sub.violations...)
+	if sub.valid() {
+		res.merge(sub)
+	}
+}
+
+// resolveRef resolves references to the root schema, JSON pointers, anchors and $id of subschemas.
+// The reference must be resolved against the base URI of the schema it is declared in.
+func (v *jsonSchemaValidator) resolveRef(ref string) (any, error) {
+	if target, ok := v.ids[ref]; ok {
+		return target, nil
+	}
+	base, fragment, _ := strings.Cut(ref, "#")
+	doc := v.root
+	if base != "" {
+		target, ok := v.ids[base]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference: %s", ref)
+		}
+		doc = target
+	}
+	if fragment == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		if target, ok := v.ids[base+"#"+fragment]; ok {
+			return target, nil
+		}
+		return nil, fmt.Errorf("unresolvable reference: %s", ref)
+	}
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference: %s", ref)
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := doc.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolvable reference: %s", ref)
+			}
+			doc = next
+		case []any:
+			i, errConv := strconv.Atoi(token)
+			if errConv != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("unresolvable reference: %s", ref)
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("unresolvable reference: %s", ref)
+		}
+	}
+	return doc, nil
+}
+
+func (v *jsonSchemaValidator) validateType(res *jsonSchemaResult, s map[string]any, value any, path string) {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, item := range t {
+			if str, ok := item.(string); ok {
+				types = append(types, str)
+			}
+		}
+	default:
+		return
+	}
+	for _, t := range types {
+		if jsonSchemaTypeMatches(t, value) {
+			return
+		}
+	}
+	res.add(path, "type", "invalid type, got %s, expected %s", jsonSchemaTypeOf(value), strings.Join(types, ", "))
+}
+
+func jsonSchemaTypeMatches(t string, value any) bool {
+	actual := jsonSchemaTypeOf(value)
+	switch t {
+	case "number":
+		return actual == "number" || actual == "integer"
+	case "integer":
+		return actual == "integer"
+	default:
+		return actual == t
+	}
+}
+
+// jsonSchemaTypeOf returns the JSON type of the value. Numbers without a fractional part are integers.
+func jsonSchemaTypeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if n, ok := jsonSchemaNumber(value); ok {
+		if n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonSchemaNumber converts numbers decoded from JSON or YAML to an exact rational number.
+// Floats are converted through their shortest decimal form, so 0.3 is a multiple of 0.1 as written in the document.
+func jsonSchemaNumber(value any) (*big.Rat, bool) {
+	switch n := value.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint64:
+		return new(big.Rat).SetUint64(n), true
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(n), 'g', -1, 32))
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case json.Number:
+		return new(big.Rat).SetString(n.String())
+	case *big.Int:
+		return new(big.Rat).SetInt(n), true
+	}
+	return nil, false
+}
+
+func (v *jsonSchemaValidator) validateEnum(res *jsonSchemaResult, s map[string]any, value any, path string) {
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, item := range enum {
+			if jsonSchemaEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.add(path, "enum", "value must be one of %v", enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonSchemaEqual(c, value) {
+		res.add(path, "const", "value must be equal to %v", c)
+	}
+}
+
+// jsonSchemaEqual compares JSON values, numbers are compared by value regardless of their Go type.
+func jsonSchemaEqual(a, b any) bool {
+	if na, ok := jsonSchemaNumber(a); ok {
+		nb, isNumber := jsonSchemaNumber(b)
+		return isNumber && na.Cmp(nb) == 0
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, item := range av {
+			other, present := bv[k]
+			if !present || !jsonSchemaEqual(item, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonSchemaEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case nil, bool, string:
+		return a == b
+	}
+	return false
+}
+
+func (v *jsonSchemaValidator) validateNumber(res *jsonSchemaResult, s map[string]any, value any, path string) {
+	if _, isBool := value.(bool); isBool {
+		return
+	}
+	n, ok := jsonSchemaNumber(value)
+	if !ok {
+		return
+	}
+	if m, present := jsonSchemaNumber(s["multipleOf"]); present && m.Sign() > 0 {
+		if !new(big.Rat).Quo(n, m).IsInt() {
+			res.add(path, "multipleOf", "value must be a multiple of %s", m.RatString())
+		}
+	}
+	if limit, present := jsonSchemaNumber(s["maximum"]); present {
+		// draft-04 boolean exclusiveMaximum
+		if excl, isBool := s["exclusiveMaximum"].(bool); isBool && excl {
+			if n.Cmp(limit) >= 0 {
+				res.add(path, "maximum", "value must be less than %s", limit.RatString())
+			}
+		} else if n.Cmp(limit) > 0 {
+			res.add(path, "maximum", "value must be less than or equal to %s", limit.RatString())
+		}
+	}
+	if limit, present := jsonSchemaNumber(s["exclusiveMaximum"]); present && n.Cmp(limit) >= 0 {
+		res.add(path, "exclusiveMaximum", "value must be less than %s", limit.RatString())
+	}
+	if limit, present := jsonSchemaNumber(s["minimum"]); present {
+		if excl, isBool := s["exclusiveMinimum"].(bool); isBool && excl {
+			if n.Cmp(limit) <= 0 {
+				res.add(path, "minimum", "value must be greater than %s", limit.RatString())
+			}
+		} else if n.Cmp(limit) < 0 {
+			res.add(path, "minimum", "value must be greater than or equal to %s", limit.RatString())
+		}
+	}
+	if limit, present := jsonSchemaNumber(s["exclusiveMinimum"]); present && n.Cmp(limit) <= 0 {
+		res.add(path, "exclusiveMinimum", "value must be greater than %s", limit.RatString())
+	}
+}
+
+func (v *jsonSchemaValidator) validateString(res *jsonSchemaResult, s map[string]any, value any, path string) {
+	str, ok := value.(string)
+	if !ok {
+		return
+	}
+	length := int64(utf8.RuneCountInString(str))
+	if limit, present := jsonSchemaCount(s["maxLength"]); present && length > limit {
+		res.add(path, "maxLength", "length must be less than or equal to %d", limit)
+	}
+	if limit, present := jsonSchemaCount(s["minLength"]); present && length < limit {
+		res.add(path, "minLength", "length must be greater than or equal to %d", limit)
+	}
+	if pattern, present := s["pattern"].(string); present && !v.patterns[pattern].MatchString(str) {
+		res.add(path, "pattern", "value must match pattern %s", pattern)
+	}
+	if format, present := s["format"].(string); present {
+		if err := validateJSONSchemaFormat(format, str); err != nil {
+			res.add(path, "format", "value must be a valid %s: %s", format, err.Error())
+		}
+	}
+}
+
+// jsonSchemaCount converts a non-negative integer keyword value.
+func jsonSchemaCount(value any) (int64, bool) {
+	n, ok := jsonSchemaNumber(value)
+	if !ok || !n.IsInt() || !n.Num().IsInt64() {
+		return 0, false
+	}
+	return n.Num().Int64(), true
+}
+
+// compilePattern compiles the pattern once, so that unsupported patterns are reported as schema errors
+// instead of violations of every validated value.
+func (v *jsonSchemaValidator) compilePattern(pattern string) error {
+	if _, ok := v.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("unsupported pattern %q: %w", pattern, err)
+	}
+	v.patterns[pattern] = re
+	return nil
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Array keywords depend on each other.
+func (v *jsonSchemaValidator) validateArray(res *jsonSchemaResult, s map[string]any, value any, path string,
+	depth int) {
+	arr, ok := value.([]any)
+	if !ok {
+		return
+	}
+	n := int64(len(arr))
+	if limit, present := jsonSchemaCount(s["maxItems"]); present && n > limit {
+		res.add(path, "maxItems", "array must have not more than %d items", limit)
+	}
+	if limit, present := jsonSchemaCount(s["minItems"]); present && n < limit {
+		res.add(path, "minItems", "array must have at least %d items", limit)
+	}
+	if unique, present := s["uniqueItems"].(bool); present && unique {
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonSchemaEqual(arr[i], arr[j]) {
+					res.add(path, "uniqueItems", "array items %d and %d must be unique", i, j)
+				}
+			}
+		}
+	}
+
+	// Tuple validation: prefixItems (2019-09+) or items as array (draft-07).
+	prefix, hasPrefix := s["prefixItems"].([]any)
+	restKey := "items"
+	if !hasPrefix {
+		if prefix, hasPrefix = s["items"].([]any); hasPrefix {
+			restKey = "additionalItems"
+		}
+	}
+	for i := 0; i < len(prefix) && i < len(arr); i++ {
+		sub := v.validateSchema(prefix[i], arr[i], fmt.Sprintf("%s[%d]", path, i), depth)
+		res.violations = append(res.violations, sub.violations...)
+	}
+	if hasPrefix && len(prefix) > res.items {
+		res.items = len(prefix)
+		if res.items > len(arr) {
+			res.items = len(arr)
+		}
+	}
+	if rest, present := s[restKey]; present {
+		if _, isTuple := rest.([]any); !isTuple {
+			for i := len(prefix); i < len(arr); i++ {
+				sub := v.validateSchema(rest, arr[i], fmt.Sprintf("%s[%d]", path, i), depth)
+				res.violations = append(res.violations, sub.violations...)
+			}
+			res.allItems = true
+		}
+	}
+
+	if contains, present := s["contains"]; present {
+		var matched int64
+		for i, item := range arr {
+			if v.validateSchema(contains, item, fmt.Sprintf("%s[%d]", path, i), depth).valid() {
+				matched++
+			}
+		}
+		minContains := int64(1)
+		if limit, isSet := jsonSchemaCount(s["minContains"]); isSet {
+			minContains = limit
+		}
+		if matched < minContains {
+			res.add(path, "contains", "array must contain at least %d matching items", minContains)
+		}
+		if limit, isSet := jsonSchemaCount(s["maxContains"]); isSet && matched > limit {
+			res.add(path, "maxContains", "array must contain not more than %d matching items", limit)
+		}
+	}
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Object keywords depend on each other.
+func (v *jsonSchemaValidator) validateObject(res *jsonSchemaResult, s map[string]any, value any, path string,
+	depth int) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	n := int64(len(obj))
+	if limit, present := jsonSchemaCount(s["maxProperties"]); present && n > limit {
+		res.add(path, "maxProperties", "object must have not more than %d properties", limit)
+	}
+	if limit, present := jsonSchemaCount(s["minProperties"]); present && n < limit {
+		res.add(path, "minProperties", "object must have at least %d properties", limit)
+	}
+	if required, present := s["required"].([]any); present {
+		var missing []string
+		for _, item := range required {
+			if name, isString := item.(string); isString {
+				if _, found := obj[name]; !found {
+					missing = append(missing, name)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			res.add(path, "required", "missing required properties: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	properties, _ := s["properties"].(map[string]any)
+	patternProperties, _ := s["patternProperties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+	for _, k := range keys {
+		item := obj[k]
+		itemPath := path + "." + k
+		matched := false
+		if propSchema, present := properties[k]; present {
+			matched = true
+			sub := v.validateSchema(propSchema, item, itemPath, depth)
+			res.violations = append(res.violations, sub.violations...)
+		}
+		for pattern, propSchema := range patternProperties {
+			if v.patterns[pattern].MatchString(k) {
+				matched = true
+				sub := v.validateSchema(propSchema, item, itemPath, depth)
+				res.violations = append(res.violations, sub.violations...)
+			}
+		}
+		if !matched && hasAdditional {
+			if allowed, isBool := additional.(bool); isBool && !allowed {
+				res.add(itemPath, "additionalProperties", "unexpected additional property %q", k)
+			} else {
+				sub := v.validateSchema(additional, item, itemPath, depth)
+				res.violations = append(res.violations, sub.violations...)
+			}
+			matched = true
+		}
+		if matched {
+			res.props[k] = struct{}{}
+		}
+	}
+
+	if names, present := s["propertyNames"]; present {
+		for _, k := range keys {
+			sub := v.validateSchema(names, k, path+"."+k, depth)
+			res.violations = append(res.violations, sub.violations...)
+		}
+	}
+
+	// dependencies (draft-07) is split into dependentRequired and dependentSchemas in 2019-09.
+	for _, key := range []string{"dependencies", "dependentRequired", "dependentSchemas"} {
+		deps, present := s[key].(map[string]any)
+		if !present {
+			continue
+		}
+		for _, k := range sortedKeys(deps) {
+			if _, found := obj[k]; !found {
+				continue
+			}
+			if required, isArray := deps[k].([]any); isArray {
+				for _, item := range required {
+					if name, isString := item.(string); isString {
+						if _, found := obj[name]; !found {
+							res.add(path, key, "property %q is required by %q", name, k)
+						}
+					}
+				}
+				continue
+			}
+			sub := v.validateSchema(deps[k], value, path, depth)
+			res.violations = append(res.violations, sub.violations...)
+			if sub.valid() {
+				res.merge(sub)
+			}
+		}
+	}
+}
+
+func (v *jsonSchemaValidator) validateCombinators(res *jsonSchemaResult, s map[string]any, value any, path string,
+	depth int) {
+	if allOf, ok := s["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			r := v.validateSchema(sub, value, path, depth)
+			res.violations = append(res.violations, r.violations...)
+			if r.valid() {
+				res.merge(r)
+			}
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		var valid bool
+		var violations []JSONSchemaViolation
+		for _, sub := range anyOf {
+			r := v.validateSchema(sub, value, path, depth)
+			if r.valid() {
+				valid = true
+				res.merge(r)
+				continue
+			}
+			violations = append(violations, r.violations...)
+		}
+		if !valid {
+			res.add(path, "anyOf", "value must be valid against at least one schema: %s",
+				joinJSONSchemaViolations(violations))
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		var matched []int
+		var violations []JSONSchemaViolation
+		for i, sub := range oneOf {
+			r := v.validateSchema(sub, value, path, depth)
+			if r.valid() {
+				matched = append(matched, i)
+				res.merge(r)
+				continue
+			}
+			violations = append(violations, r.violations...)
+		}
+		switch {
+		case len(matched) == 0:
+			res.add(path, "oneOf", "value must be valid against exactly one schema: %s",
+				joinJSONSchemaViolations(violations))
+		case len(matched) > 1:
+			res.add(path, "oneOf", "value must be valid against exactly one schema, but is valid against %v", matched)
+		}
+	}
+	if not, ok := s["not"]; ok {
+		if v.validateSchema(not, value, path, depth).valid() {
+			res.add(path, "not", "value must not be valid against the schema")
+		}
+	}
+	if cond, ok := s["if"]; ok {
+		r := v.validateSchema(cond, value, path, depth)
+		branch := "else"
+		if r.valid() {
+			res.merge(r)
+			branch = "then"
+		}
+		if sub, present := s[branch]; present {
+			br := v.validateSchema(sub, value, path, depth)
+			res.violations = append(res.violations, br.violations...)
+			if br.valid() {
+				res.merge(br)
+			}
+		}
+	}
+}
+
+// validateUnevaluated validates unevaluatedProperties and unevaluatedItems.
+// The keywords are applied after all other keywords since they depend on their annotations.
+func (v *jsonSchemaValidator) validateUnevaluated(res *jsonSchemaResult, s map[string]any, value any, path string,
+	depth int) {
+	if unevaluated, ok := s["unevaluatedProperties"]; ok {
+		if obj, isObject := value.(map[string]any); isObject {
+			for _, k := range sortedKeys(obj) {
+				if _, evaluated := res.props[k]; evaluated {
+					continue
+				}
+				if allowed, isBool := unevaluated.(bool); isBool && !allowed {
+					res.add(path+"."+k, "unevaluatedProperties", "unexpected unevaluated property %q", k)
+					continue
+				}
+				sub := v.validateSchema(unevaluated, obj[k], path+"."+k, depth)
+				res.violations = append(res.violations, sub.violations...)
+				res.props[k] = struct{}{}
+			}
+		}
+	}
+	if unevaluated, ok := s["unevaluatedItems"]; ok {
+		if arr, isArray := value.([]any); isArray && !res.allItems {
+			for i := res.items; i < len(arr); i++ {
+				if allowed, isBool := unevaluated.(bool); isBool && !allowed {
+					res.add(fmt.Sprintf("%s[%d]", path, i), "unevaluatedItems", "unexpected unevaluated item")
+					continue
+				}
+				sub := v.validateSchema(unevaluated, arr[i], fmt.Sprintf("%s[%d]", path, i), depth)
+				res.violations = append(res.violations, sub.violations...)
+			}
+			res.allItems = true
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinJSONSchemaViolations(violations []JSONSchemaViolation) string {
+	msgs := make([]string, len(violations))
+	for i, violation := range violations {
+		msgs[i] = violation.Path + ": " + violation.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	jsonSchemaHostnameRegexp = regexp.MustCompile(
+		`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+	jsonSchemaUUIDRegexp = regexp.MustCompile(
+		`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	jsonSchemaDurationRegexp = regexp.MustCompile(
+		`^P(?:\d+W|(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:\.\d+)?S)?)?)$`)
+	jsonSchemaJSONPointerRegexp = regexp.MustCompile(`^(?:/(?:[^~/]|~[01])*)*$`)
+	jsonSchemaTimeRegexp        = regexp.MustCompile(
+		`^(?i)([01]\d|2[0-3]):[0-5]\d:([0-5]\d|60)(\.\d+)?(z|[+-]([01]\d|2[0-3]):[0-5]\d)$`)
+)
+
+// validateJSONSchemaFormat validates the most common formats. Unknown formats are ignored.
+func validateJSONSchemaFormat(format string, value string) error {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(value))
+		return err
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err
+	case "time":
+		if !jsonSchemaTimeRegexp.MatchString(value) {
+			return fmt.Errorf("invalid time")
+		}
+	case "duration":
+		if value == "P" || strings.HasSuffix(value, "T") || !jsonSchemaDurationRegexp.MatchString(value) {
+			return fmt.Errorf("invalid duration")
+		}
+	case "email", "idn-email":
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			return err
+		}
+		if addr.Address != value {
+			return fmt.Errorf("invalid address")
+		}
+	case "hostname", "idn-hostname":
+		if len(value) > 253 || !jsonSchemaHostnameRegexp.MatchString(value) {
+			return fmt.Errorf("invalid hostname")
+		}
+	case "ipv4":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+			return fmt.Errorf("invalid ipv4 address")
+		}
+	case "ipv6":
+		if ip := net.ParseIP(value); ip == nil || !strings.Contains(value, ":") {
+			return fmt.Errorf("invalid ipv6 address")
+		}
+	case "uri", "iri":
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() {
+			return fmt.Errorf("uri must be absolute")
+		}
+	case "uri-reference", "iri-reference", "uri-template":
+		_, err := url.Parse(value)
+		return err
+	case "uuid":
+		if !jsonSchemaUUIDRegexp.MatchString(value) {
+			return fmt.Errorf("invalid uuid")
+		}
+	case "regex":
+		_, err := regexp.Compile(value)
+		return err
+	case "json-pointer":
+		if !jsonSchemaJSONPointerRegexp.MatchString(value) {
+			return fmt.Errorf("invalid json pointer")
+		}
+	}
+	return nil
+}
+
+// normalizeJSONSchemaValue converts maps with non-string keys decoded from YAML to JSON objects.
+func normalizeJSONSchemaValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = normalizeJSONSchemaValue(item)
+		}
+		return m
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalizeJSONSchemaValue(item)
+		}
+		return m
+	case []any:
+		arr := make([]any, len(v))
+		for i, item := range v {
+			arr[i] = normalizeJSONSchemaValue(item)
+		}
+		return arr
+	}
+	return value
+}
@@ -0,0 +1,316 @@
+package raml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jsonSchemaValidator_validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  any
+		// want contains keywords of expected violations, empty if the value is valid.
+		want []string
+	}{
+		{
+			name:   "positive: boolean schema true",
+			schema: `true`,
+			value:  map[string]any{"a": 1},
+		},
+		{
+			name:   "negative: boolean schema false",
+			schema: `false`,
+			value:  1,
+			want:   []string{"false"},
+		},
+		{
+			name:   "positive: integer type accepts float without fraction",
+			schema: `{"type": "integer"}`,
+			value:  2.0,
+		},
+		{
+			name:   "negative: type",
+			schema: `{"type": ["string", "null"]}`,
+			value:  1,
+			want:   []string{"type"},
+		},
+		{
+			name:   "positive: enum and const with different numeric types",
+			schema: `{"enum": [1, "a"], "const": 1}`,
+			value:  json.Number("1.0"),
+		},
+		{
+			name:   "negative: enum",
+			schema: `{"enum": [1, "a"]}`,
+			value:  "b",
+			want:   []string{"enum"},
+		},
+		{
+			name:   "positive: multipleOf with decimal",
+			schema: `{"multipleOf": 0.1}`,
+			value:  json.Number("0.3"),
+		},
+		{
+			name:   "positive: multipleOf with decoded float",
+			schema: `{"multipleOf": 0.1}`,
+			value:  0.3,
+		},
+		{
+			name:   "positive: multipleOf with decoded float above one",
+			schema: `{"multipleOf": 0.1}`,
+			value:  1.1,
+		},
+		{
+			name:   "negative: multipleOf with decoded float",
+			schema: `{"multipleOf": 0.1}`,
+			value:  0.35,
+			want:   []string{"multipleOf"},
+		},
+		{
+			name:   "negative: numeric bounds",
+			schema: `{"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 2}`,
+			value:  10,
+			want:   []string{"exclusiveMaximum"},
+		},
+		{
+			name:   "negative: draft-04 exclusive minimum",
+			schema: `{"minimum": 1, "exclusiveMinimum": true}`,
+			value:  1,
+			want:   []string{"minimum"},
+		},
+		{
+			name:   "negative: string length counts runes",
+			schema: `{"maxLength": 2, "pattern": "^[a-z]+$"}`,
+			value:  "äöü",
+			want:   []string{"maxLength", "pattern"},
+		},
+		{
+			name:   "negative: formats",
+			schema: `{"type": "array", "prefixItems": [{"format": "date-time"}, {"format": "email"}, {"format": "uuid"}]}`,
+			value:  []any{"2020-01-01", "not an email", "123"},
+			want:   []string{"format", "format", "format"},
+		},
+		{
+			name:   "positive: formats",
+			schema: `{"type": "array", "prefixItems": [{"format": "date-time"}, {"format": "ipv4"}, {"format": "unknown"}]}`,
+			value:  []any{"2020-01-01T10:00:00Z", "127.0.0.1", "anything"},
+		},
+		{
+			name: "negative: ref to definitions",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"definitions": {"name": {"type": "string"}},
+				"properties": {"name": {"$ref": "#/definitions/name", "type": "integer"}}
+			}`,
+			value: map[string]any{"name": 1},
+			want:  []string{"type"},
+		},
+		{
+			name: "positive: recursive ref to $defs",
+			schema: `{
+				"$defs": {"node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}}}},
+				"$ref": "#/$defs/node"
+			}`,
+			value: map[string]any{"next": map[string]any{"next": map[string]any{}}},
+		},
+		{
+			name:   "negative: ref to anchor",
+			schema: `{"$defs": {"name": {"$anchor": "name", "type": "string"}}, "items": {"$ref": "#name"}}`,
+			value:  []any{"a", 1},
+			want:   []string{"type"},
+		},
+		{
+			name: "negative: refs are resolved against the enclosing $id",
+			schema: `{
+				"$id": "https://example.com/root.json",
+				"$defs": {
+					"item": {
+						"$id": "item/schema.json",
+						"$defs": {"name": {"$anchor": "name", "type": "string"}},
+						"properties": {"name": {"$ref": "#/$defs/name"}, "alias": {"$ref": "#name"},
+							"count": {"$ref": "count.json"}}
+					},
+					"count": {"$id": "item/count.json", "type": "integer"}
+				},
+				"items": {"$ref": "item/schema.json"}
+			}`,
+			value: []any{map[string]any{"name": 1, "alias": 2, "count": "x"}},
+			want:  []string{"type", "type", "type"},
+		},
+		{
+			name:   "negative: unresolvable ref",
+			schema: `{"$ref": "#/$defs/missing"}`,
+			value:  1,
+			want:   []string{"$ref"},
+		},
+		{
+			name:   "negative: draft-07 tuple with additional items",
+			schema: `{"items": [{"type": "string"}], "additionalItems": false}`,
+			value:  []any{"a", "b"},
+			want:   []string{"false"},
+		},
+		{
+			name:   "negative: array keywords",
+			schema: `{"minItems": 5, "uniqueItems": true, "contains": {"type": "string"}, "maxContains": 1}`,
+			value:  []any{1, "a", "b", json.Number("1")},
+			want:   []string{"minItems", "uniqueItems", "maxContains"},
+		},
+		{
+			name: "negative: object keywords",
+			schema: `{
+				"properties": {"a": {"type": "string"}},
+				"patternProperties": {"^x-": {"type": "integer"}},
+				"additionalProperties": false,
+				"required": ["a", "b"],
+				"propertyNames": {"maxLength": 3}
+			}`,
+			value: map[string]any{"a": "s", "x-1": "s", "c": 1, "x-long": 1},
+			want:  []string{"required", "type", "additionalProperties", "maxLength"},
+		},
+		{
+			name: "negative: dependentRequired and dependentSchemas",
+			schema: `{
+				"dependentRequired": {"a": ["b"]},
+				"dependentSchemas": {"a": {"properties": {"a": {"type": "integer"}}}}
+			}`,
+			value: map[string]any{"a": "s"},
+			want:  []string{"dependentRequired", "type"},
+		},
+		{
+			name:   "negative: draft-07 dependencies",
+			schema: `{"dependencies": {"a": ["b"]}}`,
+			value:  map[string]any{"a": 1},
+			want:   []string{"dependencies"},
+		},
+		{
+			name:   "negative: allOf",
+			schema: `{"allOf": [{"type": "integer"}, {"minimum": 5}]}`,
+			value:  3,
+			want:   []string{"minimum"},
+		},
+		{
+			name:   "negative: anyOf",
+			schema: `{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`,
+			value:  3,
+			want:   []string{"anyOf"},
+		},
+		{
+			name:   "negative: oneOf matches more than one schema",
+			schema: `{"oneOf": [{"type": "integer"}, {"minimum": 1}]}`,
+			value:  3,
+			want:   []string{"oneOf"},
+		},
+		{
+			name:   "negative: not",
+			schema: `{"not": {"type": "integer"}}`,
+			value:  3,
+			want:   []string{"not"},
+		},
+		{
+			name: "negative: if then else",
+			schema: `{
+				"if": {"properties": {"kind": {"const": "cat"}}},
+				"then": {"required": ["meow"]},
+				"else": {"required": ["bark"]}
+			}`,
+			value: map[string]any{"kind": "cat", "bark": true},
+			want:  []string{"required"},
+		},
+		{
+			name: "positive: unevaluatedProperties with allOf",
+			schema: `{
+				"allOf": [{"properties": {"a": true}}],
+				"properties": {"b": true},
+				"unevaluatedProperties": false
+			}`,
+			value: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name: "negative: unevaluatedProperties",
+			schema: `{
+				"allOf": [{"properties": {"a": true}}],
+				"unevaluatedProperties": false
+			}`,
+			value: map[string]any{"a": 1, "c": 2},
+			want:  []string{"unevaluatedProperties"},
+		},
+		{
+			name:   "negative: unevaluatedItems",
+			schema: `{"prefixItems": [{"type": "integer"}], "unevaluatedItems": false}`,
+			value:  []any{1, 2},
+			want:   []string{"unevaluatedItems"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := newJSONSchemaValidatorFromRaw(tt.schema)
+			require.NoError(t, err)
+			violations := v.validate(tt.value, "$")
+			got := make([]string, len(violations))
+			for i, violation := range violations {
+				got[i] = violation.Keyword
+			}
+			require.ElementsMatch(t, tt.want, got, "violations: %v", violations)
+		})
+	}
+}
+
+func Test_newJSONSchemaValidatorFromRaw(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:   "positive: pattern in properties named as keywords",
+			schema: `{"properties": {"enum": {"pattern": "^a"}, "$id": {"type": "string"}}}`,
+		},
+		{
+			name:    "negative: lookahead pattern",
+			schema:  `{"properties": {"code": {"pattern": "^(?=a)"}}}`,
+			wantErr: `#/properties/code/pattern: unsupported pattern "^(?=a)"`,
+		},
+		{
+			name:    "negative: backreference in pattern properties",
+			schema:  `{"patternProperties": {"^(a)\\1$": true}}`,
+			wantErr: `#/patternProperties: unsupported pattern "^(a)\\1$"`,
+		},
+		{
+			name:    "negative: dynamic ref",
+			schema:  `{"$defs": {"node": {"$dynamicAnchor": "node"}}, "items": {"$dynamicRef": "#node"}}`,
+			wantErr: "#/items: unsupported keyword $dynamicRef",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newJSONSchemaValidatorFromRaw(tt.schema)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestJSONShape_validate(t *testing.T) {
+	r := New(nil)
+	s, err := r.MakeJSONShape(&BaseShape{Location: "test.json"}, `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`)
+	require.NoError(t, err)
+
+	require.NoError(t, s.validate(map[string]any{"name": "test"}, "$"))
+	require.NoError(t, s.validate(map[any]any{"name": "test"}, "$"))
+
+	err = s.validate(map[string]any{"name": 1}, "$.person")
+	require.ErrorContains(t, err, "path: $.person.name")
+	require.ErrorContains(t, err, "keyword: type")
+
+	require.NoError(t, (&JSONShape{BaseShape: &BaseShape{}}).validate(1, "$"))
+}
@@ -0,0 +1,1026 @@
+package raml
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+type JSONSchemaImporterOptions struct {
+	rootTypeName string
+}
+
+type JSONSchemaImporterOpt interface {
+	apply(*JSONSchemaImporterOptions)
+}
+
+type optRootTypeName struct{ name string }
+
+func (o optRootTypeName) apply(opts *JSONSchemaImporterOptions) { opts.rootTypeName = o.name }
+
+// WithRootTypeName sets the name of the type created from the root schema.
+// By default, the name is derived from the schema title or the document file name.
+func WithRootTypeName(name string) JSONSchemaImporterOpt {
+	return optRootTypeName{name}
+}
+
+// JSONSchemaImporter converts JSON Schema documents (draft-07 and 2020-12) into RAML data types.
+//
+// The root schema and its definitions ("definitions" and "$defs") become named types and references to them
+// become references to the named types. Objects, arrays and scalars are mapped to the respective RAML types,
+// "anyOf" and "oneOf" are mapped to unions and "allOf" is mapped to multiple inheritance.
+// Since RAML unions can only be declared with type expressions, union members that declare their own facets
+// are declared as separate named types.
+//
+// The result is a library of resolved shapes. Use Encoder to write it as a RAML document.
+// Keywords that cannot be expressed in RAML are ignored.
+type JSONSchemaImporter struct {
+	raml *RAML
+	opts JSONSchemaImporterOptions
+
+	location  string
+	legacyRef bool
+	root      *yaml.Node
+	lib       *Library
+	// named contains named types by JSON pointer of their schemas.
+	named map[string]*BaseShape
+	// converting contains referenced schemas being converted inline to detect recursion.
+	converting map[*yaml.Node]struct{}
+	// schemas contains schemas of named types.
+	schemas map[*BaseShape]*yaml.Node
+	// pending contains named types to be converted.
+	pending []pendingNamedType
+	// annotate converts extension keywords of the schema into annotations of the shape. Nil if not supported.
+	annotate func(schema *yaml.Node, base *BaseShape) error
+}
+
+type pendingNamedType struct {
+	base   *BaseShape
+	schema *yaml.Node
+}
+
+func NewJSONSchemaImporter(r *RAML, opts ...JSONSchemaImporterOpt) *JSONSchemaImporter {
+	im := &JSONSchemaImporter{raml: r}
+	for _, o := range opts {
+		o.apply(&im.opts)
+	}
+	return im
+}
+
+// Import converts the JSON Schema document into a library of RAML data types.
+// Location is used as the library location and to derive the root type name.
+func (im *JSONSchemaImporter) Import(data []byte, location string) (*Library, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, StacktraceNewWrapped("decode json schema", err, location)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, StacktraceNew("json schema document is empty", location)
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("json schema must be an object", location, WithNodePosition(root))
+	}
+	return im.importRoot(root, location)
+}
+
+// importRoot converts the root schema and its definitions into a library of RAML data types.
+func (im *JSONSchemaImporter) importRoot(root *yaml.Node, location string) (*Library, error) {
+	im.location = location
+	im.root = root
+	im.lib = im.raml.MakeLibrary(location)
+	im.named = make(map[string]*BaseShape)
+	im.converting = make(map[*yaml.Node]struct{})
+	im.schemas = make(map[*BaseShape]*yaml.Node)
+	im.pending = nil
+	im.legacyRef = false
+	if version := jsonSchemaKeyword(root, "$schema"); version != nil {
+		im.legacyRef = strings.Contains(version.Value, "draft-0")
+	}
+
+	// Named types are declared before conversion so that references can be resolved regardless of the order.
+	if isJSONSchemaTypeDeclaration(root) {
+		im.named["#"] = im.declare(im.rootTypeName(root), root)
+	}
+	for _, key := range []string{"definitions", "$defs"} {
+		defs := jsonSchemaKeyword(root, key)
+		if defs == nil || defs.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i != len(defs.Content); i += 2 {
+			name := defs.Content[i].Value
+			pointer := "#/" + key + "/" + strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+			im.named[pointer] = im.declare(ramlTypeName(name), defs.Content[i+1])
+		}
+	}
+
+	var st *stacktrace.StackTrace
+	// NOTE: Conversion may declare additional named types for union members.
+	for len(im.pending) > 0 {
+		item := im.pending[0]
+		im.pending = im.pending[1:]
+		if err := im.convert(item.schema, item.base, item.base.Name); err != nil {
+			se := StacktraceNewWrapped("convert type", err, location,
+				stacktrace.WithPosition(&item.base.Position), stacktrace.WithInfo("type", item.base.Name))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	if st != nil {
+		return nil, st
+	}
+	im.raml.PutFragment(location, im.lib)
+	return im.lib, nil
+}
+
+// importSchema converts the schema that is not a definition into an anonymous shape.
+// It must be called after the root is imported, so references to definitions become aliases of named types.
+// Named types declared for union members are added to the library.
+func (im *JSONSchemaImporter) importSchema(schema *yaml.Node, name string, hint string) (*BaseShape, error) {
+	base := im.raml.MakeBaseShape(name, im.location, stacktrace.Position{Line: schema.Line, Column: schema.Column})
+	if err := im.convert(schema, base, hint); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+func (im *JSONSchemaImporter) rootTypeName(root *yaml.Node) string {
+	if im.opts.rootTypeName != "" {
+		return im.opts.rootTypeName
+	}
+	if title := jsonSchemaKeyword(root, "title"); title != nil && title.Value != "" {
+		return ramlTypeName(title.Value)
+	}
+	name, _, _ := strings.Cut(filepath.Base(im.location), ".")
+	return ramlTypeName(name)
+}
+
+// declare declares a named type with a unique name in the library.
+func (im *JSONSchemaImporter) declare(name string, schema *yaml.Node) *BaseShape {
+	unique := im.uniqueTypeName(name)
+	base := im.raml.MakeBaseShape(unique, im.location, stacktrace.Position{Line: schema.Line, Column: schema.Column})
+	im.lib.Types.Set(unique, base)
+	im.raml.PutTypeIntoFragment(unique, im.location, base)
+	im.schemas[base] = schema
+	im.pending = append(im.pending, pendingNamedType{base: base, schema: schema})
+	return base
+}
+
+func (im *JSONSchemaImporter) uniqueTypeName(name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, present := im.lib.Types.Get(unique); !present {
+			return unique
+		}
+		unique = name + strconv.Itoa(i)
+	}
+}
+
+// convert converts the schema into the concrete shape of the base shape.
+// Hint is used to name the types declared for union members.
+//
+//nolint:gocognit,gocyclo,cyclop,funlen // Schema keywords are converted in one place to keep the precedence clear.
+func (im *JSONSchemaImporter) convert(schema *yaml.Node, base *BaseShape, hint string) error {
+	if schema.Kind == yaml.ScalarNode && schema.Tag == "!!bool" {
+		shapeType := TypeAny
+		if schema.Value == "false" {
+			shapeType = TypeNil
+		}
+		_, err := im.raml.MakeConcreteShapeYAML(base, shapeType, nil)
+		return err
+	}
+	if schema.Kind != yaml.MappingNode {
+		return StacktraceNew("schema must be an object or boolean", im.location, WithNodePosition(schema))
+	}
+
+	if err := im.convertAnnotations(schema, base); err != nil {
+		return err
+	}
+
+	if ref := jsonSchemaKeyword(schema, "$ref"); ref != nil {
+		if im.legacyRef || !hasJSONSchemaFacets(schema, "$ref") {
+			return im.convertRef(ref, base, hint)
+		}
+		// Keywords next to $ref extend the referenced schema since 2019-09.
+		named, ok := im.named[ref.Value]
+		if !ok {
+			// Referenced schema is merged with the sibling keywords as if they were members of "allOf".
+			return im.convertAllOf(schema, []*yaml.Node{ref}, base, hint)
+		}
+		if _, err := im.raml.MakeConcreteShapeYAML(base, im.inferType(schema, nil), nil); err != nil {
+			return err
+		}
+		base.TypeLabel = named.Name
+		base.Inherits = []*BaseShape{named}
+		return im.convertFacets(schema, base.Shape, hint)
+	}
+
+	if allOf := jsonSchemaKeyword(schema, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+		return im.convertAllOf(schema, allOf.Content, base, hint)
+	}
+
+	shapeType := im.inferType(schema, nil)
+	if shapeType == TypeUnion {
+		return im.convertUnion(schema, base, hint)
+	}
+	if _, err := im.raml.MakeConcreteShapeYAML(base, shapeType, nil); err != nil {
+		return err
+	}
+	return im.convertFacets(schema, base.Shape, hint)
+}
+
+func (im *JSONSchemaImporter) convertAnnotations(schema *yaml.Node, base *BaseShape) error {
+	if title := jsonSchemaKeyword(schema, "title"); title != nil {
+		displayName := title.Value
+		base.DisplayName = &displayName
+	}
+	if description := jsonSchemaKeyword(schema, "description"); description != nil {
+		desc := description.Value
+		base.Description = &desc
+	}
+	if im.annotate != nil {
+		if err := im.annotate(schema, base); err != nil {
+			return err
+		}
+	}
+	if def := jsonSchemaKeyword(schema, "default"); def != nil {
+		n, err := im.raml.makeYamlNode(def, im.location)
+		if err != nil {
+			return fmt.Errorf("make default node: %w", err)
+		}
+		base.Default = n
+	}
+	examples := jsonSchemaKeyword(schema, "examples")
+	if examples == nil || examples.Kind != yaml.SequenceNode || len(examples.Content) == 0 {
+		return nil
+	}
+	if len(examples.Content) == 1 {
+		ex, err := im.makeExample(examples.Content[0], "")
+		if err != nil {
+			return err
+		}
+		base.Example = ex
+		return nil
+	}
+	base.Examples = &Examples{
+		Map:      orderedmap.New[string, *Example](len(examples.Content)),
+		Location: im.location,
+		Position: stacktrace.Position{Line: examples.Line, Column: examples.Column},
+	}
+	for i, node := range examples.Content {
+		name := "example" + strconv.Itoa(i+1)
+		ex, err := im.makeExample(node, name)
+		if err != nil {
+			return err
+		}
+		base.Examples.Map.Set(name, ex)
+	}
+	return nil
+}
+
+func (im *JSONSchemaImporter) makeExample(value *yaml.Node, name string) (*Example, error) {
+	n, err := im.raml.makeYamlNode(value, im.location)
+	if err != nil {
+		return nil, fmt.Errorf("make example node: %w", err)
+	}
+	return &Example{
+		Name:                   name,
+		Data:                   n,
+		Strict:                 true,
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Location:               im.location,
+		Position:               n.Position,
+		raml:                   im.raml,
+	}, nil
+}
+
+// convertRef converts the reference to a named type into an alias and inlines other references.
+func (im *JSONSchemaImporter) convertRef(ref *yaml.Node, base *BaseShape, hint string) error {
+	if named, ok := im.named[ref.Value]; ok {
+		if _, err := im.raml.MakeConcreteShapeYAML(base, im.inferType(im.schemas[named], nil), nil); err != nil {
+			return err
+		}
+		base.TypeLabel = named.Name
+		base.Alias = named
+		return nil
+	}
+	target, err := im.resolveRef(ref)
+	if err != nil {
+		return err
+	}
+	if _, ok := im.converting[target]; ok {
+		return StacktraceNew("recursive reference to a schema that is not a definition is not supported",
+			im.location, WithNodePosition(ref), stacktrace.WithInfo("ref", ref.Value))
+	}
+	im.converting[target] = struct{}{}
+	defer delete(im.converting, target)
+	return im.convert(target, base, hint)
+}
+
+// convertAllOf converts "allOf" into multiple inheritance of named types.
+// Inline members are merged into the shape since RAML allows inheritance only from named types.
+func (im *JSONSchemaImporter) convertAllOf(schema *yaml.Node, members []*yaml.Node, base *BaseShape, hint string) error {
+	var inherits []*BaseShape
+	var inline []*yaml.Node
+	for _, member := range members {
+		var ref *yaml.Node
+		if member.Kind == yaml.ScalarNode && member.Tag == TagStr {
+			// A sibling $ref is passed as a scalar.
+			ref = member
+		} else if r := jsonSchemaKeyword(member, "$ref"); r != nil && !hasJSONSchemaFacets(member, "$ref") {
+			ref = r
+		}
+		if ref == nil {
+			inline = append(inline, member)
+			continue
+		}
+		if named, ok := im.named[ref.Value]; ok {
+			inherits = append(inherits, named)
+			continue
+		}
+		target, err := im.resolveRef(ref)
+		if err != nil {
+			return err
+		}
+		inline = append(inline, target)
+	}
+
+	shapeType := im.inferOwnType(schema)
+	if shapeType == TypeAny {
+		for _, parent := range inherits {
+			if shapeType = im.inferType(im.schemas[parent], nil); shapeType != TypeAny {
+				break
+			}
+		}
+	}
+	if shapeType == TypeAny {
+		for _, member := range inline {
+			if shapeType = im.inferType(member, nil); shapeType != TypeAny {
+				break
+			}
+		}
+	}
+	if _, err := im.raml.MakeConcreteShapeYAML(base, shapeType, nil); err != nil {
+		return err
+	}
+	switch len(inherits) {
+	case 0:
+	case 1:
+		base.TypeLabel = inherits[0].Name
+		base.Inherits = inherits
+	default:
+		// Multiple inheritance is represented by aliases the same way as the parser does.
+		for _, parent := range inherits {
+			aliasBase := im.raml.MakeBaseShape(parent.Name, im.location, base.Position)
+			if _, err := im.raml.MakeConcreteShapeYAML(aliasBase, im.inferType(im.schemas[parent], nil), nil); err != nil {
+				return err
+			}
+			aliasBase.TypeLabel = parent.Name
+			aliasBase.Alias = parent
+			base.Inherits = append(base.Inherits, aliasBase)
+		}
+	}
+	for _, member := range inline {
+		if member.Kind != yaml.MappingNode {
+			continue
+		}
+		if err := im.convertFacets(member, base.Shape, hint); err != nil {
+			return err
+		}
+	}
+	return im.convertFacets(schema, base.Shape, hint)
+}
+
+// convertUnion converts "anyOf", "oneOf" and multiple types into a union.
+func (im *JSONSchemaImporter) convertUnion(schema *yaml.Node, base *BaseShape, hint string) error {
+	var members []*yaml.Node
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if n := jsonSchemaKeyword(schema, key); n != nil && n.Kind == yaml.SequenceNode {
+			members = append(members, n.Content...)
+		}
+	}
+	if len(members) == 0 {
+		// Each type of multiple types produces a member with the same facets.
+		for _, t := range jsonSchemaTypes(schema) {
+			member := &yaml.Node{Kind: yaml.MappingNode, Line: schema.Line, Column: schema.Column}
+			for i := 0; i != len(schema.Content) && t != TypeNull; i += 2 {
+				if key := schema.Content[i].Value; key == "type" || isJSONSchemaAnnotation(key) {
+					continue
+				}
+				member.Content = append(member.Content, schema.Content[i], schema.Content[i+1])
+			}
+			member.Content = append(member.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: "type"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: t})
+			members = append(members, member)
+		}
+	}
+	s, err := im.raml.MakeConcreteShapeYAML(base, TypeUnion, nil)
+	if err != nil {
+		return err
+	}
+	union, _ := s.(*UnionShape)
+	for i, member := range members {
+		memberBase, errMember := im.convertUnionMember(member, fmt.Sprintf("%s%d", hint, i+1))
+		if errMember != nil {
+			return fmt.Errorf("convert union member: %w", errMember)
+		}
+		union.AnyOf = append(union.AnyOf, memberBase)
+	}
+	return nil
+}
+
+// convertUnionMember converts a union member.
+// Members that cannot be expressed with a type expression are declared as named types.
+func (im *JSONSchemaImporter) convertUnionMember(member *yaml.Node, name string) (*BaseShape, error) {
+	pos := stacktrace.Position{Line: member.Line, Column: member.Column}
+	memberBase := im.raml.MakeBaseShape("", im.location, pos)
+	if err := im.convert(member, memberBase, name); err != nil {
+		return nil, err
+	}
+	if n, err := (&Encoder{}).shapeNode(memberBase); err == nil && n.Kind == yaml.ScalarNode {
+		return memberBase, nil
+	}
+	if title := jsonSchemaKeyword(member, "title"); title != nil {
+		name = ramlTypeName(title.Value)
+	}
+	memberBase.Name = im.uniqueTypeName(name)
+	im.lib.Types.Set(memberBase.Name, memberBase)
+	im.raml.PutTypeIntoFragment(memberBase.Name, im.location, memberBase)
+
+	aliasBase := im.raml.MakeBaseShape("", im.location, pos)
+	if _, err := im.raml.MakeConcreteShapeYAML(aliasBase, memberBase.Type, nil); err != nil {
+		return nil, err
+	}
+	aliasBase.TypeLabel = memberBase.Name
+	aliasBase.Alias = memberBase
+	return aliasBase, nil
+}
+
+// convertFacets converts keywords of the schema into facets of the shape.
+func (im *JSONSchemaImporter) convertFacets(schema *yaml.Node, s Shape, hint string) error {
+	var err error
+	switch shape := s.(type) {
+	case *ObjectShape:
+		err = im.convertObjectFacets(schema, shape, hint)
+	case *ArrayShape:
+		err = im.convertArrayFacets(schema, shape, hint)
+	case *StringShape:
+		shape.Enum, err = im.convertEnum(schema)
+		if err != nil {
+			return err
+		}
+		shape.MinLength = jsonSchemaUint(schema, "minLength", shape.MinLength)
+		shape.MaxLength = jsonSchemaUint(schema, "maxLength", shape.MaxLength)
+		if pattern := jsonSchemaKeyword(schema, "pattern"); pattern != nil {
+			re, errCompile := regexp.Compile(pattern.Value)
+			if errCompile != nil {
+				return StacktraceNewWrapped("compile pattern", errCompile, im.location, WithNodePosition(pattern))
+			}
+			shape.Pattern = re
+		}
+	case *IntegerShape:
+		shape.Enum, err = im.convertEnum(schema)
+		if err != nil {
+			return err
+		}
+		err = im.convertIntegerFacets(schema, shape)
+	case *NumberShape:
+		shape.Enum, err = im.convertEnum(schema)
+		if err != nil {
+			return err
+		}
+		im.convertNumberFacets(schema, shape)
+	case *BooleanShape:
+		shape.Enum, err = im.convertEnum(schema)
+	}
+	return err
+}
+
+func (im *JSONSchemaImporter) convertObjectFacets(schema *yaml.Node, s *ObjectShape, hint string) error {
+	required := make(map[string]struct{})
+	if n := jsonSchemaKeyword(schema, "required"); n != nil {
+		for _, item := range n.Content {
+			required[item.Value] = struct{}{}
+		}
+	}
+	if props := jsonSchemaKeyword(schema, "properties"); props != nil && props.Kind == yaml.MappingNode {
+		if s.Properties == nil {
+			s.Properties = orderedmap.New[string, Property](len(props.Content) / 2)
+		}
+		for i := 0; i != len(props.Content); i += 2 {
+			name := props.Content[i].Value
+			value := props.Content[i+1]
+			propBase := im.raml.MakeBaseShape(name, im.location, stacktrace.Position{Line: value.Line, Column: value.Column})
+			if err := im.convert(value, propBase, hint+ramlTypeName(name)); err != nil {
+				return fmt.Errorf("convert property %s: %w", name, err)
+			}
+			_, isRequired := required[name]
+			s.Properties.Set(name, Property{Name: name, Base: propBase, Required: isRequired, raml: im.raml})
+		}
+	}
+	if patternProps := jsonSchemaKeyword(schema, "patternProperties"); patternProps != nil &&
+		patternProps.Kind == yaml.MappingNode {
+		for i := 0; i != len(patternProps.Content); i += 2 {
+			if err := im.addPatternProperty(s, patternProps.Content[i], patternProps.Content[i+1], hint); err != nil {
+				return err
+			}
+		}
+	}
+	if additional := jsonSchemaKeyword(schema, "additionalProperties"); additional != nil {
+		switch {
+		case additional.Tag == "!!bool":
+			allowed := additional.Value == "true"
+			s.AdditionalProperties = &allowed
+		case additional.Kind == yaml.MappingNode:
+			// Schema of additional properties is represented as a pattern property that matches any name.
+			pattern := &yaml.Node{Kind: yaml.ScalarNode, Value: ".*", Line: additional.Line, Column: additional.Column}
+			if err := im.addPatternProperty(s, pattern, additional, hint); err != nil {
+				return err
+			}
+		}
+	}
+	if s.PatternProperties != nil && s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		// RAML does not allow pattern properties with "additionalProperties: false".
+		s.AdditionalProperties = nil
+	}
+	s.MinProperties = jsonSchemaUint(schema, "minProperties", s.MinProperties)
+	s.MaxProperties = jsonSchemaUint(schema, "maxProperties", s.MaxProperties)
+	return nil
+}
+
+func (im *JSONSchemaImporter) addPatternProperty(s *ObjectShape, pattern *yaml.Node, value *yaml.Node,
+	hint string) error {
+	re, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		return StacktraceNewWrapped("compile pattern", err, im.location, WithNodePosition(pattern))
+	}
+	propBase := im.raml.MakeBaseShape(pattern.Value, im.location, stacktrace.Position{Line: value.Line, Column: value.Column})
+	if err = im.convert(value, propBase, hint+"Value"); err != nil {
+		return fmt.Errorf("convert pattern property %s: %w", pattern.Value, err)
+	}
+	if s.PatternProperties == nil {
+		s.PatternProperties = orderedmap.New[string, PatternProperty](0)
+	}
+	s.PatternProperties.Set(pattern.Value, PatternProperty{Pattern: re, Base: propBase, raml: im.raml})
+	return nil
+}
+
+func (im *JSONSchemaImporter) convertArrayFacets(schema *yaml.Node, s *ArrayShape, hint string) error {
+	items := jsonSchemaKeyword(schema, "items")
+	tuple := jsonSchemaKeyword(schema, "prefixItems")
+	if tuple == nil && items != nil && items.Kind == yaml.SequenceNode {
+		tuple, items = items, jsonSchemaKeyword(schema, "additionalItems")
+	}
+	if tuple != nil {
+		// Tuples are not supported by RAML, items are represented as a union of tuple members.
+		members := tuple.Content
+		if items != nil && items.Kind == yaml.MappingNode {
+			members = append(members, items)
+		}
+		items = &yaml.Node{Kind: yaml.MappingNode, Line: tuple.Line, Column: tuple.Column, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: TagStr, Value: "anyOf"},
+			{Kind: yaml.SequenceNode, Content: members},
+		}}
+		if len(members) == 1 {
+			items = members[0]
+		}
+	}
+	if items != nil && items.Tag != "!!bool" {
+		itemsBase := im.raml.MakeBaseShape("items", im.location, stacktrace.Position{Line: items.Line, Column: items.Column})
+		if err := im.convert(items, itemsBase, hint+"Item"); err != nil {
+			return fmt.Errorf("convert items: %w", err)
+		}
+		s.Items = itemsBase
+	}
+	s.MinItems = jsonSchemaUint(schema, "minItems", s.MinItems)
+	s.MaxItems = jsonSchemaUint(schema, "maxItems", s.MaxItems)
+	if unique := jsonSchemaKeyword(schema, "uniqueItems"); unique != nil {
+		b := unique.Value == "true"
+		s.UniqueItems = &b
+	}
+	return nil
+}
+
+func (im *JSONSchemaImporter) convertIntegerFacets(schema *yaml.Node, s *IntegerShape) error {
+	bound := func(key string) (*big.Rat, error) {
+		n := jsonSchemaKeyword(schema, key)
+		if n == nil || n.Tag == "!!bool" {
+			return nil, nil
+		}
+		r, ok := new(big.Rat).SetString(n.Value)
+		if !ok {
+			return nil, StacktraceNew("bound must be a number", im.location, WithNodePosition(n),
+				stacktrace.WithInfo("keyword", key))
+		}
+		return r, nil
+	}
+	// draft-04 declares exclusive bounds with boolean flags.
+	isExclusive := func(key string) bool {
+		n := jsonSchemaKeyword(schema, key)
+		return n != nil && n.Tag == "!!bool" && n.Value == "true"
+	}
+	one := big.NewInt(1)
+	// Integer bounds are rounded towards the allowed range.
+	if r, err := bound("minimum"); err != nil {
+		return err
+	} else if r != nil {
+		if isExclusive("exclusiveMinimum") {
+			s.Minimum = new(big.Int).Add(floorRat(r), one)
+		} else {
+			s.Minimum = ceilRat(r)
+		}
+	}
+	if r, err := bound("exclusiveMinimum"); err != nil {
+		return err
+	} else if r != nil {
+		s.Minimum = new(big.Int).Add(floorRat(r), one)
+	}
+	if r, err := bound("maximum"); err != nil {
+		return err
+	} else if r != nil {
+		if isExclusive("exclusiveMaximum") {
+			s.Maximum = new(big.Int).Sub(ceilRat(r), one)
+		} else {
+			s.Maximum = floorRat(r)
+		}
+	}
+	if r, err := bound("exclusiveMaximum"); err != nil {
+		return err
+	} else if r != nil {
+		s.Maximum = new(big.Int).Sub(ceilRat(r), one)
+	}
+	s.MultipleOf = jsonSchemaFloat(schema, "multipleOf", s.MultipleOf)
+	if format := jsonSchemaKeyword(schema, "format"); format != nil {
+		if _, ok := SetOfIntegerFormats[format.Value]; ok {
+			f := format.Value
+			s.Format = &f
+		}
+	}
+	return nil
+}
+
+func floorRat(r *big.Rat) *big.Int {
+	// Denominator is always positive, so Euclidean division rounds down.
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+func ceilRat(r *big.Rat) *big.Int {
+	return new(big.Int).Neg(floorRat(new(big.Rat).Neg(r)))
+}
+
+func (im *JSONSchemaImporter) convertNumberFacets(schema *yaml.Node, s *NumberShape) {
+	// NOTE: Exclusive bounds cannot be expressed in RAML and are converted to inclusive ones.
+	s.Minimum = jsonSchemaFloat(schema, "minimum", s.Minimum)
+	s.Minimum = jsonSchemaFloat(schema, "exclusiveMinimum", s.Minimum)
+	s.Maximum = jsonSchemaFloat(schema, "maximum", s.Maximum)
+	s.Maximum = jsonSchemaFloat(schema, "exclusiveMaximum", s.Maximum)
+	s.MultipleOf = jsonSchemaFloat(schema, "multipleOf", s.MultipleOf)
+	if format := jsonSchemaKeyword(schema, "format"); format != nil {
+		if _, ok := SetOfNumberFormats[format.Value]; ok {
+			f := format.Value
+			s.Format = &f
+		}
+	}
+}
+
+// convertEnum converts "enum" and "const" into enum nodes.
+func (im *JSONSchemaImporter) convertEnum(schema *yaml.Node) (Nodes, error) {
+	var values []*yaml.Node
+	if enum := jsonSchemaKeyword(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		values = enum.Content
+	} else if c := jsonSchemaKeyword(schema, "const"); c != nil {
+		values = []*yaml.Node{c}
+	}
+	if values == nil {
+		return nil, nil
+	}
+	enum := make(Nodes, 0, len(values))
+	for _, v := range values {
+		// null is allowed by a separate union member.
+		if v.Tag == TagNull {
+			continue
+		}
+		n, err := im.raml.makeYamlNode(v, im.location)
+		if err != nil {
+			return nil, fmt.Errorf("make enum node: %w", err)
+		}
+		enum = append(enum, n)
+	}
+	return enum, nil
+}
+
+// resolveRef resolves the JSON pointer reference within the document.
+func (im *JSONSchemaImporter) resolveRef(ref *yaml.Node) (*yaml.Node, error) {
+	pointer, found := strings.CutPrefix(ref.Value, "#")
+	if !found {
+		return nil, StacktraceNew("only local references are supported", im.location, WithNodePosition(ref),
+			stacktrace.WithInfo("ref", ref.Value))
+	}
+	node := resolveJSONPointer(im.root, pointer)
+	if node == nil {
+		return nil, StacktraceNew("unresolvable reference", im.location, WithNodePosition(ref),
+			stacktrace.WithInfo("ref", ref.Value))
+	}
+	return node, nil
+}
+
+// resolveJSONPointer returns the node the JSON pointer refers to or nil if the pointer is unresolvable.
+func resolveJSONPointer(root *yaml.Node, pointer string) *yaml.Node {
+	node := root
+	if pointer == "" {
+		return node
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = jsonSchemaKeyword(node, token)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// inferType returns the RAML type of the schema following references.
+func (im *JSONSchemaImporter) inferType(schema *yaml.Node, visited map[*yaml.Node]struct{}) string {
+	if schema.Kind == yaml.ScalarNode && schema.Tag == "!!bool" {
+		if schema.Value == "false" {
+			return TypeNil
+		}
+		return TypeAny
+	}
+	if schema.Kind != yaml.MappingNode {
+		return TypeAny
+	}
+	if visited == nil {
+		visited = make(map[*yaml.Node]struct{})
+	}
+	if _, ok := visited[schema]; ok {
+		return TypeAny
+	}
+	visited[schema] = struct{}{}
+
+	if t := im.inferOwnType(schema); t != TypeAny {
+		return t
+	}
+	if ref := jsonSchemaKeyword(schema, "$ref"); ref != nil {
+		if target, err := im.resolveRef(ref); err == nil {
+			return im.inferType(target, visited)
+		}
+	}
+	if allOf := jsonSchemaKeyword(schema, "allOf"); allOf != nil {
+		for _, member := range allOf.Content {
+			if t := im.inferType(member, visited); t != TypeAny {
+				return t
+			}
+		}
+	}
+	return TypeAny
+}
+
+// inferOwnType returns the RAML type of the schema by its own keywords.
+func (im *JSONSchemaImporter) inferOwnType(schema *yaml.Node) string {
+	if types := jsonSchemaTypes(schema); len(types) > 0 {
+		if len(types) > 1 {
+			return TypeUnion
+		}
+		return ramlTypeOfJSONSchemaType(types[0], jsonSchemaKeyword(schema, "format"))
+	}
+	if jsonSchemaKeyword(schema, "anyOf") != nil || jsonSchemaKeyword(schema, "oneOf") != nil {
+		return TypeUnion
+	}
+	if values := jsonSchemaEnumValues(schema); values != nil {
+		return ramlTypeOfJSONSchemaValues(values)
+	}
+	for i := 0; i != len(schema.Content); i += 2 {
+		switch schema.Content[i].Value {
+		case "properties", "patternProperties", "additionalProperties", "required", "minProperties",
+			"maxProperties", "propertyNames", "dependentRequired", "dependentSchemas":
+			return TypeObject
+		case "items", "prefixItems", "minItems", "maxItems", "uniqueItems", "contains":
+			return TypeArray
+		case "minLength", "maxLength", "pattern":
+			return TypeString
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			return TypeNumber
+		}
+	}
+	return TypeAny
+}
+
+func ramlTypeOfJSONSchemaType(t string, format *yaml.Node) string {
+	switch t {
+	case "string":
+		if format != nil {
+			switch format.Value {
+			case FormatDateTime:
+				return TypeDatetime
+			case FormatDate:
+				return TypeDateOnly
+			}
+		}
+		return TypeString
+	case "integer":
+		return TypeInteger
+	case "number":
+		return TypeNumber
+	case "boolean":
+		return TypeBoolean
+	case "object":
+		return TypeObject
+	case "array":
+		return TypeArray
+	case TypeNull:
+		return TypeNil
+	}
+	return TypeAny
+}
+
+func ramlTypeOfJSONSchemaValues(values []*yaml.Node) string {
+	t := ""
+	for _, v := range values {
+		var vt string
+		switch {
+		case v.Tag == TagNull:
+			continue
+		case v.Tag == TagStr:
+			vt = TypeString
+		case v.Tag == TagInt:
+			vt = TypeInteger
+		case v.Tag == "!!float":
+			vt = TypeNumber
+		case v.Tag == "!!bool":
+			vt = TypeBoolean
+		default:
+			return TypeAny
+		}
+		switch {
+		case t == "" || t == vt:
+			t = vt
+		case t == TypeInteger && vt == TypeNumber || t == TypeNumber && vt == TypeInteger:
+			t = TypeNumber
+		default:
+			return TypeAny
+		}
+	}
+	if t == "" {
+		return TypeNil
+	}
+	return t
+}
+
+func jsonSchemaEnumValues(schema *yaml.Node) []*yaml.Node {
+	if enum := jsonSchemaKeyword(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		return enum.Content
+	}
+	if c := jsonSchemaKeyword(schema, "const"); c != nil {
+		return []*yaml.Node{c}
+	}
+	return nil
+}
+
+// jsonSchemaTypes returns types of the "type" keyword.
+func jsonSchemaTypes(schema *yaml.Node) []string {
+	n := jsonSchemaKeyword(schema, "type")
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.ScalarNode {
+		return []string{n.Value}
+	}
+	types := make([]string, 0, len(n.Content))
+	for _, item := range n.Content {
+		types = append(types, item.Value)
+	}
+	return types
+}
+
+// jsonSchemaKeyword returns the value of the keyword in the schema object.
+func jsonSchemaKeyword(schema *yaml.Node, key string) *yaml.Node {
+	if schema.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i != len(schema.Content); i += 2 {
+		if schema.Content[i].Value == key {
+			return schema.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func jsonSchemaUint(schema *yaml.Node, key string, fallback *uint64) *uint64 {
+	n := jsonSchemaKeyword(schema, key)
+	if n == nil {
+		return fallback
+	}
+	v, err := strconv.ParseUint(n.Value, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return &v
+}
+
+func jsonSchemaFloat(schema *yaml.Node, key string, fallback *float64) *float64 {
+	n := jsonSchemaKeyword(schema, key)
+	if n == nil || n.Tag == "!!bool" {
+		return fallback
+	}
+	v, err := strconv.ParseFloat(n.Value, 64)
+	if err != nil {
+		return fallback
+	}
+	return &v
+}
+
+// isJSONSchemaAnnotation returns true if the keyword does not constrain values.
+// Extension keywords, e.g. "x-internal", are annotations as well.
+func isJSONSchemaAnnotation(key string) bool {
+	if strings.HasPrefix(key, "x-") {
+		return true
+	}
+	switch key {
+	case "$schema", "$id", "$comment", "$anchor", "$defs", "definitions", "title", "description", "default",
+		"examples", "deprecated", "readOnly", "writeOnly":
+		return true
+	}
+	return false
+}
+
+// hasJSONSchemaFacets returns true if the schema has constraining keywords except the excluded ones.
+func hasJSONSchemaFacets(schema *yaml.Node, excluded ...string) bool {
+	for i := 0; i != len(schema.Content); i += 2 {
+		key := schema.Content[i].Value
+		if isJSONSchemaAnnotation(key) {
+			continue
+		}
+		isExcluded := false
+		for _, e := range excluded {
+			if key == e {
+				isExcluded = true
+				break
+			}
+		}
+		if !isExcluded {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONSchemaTypeDeclaration returns true if the root schema declares a type rather than only definitions.
+func isJSONSchemaTypeDeclaration(root *yaml.Node) bool {
+	for i := 0; i != len(root.Content); i += 2 {
+		switch key := root.Content[i].Value; key {
+		case "$schema", "$id", "$comment", "definitions", "$defs", "title", "description":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// ramlTypeName converts the name into a valid RAML type name in PascalCase.
+func ramlTypeName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "Type"
+	}
+	typeName := sb.String()
+	if unicode.IsDigit(rune(typeName[0])) {
+		typeName = "Type" + typeName
+	}
+	return typeName
+}
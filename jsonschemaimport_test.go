@@ -0,0 +1,182 @@
+package raml
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaImporter_Import(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		path     string
+		opts     []JSONSchemaImporterOpt
+		want     []string
+		wantNot  []string
+		wantErr  bool
+		typeName string
+		valid    []any
+		invalid  []any
+	}{
+		{
+			name:     "positive: 2020-12 schema with definitions",
+			path:     "./fixtures/json_schema/pets.json",
+			typeName: "Owner",
+			want: []string{
+				"#%RAML 1.0 Library\ntypes:\n  Owner:\n    type: object\n",
+				"description: Pet owner.",
+				"age:\n        type: integer\n        minimum: 1\n        maximum: 150\n        required: false",
+				"nickname:\n        type: string | nil\n        required: false",
+				"status:\n        type: string\n        enum:\n          - active\n          - inactive\n        default: active",
+				"items: Cat | Dog",
+				"/.*/: string",
+				"type: string | OwnerContact2",
+				"registered:\n        type: datetime",
+				"Cat:\n    type: Pet\n    properties:\n      lives:",
+				"Dog:\n    type: Pet\n    properties:\n      goodBoy: boolean",
+				"OwnerContact2:\n    type: object\n    properties:\n      phone: string",
+			},
+			valid: []any{
+				map[string]any{"name": "Bob", "pets": []any{}},
+				map[string]any{
+					"name": "Bob", "pets": []any{map[string]any{"kind": "dog", "name": "Rex", "goodBoy": true}},
+					"contact": map[string]any{"phone": "123"},
+				},
+			},
+			invalid: []any{
+				map[string]any{"name": "Bob"},
+				map[string]any{"name": "Bob", "pets": []any{}, "age": 0},
+				map[string]any{"name": "Bob", "pets": []any{}, "status": "unknown"},
+				map[string]any{"name": "Bob", "pets": []any{map[string]any{"kind": "dog"}}},
+				map[string]any{"name": "Bob", "pets": []any{}, "address": map[string]any{"city": "B", "x": 1}},
+			},
+		},
+		{
+			name: "positive: draft-07 recursive definitions and multiple inheritance",
+			schema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"definitions": {
+					"node": {
+						"type": "object",
+						"properties": {
+							"value": {"type": "number", "multipleOf": 0.5},
+							"next": {"$ref": "#/definitions/node", "description": "ignored in draft-07"},
+							"tags": {"type": "array", "items": [{"type": "string"}, {"type": "integer"}]}
+						}
+					},
+					"named": {"properties": {"name": {"type": "string"}}, "required": ["name"]},
+					"namedNode": {"allOf": [{"$ref": "#/definitions/node"}, {"$ref": "#/definitions/named"}]}
+				}
+			}`,
+			typeName: "NamedNode",
+			want: []string{
+				"Node:\n    type: object\n",
+				"next:\n        type: Node\n        description: ignored in draft-07\n        required: false",
+				"tags:\n        type: array\n        items: string | integer",
+				"multipleOf: 0.5",
+				"NamedNode:\n    type: [Node, Named]",
+			},
+			wantNot: []string{"Schema:"},
+			valid: []any{
+				map[string]any{"name": "a", "value": 1.5, "tags": []any{"a", 1}},
+			},
+			invalid: []any{
+				map[string]any{"value": 1},
+				map[string]any{"name": "a", "value": "1"},
+				map[string]any{"name": "a", "tags": []any{true}},
+			},
+		},
+		{
+			name:     "positive: root type name option and integer enum",
+			schema:   `{"title": "ignored", "enum": [1, 2, 3]}`,
+			opts:     []JSONSchemaImporterOpt{WithRootTypeName("Level")},
+			typeName: "Level",
+			want:     []string{"Level:\n    type: integer\n    displayName: ignored\n    enum:\n      - 1\n"},
+			valid:    []any{2},
+			invalid:  []any{4},
+		},
+		{
+			name:    "negative: root must be an object",
+			schema:  `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "negative: external reference",
+			schema:  `{"properties": {"a": {"$ref": "other.json#/definitions/a"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative: unresolvable reference",
+			schema:  `{"properties": {"a": {"$ref": "#/definitions/missing"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative: invalid pattern",
+			schema:  `{"type": "string", "pattern": "("}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.schema)
+			location := "/tmp/schema.json"
+			if tt.path != "" {
+				var err error
+				data, err = os.ReadFile(tt.path)
+				require.NoError(t, err)
+				location = tt.path
+			}
+			r := New(context.Background())
+			lib, err := NewJSONSchemaImporter(r, tt.opts...).Import(data, location)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Import() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, NewEncoder(&buf).EncodeLibrary(lib))
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("EncodeLibrary() output does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(got, wantNot) {
+					t.Errorf("EncodeLibrary() output contains %q:\n%s", wantNot, got)
+				}
+			}
+
+			// The encoded library must be valid RAML that accepts the same values.
+			parsed, err := ParseFromString(got, "schema.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err, got)
+			parsedLib, ok := parsed.EntryPoint().(*Library)
+			require.True(t, ok)
+			typ, ok := parsedLib.Types.Get(tt.typeName)
+			require.True(t, ok, "type %s not found", tt.typeName)
+			for _, v := range tt.valid {
+				require.NoError(t, typ.Validate(v), "value: %v", v)
+			}
+			for _, v := range tt.invalid {
+				require.Error(t, typ.Validate(v), "value: %v", v)
+			}
+
+			// Imported shapes can be unwrapped without encoding.
+			imported, ok := lib.Types.Get(tt.typeName)
+			require.True(t, ok)
+			unwrapped, err := r.UnwrapShape(imported)
+			require.NoError(t, err)
+			for _, v := range tt.valid {
+				require.NoError(t, unwrapped.Validate(v), "value: %v", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the configuration file looked up by FindConfig.
+const ConfigFileName = ".ramllint.yaml"
+
+// Config is the configuration of the linter.
+//
+// Example of .ramllint.yaml:
+//
+//	rules:
+//	  type-description: off
+//	  no-unused-types: error
+//	  max-nesting-depth:
+//	    severity: warning
+//	    max: 3
+type Config struct {
+	// Rules contains configurations of rules by names. Rules that are not configured use default severities.
+	Rules map[string]RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig is the configuration of a rule. It is either a severity or a mapping with
+// the severity and options of the rule.
+type RuleConfig struct {
+	Severity Severity
+	Options  map[string]any
+}
+
+func (rc *RuleConfig) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		// The literal value is used, since "off" is a boolean in YAML 1.1.
+		rc.Severity = Severity(value.Value)
+	case yaml.MappingNode:
+		if err := value.Decode(&rc.Options); err != nil {
+			return fmt.Errorf("decode options: %w", err)
+		}
+		if s, ok := rc.Options["severity"]; ok {
+			severity, isString := s.(string)
+			if !isString {
+				return fmt.Errorf("line %d: severity must be a string", value.Line)
+			}
+			rc.Severity = Severity(severity)
+			delete(rc.Options, "severity")
+		}
+	default:
+		return fmt.Errorf("line %d: rule configuration must be a severity or a mapping", value.Line)
+	}
+	if rc.Severity == "" {
+		return nil
+	}
+	if err := rc.Severity.Validate(); err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	return nil
+}
+
+// LoadConfig reads the configuration from the file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var config Config
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decode config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// FindConfig looks up the configuration file in dir and its parents.
+// Returns an empty path if the file is not found.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("absolute path: %w", err)
+	}
+	for {
+		p := filepath.Join(dir, ConfigFileName)
+		if _, err = os.Stat(p); err == nil {
+			return p, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat config: %w", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "positive: severities and options",
+			content: `rules:
+  type-description: off
+  no-unused-types: error
+  max-nesting-depth:
+    severity: info
+    max: 3
+`,
+			want: &Config{Rules: map[string]RuleConfig{
+				RuleTypeDescription: {Severity: SeverityOff},
+				RuleNoUnusedTypes:   {Severity: SeverityError},
+				RuleMaxNestingDepth: {Severity: SeverityInfo, Options: map[string]any{"max": 3}},
+			}},
+		},
+		{
+			name: "positive: options without severity",
+			content: `rules:
+  max-nesting-depth:
+    max: 2
+`,
+			want: &Config{Rules: map[string]RuleConfig{
+				RuleMaxNestingDepth: {Options: map[string]any{"max": 2}},
+			}},
+		},
+		{
+			name:    "negative: unknown severity",
+			content: "rules:\n  type-description: fatal\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative: severity is not a string",
+			content: "rules:\n  type-description:\n    severity: [error]\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative: rule configuration is a list",
+			content: "rules:\n  type-description: [error]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(p, []byte(tt.content), 0o600))
+			got, err := LoadConfig(p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "api", "v1")
+	require.NoError(t, os.MkdirAll(nested, 0o750))
+
+	got, err := FindConfig(nested)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	p := filepath.Join(root, ConfigFileName)
+	require.NoError(t, os.WriteFile(p, []byte("rules: {}\n"), 0o600))
+	got, err = FindConfig(nested)
+	require.NoError(t, err)
+	require.Equal(t, p, got)
+}
@@ -0,0 +1,520 @@
+// Package lint checks RAML documents against style rules that go beyond validity of the specification.
+//
+// Rules implement Rule and any of FragmentRule, ShapeRule and ResourceRule to be called for API and library
+// fragments, shapes and resources of the linted document. Severities and options of rules are configured with
+// Config, usually loaded from a .ramllint.yaml file.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// DisableAnnotation is the name of the annotation that disables rules for a fragment or a type.
+// The value of the annotation is a rule name or a list of rule names, "*" disables all rules.
+// The annotation may be declared in a library, e.g. "(lint.lintDisable)".
+const DisableAnnotation = "lintDisable"
+
+// Severity is the severity of an issue.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	// SeverityOff disables the rule.
+	SeverityOff Severity = "off"
+)
+
+// Validate returns an error if the severity is unknown.
+func (s Severity) Validate() error {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return nil
+	}
+	return fmt.Errorf("unknown severity %q", s)
+}
+
+// Issue is a violation of a rule.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Location string   `json:"location"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", i.Location, i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// Rule is a lint rule.
+type Rule interface {
+	// Name returns the name of the rule used in configuration, disable annotations and issues.
+	Name() string
+	// Description returns a short description of the rule.
+	Description() string
+	// DefaultSeverity returns the severity of the rule if it is not configured.
+	DefaultSeverity() Severity
+}
+
+// FragmentRule is a rule that checks API and library fragments.
+type FragmentRule interface {
+	Rule
+	CheckFragment(ctx *Context, frag raml.Fragment)
+}
+
+// ShapeRule is a rule that checks shapes declared in fragments, including inline shapes of properties, items,
+// union members and resources. Referenced types are checked where they are declared.
+type ShapeRule interface {
+	Rule
+	CheckShape(ctx *Context, shape *raml.BaseShape)
+}
+
+// ResourceRule is a rule that checks resources of APIs, including nested resources.
+type ResourceRule interface {
+	Rule
+	CheckResource(ctx *Context, res *raml.Resource)
+}
+
+// Context is passed to rules to report issues and read the linted model.
+type Context struct {
+	// RAML is the linted model. Shapes are not unwrapped, so references between types are preserved.
+	RAML *raml.RAML
+	// Fragment is the API or library fragment being checked.
+	Fragment raml.Fragment
+	// Depth is the nesting depth of the checked shape, 0 for declared types and shapes of resources.
+	Depth int
+
+	linter   *Linter
+	rule     Rule
+	severity Severity
+	options  map[string]any
+	disabled map[string]struct{}
+	issues   *[]Issue
+}
+
+// Report reports an issue of the current rule at the position in the location.
+func (c *Context) Report(location string, pos stacktrace.Position, format string, args ...any) {
+	if isDisabled(c.disabled, c.rule.Name()) {
+		return
+	}
+	*c.issues = append(*c.issues, Issue{
+		Rule:     c.rule.Name(),
+		Severity: c.severity,
+		Message:  fmt.Sprintf(format, args...),
+		Location: location,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	})
+}
+
+// ReportShape reports an issue of the current rule at the shape.
+func (c *Context) ReportShape(shape *raml.BaseShape, format string, args ...any) {
+	if isDisabled(disabledRules(shape.CustomDomainProperties), c.rule.Name()) {
+		return
+	}
+	c.Report(shape.Location, shape.Position, format, args...)
+}
+
+// IntOption returns the integer option of the current rule or def if the option is not configured.
+func (c *Context) IntOption(name string, def int) int {
+	switch v := c.options[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+// IsReferenced reports whether the shape is referenced by another shape, including references from other fragments.
+func (c *Context) IsReferenced(shape *raml.BaseShape) bool {
+	_, ok := c.linter.refs[shape]
+	return ok
+}
+
+// IsReferencedFrom reports whether any shape of the location is referenced from the fragment at the location from.
+func (c *Context) IsReferencedFrom(location string, from string) bool {
+	for _, src := range c.linter.refsByLocation[location] {
+		if src == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Linter checks documents with a set of rules.
+type Linter struct {
+	rules  []Rule
+	config *Config
+
+	// refs contains referenced shapes.
+	refs map[*raml.BaseShape]struct{}
+	// refsByLocation contains locations of referencing shapes by locations of referenced shapes.
+	refsByLocation map[string][]string
+}
+
+// New creates a linter with the rules. Built-in rules are used if no rules are given.
+// Returns an error if the configuration refers to unknown rules.
+func New(config *Config, rules ...Rule) (*Linter, error) {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	known := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		known[rule.Name()] = struct{}{}
+	}
+	for name := range config.Rules {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown rule %q in configuration", name)
+		}
+	}
+	return &Linter{rules: rules, config: config}, nil
+}
+
+// Rules returns the rules of the linter.
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Lint checks the entry point of the model and local libraries it uses.
+// The model must be parsed without unwrapping. Issues are sorted by locations and positions.
+func (l *Linter) Lint(r *raml.RAML) []Issue {
+	l.collectReferences(r)
+	issues := []Issue{}
+	for _, frag := range lintedFragments(r.EntryPoint()) {
+		disabled := disabledRules(fragmentAnnotations(frag))
+		for _, rule := range l.rules {
+			rc := l.config.Rules[rule.Name()]
+			severity := rc.Severity
+			if severity == "" {
+				severity = rule.DefaultSeverity()
+			}
+			if severity == SeverityOff {
+				continue
+			}
+			ctx := &Context{
+				RAML:     r,
+				Fragment: frag,
+				linter:   l,
+				rule:     rule,
+				severity: severity,
+				options:  rc.Options,
+				disabled: disabled,
+				issues:   &issues,
+			}
+			l.check(ctx, rule, frag)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return issues
+}
+
+func (l *Linter) check(ctx *Context, rule Rule, frag raml.Fragment) {
+	if fr, ok := rule.(FragmentRule); ok {
+		fr.CheckFragment(ctx, frag)
+	}
+	sr, isShapeRule := rule.(ShapeRule)
+	rr, isResourceRule := rule.(ResourceRule)
+	if !isShapeRule && !isResourceRule {
+		return
+	}
+	visited := make(map[*raml.BaseShape]struct{})
+	walkShapes := func(shapes []*raml.BaseShape) {
+		if !isShapeRule {
+			return
+		}
+		for _, shape := range shapes {
+			walkShape(ctx, sr, shape, 0, visited)
+		}
+	}
+	walkShapes(fragmentShapes(frag))
+	api, ok := frag.(*raml.API)
+	if !ok {
+		return
+	}
+	for pair := api.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		walkResource(pair.Value, func(res *raml.Resource) {
+			if isResourceRule {
+				rr.CheckResource(ctx, res)
+			}
+			walkShapes(ResourceShapes(res))
+		})
+	}
+}
+
+// walkShape checks the shape and its inline shapes. References are not followed.
+func walkShape(ctx *Context, rule ShapeRule, shape *raml.BaseShape, depth int, visited map[*raml.BaseShape]struct{}) {
+	if shape == nil {
+		return
+	}
+	if _, ok := visited[shape]; ok {
+		return
+	}
+	visited[shape] = struct{}{}
+	disabled := ctx.disabled
+	if own := disabledRules(shape.CustomDomainProperties); len(own) > 0 {
+		ctx.disabled = mergeDisabled(disabled, own)
+		defer func() { ctx.disabled = disabled }()
+	}
+	ctx.Depth = depth
+	rule.CheckShape(ctx, shape)
+	for _, child := range InlineShapes(shape) {
+		walkShape(ctx, rule, child, depth+1, visited)
+	}
+	ctx.Depth = depth
+}
+
+func walkResource(res *raml.Resource, fn func(res *raml.Resource)) {
+	fn(res)
+	for pair := res.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		walkResource(pair.Value, fn)
+	}
+}
+
+// InlineShapes returns shapes declared inline in the shape: properties, items and union members.
+func InlineShapes(shape *raml.BaseShape) []*raml.BaseShape {
+	var shapes []*raml.BaseShape
+	switch s := shape.Shape.(type) {
+	case *raml.ObjectShape:
+		if s.Properties != nil {
+			for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				shapes = append(shapes, pair.Value.Base)
+			}
+		}
+		if s.PatternProperties != nil {
+			for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				shapes = append(shapes, pair.Value.Base)
+			}
+		}
+	case *raml.ArrayShape:
+		if s.Items != nil {
+			shapes = append(shapes, s.Items)
+		}
+	case *raml.UnionShape:
+		shapes = append(shapes, s.AnyOf...)
+	}
+	return shapes
+}
+
+// ResourceShapes returns shapes of parameters, bodies and responses of the resource and its methods,
+// not including nested resources.
+func ResourceShapes(res *raml.Resource) []*raml.BaseShape {
+	var shapes []*raml.BaseShape
+	props := func(m *orderedmap.OrderedMap[string, raml.Property]) {
+		if m == nil {
+			return
+		}
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			shapes = append(shapes, pair.Value.Base)
+		}
+	}
+	bodies := func(m *orderedmap.OrderedMap[string, *raml.Body]) {
+		if m == nil {
+			return
+		}
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			shapes = append(shapes, pair.Value.Shape)
+		}
+	}
+	props(res.URIParameters)
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		m := pair.Value
+		if m.QueryString != nil {
+			shapes = append(shapes, m.QueryString)
+		}
+		props(m.QueryParameters)
+		props(m.Headers)
+		bodies(m.Body)
+		for rPair := m.Responses.Oldest(); rPair != nil; rPair = rPair.Next() {
+			props(rPair.Value.Headers)
+			bodies(rPair.Value.Body)
+		}
+	}
+	return shapes
+}
+
+// collectReferences collects shapes referenced by other shapes of the model.
+func (l *Linter) collectReferences(r *raml.RAML) {
+	l.refs = make(map[*raml.BaseShape]struct{})
+	l.refsByLocation = make(map[string][]string)
+	add := func(from *raml.BaseShape, to *raml.BaseShape) {
+		if to == nil || to == from {
+			return
+		}
+		l.refs[to] = struct{}{}
+		l.refsByLocation[to.Location] = append(l.refsByLocation[to.Location], from.Location)
+	}
+	for _, shape := range r.GetShapes() {
+		add(shape, shape.Alias)
+		for _, parent := range shape.Inherits {
+			add(shape, parent)
+		}
+		if shape.Link != nil {
+			add(shape, shape.Link.Shape)
+		}
+	}
+	for _, de := range r.GetAllAnnotationsPtr() {
+		if de.DefinedBy != nil {
+			l.refs[de.DefinedBy] = struct{}{}
+			l.refsByLocation[de.DefinedBy.Location] = append(l.refsByLocation[de.DefinedBy.Location], de.Location)
+		}
+	}
+}
+
+// lintedFragments returns the entry point and local libraries it uses transitively.
+// Remote libraries are not linted since they cannot be fixed in place.
+func lintedFragments(entry raml.Fragment) []raml.Fragment {
+	var frags []raml.Fragment
+	seen := make(map[string]struct{})
+	var visit func(frag raml.Fragment)
+	visit = func(frag raml.Fragment) {
+		if _, ok := seen[frag.GetLocation()]; ok {
+			return
+		}
+		seen[frag.GetLocation()] = struct{}{}
+		frags = append(frags, frag)
+		uses := fragmentUses(frag)
+		if uses == nil {
+			return
+		}
+		for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+			lib := pair.Value.Link
+			if lib == nil || strings.HasPrefix(lib.Location, "http://") || strings.HasPrefix(lib.Location, "https://") {
+				continue
+			}
+			visit(lib)
+		}
+	}
+	if entry != nil {
+		visit(entry)
+	}
+	return frags
+}
+
+func fragmentUses(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.LibraryLink] {
+	switch f := frag.(type) {
+	case *raml.API:
+		return f.Uses
+	case *raml.Library:
+		return f.Uses
+	case *raml.DataType:
+		return f.Uses
+	}
+	return nil
+}
+
+// fragmentTypes returns types declared in the fragment.
+func fragmentTypes(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.BaseShape] {
+	switch f := frag.(type) {
+	case *raml.API:
+		return f.Types
+	case *raml.Library:
+		return f.Types
+	}
+	return nil
+}
+
+// fragmentShapes returns types and annotation types declared in the fragment, or the shape of a data type.
+func fragmentShapes(frag raml.Fragment) []*raml.BaseShape {
+	var shapes []*raml.BaseShape
+	add := func(m *orderedmap.OrderedMap[string, *raml.BaseShape]) {
+		if m == nil {
+			return
+		}
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			shapes = append(shapes, pair.Value)
+		}
+	}
+	switch f := frag.(type) {
+	case *raml.API:
+		add(f.Types)
+		add(f.AnnotationTypes)
+	case *raml.Library:
+		add(f.Types)
+		add(f.AnnotationTypes)
+	case *raml.DataType:
+		shapes = append(shapes, f.Shape)
+	}
+	return shapes
+}
+
+func fragmentAnnotations(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.DomainExtension] {
+	switch f := frag.(type) {
+	case *raml.API:
+		return f.CustomDomainProperties
+	case *raml.Library:
+		return f.CustomDomainProperties
+	}
+	return nil
+}
+
+// disabledRules returns rules disabled by the disable annotation.
+func disabledRules(annotations *orderedmap.OrderedMap[string, *raml.DomainExtension]) map[string]struct{} {
+	if annotations == nil {
+		return nil
+	}
+	var disabled map[string]struct{}
+	for pair := annotations.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+		if name != DisableAnnotation || pair.Value.Extension == nil {
+			continue
+		}
+		if disabled == nil {
+			disabled = make(map[string]struct{})
+		}
+		switch v := pair.Value.Extension.Value.(type) {
+		case string:
+			disabled[v] = struct{}{}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					disabled[s] = struct{}{}
+				}
+			}
+		}
+	}
+	return disabled
+}
+
+func mergeDisabled(a, b map[string]struct{}) map[string]struct{} {
+	res := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		res[k] = struct{}{}
+	}
+	for k := range b {
+		res[k] = struct{}{}
+	}
+	return res
+}
+
+func isDisabled(disabled map[string]struct{}, rule string) bool {
+	if _, ok := disabled["*"]; ok {
+		return true
+	}
+	_, ok := disabled[rule]
+	return ok
+}
@@ -0,0 +1,221 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+const testLib = `#%RAML 1.0 Library
+annotationTypes:
+  lintDisable: string[]
+types:
+  Name:
+    description: Name of a pet.
+    type: string
+    minLength: 1
+  Status:
+    description: Status of a pet.
+    type: string
+    enum: [AVAILABLE, sold]
+  Unused:
+    description: Not referenced anywhere.
+    type: string
+`
+
+const testUnusedLib = `#%RAML 1.0 Library
+types:
+  Tag:
+    description: Tag.
+    type: string
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+	return dir
+}
+
+type issueKey struct {
+	Rule string
+	File string
+	Line int
+}
+
+func TestLinter_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		api    string
+		config *Config
+		want   []issueKey
+	}{
+		{
+			name: "positive: all rules",
+			api: `#%RAML 1.0
+title: Pets
+uses:
+  lib: lib.raml
+  unused: unused.raml
+types:
+  Pet:
+    description: A pet.
+    type: object
+    properties:
+      name: lib.Name
+      status: lib.Status
+      Owner_Name: string
+      nested:
+        properties:
+          a:
+            properties:
+              b:
+                properties:
+                  c:
+                    properties:
+                      d:
+                        properties:
+                          e: string
+  bad_name:
+    type: string
+/pets:
+  post:
+    body:
+      application/json:
+        properties:
+          name: string
+    responses:
+      200:
+        body:
+          application/json: Pet
+`,
+			want: []issueKey{
+				{RuleNoUnusedLibraries, "api.raml", 5},
+				{RulePropertyNameCamelCase, "api.raml", 13},
+				{RuleMaxNestingDepth, "api.raml", 24},
+				{RuleTypeNamePascalCase, "api.raml", 26},
+				{RuleTypeDescription, "api.raml", 26},
+				{RuleNoUnusedTypes, "api.raml", 26},
+				{RuleNoInlineBodyObject, "api.raml", 31},
+				{RuleEnumUpperCase, "lib.raml", 12},
+				{RuleNoUnusedTypes, "lib.raml", 14},
+				// Libraries are linted even if they are not referenced.
+				{RuleNoUnusedTypes, "unused.raml", 4},
+			},
+		},
+		{
+			name: "positive: configured severities and options",
+			api: `#%RAML 1.0
+title: Pets
+uses:
+  lib: lib.raml
+types:
+  Pet:
+    type: object
+    properties:
+      name: lib.Name
+      status: lib.Status
+      owner:
+        properties:
+          name: string
+/pets:
+  get:
+    responses:
+      200:
+        body:
+          application/json: Pet
+`,
+			config: &Config{Rules: map[string]RuleConfig{
+				RuleTypeDescription: {Severity: SeverityOff},
+				RuleNoUnusedTypes:   {Severity: SeverityOff},
+				RuleEnumUpperCase:   {Severity: SeverityOff},
+				RuleMaxNestingDepth: {Options: map[string]any{"max": 0}},
+			}},
+			want: []issueKey{
+				{RuleMaxNestingDepth, "api.raml", 9},
+				{RuleMaxNestingDepth, "api.raml", 10},
+				{RuleMaxNestingDepth, "api.raml", 12},
+				// Items of the annotation type are nested as well.
+				{RuleMaxNestingDepth, "lib.raml", 3},
+			},
+		},
+		{
+			name: "positive: rules disabled by annotations",
+			api: `#%RAML 1.0
+title: Pets
+uses:
+  lib: lib.raml
+(lib.lintDisable): [no-unused-types]
+types:
+  Pet:
+    (lib.lintDisable): "*"
+    type: object
+    properties:
+      Bad_Name: lib.Status
+  bad_name:
+    description: Bad name.
+    type: lib.Name
+/pets:
+  get:
+    responses:
+      200:
+        body:
+          application/json: Pet
+`,
+			want: []issueKey{
+				{RuleTypeNamePascalCase, "api.raml", 13},
+				// Annotations of the API do not disable rules in libraries.
+				{RuleEnumUpperCase, "lib.raml", 12},
+				{RuleNoUnusedTypes, "lib.raml", 14},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"api.raml":    tt.api,
+				"lib.raml":    testLib,
+				"unused.raml": testUnusedLib,
+			})
+			r, err := raml.ParseFromPath(filepath.Join(dir, "api.raml"))
+			require.NoError(t, err)
+			l, err := New(tt.config)
+			require.NoError(t, err)
+			got := make([]issueKey, 0)
+			for _, issue := range l.Lint(r) {
+				got = append(got, issueKey{issue.Rule, filepath.Base(issue.Location), issue.Line})
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLinter_Lint_library(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"lib.raml": testLib})
+	r, err := raml.ParseFromPath(filepath.Join(dir, "lib.raml"))
+	require.NoError(t, err)
+	l, err := New(nil)
+	require.NoError(t, err)
+	issues := l.Lint(r)
+	// Types of the linted library are its public interface.
+	require.Len(t, issues, 1)
+	require.Equal(t, RuleEnumUpperCase, issues[0].Rule)
+	require.Equal(t, SeverityWarning, issues[0].Severity)
+	require.Equal(t, filepath.Join(dir, "lib.raml")+`:12:23: warning: enum value "sold" must be UPPER_CASE `+
+		"[enum-upper-case]", issues[0].String())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&Config{Rules: map[string]RuleConfig{"unknown-rule": {Severity: SeverityError}}})
+	require.Error(t, err)
+	l, err := New(nil, TypeDescription{})
+	require.NoError(t, err)
+	require.Len(t, l.Rules(), 1)
+}
@@ -0,0 +1,299 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// Names of built-in rules.
+const (
+	RuleTypeNamePascalCase    = "type-name-pascal-case"
+	RulePropertyNameCamelCase = "property-name-camel-case"
+	RuleTypeDescription       = "type-description"
+	RuleNoInlineBodyObject    = "no-inline-body-object"
+	RuleEnumUpperCase         = "enum-upper-case"
+	RuleNoUnusedTypes         = "no-unused-types"
+	RuleNoUnusedLibraries     = "no-unused-libraries"
+	RuleMaxNestingDepth       = "max-nesting-depth"
+)
+
+// DefaultMaxNestingDepth is the default limit of the max-nesting-depth rule.
+const DefaultMaxNestingDepth = 5
+
+var (
+	rePascalCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	reCamelCase  = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	reUpperCase  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// DefaultRules returns built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		TypeNamePascalCase{},
+		PropertyNameCamelCase{},
+		TypeDescription{},
+		NoInlineBodyObject{},
+		EnumUpperCase{},
+		NoUnusedTypes{},
+		NoUnusedLibraries{},
+		MaxNestingDepth{},
+	}
+}
+
+// TypeNamePascalCase requires names of declared types to be PascalCase.
+type TypeNamePascalCase struct{}
+
+func (TypeNamePascalCase) Name() string { return RuleTypeNamePascalCase }
+
+func (TypeNamePascalCase) Description() string { return "type names must be PascalCase" }
+
+func (TypeNamePascalCase) DefaultSeverity() Severity { return SeverityError }
+
+func (TypeNamePascalCase) CheckFragment(ctx *Context, frag raml.Fragment) {
+	types := fragmentTypes(frag)
+	if types == nil {
+		return
+	}
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		if !rePascalCase.MatchString(pair.Key) {
+			ctx.ReportShape(pair.Value, "type name %q must be PascalCase", pair.Key)
+		}
+	}
+}
+
+// PropertyNameCamelCase requires names of object properties to be camelCase. Pattern properties are not checked.
+type PropertyNameCamelCase struct{}
+
+func (PropertyNameCamelCase) Name() string { return RulePropertyNameCamelCase }
+
+func (PropertyNameCamelCase) Description() string { return "property names must be camelCase" }
+
+func (PropertyNameCamelCase) DefaultSeverity() Severity { return SeverityError }
+
+func (PropertyNameCamelCase) CheckShape(ctx *Context, shape *raml.BaseShape) {
+	obj, ok := shape.Shape.(*raml.ObjectShape)
+	if !ok || obj.Properties == nil {
+		return
+	}
+	for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if !reCamelCase.MatchString(pair.Key) {
+			ctx.ReportShape(pair.Value.Base, "property name %q must be camelCase", pair.Key)
+		}
+	}
+}
+
+// TypeDescription requires declared types to have a description.
+type TypeDescription struct{}
+
+func (TypeDescription) Name() string { return RuleTypeDescription }
+
+func (TypeDescription) Description() string { return "declared types must have a description" }
+
+func (TypeDescription) DefaultSeverity() Severity { return SeverityWarning }
+
+func (TypeDescription) CheckFragment(ctx *Context, frag raml.Fragment) {
+	types := fragmentTypes(frag)
+	if types == nil {
+		return
+	}
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		shape := pair.Value
+		if hasDescription(shape) || shape.Link != nil && hasDescription(shape.Link.Shape) {
+			continue
+		}
+		ctx.ReportShape(shape, "type %q has no description", pair.Key)
+	}
+}
+
+func hasDescription(shape *raml.BaseShape) bool {
+	return shape != nil && shape.Description != nil && strings.TrimSpace(*shape.Description) != ""
+}
+
+// NoInlineBodyObject forbids anonymous object types in request and response bodies,
+// so that bodies refer to declared types.
+type NoInlineBodyObject struct{}
+
+func (NoInlineBodyObject) Name() string { return RuleNoInlineBodyObject }
+
+func (NoInlineBodyObject) Description() string {
+	return "bodies must not declare anonymous object types"
+}
+
+func (NoInlineBodyObject) DefaultSeverity() Severity { return SeverityWarning }
+
+func (NoInlineBodyObject) CheckResource(ctx *Context, res *raml.Resource) {
+	check := func(method string, what string, body *raml.Body) {
+		shape := body.Shape
+		if shape == nil {
+			return
+		}
+		// Items of an anonymous array are declared in the body as well.
+		if arr, ok := shape.Shape.(*raml.ArrayShape); ok && !isReference(shape) && arr.Items != nil {
+			shape = arr.Items
+		}
+		if isAnonymousObject(shape) {
+			ctx.ReportShape(shape, "%s %s %s: %s body declares an anonymous object type",
+				strings.ToUpper(method), res.FullURI(), what, body.MediaType)
+		}
+	}
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		m := pair.Value
+		for bPair := m.Body.Oldest(); bPair != nil; bPair = bPair.Next() {
+			check(m.Name, "request", bPair.Value)
+		}
+		for rPair := m.Responses.Oldest(); rPair != nil; rPair = rPair.Next() {
+			for bPair := rPair.Value.Body.Oldest(); bPair != nil; bPair = bPair.Next() {
+				check(m.Name, "response "+rPair.Key, bPair.Value)
+			}
+		}
+	}
+}
+
+// isAnonymousObject reports whether the shape is an object type that neither references nor extends a declared type.
+func isAnonymousObject(shape *raml.BaseShape) bool {
+	if shape == nil {
+		return false
+	}
+	_, ok := shape.Shape.(*raml.ObjectShape)
+	return ok && !isReference(shape)
+}
+
+// isReference reports whether the shape references or extends another type.
+func isReference(shape *raml.BaseShape) bool {
+	return shape.Alias != nil || shape.Link != nil || len(shape.Inherits) > 0
+}
+
+// EnumUpperCase requires string enum values to be UPPER_CASE.
+type EnumUpperCase struct{}
+
+func (EnumUpperCase) Name() string { return RuleEnumUpperCase }
+
+func (EnumUpperCase) Description() string { return "string enum values must be UPPER_CASE" }
+
+func (EnumUpperCase) DefaultSeverity() Severity { return SeverityWarning }
+
+func (EnumUpperCase) CheckShape(ctx *Context, shape *raml.BaseShape) {
+	s, ok := shape.Shape.(*raml.StringShape)
+	if !ok {
+		return
+	}
+	for _, node := range s.Enum {
+		v, isString := node.Value.(string)
+		if isString && !reUpperCase.MatchString(v) {
+			ctx.Report(node.Location, node.Position, "enum value %q must be UPPER_CASE", v)
+		}
+	}
+}
+
+// NoUnusedTypes forbids declared types that are not referenced. Types of a library that is the entry point are
+// its public interface and are not checked.
+type NoUnusedTypes struct{}
+
+func (NoUnusedTypes) Name() string { return RuleNoUnusedTypes }
+
+func (NoUnusedTypes) Description() string { return "declared types must be used" }
+
+func (NoUnusedTypes) DefaultSeverity() Severity { return SeverityWarning }
+
+func (NoUnusedTypes) CheckFragment(ctx *Context, frag raml.Fragment) {
+	if _, ok := frag.(*raml.Library); ok && ctx.RAML.EntryPoint() == frag {
+		return
+	}
+	types := fragmentTypes(frag)
+	if types == nil {
+		return
+	}
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		if !ctx.IsReferenced(pair.Value) {
+			ctx.ReportShape(pair.Value, "type %q is declared but not used", pair.Key)
+		}
+	}
+}
+
+// NoUnusedLibraries forbids libraries in "uses" that are not referenced by the fragment.
+type NoUnusedLibraries struct{}
+
+func (NoUnusedLibraries) Name() string { return RuleNoUnusedLibraries }
+
+func (NoUnusedLibraries) Description() string { return "used libraries must be referenced" }
+
+func (NoUnusedLibraries) DefaultSeverity() Severity { return SeverityWarning }
+
+func (NoUnusedLibraries) CheckFragment(ctx *Context, frag raml.Fragment) {
+	uses := fragmentUses(frag)
+	if uses == nil {
+		return
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		link := pair.Value
+		if link.Link == nil {
+			continue
+		}
+		if ctx.IsReferencedFrom(link.Link.Location, frag.GetLocation()) || appliesDeclarations(frag, pair.Key) {
+			continue
+		}
+		ctx.Report(link.Location, link.Position, "library %q is used but none of its declarations are referenced",
+			pair.Key)
+	}
+}
+
+// appliesDeclarations reports whether resources of the API apply traits, resource types or security schemes
+// of the library with the alias.
+func appliesDeclarations(frag raml.Fragment, alias string) bool {
+	api, ok := frag.(*raml.API)
+	if !ok {
+		return false
+	}
+	prefix := alias + "."
+	hasPrefix := func(reqs []*raml.SecurityRequirement) bool {
+		for _, req := range reqs {
+			if req != nil && strings.HasPrefix(req.Name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	if hasPrefix(api.SecuredBy) {
+		return true
+	}
+	found := false
+	for pair := api.Resources.Oldest(); pair != nil && !found; pair = pair.Next() {
+		walkResource(pair.Value, func(res *raml.Resource) {
+			if strings.HasPrefix(res.Type, prefix) || hasPrefix(res.SecuredBy) {
+				found = true
+			}
+			for mPair := res.Methods.Oldest(); mPair != nil; mPair = mPair.Next() {
+				for _, trait := range mPair.Value.Is {
+					if strings.HasPrefix(trait, prefix) {
+						found = true
+					}
+				}
+				if hasPrefix(mPair.Value.SecuredBy) {
+					found = true
+				}
+			}
+		})
+	}
+	return found
+}
+
+// MaxNestingDepth limits nesting of inline shapes. The limit is set by the "max" option.
+type MaxNestingDepth struct{}
+
+func (MaxNestingDepth) Name() string { return RuleMaxNestingDepth }
+
+func (MaxNestingDepth) Description() string {
+	return "inline shapes must not be nested deeper than the limit, 5 by default"
+}
+
+func (MaxNestingDepth) DefaultSeverity() Severity { return SeverityWarning }
+
+func (MaxNestingDepth) CheckShape(ctx *Context, shape *raml.BaseShape) {
+	limit := ctx.IntOption("max", DefaultMaxNestingDepth)
+	// Only the first shape beyond the limit is reported, not each of its nested shapes.
+	if ctx.Depth == limit+1 {
+		ctx.ReportShape(shape, "shape is nested %d levels deep, the limit is %d", ctx.Depth, limit)
+	}
+}
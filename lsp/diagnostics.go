@@ -0,0 +1,118 @@
+package lsp
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// diagnosticSource is the source of diagnostics published by the server.
+const diagnosticSource = "raml"
+
+// reYAMLLine matches the line number in errors of the YAML decoder that carry no position.
+var reYAMLLine = regexp.MustCompile(`line (\d+)`)
+
+// diagnosticsCollector converts errors of the parser to diagnostics grouped by locations of fragments.
+type diagnosticsCollector struct {
+	// texts contains texts of opened documents by locations to convert positions to UTF-16 ranges.
+	texts map[string]string
+	diags map[string][]Diagnostic
+}
+
+func newDiagnosticsCollector(docs map[string]*document) *diagnosticsCollector {
+	c := &diagnosticsCollector{texts: make(map[string]string, len(docs)), diags: make(map[string][]Diagnostic)}
+	for _, doc := range docs {
+		c.texts[doc.path] = doc.text
+	}
+	return c
+}
+
+// collect adds diagnostics for the error. Errors that carry no location are reported at the start of the fallback
+// location, which is the location of the parsed document.
+func (c *diagnosticsCollector) collect(err error, fallback string) {
+	st, ok := stacktrace.Unwrap(err)
+	if !ok {
+		c.add(fallback, nil, []string{err.Error()})
+		return
+	}
+	c.walk(st, fallback, nil, nil)
+}
+
+// walk walks the stack trace and adds a diagnostic for each leaf.
+// Messages are accumulated while the trace stays in one location, so every diagnostic reads as a chain of context
+// of the fragment it is reported in.
+func (c *diagnosticsCollector) walk(st *stacktrace.StackTrace, location string, pos *stacktrace.Position,
+	msgs []string,
+) {
+	if st.Location != nil && string(*st.Location) != "" && string(*st.Location) != location {
+		location = string(*st.Location)
+		pos = nil
+		msgs = nil
+	}
+	if st.Position != nil {
+		pos = st.Position
+	}
+	if msg := st.MessageWithInfo(); msg != "" {
+		// Copy the messages since branches of the list share the prefix.
+		msgs = append(msgs[:len(msgs):len(msgs)], msg)
+	}
+	if st.Wrapped == nil && len(st.List) == 0 {
+		c.add(location, pos, msgs)
+		return
+	}
+	if st.Wrapped != nil {
+		c.walk(st.Wrapped, location, pos, msgs)
+	}
+	for _, item := range st.List {
+		c.walk(item, location, pos, msgs)
+	}
+}
+
+func (c *diagnosticsCollector) add(location string, pos *stacktrace.Position, msgs []string) {
+	msg := strings.Join(msgs, ": ")
+	if pos == nil {
+		// Errors of the YAML decoder mention the line in the message.
+		if m := reYAMLLine.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			pos = &stacktrace.Position{Line: line, Column: 1}
+		}
+	}
+	diag := Diagnostic{
+		Range:    c.rangeAt(location, pos),
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  msg,
+	}
+	for _, d := range c.diags[location] {
+		if d == diag {
+			return
+		}
+	}
+	c.diags[location] = append(c.diags[location], diag)
+}
+
+// rangeAt returns the range from the one-based position to the end of its line.
+func (c *diagnosticsCollector) rangeAt(location string, pos *stacktrace.Position) Range {
+	if pos == nil || pos.Line < 1 {
+		return Range{}
+	}
+	line := pos.Line - 1
+	column := pos.Column - 1
+	if column < 0 {
+		column = 0
+	}
+	text, ok := c.texts[location]
+	if !ok {
+		p := Position{Line: line, Character: column}
+		return Range{Start: p, End: p}
+	}
+	lineText := lineAt(text, line)
+	start := runeToUTF16Offset(lineText, column)
+	end := byteToUTF16Offset(lineText, len(strings.TrimRight(lineText, " \t")))
+	if end < start {
+		end = start
+	}
+	return Range{Start: Position{Line: line, Character: start}, End: Position{Line: line, Character: end}}
+}
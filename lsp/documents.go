@@ -0,0 +1,209 @@
+package lsp
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// document is a text document opened in the client.
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    string
+}
+
+// applyChange applies the content change to the text of the document.
+func (d *document) applyChange(change TextDocumentContentChangeEvent) error {
+	if change.Range == nil {
+		d.text = change.Text
+		return nil
+	}
+	start := offsetAt(d.text, change.Range.Start)
+	end := offsetAt(d.text, change.Range.End)
+	if start > end {
+		return fmt.Errorf("invalid range %v", *change.Range)
+	}
+	d.text = d.text[:start] + change.Text + d.text[end:]
+	return nil
+}
+
+// lineAt returns the line of the text with the zero-based index, without the line terminator.
+func lineAt(text string, line int) string {
+	for i := 0; i < line; i++ {
+		nl := strings.IndexByte(text, '\n')
+		if nl < 0 {
+			return ""
+		}
+		text = text[nl+1:]
+	}
+	if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+		text = text[:nl]
+	}
+	return strings.TrimSuffix(text, "\r")
+}
+
+// offsetAt returns the byte offset of the position in the text.
+// Positions beyond the end of a line or the text are clamped.
+func offsetAt(text string, pos Position) int {
+	offset := 0
+	for i := 0; i < pos.Line; i++ {
+		nl := strings.IndexByte(text[offset:], '\n')
+		if nl < 0 {
+			return len(text)
+		}
+		offset += nl + 1
+	}
+	line := text[offset:]
+	if nl := strings.IndexByte(line, '\n'); nl >= 0 {
+		line = line[:nl]
+	}
+	return offset + utf16ToByteOffset(line, pos.Character)
+}
+
+// utf16ToByteOffset converts the offset in UTF-16 code units to the byte offset in the line.
+func utf16ToByteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// byteToUTF16Offset converts the byte offset in the line to the offset in UTF-16 code units.
+func byteToUTF16Offset(line string, offset int) int {
+	units := 0
+	for i, r := range line {
+		if i >= offset {
+			break
+		}
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// runeToUTF16Offset converts the offset in runes to the offset in UTF-16 code units.
+func runeToUTF16Offset(line string, runes int) int {
+	units := 0
+	for _, r := range line {
+		if runes <= 0 {
+			break
+		}
+		units += utf16.RuneLen(r)
+		runes--
+	}
+	return units + runes
+}
+
+// uriToPath converts the file URI to the OS path.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse uri: %w", err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri scheme %q", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI converts the location of a fragment to the URI. Remote locations are already URIs.
+func pathToURI(location string) string {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return location
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(location)}
+	return u.String()
+}
+
+// overlayFS is the OS file system with contents of opened documents taking precedence over files on disk,
+// so that unsaved changes are visible to the parser. Names are paths relative to the root of the file system.
+type overlayFS struct {
+	docs map[string]string
+	os   fs.FS
+}
+
+func newOverlayFS(docs map[string]*document) *overlayFS {
+	o := &overlayFS{docs: make(map[string]string, len(docs)), os: os.DirFS("/")}
+	for _, doc := range docs {
+		o.docs[strings.TrimPrefix(filepath.ToSlash(doc.path), "/")] = doc.text
+	}
+	return o
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if text, ok := o.docs[name]; ok {
+		return &docFile{Reader: strings.NewReader(text), name: name, size: int64(len(text))}, nil
+	}
+	return o.os.Open(name)
+}
+
+func (o *overlayFS) ReadFile(name string) ([]byte, error) {
+	if text, ok := o.docs[name]; ok {
+		return []byte(text), nil
+	}
+	return fs.ReadFile(o.os, name)
+}
+
+// docFile is an opened document read as a file.
+type docFile struct {
+	*strings.Reader
+	name string
+	size int64
+}
+
+func (f *docFile) Stat() (fs.FileInfo, error) { return f, nil }
+
+func (f *docFile) Close() error { return nil }
+
+func (f *docFile) Name() string { return filepath.Base(f.name) }
+
+func (f *docFile) Size() int64 { return f.size }
+
+func (f *docFile) Mode() fs.FileMode { return 0o444 }
+
+func (f *docFile) ModTime() time.Time { return time.Time{} }
+
+func (f *docFile) IsDir() bool { return false }
+
+func (f *docFile) Sys() any { return nil }
+
+// isWordChar reports whether the rune may be a part of a type reference, a library alias or a path.
+func isWordChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		strings.ContainsRune("_.-/()", r)
+}
+
+// wordAt returns the word around the byte offset in the line and its byte bounds.
+func wordAt(line string, offset int) (string, int, int) {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	start := offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if !isWordChar(r) {
+			break
+		}
+		start -= size
+	}
+	end := offset
+	for end < len(line) {
+		r, size := utf8.DecodeRuneInString(line[end:])
+		if !isWordChar(r) {
+			break
+		}
+		end += size
+	}
+	return line[start:end], start, end
+}
@@ -0,0 +1,139 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_document_applyChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		change  TextDocumentContentChangeEvent
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "full document",
+			text:   "a: 1\n",
+			change: TextDocumentContentChangeEvent{Text: "b: 2\n"},
+			want:   "b: 2\n",
+		},
+		{
+			name: "insert",
+			text: "a: 1\nb: 2\n",
+			change: TextDocumentContentChangeEvent{
+				Range: &Range{Start: Position{1, 3}, End: Position{1, 3}},
+				Text:  "1",
+			},
+			want: "a: 1\nb: 12\n",
+		},
+		{
+			name: "replace across lines",
+			text: "a: 1\nb: 2\nc: 3\n",
+			change: TextDocumentContentChangeEvent{
+				Range: &Range{Start: Position{0, 3}, End: Position{2, 1}},
+				Text:  "0\nd",
+			},
+			want: "a: 0\nd: 3\n",
+		},
+		{
+			name: "surrogate pairs are two code units",
+			text: "a: \U0001F600x\n",
+			change: TextDocumentContentChangeEvent{
+				Range: &Range{Start: Position{0, 5}, End: Position{0, 6}},
+				Text:  "y",
+			},
+			want: "a: \U0001F600y\n",
+		},
+		{
+			name: "range beyond the end is clamped",
+			text: "a: 1",
+			change: TextDocumentContentChangeEvent{
+				Range: &Range{Start: Position{0, 4}, End: Position{5, 0}},
+				Text:  "0",
+			},
+			want: "a: 10",
+		},
+		{
+			name: "negative: reversed range",
+			text: "a: 1",
+			change: TextDocumentContentChangeEvent{
+				Range: &Range{Start: Position{0, 3}, End: Position{0, 1}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &document{text: tt.text}
+			err := d.applyChange(tt.change)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("applyChange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				require.Equal(t, tt.want, d.text)
+			}
+		})
+	}
+}
+
+func Test_wordAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		offset int
+		want   string
+	}{
+		{
+			name:   "reference",
+			line:   "  type: lib.Pet[]",
+			offset: 12,
+			want:   "lib.Pet",
+		},
+		{
+			name:   "end of word",
+			line:   "  type: lib.Pet",
+			offset: 15,
+			want:   "lib.Pet",
+		},
+		{
+			name:   "annotation",
+			line:   "  (lib.deprecated): true",
+			offset: 4,
+			want:   "(lib.deprecated)",
+		},
+		{
+			name:   "union member",
+			line:   "type: Cat | Dog",
+			offset: 13,
+			want:   "Dog",
+		},
+		{
+			name:   "whitespace",
+			line:   "type:  Cat",
+			offset: 6,
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, _ := wordAt(tt.line, tt.offset); got != tt.want {
+				t.Errorf("wordAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uriToPath(t *testing.T) {
+	path, err := uriToPath("file:///home/user/api%20v1/api.raml")
+	require.NoError(t, err)
+	require.Equal(t, "/home/user/api v1/api.raml", path)
+	require.Equal(t, "file:///home/user/api%20v1/api.raml", pathToURI(path))
+	require.Equal(t, "https://example.com/lib.raml", pathToURI("https://example.com/lib.raml"))
+
+	_, err = uriToPath("untitled:Untitled-1")
+	require.Error(t, err)
+}
@@ -0,0 +1,255 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// reInclude matches the path of an include in a line.
+var reInclude = regexp.MustCompile(`!include\s+(\S+)`)
+
+// builtinTypes are types that may be referenced in any fragment.
+var builtinTypes = []string{
+	raml.TypeAny, raml.TypeString, raml.TypeNumber, raml.TypeInteger, raml.TypeBoolean, raml.TypeDateOnly,
+	raml.TypeTimeOnly, raml.TypeDatetimeOnly, raml.TypeDatetime, raml.TypeFile, raml.TypeArray, raml.TypeObject,
+	raml.TypeNil,
+}
+
+// facetNames are facets of type declarations.
+var facetNames = []string{
+	raml.FacetType, raml.FacetDescription, raml.FacetDisplayName, raml.FacetExample, raml.FacetExamples,
+	raml.FacetDefault, raml.FacetRequired, raml.FacetFacets, raml.FacetEnum, raml.FacetProperties,
+	raml.FacetAdditionalProperties, raml.FacetMinProperties, raml.FacetMaxProperties, raml.FacetDiscriminator,
+	raml.FacetDiscriminatorValue, raml.FacetItems, raml.FacetMinItems, raml.FacetMaxItems, raml.FacetUniqueItems,
+	raml.FacetPattern, raml.FacetMinLength, raml.FacetMaxLength, raml.FacetMinimum, raml.FacetMaximum,
+	raml.FacetFormat, raml.FacetMultipleOf, raml.FacetFileTypes, raml.FacetAllowedTargets,
+}
+
+// cursor is a position in an opened document resolved to the word under it.
+type cursor struct {
+	doc  *document
+	frag raml.Fragment
+	line string
+	// offset is the byte offset of the position in the line.
+	offset int
+	word   string
+	start  int
+	end    int
+}
+
+func (s *Server) cursorAt(params TextDocumentPositionParams) (*cursor, error) {
+	doc := s.document(params.TextDocument.URI)
+	if doc == nil {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "document is not opened"}
+	}
+	line := lineAt(doc.text, params.Position.Line)
+	c := &cursor{doc: doc, line: line, offset: utf16ToByteOffset(line, params.Position.Character)}
+	c.word, c.start, c.end = wordAt(line, c.offset)
+	if model, ok := s.models[doc.path]; ok {
+		c.frag = model.EntryPoint()
+	}
+	return c, nil
+}
+
+// wordRange returns the range of the word under the cursor.
+func (c *cursor) wordRange(line int) Range {
+	return Range{
+		Start: Position{Line: line, Character: byteToUTF16Offset(c.line, c.start)},
+		End:   Position{Line: line, Character: byteToUTF16Offset(c.line, c.end)},
+	}
+}
+
+// definition returns the declaration of the type, the annotation type, the library or the included file
+// under the cursor.
+func (s *Server) definition(params TextDocumentPositionParams) ([]Location, error) {
+	c, err := s.cursorAt(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range reInclude.FindAllStringSubmatchIndex(c.line, -1) {
+		if c.offset >= m[2] && c.offset <= m[3] {
+			return []Location{fileLocation(c.doc.path, c.line[m[2]:m[3]])}, nil
+		}
+	}
+	if c.word == "" {
+		return []Location{}, nil
+	}
+	if isPathLike(c.word) {
+		return []Location{fileLocation(c.doc.path, c.word)}, nil
+	}
+	if c.frag == nil {
+		return []Location{}, nil
+	}
+	uses := fragmentUses(c.frag)
+	// The alias part of a reference leads to the library.
+	if alias, _, found := strings.Cut(c.word, "."); found && c.offset <= c.start+len(alias) {
+		if link, ok := uses.Get(alias); ok {
+			return []Location{libraryLocation(c.doc.path, link)}, nil
+		}
+	}
+	if shape := lookupShape(c.frag, c.word); shape != nil {
+		return []Location{shapeLocation(shape)}, nil
+	}
+	if link, ok := uses.Get(c.word); ok {
+		return []Location{libraryLocation(c.doc.path, link)}, nil
+	}
+	return []Location{}, nil
+}
+
+// hover returns the unwrapped shape of the type or the annotation type under the cursor.
+func (s *Server) hover(params TextDocumentPositionParams) (*Hover, error) {
+	c, err := s.cursorAt(params)
+	if err != nil {
+		return nil, err
+	}
+	if c.frag == nil || c.word == "" {
+		return nil, nil
+	}
+	shape := lookupShape(c.frag, c.word)
+	if shape == nil {
+		return nil, nil
+	}
+	model := s.models[c.doc.path]
+	dt := model.MakeDataType(shape.Location)
+	dt.Shape = shape
+	var buf bytes.Buffer
+	if err = raml.NewEncoder(&buf, raml.WithInlineTypes()).EncodeDataType(dt); err != nil {
+		return nil, fmt.Errorf("encode shape: %w", err)
+	}
+	_, body, _ := strings.Cut(buf.String(), "\n")
+	r := c.wordRange(params.Position.Line)
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: fmt.Sprintf("**%s**\n\n```yaml\n%s```", strings.Trim(c.word, "()"), body),
+		},
+		Range: &r,
+	}, nil
+}
+
+// completion proposes type names in values and facet names in keys.
+func (s *Server) completion(params TextDocumentPositionParams) (*CompletionList, error) {
+	c, err := s.cursorAt(params)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSpace(c.line[:c.offset])
+	items := []CompletionItem{}
+	if strings.Contains(prefix, ":") || strings.HasPrefix(prefix, "-") {
+		for _, name := range builtinTypes {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindKeyword, Detail: "built-in type"})
+		}
+		if c.frag != nil {
+			items = append(items, declaredTypes(c.frag)...)
+		}
+	} else {
+		for _, name := range facetNames {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindProperty, Detail: "facet"})
+		}
+	}
+	return &CompletionList{Items: items}, nil
+}
+
+// declaredTypes returns completion items for types declared in the fragment and in libraries it uses.
+func declaredTypes(frag raml.Fragment) []CompletionItem {
+	var items []CompletionItem
+	if types := fragmentTypes(frag); types != nil {
+		for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+			items = append(items, CompletionItem{Label: pair.Key, Kind: CompletionKindClass, Detail: "type"})
+		}
+	}
+	for pair := fragmentUses(frag).Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link == nil {
+			continue
+		}
+		for t := pair.Value.Link.Types.Oldest(); t != nil; t = t.Next() {
+			items = append(items, CompletionItem{
+				Label:  pair.Key + "." + t.Key,
+				Kind:   CompletionKindClass,
+				Detail: "type from " + pair.Value.Value,
+			})
+		}
+	}
+	return items
+}
+
+// lookupShape returns the type referenced by the word, or the annotation type if the word is parenthesized.
+func lookupShape(frag raml.Fragment, word string) *raml.BaseShape {
+	word = strings.TrimSuffix(word, ".")
+	if strings.HasPrefix(word, "(") && strings.HasSuffix(word, ")") {
+		shape, err := frag.GetReferenceAnnotationType(strings.Trim(word, "()"))
+		if err != nil {
+			return nil
+		}
+		return shape
+	}
+	shape, err := frag.GetReferenceType(strings.Trim(word, "()"))
+	if err != nil {
+		return nil
+	}
+	return shape
+}
+
+func fragmentUses(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.LibraryLink] {
+	var uses *orderedmap.OrderedMap[string, *raml.LibraryLink]
+	switch f := frag.(type) {
+	case *raml.API:
+		uses = f.Uses
+	case *raml.Library:
+		uses = f.Uses
+	case *raml.DataType:
+		uses = f.Uses
+	}
+	if uses == nil {
+		return orderedmap.New[string, *raml.LibraryLink](0)
+	}
+	return uses
+}
+
+func fragmentTypes(frag raml.Fragment) *orderedmap.OrderedMap[string, *raml.BaseShape] {
+	switch f := frag.(type) {
+	case *raml.API:
+		return f.Types
+	case *raml.Library:
+		return f.Types
+	}
+	return nil
+}
+
+// isPathLike reports whether the word is a path to a fragment or an included file.
+func isPathLike(word string) bool {
+	switch filepath.Ext(word) {
+	case ".raml", ".yaml", ".yml", ".json", ".xsd":
+		return true
+	}
+	return false
+}
+
+func fileLocation(docPath string, ref string) Location {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return Location{URI: ref}
+	}
+	return Location{URI: pathToURI(filepath.Join(filepath.Dir(docPath), ref))}
+}
+
+func libraryLocation(docPath string, link *raml.LibraryLink) Location {
+	if link.Link != nil {
+		return Location{URI: pathToURI(link.Link.Location)}
+	}
+	return fileLocation(docPath, link.Value)
+}
+
+func shapeLocation(shape *raml.BaseShape) Location {
+	pos := Position{Line: shape.Line - 1, Character: shape.Column - 1}
+	if pos.Line < 0 || pos.Character < 0 {
+		pos = Position{}
+	}
+	return Location{URI: pathToURI(shape.Location), Range: Range{Start: pos, End: pos}}
+}
@@ -0,0 +1,354 @@
+// Package lsp implements a Language Server Protocol server for RAML 1.0.
+//
+// The server speaks JSON-RPC 2.0 over any reader and writer, e.g. stdio, and provides diagnostics, go-to-definition
+// of type references and library aliases, hover with unwrapped shapes and completion of facet names and type names.
+// Documents are re-parsed on every change with unsaved contents of opened documents taking precedence over files
+// on disk.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-raml/v2"
+)
+
+// CodeServerNotInitialized is returned for requests received before the initialize request.
+const CodeServerNotInitialized = -32002
+
+// ErrExitWithoutShutdown is returned by Serve if the client sends exit without the shutdown request.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+// Server is a RAML language server. Messages are handled sequentially in the order they are received.
+type Server struct {
+	conn *Conn
+
+	initialized bool
+	shutdown    bool
+
+	// docs contains opened documents by paths.
+	docs map[string]*document
+	// models contains last successfully parsed models of opened documents by paths.
+	models map[string]*raml.RAML
+	// diags contains diagnostics of the last parse of opened documents by paths.
+	// Diagnostics are grouped by locations since errors of a document may be reported in fragments it uses.
+	diags map[string]map[string][]Diagnostic
+	// published contains locations with published diagnostics to clear them once errors are fixed.
+	published map[string]struct{}
+}
+
+// NewServer creates a server that reads messages from r and writes messages to w.
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn:      NewConn(r, w),
+		docs:      make(map[string]*document),
+		models:    make(map[string]*raml.RAML),
+		diags:     make(map[string]map[string][]Diagnostic),
+		published: make(map[string]struct{}),
+	}
+}
+
+// Serve handles messages until the exit notification, the end of input or cancellation of the context.
+func (s *Server) Serve(ctx context.Context) error {
+	type readResult struct {
+		msg *Message
+		err error
+	}
+	msgs := make(chan readResult)
+	go func() {
+		for {
+			msg, err := s.conn.Read()
+			select {
+			case msgs <- readResult{msg, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				var respErr *ResponseError
+				if !errors.As(err, &respErr) {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		var res readResult
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case res = <-msgs:
+		}
+		if res.err != nil {
+			var respErr *ResponseError
+			if errors.As(res.err, &respErr) {
+				// The stream is still in sync, the malformed message is skipped.
+				if err := s.conn.Reply(nil, nil, respErr); err != nil {
+					return err
+				}
+				continue
+			}
+			if errors.Is(res.err, io.EOF) {
+				return nil
+			}
+			return res.err
+		}
+		if res.msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(ctx, res.msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches the message. Only errors of the connection are returned, errors of handlers are replied.
+func (s *Server) handle(ctx context.Context, msg *Message) error {
+	if !msg.IsRequest() {
+		if msg.Method == "" || !s.initialized || s.shutdown {
+			// Responses are not expected and notifications are dropped before initialization.
+			return nil
+		}
+		return s.handleNotification(ctx, msg)
+	}
+	switch {
+	case msg.Method == "initialize":
+		if s.initialized {
+			return s.conn.Reply(msg.ID, nil, &ResponseError{Code: CodeInvalidRequest, Message: "already initialized"})
+		}
+		s.initialized = true
+		return s.conn.Reply(msg.ID, s.capabilities(), nil)
+	case !s.initialized:
+		return s.conn.Reply(msg.ID, nil, &ResponseError{Code: CodeServerNotInitialized, Message: "not initialized"})
+	case s.shutdown:
+		return s.conn.Reply(msg.ID, nil, &ResponseError{Code: CodeInvalidRequest, Message: "shutdown requested"})
+	}
+
+	var result any
+	var err error
+	switch msg.Method {
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err = unmarshalParams(msg.Params, &params); err == nil {
+			result, err = s.definition(params)
+		}
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err = unmarshalParams(msg.Params, &params); err == nil {
+			result, err = s.hover(params)
+		}
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err = unmarshalParams(msg.Params, &params); err == nil {
+			result, err = s.completion(params)
+		}
+	default:
+		err = &ResponseError{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+	if err != nil {
+		var respErr *ResponseError
+		if !errors.As(err, &respErr) {
+			respErr = &ResponseError{Code: CodeInternalError, Message: err.Error()}
+		}
+		return s.conn.Reply(msg.ID, nil, respErr)
+	}
+	return s.conn.Reply(msg.ID, result, nil)
+}
+
+func (s *Server) handleNotification(ctx context.Context, msg *Message) error {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil
+		}
+		s.docs[path] = &document{
+			uri:     params.TextDocument.URI,
+			path:    path,
+			version: params.TextDocument.Version,
+			text:    params.TextDocument.Text,
+		}
+		return s.reparse(ctx, path)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil
+		}
+		doc := s.document(params.TextDocument.URI)
+		if doc == nil {
+			return nil
+		}
+		for _, change := range params.ContentChanges {
+			if err := doc.applyChange(change); err != nil {
+				return nil
+			}
+		}
+		doc.version = params.TextDocument.Version
+		return s.reparse(ctx, doc.path)
+	case "textDocument/didSave":
+		var params struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil
+		}
+		if doc := s.document(params.TextDocument.URI); doc != nil {
+			return s.reparse(ctx, doc.path)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil
+		}
+		doc := s.document(params.TextDocument.URI)
+		if doc == nil {
+			return nil
+		}
+		delete(s.docs, doc.path)
+		delete(s.models, doc.path)
+		delete(s.diags, doc.path)
+		// Documents that use the closed one must see its content on disk.
+		return s.reparse(ctx, doc.path)
+	}
+	return nil
+}
+
+func (s *Server) capabilities() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:   TextDocumentSyncOptions{OpenClose: true, Change: SyncIncremental},
+			DefinitionProvider: true,
+			HoverProvider:      true,
+			CompletionProvider: CompletionOptions{TriggerCharacters: []string{".", " "}},
+		},
+		ServerInfo: ServerInfo{Name: "raml-lsp"},
+	}
+}
+
+func (s *Server) document(uri string) *document {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil
+	}
+	return s.docs[path]
+}
+
+// reparse parses the changed document and opened documents that depend on it, then publishes diagnostics.
+func (s *Server) reparse(ctx context.Context, changed string) error {
+	fsys := newOverlayFS(s.docs)
+	paths := make([]string, 0, len(s.docs))
+	for path := range s.docs {
+		if path == changed || s.dependsOn(path, changed) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		s.parse(ctx, s.docs[path], fsys)
+	}
+	return s.publishDiagnostics()
+}
+
+// dependsOn reports whether the last model of the document uses the fragment at the location.
+// Documents without a model are always re-parsed since the fragment may fix them.
+func (s *Server) dependsOn(path string, location string) bool {
+	model, ok := s.models[path]
+	if !ok {
+		return true
+	}
+	return model.GetFragment(location) != nil
+}
+
+// parse parses the document and stores its model and diagnostics.
+// The last good model is kept if the document cannot be parsed, so navigation works while the document is edited.
+func (s *Server) parse(ctx context.Context, doc *document, fsys *overlayFS) {
+	if !strings.HasPrefix(doc.text, "#%RAML") {
+		// Included files like JSON examples are not parsed on their own.
+		delete(s.diags, doc.path)
+		return
+	}
+	collector := newDiagnosticsCollector(s.docs)
+	r, err := raml.ParseFromStringCtx(ctx, doc.text, filepath.Base(doc.path), filepath.Dir(doc.path),
+		raml.OptWithFS(fsys), raml.OptWithUnwrap())
+	if err == nil {
+		s.models[doc.path] = r
+		// Validation errors do not invalidate the model.
+		err = r.ValidateShapes()
+	}
+	if err != nil {
+		collector.collect(err, doc.path)
+	}
+	s.diags[doc.path] = collector.diags
+}
+
+// publishDiagnostics publishes diagnostics of all opened documents merged by locations.
+func (s *Server) publishDiagnostics() error {
+	merged := make(map[string][]Diagnostic)
+	for _, byLocation := range s.diags {
+		for location, diags := range byLocation {
+			for _, diag := range diags {
+				if !containsDiagnostic(merged[location], diag) {
+					merged[location] = append(merged[location], diag)
+				}
+			}
+		}
+	}
+	locations := make([]string, 0, len(merged)+len(s.published))
+	for location := range merged {
+		locations = append(locations, location)
+	}
+	for location := range s.published {
+		if _, ok := merged[location]; !ok {
+			locations = append(locations, location)
+		}
+	}
+	sort.Strings(locations)
+	for _, location := range locations {
+		params := PublishDiagnosticsParams{URI: pathToURI(location), Diagnostics: merged[location]}
+		if params.Diagnostics == nil {
+			params.Diagnostics = []Diagnostic{}
+			delete(s.published, location)
+		} else {
+			s.published[location] = struct{}{}
+		}
+		if doc, ok := s.docs[location]; ok {
+			version := doc.version
+			params.Version = &version
+		}
+		if err := s.conn.Notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsDiagnostic(diags []Diagnostic, diag Diagnostic) bool {
+	for _, d := range diags {
+		if d == diag {
+			return true
+		}
+	}
+	return false
+}
+
+func unmarshalParams(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
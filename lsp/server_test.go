@@ -0,0 +1,304 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testClient is an in-process JSON-RPC client of the server.
+type testClient struct {
+	t      *testing.T
+	conn   *Conn
+	nextID int
+	// diags contains the last published diagnostics by URIs.
+	diags map[string][]Diagnostic
+}
+
+func newTestClient(t *testing.T) (*testClient, <-chan error) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	srv := NewServer(serverR, serverW)
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(context.Background())
+		_ = serverW.Close()
+	}()
+	t.Cleanup(func() { _ = clientW.Close() })
+	return &testClient{t: t, conn: NewConn(clientR, clientW), diags: make(map[string][]Diagnostic)}, done
+}
+
+// call sends the request and decodes the result of the response into result.
+// Notifications received before the response are recorded.
+func (c *testClient) call(method string, params any, result any) *ResponseError {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	raw, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.conn.Write(&Message{ID: &id, Method: method, Params: raw}))
+	for {
+		msg, err := c.conn.Read()
+		require.NoError(c.t, err)
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var p PublishDiagnosticsParams
+			require.NoError(c.t, json.Unmarshal(msg.Params, &p))
+			c.diags[p.URI] = p.Diagnostics
+			continue
+		}
+		require.NotNil(c.t, msg.ID)
+		require.Equal(c.t, string(id), string(*msg.ID))
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			require.NoError(c.t, json.Unmarshal(msg.Result, result))
+		}
+		return nil
+	}
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	require.NoError(c.t, c.conn.Notify(method, params))
+}
+
+// sync waits until preceding notifications are handled. Messages are handled in order, so the response to any
+// request follows diagnostics published for preceding notifications.
+func (c *testClient) sync() {
+	c.t.Helper()
+	respErr := c.call("raml/sync", nil, nil)
+	require.NotNil(c.t, respErr)
+	require.Equal(c.t, CodeMethodNotFound, respErr.Code)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+}
+
+const testAPI = `#%RAML 1.0
+title: Pets
+uses:
+  lib: libs/lib.raml
+types:
+  Owner:
+    type: object
+    properties:
+      pet: lib.Pet
+/pets:
+  get:
+    responses:
+      200:
+        body:
+          application/json:
+            type: lib.Pet
+            example: !include examples/pet.json
+`
+
+const testLib = `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    minLength: 1
+  Pet:
+    type: object
+    properties:
+      name: Name
+      age?: integer
+`
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"libs/lib.raml":     testLib,
+		"examples/pet.json": `{"name": "Rex"}`,
+	})
+	apiURI := pathToURI(filepath.Join(dir, "api.raml"))
+	libURI := pathToURI(filepath.Join(dir, "libs", "lib.raml"))
+	at := func(uri string, line, character int) TextDocumentPositionParams {
+		return TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: line, Character: character},
+		}
+	}
+
+	c, done := newTestClient(t)
+	require.Equal(t, CodeServerNotInitialized, c.call("textDocument/hover", at(apiURI, 0, 0), nil).Code)
+	var initRes InitializeResult
+	require.Nil(t, c.call("initialize", map[string]any{"capabilities": map[string]any{}}, &initRes))
+	require.Equal(t, SyncIncremental, initRes.Capabilities.TextDocumentSync.Change)
+	require.True(t, initRes.Capabilities.DefinitionProvider)
+	require.True(t, initRes.Capabilities.HoverProvider)
+	c.notify("initialized", struct{}{})
+
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: apiURI, LanguageID: "raml", Version: 1, Text: testAPI},
+	})
+	c.sync()
+	require.Empty(t, c.diags[apiURI], "valid document must have no diagnostics")
+
+	t.Run("definition", func(t *testing.T) {
+		tests := []struct {
+			name string
+			pos  TextDocumentPositionParams
+			want []Location
+		}{
+			{
+				name: "type from library",
+				pos:  at(apiURI, 8, 16),
+				want: []Location{{URI: libURI, Range: Range{Start: Position{6, 4}, End: Position{6, 4}}}},
+			},
+			{
+				name: "library alias in reference",
+				pos:  at(apiURI, 8, 12),
+				want: []Location{{URI: libURI}},
+			},
+			{
+				name: "library alias in uses",
+				pos:  at(apiURI, 3, 3),
+				want: []Location{{URI: libURI}},
+			},
+			{
+				name: "path of library",
+				pos:  at(apiURI, 3, 12),
+				want: []Location{{URI: libURI}},
+			},
+			{
+				name: "included file",
+				pos:  at(apiURI, 16, 32),
+				want: []Location{{URI: pathToURI(filepath.Join(dir, "examples", "pet.json"))}},
+			},
+			{
+				name: "local type",
+				pos:  at(apiURI, 5, 3),
+				want: []Location{{URI: apiURI, Range: Range{Start: Position{6, 4}, End: Position{6, 4}}}},
+			},
+			{
+				name: "unknown word",
+				pos:  at(apiURI, 1, 9),
+				want: []Location{},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got []Location
+				require.Nil(t, c.call("textDocument/definition", tt.pos, &got))
+				require.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		var got *Hover
+		require.Nil(t, c.call("textDocument/hover", at(apiURI, 15, 22), &got))
+		require.NotNil(t, got)
+		require.Equal(t, "markdown", got.Contents.Kind)
+		require.Contains(t, got.Contents.Value, "**lib.Pet**")
+		// Referenced types are inlined in the unwrapped shape.
+		require.Contains(t, got.Contents.Value, "minLength: 1")
+		require.Equal(t, &Range{Start: Position{15, 18}, End: Position{15, 25}}, got.Range)
+
+		got = nil
+		require.Nil(t, c.call("textDocument/hover", at(apiURI, 0, 2), &got))
+		require.Nil(t, got)
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		labels := func(pos TextDocumentPositionParams) []string {
+			var got CompletionList
+			require.Nil(t, c.call("textDocument/completion", pos, &got))
+			res := make([]string, 0, len(got.Items))
+			for _, item := range got.Items {
+				res = append(res, item.Label)
+			}
+			return res
+		}
+		values := labels(at(apiURI, 8, 11))
+		require.Contains(t, values, "string")
+		require.Contains(t, values, "Owner")
+		require.Contains(t, values, "lib.Pet")
+		require.Contains(t, values, "lib.Name")
+		require.NotContains(t, values, "minLength")
+
+		keys := labels(at(apiURI, 7, 4))
+		require.Contains(t, keys, "minLength")
+		require.Contains(t, keys, "properties")
+		require.NotContains(t, keys, "lib.Pet")
+	})
+
+	t.Run("incremental change", func(t *testing.T) {
+		// Replace "lib.Pet" of the property with an unknown type.
+		c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument: VersionedTextDocumentIdentifier{URI: apiURI, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{
+				{Range: &Range{Start: Position{8, 15}, End: Position{8, 18}}, Text: "Cat"},
+			},
+		})
+		c.sync()
+		require.Len(t, c.diags[apiURI], 1)
+		diag := c.diags[apiURI][0]
+		require.Equal(t, SeverityError, diag.Severity)
+		require.Equal(t, 8, diag.Range.Start.Line)
+		require.Contains(t, diag.Message, "lib.Cat")
+
+		// Navigation uses the last good model while the document is invalid.
+		var got []Location
+		require.Nil(t, c.call("textDocument/definition", at(apiURI, 15, 22), &got))
+		require.Len(t, got, 1)
+
+		c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument: VersionedTextDocumentIdentifier{URI: apiURI, Version: 3},
+			ContentChanges: []TextDocumentContentChangeEvent{
+				{Range: &Range{Start: Position{8, 15}, End: Position{8, 18}}, Text: "Pet"},
+			},
+		})
+		c.sync()
+		diags, ok := c.diags[apiURI]
+		require.True(t, ok)
+		require.Empty(t, diags, "diagnostics must be cleared")
+	})
+
+	t.Run("unsaved library", func(t *testing.T) {
+		c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: libURI, LanguageID: "raml", Version: 1, Text: testLib},
+		})
+		c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument: VersionedTextDocumentIdentifier{URI: libURI, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{
+				{Text: strings.Replace(testLib, "minLength: 1", "minLength: 10", 1)},
+			},
+		})
+		c.sync()
+		// The example in the API violates the changed library that is not saved yet.
+		require.NotEmpty(t, c.diags[apiURI])
+		require.Empty(t, c.diags[libURI])
+
+		c.notify("textDocument/didClose", DidCloseTextDocumentParams{
+			TextDocument: TextDocumentIdentifier{URI: libURI},
+		})
+		c.sync()
+		require.Empty(t, c.diags[apiURI], "closed library must be read from disk")
+	})
+
+	require.Nil(t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	require.NoError(t, <-done)
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c, done := newTestClient(t)
+	require.Nil(t, c.call("initialize", map[string]any{}, nil))
+	c.notify("exit", nil)
+	require.ErrorIs(t, <-done, ErrExitWithoutShutdown)
+}
@@ -0,0 +1,181 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorHandlerFunc writes the response for a request rejected by the middleware.
+type ErrorHandlerFunc func(w http.ResponseWriter, req *http.Request, err error)
+
+// ViolationHookFunc is called for every request or response that does not conform to the API definition.
+// route is nil if the request did not match any resource method. isResponse is set for response violations.
+type ViolationHookFunc func(req *http.Request, route *Route, err error, isResponse bool)
+
+type middlewareOptions struct {
+	errorHandler       ErrorHandlerFunc
+	violationHooks     []ViolationHookFunc
+	validateResponses  bool
+	requestsReportOnly bool
+}
+
+type MiddlewareOpt interface {
+	Apply(*middlewareOptions)
+}
+
+type middlewareOptWithErrorHandler struct{ f ErrorHandlerFunc }
+
+func (o middlewareOptWithErrorHandler) Apply(opt *middlewareOptions) {
+	opt.errorHandler = o.f
+}
+
+// OptWithErrorHandler replaces the default handler that writes rejected requests.
+func OptWithErrorHandler(f ErrorHandlerFunc) MiddlewareOpt {
+	return middlewareOptWithErrorHandler{f: f}
+}
+
+type middlewareOptWithViolationHook struct{ f ViolationHookFunc }
+
+func (o middlewareOptWithViolationHook) Apply(opt *middlewareOptions) {
+	opt.violationHooks = append(opt.violationHooks, o.f)
+}
+
+// OptWithViolationHook adds a hook that is called for every violation, e.g. for logging.
+func OptWithViolationHook(f ViolationHookFunc) MiddlewareOpt {
+	return middlewareOptWithViolationHook{f: f}
+}
+
+type middlewareOptWithResponseValidation struct{}
+
+func (middlewareOptWithResponseValidation) Apply(opt *middlewareOptions) {
+	opt.validateResponses = true
+}
+
+// OptWithResponseValidation enables validation of responses. Responses are never modified or rejected,
+// violations are only reported to the violation hooks.
+func OptWithResponseValidation() MiddlewareOpt {
+	return middlewareOptWithResponseValidation{}
+}
+
+type middlewareOptWithRequestsReportOnly struct{}
+
+func (middlewareOptWithRequestsReportOnly) Apply(opt *middlewareOptions) {
+	opt.requestsReportOnly = true
+}
+
+// OptWithRequestsReportOnly makes invalid requests pass to the handler, violations are only reported to the hooks.
+func OptWithRequestsReportOnly() MiddlewareOpt {
+	return middlewareOptWithRequestsReportOnly{}
+}
+
+// NewMiddleware creates a net/http middleware that enforces the API contract of the parsed RAML.
+// The entry point of the RAML must be an API parsed with OptWithUnwrap.
+func NewMiddleware(r *RAML, opts ...MiddlewareOpt) (func(http.Handler) http.Handler, error) {
+	api, ok := r.EntryPoint().(*API)
+	if !ok {
+		return nil, fmt.Errorf("entry point is not an api")
+	}
+	v, err := NewHTTPValidator(api)
+	if err != nil {
+		return nil, fmt.Errorf("new http validator: %w", err)
+	}
+	o := &middlewareOptions{errorHandler: DefaultErrorHandler}
+	for _, opt := range opts {
+		opt.Apply(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route, errValidate := v.ValidateRequest(req)
+			if errValidate != nil {
+				o.report(req, route, errValidate, false)
+				if !o.requestsReportOnly {
+					o.errorHandler(w, req, errValidate)
+					return
+				}
+			}
+			if !o.validateResponses || route == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rec, req)
+			if errResp := ValidateResponse(route.Method, rec.statusCode, rec.header(), rec.body.Bytes()); errResp != nil {
+				o.report(req, route, errResp, true)
+			}
+		})
+	}, nil
+}
+
+func (o *middlewareOptions) report(req *http.Request, route *Route, err error, isResponse bool) {
+	for _, hook := range o.violationHooks {
+		hook(req, route, err, isResponse)
+	}
+}
+
+// DefaultErrorHandler writes violations as a JSON object.
+// Unknown resources are rejected with 404, unsupported methods with 405 and other violations with 400.
+func DefaultErrorHandler(w http.ResponseWriter, req *http.Request, err error) {
+	status := http.StatusBadRequest
+	body := struct {
+		Error      string       `json:"error"`
+		Violations []*Violation `json:"violations,omitempty"`
+	}{Error: err.Error()}
+	var verr *HTTPValidationError
+	if errors.As(err, &verr) {
+		body.Error = "request does not conform to the api definition"
+		body.Violations = verr.Violations
+		// Route violations are named by the method if the path matched a resource, otherwise by the path.
+		if len(verr.Violations) == 1 && verr.Violations[0].In == ViolationInRoute {
+			status = http.StatusNotFound
+			if verr.Violations[0].Name == req.Method {
+				status = http.StatusMethodNotAllowed
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// responseRecorder passes the response through and keeps a copy of its status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader {
+		rec.statusCode = statusCode
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(data)
+	rec.body.Write(data[:n])
+	return n, err
+}
+
+// header returns the headers of the response. If the handler did not set Content-Type,
+// the type sniffed by net/http from the body is returned as it is sent to the client.
+func (rec *responseRecorder) header() http.Header {
+	header := rec.Header()
+	if _, ok := header["Content-Type"]; ok || rec.body.Len() == 0 {
+		return header
+	}
+	header = header.Clone()
+	header.Set("Content-Type", http.DetectContentType(rec.body.Bytes()))
+	return header
+}
+
+// Unwrap returns the underlying writer, so that http.ResponseController can access optional interfaces.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,160 @@
+package raml
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMiddleware(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_http.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/v1/books/2" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": "two"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": 1, "title": "Dune"}`))
+	})
+
+	type violation struct {
+		path       string
+		isResponse bool
+	}
+	tests := []struct {
+		name           string
+		opts           []MiddlewareOpt
+		target         string
+		wantStatus     int
+		wantBody       string
+		wantViolations []violation
+	}{
+		{
+			name:       "positive: valid request is passed to the handler",
+			target:     "/v1/books/1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id": 1, "title": "Dune"}`,
+		},
+		{
+			name:           "negative: invalid request is rejected",
+			target:         "/v1/books/0",
+			wantStatus:     http.StatusBadRequest,
+			wantBody:       `"in":"uri","name":"bookId"`,
+			wantViolations: []violation{{path: "/v1/books/0"}},
+		},
+		{
+			name:           "negative: unknown resource is rejected",
+			target:         "/v1/authors",
+			wantStatus:     http.StatusNotFound,
+			wantViolations: []violation{{path: "/v1/authors"}},
+		},
+		{
+			name: "negative: custom error handler",
+			opts: []MiddlewareOpt{OptWithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte("custom: " + err.Error()))
+			})},
+			target:         "/v1/books/0",
+			wantStatus:     http.StatusUnprocessableEntity,
+			wantBody:       `custom: http validation failed`,
+			wantViolations: []violation{{path: "/v1/books/0"}},
+		},
+		{
+			name:           "negative: report only request",
+			opts:           []MiddlewareOpt{OptWithRequestsReportOnly()},
+			target:         "/v1/books/0",
+			wantStatus:     http.StatusOK,
+			wantBody:       `{"id": 1, "title": "Dune"}`,
+			wantViolations: []violation{{path: "/v1/books/0"}},
+		},
+		{
+			name:           "negative: invalid response is reported but not modified",
+			opts:           []MiddlewareOpt{OptWithResponseValidation()},
+			target:         "/v1/books/2",
+			wantStatus:     http.StatusOK,
+			wantBody:       `{"id": "two"}`,
+			wantViolations: []violation{{path: "/v1/books/2", isResponse: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []violation
+			hook := OptWithViolationHook(func(req *http.Request, _ *Route, err error, isResponse bool) {
+				require.Error(t, err)
+				got = append(got, violation{path: req.URL.Path, isResponse: isResponse})
+			})
+			mw, err := NewMiddleware(rml, append(tt.opts, hook)...)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			mw(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+			require.Equal(t, tt.wantViolations, got)
+		})
+	}
+}
+
+func TestNewMiddleware_SniffedContentType(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_http.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+
+	var (
+		mu         sync.Mutex
+		violations []string
+	)
+	mw, err := NewMiddleware(rml, OptWithResponseValidation(),
+		OptWithViolationHook(func(req *http.Request, _ *Route, err error, _ bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			violations = append(violations, req.URL.Path+": "+err.Error())
+		}))
+	require.NoError(t, err)
+	// Handler relies on net/http to sniff the content type. A real server is used since httptest.ResponseRecorder
+	// sets the sniffed content type in the header map.
+	srv := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/v1/books/1/cover" {
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n"))
+			return
+		}
+		_, _ = w.Write([]byte("cover"))
+	})))
+	t.Cleanup(srv.Close)
+
+	for _, path := range []string{"/v1/books/1/cover", "/v1/books/2/cover"} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	// Sniffed image/png is declared, while sniffed text/plain is not.
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, violations, 1, violations)
+	require.True(t, strings.HasPrefix(violations[0], "/v1/books/2/cover: "), violations)
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	verr := &HTTPValidationError{}
+	verr.add(ViolationInRoute, http.MethodPut, "method is not allowed for /v1/books")
+	DefaultErrorHandler(rec, httptest.NewRequest(http.MethodPut, "/v1/books", nil), verr)
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Error      string       `json:"error"`
+		Violations []*Violation `json:"violations"`
+	}
+	require.NoError(t, json.NewDecoder(strings.NewReader(rec.Body.String())).Decode(&body))
+	require.Equal(t, verr.Violations, body.Violations)
+}
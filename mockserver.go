@@ -0,0 +1,224 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NewMockHandler creates an HTTP handler that serves every resource method of the API with declared examples.
+// Requests are validated the same way as by HTTPValidator and rejected with DefaultErrorHandler if invalid.
+//
+// The response is selected as follows:
+//   - the status code requested by the "Prefer: code=<status>" header, otherwise the lowest declared 2xx code,
+//     otherwise the lowest declared code;
+//   - the media type accepted by the "Accept" header, otherwise the first declared media type;
+//   - the example requested by the "Prefer: example=<name>" header, otherwise the single or the first example.
+//
+// If the body type does not declare examples, the example is composed from examples of its properties and items.
+func NewMockHandler(r *RAML) (http.Handler, error) {
+	api, ok := r.EntryPoint().(*API)
+	if !ok {
+		return nil, fmt.Errorf("entry point is not an api")
+	}
+	v, err := NewHTTPValidator(api)
+	if err != nil {
+		return nil, fmt.Errorf("new http validator: %w", err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route, errValidate := v.ValidateRequest(req)
+		if errValidate != nil {
+			DefaultErrorHandler(w, req, errValidate)
+			return
+		}
+		prefer := parsePreferHeader(req.Header.Get("Prefer"))
+		resp, errSelect := selectMockResponse(route.Method, prefer["code"])
+		if errSelect != nil {
+			DefaultErrorHandler(w, req, errSelect)
+			return
+		}
+		if resp == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeMockResponse(w, req, resp, prefer["example"])
+	}), nil
+}
+
+// parsePreferHeader parses preferences of the "Prefer" header (RFC 7240), e.g. "code=404, example=notFound".
+func parsePreferHeader(header string) map[string]string {
+	prefs := make(map[string]string)
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		prefs[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	return prefs
+}
+
+// selectMockResponse returns the declared response for the preferred status code.
+// Returns nil if the method does not declare responses.
+func selectMockResponse(m *Method, preferredCode string) (*Response, error) {
+	if preferredCode != "" {
+		resp, ok := m.Responses.Get(preferredCode)
+		if !ok {
+			verr := &HTTPValidationError{}
+			verr.add(ViolationInStatus, preferredCode, "preferred response status code is not declared")
+			return nil, verr
+		}
+		return resp, nil
+	}
+	codes := make([]string, 0, m.Responses.Len())
+	for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		codes = append(codes, pair.Key)
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	sort.Strings(codes)
+	code := codes[0]
+	for _, c := range codes {
+		if strings.HasPrefix(c, "2") {
+			code = c
+			break
+		}
+	}
+	resp, _ := m.Responses.Get(code)
+	return resp, nil
+}
+
+func writeMockResponse(w http.ResponseWriter, req *http.Request, resp *Response, exampleName string) {
+	status, _ := strconv.Atoi(resp.Code)
+	for pair := resp.Headers.Oldest(); pair != nil; pair = pair.Next() {
+		if value, ok := mockValue(pair.Value.Base, "", make(map[*BaseShape]struct{})); ok {
+			w.Header().Set(pair.Value.Name, fmt.Sprint(value))
+		}
+	}
+	body := selectMockBody(resp, req.Header.Get("Accept"))
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	value, ok := mockValue(body.Shape, exampleName, make(map[*BaseShape]struct{}))
+	w.Header().Set("Content-Type", body.MediaType)
+	w.WriteHeader(status)
+	if !ok {
+		return
+	}
+	if s, isString := value.(string); isString && !IsJSONMediaType(body.MediaType) {
+		_, _ = w.Write([]byte(s))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// selectMockBody returns the body of the first declared media type accepted by the client.
+func selectMockBody(resp *Response, accept string) *Body {
+	var first *Body
+	for pair := resp.Body.Oldest(); pair != nil; pair = pair.Next() {
+		if first == nil {
+			first = pair.Value
+		}
+		if accept == "" {
+			break
+		}
+		for _, part := range strings.Split(accept, ",") {
+			mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mt == pair.Key {
+				return pair.Value
+			}
+		}
+	}
+	return first
+}
+
+// mockValue returns the example value of the shape.
+// If the shape does not declare examples, the value is composed from examples of object properties and array items.
+func mockValue(base *BaseShape, exampleName string, visited map[*BaseShape]struct{}) (any, bool) {
+	if base == nil {
+		return nil, false
+	}
+	if value, ok := declaredExample(base, exampleName); ok {
+		return value, true
+	}
+	if _, ok := visited[base]; ok {
+		return nil, false
+	}
+	visited[base] = struct{}{}
+	defer delete(visited, base)
+
+	switch s := base.Shape.(type) {
+	case *ObjectShape:
+		obj := make(map[string]any)
+		if s.Properties != nil {
+			for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if value, ok := mockValue(pair.Value.Base, "", visited); ok {
+					obj[pair.Value.Name] = value
+				}
+			}
+		}
+		return obj, true
+	case *ArrayShape:
+		if value, ok := mockValue(s.Items, "", visited); ok {
+			return []any{value}, true
+		}
+		return []any{}, true
+	case *UnionShape:
+		for _, member := range s.AnyOf {
+			if value, ok := mockValue(member, "", visited); ok {
+				return value, true
+			}
+		}
+	case *StringShape:
+		if len(s.Enum) > 0 {
+			return s.Enum[0].Value, true
+		}
+	case *IntegerShape:
+		if len(s.Enum) > 0 {
+			return s.Enum[0].Value, true
+		}
+	case *NumberShape:
+		if len(s.Enum) > 0 {
+			return s.Enum[0].Value, true
+		}
+	case *BooleanShape:
+		if len(s.Enum) > 0 {
+			return s.Enum[0].Value, true
+		}
+	}
+	return nil, false
+}
+
+// declaredExample returns the example, the named or the first of examples, or the default value of the shape.
+// Examples of parents are used if the shape does not declare its own.
+func declaredExample(base *BaseShape, exampleName string) (any, bool) {
+	if base.Example != nil && base.Example.Data != nil {
+		return base.Example.Data.Value, true
+	}
+	if base.Examples != nil {
+		examples := base.Examples.Map
+		if base.Examples.Link != nil {
+			examples = base.Examples.Link.Map
+		}
+		if examples != nil {
+			if ex, ok := examples.Get(exampleName); ok && ex.Data != nil {
+				return ex.Data.Value, true
+			}
+			if pair := examples.Oldest(); pair != nil && pair.Value.Data != nil {
+				return pair.Value.Data.Value, true
+			}
+		}
+	}
+	if base.Default != nil {
+		return base.Default.Value, true
+	}
+	for _, parent := range base.Inherits {
+		if value, ok := declaredExample(parent, exampleName); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
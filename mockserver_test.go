@@ -0,0 +1,118 @@
+package raml
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMockHandler(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_mock.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	handler, err := NewMockHandler(rml)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		header          http.Header
+		wantStatus      int
+		wantContentType string
+		wantHeader      http.Header
+		wantBody        string
+	}{
+		{
+			name:            "positive: example composed from properties",
+			target:          "/v1/books",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantHeader:      http.Header{"X-Total-Count": {"1"}},
+			wantBody:        `[{"format":"paperback","id":1,"title":"Dune"}]`,
+		},
+		{
+			name:            "positive: accepted media type",
+			target:          "/v1/books",
+			header:          http.Header{"Accept": {"text/html, text/plain;q=0.9"}},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain",
+			wantBody:        "Dune",
+		},
+		{
+			name:            "positive: first of named examples",
+			target:          "/v1/books/1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"format":"paperback","id":1,"title":"Dune"}`,
+		},
+		{
+			name:            "positive: preferred example",
+			target:          "/v1/books/2",
+			header:          http.Header{"Prefer": {"example=hyperion"}},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"format":"hardcover","id":2,"title":"Hyperion"}`,
+		},
+		{
+			name:            "positive: preferred status code",
+			target:          "/v1/books/1",
+			header:          http.Header{"Prefer": {"code=404"}},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json",
+			wantBody:        `{"message":"book not found"}`,
+		},
+		{
+			name:       "positive: no responses declared",
+			method:     http.MethodDelete,
+			target:     "/v1/books/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:            "negative: invalid request",
+			target:          "/v1/books/0",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json",
+			wantBody:        `"in":"uri","name":"bookId"`,
+		},
+		{
+			name:       "negative: unsupported method",
+			method:     http.MethodPost,
+			target:     "/v1/books",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "negative: undeclared preferred status code",
+			target:     "/v1/books/1",
+			header:     http.Header{"Prefer": {"code=500"}},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"in":"status","name":"500"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantContentType != "" {
+				require.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			}
+			for k, v := range tt.wantHeader {
+				require.Equal(t, v, rec.Header()[k])
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("ServeHTTP() body = %s, want to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -553,7 +553,7 @@ func Test_scalarNodeToDataNode(t *testing.T) {
 			if tt.prepare != nil {
 				tt.prepare(t)
 			}
-			got, err := scalarNodeToDataNode(tt.args.node, tt.args.location, tt.args.isInclude)
+			got, err := (&RAML{}).scalarNodeToDataNode(tt.args.node, tt.args.location, tt.args.isInclude)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("scalarNodeToDataNode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -674,7 +674,7 @@ func Test_yamlNodeToDataNode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := yamlNodeToDataNode(tt.args.node, tt.args.location, tt.args.isInclude)
+			got, err := (&RAML{}).yamlNodeToDataNode(tt.args.node, tt.args.location, tt.args.isInclude)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("yamlNodeToDataNode() error = %v, wantErr %v", err, tt.wantErr)
 				return
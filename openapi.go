@@ -0,0 +1,903 @@
+package raml
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// OpenAPIVersion is the version of OpenAPI documents produced by OpenAPIConverter.
+const OpenAPIVersion = "3.1.0"
+
+const openAPISchemasRef = "#/components/schemas/"
+
+// uriTemplateRegexp matches parameters of URI templates, e.g. "{id}".
+var uriTemplateRegexp = regexp.MustCompile(`{([^{}]+)}`)
+
+// OpenAPI is the root of an OpenAPI 3.1 document.
+type OpenAPI struct {
+	OpenAPI    string                                           `json:"openapi" yaml:"openapi"`
+	Info       *OpenAPIInfo                                     `json:"info" yaml:"info"`
+	Servers    []*OpenAPIServer                                 `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      *orderedmap.OrderedMap[string, *OpenAPIPathItem] `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Components *OpenAPIComponents                               `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// OpenAPIInfo is the metadata of the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// OpenAPIServer is a server that hosts the API.
+type OpenAPIServer struct {
+	URL       string                                                 `json:"url" yaml:"url"`
+	Variables *orderedmap.OrderedMap[string, *OpenAPIServerVariable] `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+// OpenAPIServerVariable is a variable of the server URL template.
+type OpenAPIServerVariable struct {
+	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default     string   `json:"default" yaml:"default"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// OpenAPIPathItem describes operations available on a single path.
+type OpenAPIPathItem struct {
+	Summary     string              `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []*OpenAPIParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Get         *OpenAPIOperation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Put         *OpenAPIOperation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Post        *OpenAPIOperation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete      *OpenAPIOperation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options     *OpenAPIOperation   `json:"options,omitempty" yaml:"options,omitempty"`
+	Head        *OpenAPIOperation   `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch       *OpenAPIOperation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// OpenAPIOperation is a single API operation on a path.
+type OpenAPIOperation struct {
+	Summary     string                                           `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                                           `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []*OpenAPIParameter                              `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody                              `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   *orderedmap.OrderedMap[string, *OpenAPIResponse] `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Security    []OpenAPISecurityRequirement                     `json:"security,omitempty" yaml:"security,omitempty"`
+}
+
+// OpenAPIParameter is a path, query or header parameter of an operation.
+type OpenAPIParameter struct {
+	Name        string         `json:"name" yaml:"name"`
+	In          string         `json:"in" yaml:"in"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody is a request body of an operation.
+type OpenAPIRequestBody struct {
+	Content  *orderedmap.OrderedMap[string, *OpenAPIMediaType] `json:"content" yaml:"content"`
+	Required bool                                              `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// OpenAPIResponse is a response of an operation.
+type OpenAPIResponse struct {
+	Description string                                            `json:"description" yaml:"description"`
+	Headers     *orderedmap.OrderedMap[string, *OpenAPIHeader]    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     *orderedmap.OrderedMap[string, *OpenAPIMediaType] `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// OpenAPIHeader is a header of a response.
+type OpenAPIHeader struct {
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIMediaType is a body of a specific media type.
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema                                  `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  any                                             `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples *orderedmap.OrderedMap[string, *OpenAPIExample] `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+// OpenAPIExample is a named example of a body.
+type OpenAPIExample struct {
+	Summary     string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Value       any    `json:"value" yaml:"value"`
+}
+
+// OpenAPIComponents contains reusable schemas and security schemes.
+type OpenAPIComponents struct {
+	Schemas         *orderedmap.OrderedMap[string, *OpenAPISchema]         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	SecuritySchemes *orderedmap.OrderedMap[string, *OpenAPISecurityScheme] `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+// OpenAPISecurityScheme is a security scheme that can be used by operations.
+type OpenAPISecurityScheme struct {
+	Type        string             `json:"type" yaml:"type"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Name        string             `json:"name,omitempty" yaml:"name,omitempty"`
+	In          string             `json:"in,omitempty" yaml:"in,omitempty"`
+	Scheme      string             `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Flows       *OpenAPIOAuthFlows `json:"flows,omitempty" yaml:"flows,omitempty"`
+}
+
+// OpenAPIOAuthFlows contains OAuth 2.0 flows supported by the security scheme.
+type OpenAPIOAuthFlows struct {
+	Implicit          *OpenAPIOAuthFlow `json:"implicit,omitempty" yaml:"implicit,omitempty"`
+	Password          *OpenAPIOAuthFlow `json:"password,omitempty" yaml:"password,omitempty"`
+	ClientCredentials *OpenAPIOAuthFlow `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OpenAPIOAuthFlow `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty"`
+}
+
+// OpenAPIOAuthFlow is a single OAuth 2.0 flow.
+type OpenAPIOAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+}
+
+// OpenAPISecurityRequirement lists scopes required by security schemes. An empty requirement allows anonymous access.
+type OpenAPISecurityRequirement map[string][]string
+
+// OpenAPISchema is the OpenAPI dialect of JSON Schema produced by JSONSchemaConverter.
+type OpenAPISchema struct {
+	JSONSchemaGeneric[*OpenAPISchema] `yaml:",inline"`
+
+	// base is the shape the schema is converted from, nil for nested schemas of JSON types.
+	base *BaseShape
+}
+
+func (js *OpenAPISchema) Generic() *JSONSchemaGeneric[*OpenAPISchema] { return &js.JSONSchemaGeneric }
+
+func (js *OpenAPISchema) ShallowCopy() *OpenAPISchema {
+	if js == nil {
+		return nil
+	}
+	return &OpenAPISchema{JSONSchemaGeneric: *js.JSONSchemaGeneric.ShallowCopy(), base: js.base}
+}
+
+func (js *OpenAPISchema) DeepCopy() *OpenAPISchema {
+	if js == nil {
+		return nil
+	}
+	return &OpenAPISchema{JSONSchemaGeneric: *js.JSONSchemaGeneric.DeepCopy(), base: js.base}
+}
+
+func (js *OpenAPISchema) Map() map[string]any {
+	if js == nil {
+		return nil
+	}
+	return js.JSONSchemaGeneric.Map()
+}
+
+func openAPISchemaWrapper(
+	_ *JSONSchemaConverter[*OpenAPISchema], core *JSONSchemaGeneric[*OpenAPISchema], b *BaseShape,
+) *OpenAPISchema {
+	if core == nil {
+		return nil
+	}
+	return &OpenAPISchema{JSONSchemaGeneric: *core, base: b}
+}
+
+// OpenAPIConverter converts a RAML API to an OpenAPI 3.1 document.
+//
+// Schemas are produced by JSONSchemaConverter. Types declared by the API and by the libraries it uses
+// are placed in components/schemas, and shapes that reference them without changing their constraints
+// are converted to references. Resource types and traits are expanded into resources and methods.
+// RAML features that cannot be represented in OpenAPI, such as annotations, custom facets or
+// OAuth 1.0 security schemes, are reported as warnings.
+type OpenAPIConverter struct {
+	raml *RAML
+
+	doc *OpenAPI
+	// names contains component names of named types by shape ID.
+	names map[int64]string
+	// raw contains converted schemas of named types before references are resolved by shape ID.
+	raw map[int64]*OpenAPISchema
+	// recursive contains component names of recursive schemas that are not named types.
+	recursive map[*OpenAPISchema]string
+	// securitySchemes contains component names of converted security schemes, empty for unsupported ones.
+	securitySchemes map[*SecurityScheme]string
+	warnings        []*stacktrace.StackTrace
+	// warned contains keys of reported warnings to avoid duplicates.
+	warned map[string]struct{}
+}
+
+func NewOpenAPIConverter(r *RAML) *OpenAPIConverter {
+	return &OpenAPIConverter{raml: r}
+}
+
+// Warnings returns warnings about RAML features lost by the last conversion.
+func (o *OpenAPIConverter) Warnings() []*stacktrace.StackTrace {
+	return o.warnings
+}
+
+// Convert converts the entry point to an OpenAPI document.
+// The entry point must be an API parsed with OptWithUnwrap.
+func (o *OpenAPIConverter) Convert() (*OpenAPI, error) {
+	api, ok := o.raml.EntryPoint().(*API)
+	if !ok {
+		return nil, fmt.Errorf("entry point must be an api")
+	}
+	o.names = make(map[int64]string)
+	o.raw = make(map[int64]*OpenAPISchema)
+	o.recursive = make(map[*OpenAPISchema]string)
+	o.securitySchemes = make(map[*SecurityScheme]string)
+	o.warnings = nil
+	o.warned = make(map[string]struct{})
+	o.doc = &OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info:    o.info(api),
+		Servers: o.servers(api),
+		Paths:   orderedmap.New[string, *OpenAPIPathItem](),
+		Components: &OpenAPIComponents{
+			Schemas:         orderedmap.New[string, *OpenAPISchema](),
+			SecuritySchemes: orderedmap.New[string, *OpenAPISecurityScheme](),
+		},
+	}
+	if err := o.convertTypes(api); err != nil {
+		return nil, fmt.Errorf("convert types: %w", err)
+	}
+	o.warnAnnotations(api.CustomDomainProperties, api.Location, stacktrace.Position{})
+	for pair := api.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		if err := o.convertResource(pair.Value); err != nil {
+			return nil, fmt.Errorf("convert resource %s: %w", pair.Value.FullURI(), err)
+		}
+	}
+	if o.doc.Paths.Len() == 0 {
+		o.doc.Paths = nil
+	}
+	if o.doc.Components.Schemas.Len() == 0 {
+		o.doc.Components.Schemas = nil
+	}
+	if o.doc.Components.SecuritySchemes.Len() == 0 {
+		o.doc.Components.SecuritySchemes = nil
+	}
+	if o.doc.Components.Schemas == nil && o.doc.Components.SecuritySchemes == nil {
+		o.doc.Components = nil
+	}
+	return o.doc, nil
+}
+
+func (o *OpenAPIConverter) warn(msg string, location string, pos stacktrace.Position, opts ...stacktrace.Option) {
+	key := fmt.Sprintf("%s:%d:%d: %s", location, pos.Line, pos.Column, msg)
+	if _, ok := o.warned[key]; ok {
+		return
+	}
+	o.warned[key] = struct{}{}
+	opts = append(opts, stacktrace.WithPosition(&pos))
+	o.warnings = append(o.warnings, StacktraceNew(msg, location, opts...))
+}
+
+func (o *OpenAPIConverter) warnAnnotations(
+	annotations *orderedmap.OrderedMap[string, *DomainExtension], location string, pos stacktrace.Position,
+) {
+	for pair := annotations.Oldest(); pair != nil; pair = pair.Next() {
+		o.warn("annotation is not converted", location, pos, stacktrace.WithInfo("annotation", pair.Key))
+	}
+}
+
+func (o *OpenAPIConverter) info(api *API) *OpenAPIInfo {
+	info := &OpenAPIInfo{Title: api.Title, Description: api.Description, Version: api.Version}
+	if info.Version == "" {
+		info.Version = "unspecified"
+		o.warn("api version is not declared", api.Location, stacktrace.Position{})
+	}
+	sections := make([]string, 0, len(api.Documentation)+1)
+	if info.Description != "" {
+		sections = append(sections, info.Description)
+	}
+	// NOTE: OpenAPI does not have a separate documentation, so it becomes a part of the description.
+	for _, item := range api.Documentation {
+		sections = append(sections, fmt.Sprintf("## %s\n\n%s", item.Title, item.Content))
+	}
+	info.Description = strings.Join(sections, "\n\n")
+	return info
+}
+
+func (o *OpenAPIConverter) servers(api *API) []*OpenAPIServer {
+	if api.BaseURI == "" {
+		return nil
+	}
+	uri := strings.ReplaceAll(api.BaseURI, "{version}", api.Version)
+	var variables *orderedmap.OrderedMap[string, *OpenAPIServerVariable]
+	for _, match := range uriTemplateRegexp.FindAllStringSubmatch(uri, -1) {
+		name := match[1]
+		if variables == nil {
+			variables = orderedmap.New[string, *OpenAPIServerVariable]()
+		}
+		if _, ok := variables.Get(name); ok {
+			continue
+		}
+		v := &OpenAPIServerVariable{}
+		if api.BaseURIParameters != nil {
+			if param, ok := api.BaseURIParameters.Get(name); ok {
+				o.serverVariable(v, param.Base)
+			}
+		}
+		if v.Default == "" {
+			o.warn("base uri parameter does not have a default value", api.Location, stacktrace.Position{},
+				stacktrace.WithInfo("parameter", name))
+		}
+		variables.Set(name, v)
+	}
+	if len(api.Protocols) < 2 {
+		return []*OpenAPIServer{{URL: uri, Variables: variables}}
+	}
+	// NOTE: OpenAPI does not have protocols, so a server is declared for each protocol.
+	_, rest, found := strings.Cut(uri, "://")
+	if !found {
+		rest = uri
+	}
+	servers := make([]*OpenAPIServer, len(api.Protocols))
+	for i, protocol := range api.Protocols {
+		servers[i] = &OpenAPIServer{URL: strings.ToLower(protocol) + "://" + rest, Variables: variables}
+	}
+	return servers
+}
+
+// serverVariable fills the server variable from the shape of the base URI parameter.
+func (o *OpenAPIConverter) serverVariable(v *OpenAPIServerVariable, base *BaseShape) {
+	if base.Description != nil {
+		v.Description = *base.Description
+	}
+	if s, ok := base.Shape.(*StringShape); ok {
+		for _, item := range s.Enum {
+			v.Enum = append(v.Enum, fmt.Sprint(item.Value))
+		}
+	}
+	switch {
+	case base.Default != nil:
+		v.Default = fmt.Sprint(base.Default.Value)
+	case len(v.Enum) > 0:
+		v.Default = v.Enum[0]
+	case base.Example != nil:
+		v.Default = fmt.Sprint(base.Example.Data.Value)
+	}
+}
+
+// convertTypes declares types of the API and of the libraries it uses as components.
+// Library types are prefixed with the library alias if their names are already taken.
+func (o *OpenAPIConverter) convertTypes(api *API) error {
+	var types []*BaseShape
+	visited := map[string]struct{}{api.Location: {}}
+	addTypes := func(namespace string, m *orderedmap.OrderedMap[string, *BaseShape]) {
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			name := pair.Key
+			if _, ok := o.doc.Components.Schemas.Get(name); ok && namespace != "" {
+				name = namespace + "." + name
+			}
+			for i := 2; ; i++ {
+				if _, ok := o.doc.Components.Schemas.Get(name); !ok {
+					break
+				}
+				name = pair.Key + strconv.Itoa(i)
+			}
+			// NOTE: Occupy the name before schemas are converted.
+			o.doc.Components.Schemas.Set(name, nil)
+			o.names[pair.Value.ID] = name
+			types = append(types, pair.Value)
+		}
+	}
+	var addLibraries func(uses *orderedmap.OrderedMap[string, *LibraryLink])
+	addLibraries = func(uses *orderedmap.OrderedMap[string, *LibraryLink]) {
+		for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+			lib := pair.Value.Link
+			if lib == nil {
+				continue
+			}
+			if _, ok := visited[lib.Location]; ok {
+				continue
+			}
+			visited[lib.Location] = struct{}{}
+			addTypes(pair.Key, lib.Types)
+			addLibraries(lib.Uses)
+		}
+	}
+	addTypes("", api.Types)
+	addLibraries(api.Uses)
+
+	convs := make([]*JSONSchemaConverter[*OpenAPISchema], len(types))
+	for i, base := range types {
+		if !base.IsUnwrapped() {
+			return fmt.Errorf("type %s must be unwrapped", base.Name)
+		}
+		conv, err := NewJSONSchemaConverter[*OpenAPISchema](WithWrapper(openAPISchemaWrapper))
+		if err != nil {
+			return fmt.Errorf("create json schema converter: %w", err)
+		}
+		convs[i] = conv
+		o.raw[base.ID] = conv.Visit(base.Shape)
+	}
+	// NOTE: References are resolved after all named types are converted since they compare schemas.
+	for i, base := range types {
+		o.doc.Components.Schemas.Set(o.names[base.ID], o.resolve(o.raw[base.ID].DeepCopy(), convs[i], base))
+	}
+	return nil
+}
+
+// schema converts the shape to a schema with references to components.
+func (o *OpenAPIConverter) schema(base *BaseShape) (*OpenAPISchema, error) {
+	if !base.IsUnwrapped() {
+		return nil, StacktraceNew("shape must be unwrapped", base.Location, stacktrace.WithPosition(&base.Position))
+	}
+	conv, err := NewJSONSchemaConverter[*OpenAPISchema](WithWrapper(openAPISchemaWrapper))
+	if err != nil {
+		return nil, fmt.Errorf("create json schema converter: %w", err)
+	}
+	return o.resolve(conv.Visit(base.Shape), conv, nil), nil
+}
+
+// resolve replaces schemas of shapes that reference named types with references to components.
+// Root is the named type the schema is converted for, it is not replaced with a reference to itself.
+//
+//nolint:gocognit // Every keyword with nested schemas is resolved in one place.
+func (o *OpenAPIConverter) resolve(
+	s *OpenAPISchema, conv *JSONSchemaConverter[*OpenAPISchema], root *BaseShape,
+) *OpenAPISchema {
+	if s == nil {
+		return nil
+	}
+	if name, found := strings.CutPrefix(s.Ref, "#/definitions/"); found && s.base == nil {
+		return o.resolveRecursive(s, name, conv)
+	}
+	if ref, ok := o.reference(s, root); ok {
+		return ref
+	}
+	if b := s.base; b != nil {
+		o.warnAnnotations(b.CustomDomainProperties, b.Location, b.Position)
+		for pair := b.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+			o.warn("custom facet is not converted", b.Location, b.Position, stacktrace.WithInfo("facet", pair.Key))
+		}
+	}
+
+	g := s.Generic()
+	for i, item := range g.AllOf {
+		g.AllOf[i] = o.resolve(item, conv, root)
+	}
+	for i, item := range g.AnyOf {
+		g.AnyOf[i] = o.resolve(item, conv, root)
+	}
+	for i, item := range g.OneOf {
+		g.OneOf[i] = o.resolve(item, conv, root)
+	}
+	g.Not = o.resolve(g.Not, conv, root)
+	g.If = o.resolve(g.If, conv, root)
+	g.Then = o.resolve(g.Then, conv, root)
+	g.Else = o.resolve(g.Else, conv, root)
+	g.Items = o.resolve(g.Items, conv, root)
+	g.PropertyNames = o.resolve(g.PropertyNames, conv, root)
+	for pair := g.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		pair.Value = o.resolve(pair.Value, conv, root)
+	}
+	for pair := g.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		pair.Value = o.resolve(pair.Value, conv, root)
+	}
+	return s
+}
+
+// resolveRecursive resolves the reference to the definition of a recursive shape.
+// Definitions that are not named types are declared as separate components.
+func (o *OpenAPIConverter) resolveRecursive(
+	s *OpenAPISchema, name string, conv *JSONSchemaConverter[*OpenAPISchema],
+) *OpenAPISchema {
+	def, ok := conv.definitions[name]
+	if !ok || def == nil || def.base == nil {
+		o.warn("reference of json schema is not resolved", "", stacktrace.Position{},
+			stacktrace.WithInfo("ref", s.Ref))
+		return s
+	}
+	if ref, isRef := o.reference(def, nil); isRef {
+		return &OpenAPISchema{JSONSchemaGeneric: JSONSchemaGeneric[*OpenAPISchema]{Ref: ref.Ref}}
+	}
+	component, ok := o.recursive[def]
+	if !ok {
+		hint := ramlTypeName(def.base.Name)
+		component = hint
+		for i := 2; ; i++ {
+			if _, taken := o.doc.Components.Schemas.Get(component); !taken {
+				break
+			}
+			component = hint + strconv.Itoa(i)
+		}
+		o.recursive[def] = component
+		o.doc.Components.Schemas.Set(component, nil)
+		o.doc.Components.Schemas.Set(component, o.resolve(def, conv, def.base))
+	}
+	return &OpenAPISchema{JSONSchemaGeneric: JSONSchemaGeneric[*OpenAPISchema]{Ref: openAPISchemasRef + component}}
+}
+
+// openAPIAnnotationKeywords are keywords that do not constrain values.
+var openAPIAnnotationKeywords = []string{"title", "description", "default", "examples"}
+
+// reference returns the reference to the component of the named type that the schema is converted from.
+// Shapes that reference named types are converted to references if they only change annotation keywords,
+// e.g. description or examples, which are kept next to the reference.
+func (o *OpenAPIConverter) reference(s *OpenAPISchema, root *BaseShape) (*OpenAPISchema, bool) {
+	base := s.base
+	if base == nil || base == root {
+		return nil, false
+	}
+	if name, ok := o.names[base.ID]; ok {
+		return &OpenAPISchema{JSONSchemaGeneric: JSONSchemaGeneric[*OpenAPISchema]{Ref: openAPISchemasRef + name}}, true
+	}
+	if base.TypeLabel == "" || len(base.Inherits) > 1 {
+		return nil, false
+	}
+	ref, err := o.raml.GetReferencedType(base.TypeLabel, base.Location)
+	if err != nil || ref.ID == base.ID {
+		return nil, false
+	}
+	name, ok := o.names[ref.ID]
+	if !ok {
+		return nil, false
+	}
+	own, parent := s.Map(), o.raw[ref.ID].Map()
+	result := &OpenAPISchema{JSONSchemaGeneric: JSONSchemaGeneric[*OpenAPISchema]{Ref: openAPISchemasRef + name}}
+	g, rg := s.Generic(), result.Generic()
+	for _, keyword := range openAPIAnnotationKeywords {
+		if !reflect.DeepEqual(own[keyword], parent[keyword]) {
+			switch keyword {
+			case "title":
+				rg.Title = g.Title
+			case "description":
+				rg.Description = g.Description
+			case "default":
+				rg.Default = g.Default
+			case "examples":
+				rg.Examples = g.Examples
+			}
+		}
+		delete(own, keyword)
+		delete(parent, keyword)
+	}
+	if !reflect.DeepEqual(own, parent) {
+		return nil, false
+	}
+	return result, true
+}
+
+func (o *OpenAPIConverter) convertResource(res *Resource) error {
+	if res.Type != "" {
+		o.warn("resource type is expanded into the resource", res.Location, res.Position,
+			stacktrace.WithInfo("resourceType", res.Type))
+	}
+	o.warnAnnotations(res.CustomDomainProperties, res.Location, res.Position)
+
+	uri := res.FullURI()
+	item := &OpenAPIPathItem{Summary: res.DisplayName, Description: res.Description}
+	if item.Summary == res.URI {
+		item.Summary = ""
+	}
+	for _, match := range uriTemplateRegexp.FindAllStringSubmatch(uri, -1) {
+		param, err := o.uriParameter(res, match[1])
+		if err != nil {
+			return err
+		}
+		item.Parameters = append(item.Parameters, param)
+	}
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		op, err := o.convertMethod(pair.Value)
+		if err != nil {
+			return fmt.Errorf("convert method %s: %w", pair.Key, err)
+		}
+		switch pair.Key {
+		case "get":
+			item.Get = op
+		case "put":
+			item.Put = op
+		case "post":
+			item.Post = op
+		case "delete":
+			item.Delete = op
+		case "options":
+			item.Options = op
+		case "head":
+			item.Head = op
+		case "patch":
+			item.Patch = op
+		}
+	}
+	if res.Methods.Len() > 0 {
+		o.doc.Paths.Set(uri, item)
+	}
+	for pair := res.Resources.Oldest(); pair != nil; pair = pair.Next() {
+		if err := o.convertResource(pair.Value); err != nil {
+			return fmt.Errorf("convert resource %s: %w", pair.Value.FullURI(), err)
+		}
+	}
+	return nil
+}
+
+// uriParameter returns the path parameter declared by the resource or its parents.
+// Parameters that are not declared are strings.
+func (o *OpenAPIConverter) uriParameter(res *Resource, name string) (*OpenAPIParameter, error) {
+	for r := res; r != nil; r = r.Parent {
+		if r.URIParameters == nil {
+			continue
+		}
+		if prop, ok := r.URIParameters.Get(name); ok {
+			param, err := o.parameter(name, "path", prop)
+			if err != nil {
+				return nil, err
+			}
+			param.Required = true
+			return param, nil
+		}
+	}
+	return &OpenAPIParameter{
+		Name:     name,
+		In:       "path",
+		Required: true,
+		Schema:   &OpenAPISchema{JSONSchemaGeneric: JSONSchemaGeneric[*OpenAPISchema]{Type: TypeString}},
+	}, nil
+}
+
+func (o *OpenAPIConverter) parameter(name string, in string, prop Property) (*OpenAPIParameter, error) {
+	schema, err := o.schema(prop.Base)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s parameter %s: %w", in, name, err)
+	}
+	param := &OpenAPIParameter{Name: name, In: in, Required: prop.Required, Schema: schema}
+	if prop.Base.Description != nil {
+		param.Description = *prop.Base.Description
+	}
+	return param, nil
+}
+
+func (o *OpenAPIConverter) parameters(
+	params *orderedmap.OrderedMap[string, Property], in string,
+) ([]*OpenAPIParameter, error) {
+	var result []*OpenAPIParameter
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		param, err := o.parameter(pair.Key, in, pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, param)
+	}
+	return result, nil
+}
+
+func (o *OpenAPIConverter) convertMethod(m *Method) (*OpenAPIOperation, error) {
+	if len(m.Is) > 0 {
+		o.warn("traits are expanded into the method", m.Location, m.Position,
+			stacktrace.WithInfo("traits", strings.Join(m.Is, ", ")))
+	}
+	o.warnAnnotations(m.CustomDomainProperties, m.Location, m.Position)
+
+	op := &OpenAPIOperation{Description: m.Description}
+	if m.DisplayName != m.Name {
+		op.Summary = m.DisplayName
+	}
+	params, err := o.parameters(m.QueryParameters, "query")
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, params...)
+	if m.QueryString != nil {
+		params, err = o.queryString(m.QueryString)
+		if err != nil {
+			return nil, err
+		}
+		op.Parameters = append(op.Parameters, params...)
+	}
+	params, err = o.parameters(m.Headers, "header")
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, params...)
+
+	if m.Body.Len() > 0 {
+		content, errContent := o.content(m.Body)
+		if errContent != nil {
+			return nil, fmt.Errorf("convert body: %w", errContent)
+		}
+		op.RequestBody = &OpenAPIRequestBody{Content: content, Required: true}
+	}
+	if m.Responses.Len() > 0 {
+		op.Responses = orderedmap.New[string, *OpenAPIResponse](m.Responses.Len())
+		for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+			resp, errResp := o.convertResponse(pair.Value)
+			if errResp != nil {
+				return nil, fmt.Errorf("convert response %s: %w", pair.Key, errResp)
+			}
+			op.Responses.Set(pair.Key, resp)
+		}
+	}
+	for _, req := range m.SecuredBy {
+		if secReq, ok := o.securityRequirement(req); ok {
+			op.Security = append(op.Security, secReq)
+		}
+	}
+	return op, nil
+}
+
+// queryString converts properties of the query string to query parameters
+// since OpenAPI 3.1 does not describe the query string as a whole.
+func (o *OpenAPIConverter) queryString(base *BaseShape) ([]*OpenAPIParameter, error) {
+	obj, ok := base.Shape.(*ObjectShape)
+	if !ok {
+		o.warn("query string that is not an object is not converted", base.Location, base.Position)
+		return nil, nil
+	}
+	if obj.PatternProperties.Len() > 0 {
+		o.warn("pattern properties of query string are not converted", base.Location, base.Position)
+	}
+	return o.parameters(obj.Properties, "query")
+}
+
+func (o *OpenAPIConverter) convertResponse(resp *Response) (*OpenAPIResponse, error) {
+	o.warnAnnotations(resp.CustomDomainProperties, resp.Location, resp.Position)
+
+	result := &OpenAPIResponse{Description: resp.Description}
+	if result.Description == "" {
+		if code, err := strconv.Atoi(resp.Code); err == nil {
+			result.Description = http.StatusText(code)
+		}
+	}
+	if resp.Headers.Len() > 0 {
+		result.Headers = orderedmap.New[string, *OpenAPIHeader](resp.Headers.Len())
+		for pair := resp.Headers.Oldest(); pair != nil; pair = pair.Next() {
+			param, err := o.parameter(pair.Key, "header", pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			result.Headers.Set(pair.Key, &OpenAPIHeader{
+				Description: param.Description, Required: param.Required, Schema: param.Schema,
+			})
+		}
+	}
+	if resp.Body.Len() > 0 {
+		content, err := o.content(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("convert body: %w", err)
+		}
+		result.Content = content
+	}
+	return result, nil
+}
+
+func (o *OpenAPIConverter) content(
+	body *orderedmap.OrderedMap[string, *Body],
+) (*orderedmap.OrderedMap[string, *OpenAPIMediaType], error) {
+	content := orderedmap.New[string, *OpenAPIMediaType](body.Len())
+	for pair := body.Oldest(); pair != nil; pair = pair.Next() {
+		shape := pair.Value.Shape
+		schema, err := o.schema(shape)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", pair.Key, err)
+		}
+		mt := &OpenAPIMediaType{Schema: schema}
+		// NOTE: Examples of the body are declared by the media type.
+		schema.Examples = nil
+		switch {
+		case shape.Example != nil:
+			mt.Example = shape.Example.Data.Value
+		case shape.Examples != nil:
+			mt.Examples = orderedmap.New[string, *OpenAPIExample](shape.Examples.Map.Len())
+			for ex := shape.Examples.Map.Oldest(); ex != nil; ex = ex.Next() {
+				mt.Examples.Set(ex.Key, &OpenAPIExample{
+					Summary:     ex.Value.DisplayName,
+					Description: ex.Value.Description,
+					Value:       ex.Value.Data.Value,
+				})
+			}
+		}
+		content.Set(pair.Key, mt)
+	}
+	return content, nil
+}
+
+// securityRequirement converts the security requirement. Requirements of unsupported security schemes are skipped.
+func (o *OpenAPIConverter) securityRequirement(req *SecurityRequirement) (OpenAPISecurityRequirement, bool) {
+	if req.IsAnonymous() {
+		return OpenAPISecurityRequirement{}, true
+	}
+	name := o.securityScheme(req.Name, req.Scheme)
+	if name == "" {
+		return nil, false
+	}
+	scopes := []string{}
+	if values, ok := req.Parameters["scopes"].([]any); ok {
+		for _, v := range values {
+			scopes = append(scopes, fmt.Sprint(v))
+		}
+	}
+	return OpenAPISecurityRequirement{name: scopes}, true
+}
+
+// securityScheme declares the security scheme as a component and returns its name.
+// An empty name is returned for security schemes that cannot be converted.
+func (o *OpenAPIConverter) securityScheme(name string, ss *SecurityScheme) string {
+	if component, ok := o.securitySchemes[ss]; ok {
+		return component
+	}
+	o.warnAnnotations(ss.CustomDomainProperties, ss.Location, ss.Position)
+	result := &OpenAPISecurityScheme{Description: ss.Description}
+	switch ss.Type {
+	case SecuritySchemeBasic:
+		result.Type, result.Scheme = "http", "basic"
+	case SecuritySchemeDigest:
+		result.Type, result.Scheme = "http", "digest"
+	case SecuritySchemeOAuth2:
+		result.Type = "oauth2"
+		result.Flows = o.oauth2Flows(ss)
+	case SecuritySchemePassThrough:
+		result.Type = "apiKey"
+		if !o.apiKey(result, ss) {
+			result = nil
+		}
+	default:
+		o.warn("security scheme is not converted", ss.Location, ss.Position,
+			stacktrace.WithInfo("securityScheme", name), stacktrace.WithInfo("type", ss.Type))
+		result = nil
+	}
+	if result == nil {
+		o.securitySchemes[ss] = ""
+		return ""
+	}
+	o.securitySchemes[ss] = name
+	o.doc.Components.SecuritySchemes.Set(name, result)
+	return name
+}
+
+func (o *OpenAPIConverter) oauth2Flows(ss *SecurityScheme) *OpenAPIOAuthFlows {
+	flows := &OpenAPIOAuthFlows{}
+	if ss.OAuth2 == nil {
+		return flows
+	}
+	newFlow := func(authorizationURL, tokenURL string) *OpenAPIOAuthFlow {
+		flow := &OpenAPIOAuthFlow{AuthorizationURL: authorizationURL, TokenURL: tokenURL, Scopes: map[string]string{}}
+		for _, scope := range ss.OAuth2.Scopes {
+			flow.Scopes[scope] = ""
+		}
+		return flow
+	}
+	for _, grant := range ss.OAuth2.AuthorizationGrants {
+		switch grant {
+		case "authorization_code":
+			flows.AuthorizationCode = newFlow(ss.OAuth2.AuthorizationURI, ss.OAuth2.AccessTokenURI)
+		case "password":
+			flows.Password = newFlow("", ss.OAuth2.AccessTokenURI)
+		case "client_credentials":
+			flows.ClientCredentials = newFlow("", ss.OAuth2.AccessTokenURI)
+		case "implicit":
+			flows.Implicit = newFlow(ss.OAuth2.AuthorizationURI, "")
+		default:
+			o.warn("authorization grant is not converted", ss.Location, ss.Position,
+				stacktrace.WithInfo("grant", grant))
+		}
+	}
+	return flows
+}
+
+// apiKey fills the API key security scheme from the only header or query parameter of the pass through scheme.
+func (o *OpenAPIConverter) apiKey(result *OpenAPISecurityScheme, ss *SecurityScheme) bool {
+	var headers, queryParameters *orderedmap.OrderedMap[string, Property]
+	if ss.DescribedBy != nil {
+		headers, queryParameters = ss.DescribedBy.Headers, ss.DescribedBy.QueryParameters
+	}
+	switch {
+	case headers.Len() == 1 && queryParameters.Len() == 0:
+		result.In, result.Name = "header", headers.Oldest().Key
+	case headers.Len() == 0 && queryParameters.Len() == 1:
+		result.In, result.Name = "query", queryParameters.Oldest().Key
+	default:
+		o.warn("pass through security scheme must describe exactly one header or query parameter",
+			ss.Location, ss.Position, stacktrace.WithInfo("securityScheme", ss.Name))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package raml
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIConverter_Convert(t *testing.T) {
+	common := `#%RAML 1.0 Library
+types:
+  Tag:
+    type: string
+    maxLength: 5
+`
+	tests := []struct {
+		name         string
+		content      string
+		want         []string
+		wantNot      []string
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name: "positive: resources, types and security schemes",
+			content: `#%RAML 1.0
+title: Pets
+version: v1
+baseUri: https://api.example.com/{version}/{region}
+baseUriParameters:
+  region:
+    enum: [eu, us]
+uses:
+  common: common.raml
+securitySchemes:
+  oauth:
+    type: OAuth 2.0
+    settings:
+      authorizationUri: https://auth.example.com/authorize
+      accessTokenUri: https://auth.example.com/token
+      authorizationGrants: [authorization_code, client_credentials]
+      scopes: [read, write]
+  basic:
+    type: Basic Authentication
+types:
+  Pet:
+    properties:
+      name: string
+      next?: Pet
+      tag?: common.Tag
+/pets:
+  securedBy: [oauth: {scopes: [read]}, null]
+  get:
+    queryParameters:
+      limit?:
+        type: integer
+        description: Page size.
+    responses:
+      200:
+        headers:
+          X-Total: integer
+        body:
+          application/json: Pet[]
+  post:
+    securedBy: [basic]
+    body:
+      application/json:
+        type: Pet
+        description: New pet.
+        examples:
+          rex:
+            displayName: Rex
+            value: {name: Rex}
+  /{id}:
+    put:
+      body:
+        application/json:
+          type: Pet
+          minProperties: 1
+`,
+			want: []string{
+				`"openapi":"3.1.0","info":{"title":"Pets","version":"v1"}`,
+				`"servers":[{"url":"https://api.example.com/v1/{region}","variables":{"region":{"enum":["eu","us"],"default":"eu"}}}]`,
+				`"parameters":[{"name":"limit","in":"query","description":"Page size.","schema":{"type":"integer","description":"Page size."}}]`,
+				`"headers":{"X-Total":{"required":true,"schema":{"type":"integer"}}}`,
+				`"schema":{"items":{"$ref":"#/components/schemas/Pet"},"type":"array"}`,
+				`"security":[{"oauth":["read"]},{}]`,
+				`"schema":{"$ref":"#/components/schemas/Pet","description":"New pet."},"examples":{"rex":{"summary":"Rex","value":{"name":"Rex"}}}`,
+				`"security":[{"basic":[]}]`,
+				`"/pets/{id}":{"parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"string"}}]`,
+				`"minProperties":1`,
+				`"Pet":{"properties":{"name":{"type":"string"},"next":{"$ref":"#/components/schemas/Pet"},"tag":{"$ref":"#/components/schemas/Tag"}}`,
+				`"Tag":{"type":"string","maxLength":5}`,
+				`"oauth":{"type":"oauth2","flows":{"clientCredentials":{"tokenUrl":"https://auth.example.com/token","scopes":{"read":"","write":""}},"authorizationCode":{"authorizationUrl":"https://auth.example.com/authorize","tokenUrl":"https://auth.example.com/token","scopes":{"read":"","write":""}}}}`,
+				`"basic":{"type":"http","scheme":"basic"}`,
+			},
+		},
+		{
+			name: "positive: lossy mappings are reported",
+			content: `#%RAML 1.0
+title: Pets
+annotationTypes:
+  internal: boolean
+securitySchemes:
+  legacy:
+    type: OAuth 1.0
+    settings:
+      requestTokenUri: https://auth.example.com/request
+      authorizationUri: https://auth.example.com/authorize
+      tokenCredentialsUri: https://auth.example.com/token
+  key:
+    type: Pass Through
+    describedBy:
+      headers:
+        X-Key: string
+resourceTypes:
+  collection:
+    get:
+      responses:
+        200:
+          body:
+            application/json: <<item>>[]
+traits:
+  paged:
+    queryParameters:
+      page?: integer
+types:
+  Pet:
+    (internal): true
+    facets:
+      kind?: string
+    properties:
+      name: string
+/pets:
+  type: { collection: { item: Pet } }
+  (internal): false
+  securedBy: [legacy, key]
+  get:
+    is: [paged]
+  delete:
+    queryString:
+      type: string
+`,
+			want: []string{
+				`"key":{"type":"apiKey","name":"X-Key","in":"header"}`,
+				`"security":[{"key":[]}]`,
+				`"info":{"title":"Pets","version":"unspecified"}`,
+			},
+			wantNot: []string{`"legacy"`},
+			wantWarnings: []string{
+				"api version is not declared",
+				"annotation is not converted",
+				"resource type is expanded into the resource",
+				"traits are expanded into the method",
+				"security scheme is not converted",
+				"query string that is not an object is not converted",
+			},
+		},
+		{
+			name: "negative: entry point must be an api",
+			content: `#%RAML 1.0 Library
+types:
+  Pet: object
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(common), 0o600))
+			r, err := ParseFromString(tt.content, "api.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+
+			conv := NewOpenAPIConverter(r)
+			doc, err := conv.Convert()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := json.Marshal(doc)
+			require.NoError(t, err)
+			got := string(b)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Convert() output does not contain %s:\n%s", want, got)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(got, wantNot) {
+					t.Errorf("Convert() output contains %s:\n%s", wantNot, got)
+				}
+			}
+			var warnings []string
+			for _, w := range conv.Warnings() {
+				warnings = append(warnings, w.Error())
+			}
+			for _, want := range tt.wantWarnings {
+				found := false
+				for _, w := range warnings {
+					if strings.Contains(w, want) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Warnings() do not contain %q: %v", want, warnings)
+				}
+			}
+			if len(tt.wantWarnings) == 0 && len(warnings) > 0 {
+				t.Errorf("Warnings() = %v, want none", warnings)
+			}
+		})
+	}
+}
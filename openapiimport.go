@@ -0,0 +1,1020 @@
+package raml
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// OpenAPITypesAlias is the alias of the types library in the API produced by OpenAPIImporter.
+const OpenAPITypesAlias = "types"
+
+// OpenAPITypesLibrary returns the path of the types library relative to the API located at location.
+// The name is derived from the API file name, e.g. "api-types.raml" for "api.raml".
+func OpenAPITypesLibrary(location string) string {
+	name := filepath.Base(location)
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "-types.raml"
+}
+
+// openAPIGrants maps OpenAPI OAuth 2.0 flows to RAML authorization grants.
+var openAPIGrants = []struct {
+	flow  string
+	grant string
+}{
+	{"authorizationCode", "authorization_code"},
+	{"implicit", "implicit"},
+	{"password", "password"},
+	{"clientCredentials", "client_credentials"},
+}
+
+// OpenAPIImporter converts OpenAPI 3.0 and 3.1 documents in JSON or YAML into RAML APIs.
+//
+// Schemas of components/schemas become types of a library that the API uses as OpenAPITypesAlias.
+// Schemas are converted by JSONSchemaImporter, OpenAPI 3.0 keywords such as "nullable" are converted
+// into their JSON Schema equivalents first. Paths become resources nested by common prefixes,
+// operations become methods and security schemes become RAML security schemes.
+// Specification extensions ("x-" keys) become annotations, annotation types are declared in the library.
+//
+// Use Encoder to write the API and the library. Features that cannot be represented in RAML,
+// such as cookie parameters, callbacks or default responses, are reported as warnings.
+type OpenAPIImporter struct {
+	raml *RAML
+
+	location string
+	root     *yaml.Node
+	schemas  *JSONSchemaImporter
+	api      *API
+	lib      *Library
+	// normalized contains schemas converted from OpenAPI 3.0 to JSON Schema.
+	normalized map[*yaml.Node]struct{}
+	legacy     bool
+	// resources contains resources by full paths.
+	resources map[string]*Resource
+	// parents contains the closest path that is a prefix of the path.
+	parents map[string]string
+	// extensions contains values of specification extensions by annotation names.
+	extensions *orderedmap.OrderedMap[string, []*yaml.Node]
+	// securitySchemes contains converted security schemes by names, nil for unsupported ones.
+	securitySchemes map[string]*SecurityScheme
+	warnings        []*stacktrace.StackTrace
+	// warned contains keys of reported warnings to avoid duplicates.
+	warned map[string]struct{}
+}
+
+func NewOpenAPIImporter(r *RAML) *OpenAPIImporter {
+	return &OpenAPIImporter{raml: r}
+}
+
+// Warnings returns warnings about OpenAPI features lost by the last import.
+func (im *OpenAPIImporter) Warnings() []*stacktrace.StackTrace {
+	return im.warnings
+}
+
+// Import converts the OpenAPI document into an API located at location.
+// The API uses the types library located at OpenAPITypesLibrary(location) relative to the API
+// unless the document declares neither schemas nor specification extensions.
+func (im *OpenAPIImporter) Import(data []byte, location string) (*API, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, StacktraceNewWrapped("decode openapi", err, location)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, StacktraceNew("openapi document must be an object", location)
+	}
+	root := doc.Content[0]
+	version := jsonSchemaKeyword(root, "openapi")
+	if version == nil || (!strings.HasPrefix(version.Value, "3.0") && !strings.HasPrefix(version.Value, "3.1")) {
+		return nil, StacktraceNew("unsupported openapi version", location, WithNodePosition(root))
+	}
+
+	im.location = location
+	im.root = root
+	im.legacy = strings.HasPrefix(version.Value, "3.0")
+	im.normalized = make(map[*yaml.Node]struct{})
+	im.resources = make(map[string]*Resource)
+	im.parents = make(map[string]string)
+	im.extensions = orderedmap.New[string, []*yaml.Node]()
+	im.securitySchemes = make(map[string]*SecurityScheme)
+	im.warnings = nil
+	im.warned = make(map[string]struct{})
+
+	if err := im.importTypes(); err != nil {
+		return nil, fmt.Errorf("import types: %w", err)
+	}
+	im.api = im.raml.MakeAPI(location)
+	if err := im.importInfo(); err != nil {
+		return nil, fmt.Errorf("import info: %w", err)
+	}
+	im.importServers()
+	if err := im.importSecuritySchemes(); err != nil {
+		return nil, fmt.Errorf("import security schemes: %w", err)
+	}
+	securedBy, err := im.securedBy(jsonSchemaKeyword(root, "security"))
+	if err != nil {
+		return nil, err
+	}
+	im.api.SecuredBy = securedBy
+	if err = im.importPaths(); err != nil {
+		return nil, fmt.Errorf("import paths: %w", err)
+	}
+	for _, key := range []string{"webhooks", "tags"} {
+		if n := jsonSchemaKeyword(root, key); n != nil {
+			im.warn(key+" are not converted", n)
+		}
+	}
+	if err = im.declareAnnotationTypes(); err != nil {
+		return nil, err
+	}
+	if im.lib.Types.Len() > 0 || im.lib.AnnotationTypes.Len() > 0 {
+		im.api.Uses.Set(OpenAPITypesAlias, &LibraryLink{
+			Value:    OpenAPITypesLibrary(location),
+			Link:     im.lib,
+			Location: location,
+		})
+	}
+	return im.api, nil
+}
+
+func (im *OpenAPIImporter) warn(msg string, node *yaml.Node, opts ...stacktrace.Option) {
+	pos := stacktrace.Position{Line: node.Line, Column: node.Column}
+	key := fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, msg)
+	if _, ok := im.warned[key]; ok {
+		return
+	}
+	im.warned[key] = struct{}{}
+	opts = append(opts, stacktrace.WithPosition(&pos))
+	im.warnings = append(im.warnings, StacktraceNew(msg, im.location, opts...))
+}
+
+// importTypes converts components/schemas into the types library.
+func (im *OpenAPIImporter) importTypes() error {
+	defs := &yaml.Node{Kind: yaml.MappingNode}
+	if schemas := resolveJSONPointer(im.root, "/components/schemas"); schemas != nil && schemas.Kind == yaml.MappingNode {
+		defs = schemas
+	}
+	for i := 1; i < len(defs.Content); i += 2 {
+		im.normalizeSchema(defs.Content[i])
+	}
+	dialect := "https://json-schema.org/draft/2020-12/schema"
+	if im.legacy {
+		// Keywords next to $ref are ignored in OpenAPI 3.0 as in JSON Schema draft-04.
+		dialect = "http://json-schema.org/draft-04/schema#"
+	}
+	// Schemas are imported as definitions of a JSON Schema document, so references to them become named types.
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		yamlString("$schema"), yamlString(dialect),
+		yamlString("$defs"), defs,
+	}}
+	im.schemas = NewJSONSchemaImporter(im.raml)
+	im.schemas.annotate = func(schema *yaml.Node, base *BaseShape) error {
+		return im.annotations(schema, base.CustomDomainProperties)
+	}
+	lib, err := im.schemas.importRoot(root, resolveLocation(im.location, OpenAPITypesLibrary(im.location)))
+	if err != nil {
+		return err
+	}
+	im.lib = lib
+	return nil
+}
+
+// normalizeSchema converts OpenAPI keywords of the schema and its subschemas into JSON Schema keywords
+// and rewrites references to components/schemas into references to definitions.
+//
+//nolint:gocognit,gocyclo,cyclop // Keywords are converted in one place to keep the order clear.
+func (im *OpenAPIImporter) normalizeSchema(schema *yaml.Node) {
+	if schema.Kind != yaml.MappingNode {
+		return
+	}
+	if _, ok := im.normalized[schema]; ok {
+		return
+	}
+	im.normalized[schema] = struct{}{}
+
+	var nullable bool
+	var minimum, maximum *yaml.Node
+	content := make([]*yaml.Node, 0, len(schema.Content))
+	for i := 0; i != len(schema.Content); i += 2 {
+		key, value := schema.Content[i], schema.Content[i+1]
+		switch key.Value {
+		case "$ref":
+			if name, found := strings.CutPrefix(value.Value, "#/components/schemas/"); found {
+				value.Value = "#/$defs/" + name
+			}
+		case "nullable":
+			if im.legacy {
+				nullable = value.Value == "true"
+				continue
+			}
+		case "example":
+			// The deprecated keyword is used only if there are no examples.
+			if jsonSchemaKeyword(schema, "examples") == nil {
+				key = yamlString("examples")
+				value = &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{value}}
+			}
+		case "minimum":
+			minimum = value
+		case "maximum":
+			maximum = value
+		case "properties", "patternProperties":
+			for j := 1; j < len(value.Content); j += 2 {
+				im.normalizeSchema(value.Content[j])
+			}
+		case "items", "additionalProperties", "not":
+			im.normalizeSchema(value)
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			for _, item := range value.Content {
+				im.normalizeSchema(item)
+			}
+		}
+		content = append(content, key, value)
+	}
+	schema.Content = content
+
+	if im.legacy {
+		// Boolean exclusive bounds of OpenAPI 3.0 replace the bounds.
+		im.normalizeExclusiveBound(schema, "exclusiveMinimum", "minimum", minimum)
+		im.normalizeExclusiveBound(schema, "exclusiveMaximum", "maximum", maximum)
+	}
+	if !nullable {
+		return
+	}
+	if t := jsonSchemaKeyword(schema, "type"); t != nil && t.Kind == yaml.ScalarNode {
+		*t = yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: []*yaml.Node{
+			yamlString(t.Value), yamlString(TypeNull),
+		}, Line: t.Line, Column: t.Column}
+		return
+	}
+	member := &yaml.Node{Kind: yaml.MappingNode, Content: schema.Content, Line: schema.Line, Column: schema.Column}
+	schema.Content = []*yaml.Node{
+		yamlString("anyOf"),
+		{Kind: yaml.SequenceNode, Content: []*yaml.Node{
+			member,
+			{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString("type"), yamlString(TypeNull)}},
+		}},
+	}
+}
+
+func (im *OpenAPIImporter) normalizeExclusiveBound(schema *yaml.Node, key string, boundKey string, bound *yaml.Node) {
+	for i := 0; i != len(schema.Content); i += 2 {
+		if schema.Content[i].Value != key || schema.Content[i+1].Tag != "!!bool" {
+			continue
+		}
+		if schema.Content[i+1].Value == "true" && bound != nil {
+			schema.Content[i+1] = bound
+			schema.Content = removeJSONSchemaKeyword(schema.Content, boundKey)
+		} else {
+			schema.Content = append(schema.Content[:i], schema.Content[i+2:]...)
+		}
+		return
+	}
+}
+
+func removeJSONSchemaKeyword(content []*yaml.Node, key string) []*yaml.Node {
+	for i := 0; i != len(content); i += 2 {
+		if content[i].Value == key {
+			return append(content[:i], content[i+2:]...)
+		}
+	}
+	return content
+}
+
+// importSchema converts the schema of a parameter or a body. Missing schemas allow any value of the fallback type.
+func (im *OpenAPIImporter) importSchema(
+	schema *yaml.Node, name string, hint string, fallback string,
+) (*BaseShape, error) {
+	if schema == nil {
+		base := im.raml.MakeBaseShape(name, im.location, stacktrace.Position{})
+		if _, err := im.raml.MakeConcreteShapeYAML(base, fallback, nil); err != nil {
+			return nil, err
+		}
+		return base, nil
+	}
+	im.normalizeSchema(schema)
+	base, err := im.schemas.importSchema(schema, name, hint)
+	if err != nil {
+		return nil, StacktraceNewWrapped("convert schema", err, im.location, WithNodePosition(schema))
+	}
+	return base, nil
+}
+
+// resolve follows references to components, e.g. "#/components/parameters/limit".
+func (im *OpenAPIImporter) resolve(node *yaml.Node) (*yaml.Node, error) {
+	visited := make(map[*yaml.Node]struct{})
+	for node != nil {
+		ref := jsonSchemaKeyword(node, "$ref")
+		if ref == nil {
+			return node, nil
+		}
+		if _, ok := visited[node]; ok {
+			return nil, StacktraceNew("circular reference", im.location, WithNodePosition(ref),
+				stacktrace.WithInfo("ref", ref.Value))
+		}
+		visited[node] = struct{}{}
+		pointer, found := strings.CutPrefix(ref.Value, "#")
+		if !found {
+			return nil, StacktraceNew("only local references are supported", im.location, WithNodePosition(ref),
+				stacktrace.WithInfo("ref", ref.Value))
+		}
+		next := resolveJSONPointer(im.root, pointer)
+		if next == nil {
+			return nil, StacktraceNew("unresolvable reference", im.location, WithNodePosition(ref),
+				stacktrace.WithInfo("ref", ref.Value))
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// annotations converts specification extensions of the node into annotations.
+// Annotations are defined by annotation types of the library, so the encoder qualifies their names as needed.
+func (im *OpenAPIImporter) annotations(
+	node *yaml.Node, annotations *orderedmap.OrderedMap[string, *DomainExtension],
+) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !strings.HasPrefix(key.Value, "x-") {
+			continue
+		}
+		name := openAPIAnnotationName(key.Value)
+		n, err := im.raml.makeYamlNode(value, im.location)
+		if err != nil {
+			return fmt.Errorf("make annotation node: %w", err)
+		}
+		annotations.Set(name, &DomainExtension{
+			Name:      name,
+			Extension: n,
+			DefinedBy: im.annotationType(name, value),
+			Location:  im.location,
+			Position:  stacktrace.Position{Line: key.Line, Column: key.Column},
+			raml:      im.raml,
+		})
+	}
+	return nil
+}
+
+// annotationNodes returns specification extensions of the node as annotations of raw declarations of the API.
+func (im *OpenAPIImporter) annotationNodes(node *yaml.Node) []*yaml.Node {
+	var nodes []*yaml.Node
+	for i := 0; i != len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !strings.HasPrefix(key.Value, "x-") {
+			continue
+		}
+		name := openAPIAnnotationName(key.Value)
+		im.annotationType(name, value)
+		nodes = append(nodes, yamlString("("+OpenAPITypesAlias+"."+name+")"), value)
+	}
+	return nodes
+}
+
+// annotationType returns the annotation type of the specification extension declaring it if needed.
+// The value is used to infer the type.
+func (im *OpenAPIImporter) annotationType(name string, value *yaml.Node) *BaseShape {
+	values, _ := im.extensions.Get(name)
+	im.extensions.Set(name, append(values, value))
+	if base, ok := im.schemas.lib.AnnotationTypes.Get(name); ok {
+		return base
+	}
+	pos := stacktrace.Position{Line: value.Line, Column: value.Column}
+	base := im.raml.MakeBaseShape(name, im.schemas.lib.Location, pos)
+	im.schemas.lib.AnnotationTypes.Set(name, base)
+	return base
+}
+
+// declareAnnotationTypes makes shapes of annotation types once all values are known.
+// Types are inferred from the scalar values, other values are allowed by the "any" type.
+func (im *OpenAPIImporter) declareAnnotationTypes() error {
+	for pair := im.schemas.lib.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+		values, _ := im.extensions.Get(pair.Key)
+		shapeType := ramlTypeOfJSONSchemaValues(values)
+		if shapeType == TypeNil {
+			shapeType = TypeAny
+		}
+		if _, err := im.raml.MakeConcreteShapeYAML(pair.Value, shapeType, nil); err != nil {
+			return StacktraceNewWrapped("make annotation type", err, im.location,
+				stacktrace.WithPosition(&pair.Value.Position), stacktrace.WithInfo("name", pair.Key))
+		}
+	}
+	return nil
+}
+
+// openAPIAnnotationName converts the name of the specification extension into the annotation name.
+func openAPIAnnotationName(key string) string {
+	var sb strings.Builder
+	for _, r := range strings.TrimPrefix(key, "x-") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "extension"
+	}
+	return sb.String()
+}
+
+func (im *OpenAPIImporter) importInfo() error {
+	if err := im.annotations(im.root, im.api.CustomDomainProperties); err != nil {
+		return err
+	}
+	info := jsonSchemaKeyword(im.root, "info")
+	if info == nil {
+		return StacktraceNew("info is required", im.location, WithNodePosition(im.root))
+	}
+	if title := jsonSchemaKeyword(info, "title"); title != nil {
+		im.api.Title = title.Value
+	}
+	if im.api.Title == "" {
+		return StacktraceNew("title is required", im.location, WithNodePosition(info))
+	}
+	if description := jsonSchemaKeyword(info, "description"); description != nil {
+		im.api.Description = description.Value
+	}
+	if version := jsonSchemaKeyword(info, "version"); version != nil {
+		im.api.Version = version.Value
+	}
+	for _, key := range []string{"termsOfService", "contact", "license"} {
+		if n := jsonSchemaKeyword(info, key); n != nil {
+			im.warn("info "+key+" is not converted", n)
+		}
+	}
+	if docs := jsonSchemaKeyword(im.root, "externalDocs"); docs != nil {
+		if u := jsonSchemaKeyword(docs, "url"); u != nil {
+			item := &DocumentationItem{
+				Title:    "External documentation",
+				Content:  u.Value,
+				Location: im.location,
+				Position: stacktrace.Position{Line: docs.Line, Column: docs.Column},
+			}
+			if description := jsonSchemaKeyword(docs, "description"); description != nil {
+				item.Content = fmt.Sprintf("[%s](%s)", description.Value, u.Value)
+			}
+			im.api.Documentation = append(im.api.Documentation, item)
+		}
+	}
+	return im.annotations(info, im.api.CustomDomainProperties)
+}
+
+// importServers converts the first server into the base URI. Server variables become base URI parameters.
+func (im *OpenAPIImporter) importServers() {
+	servers := jsonSchemaKeyword(im.root, "servers")
+	if servers == nil || servers.Kind != yaml.SequenceNode || len(servers.Content) == 0 {
+		return
+	}
+	if len(servers.Content) > 1 {
+		im.warn("only the first server is converted", servers.Content[1])
+	}
+	server := servers.Content[0]
+	if u := jsonSchemaKeyword(server, "url"); u != nil {
+		im.api.BaseURI = u.Value
+		if parsed, err := url.Parse(strings.NewReplacer("{", "", "}", "").Replace(u.Value)); err == nil &&
+			parsed.Scheme != "" {
+			im.api.Protocols = []string{strings.ToUpper(parsed.Scheme)}
+		}
+	}
+	variables := jsonSchemaKeyword(server, "variables")
+	if variables == nil || variables.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i != len(variables.Content); i += 2 {
+		name, variable := variables.Content[i].Value, variables.Content[i+1]
+		if name == "version" {
+			// The version parameter is provided by the version of the API.
+			continue
+		}
+		schema := &yaml.Node{Kind: yaml.MappingNode, Line: variable.Line, Column: variable.Column}
+		appendYAMLPair(schema, "type", yamlString(TypeString))
+		for _, key := range []string{"enum", "default", "description"} {
+			if n := jsonSchemaKeyword(variable, key); n != nil {
+				appendYAMLPair(schema, key, n)
+			}
+		}
+		base, err := im.importSchema(schema, name, ramlTypeName(name), TypeString)
+		if err != nil {
+			im.warn("server variable is not converted", variable, stacktrace.WithInfo("name", name))
+			continue
+		}
+		im.api.BaseURIParameters.Set(name, Property{Name: name, Base: base, Required: true, raml: im.raml})
+	}
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Security scheme types are converted in one place.
+func (im *OpenAPIImporter) importSecuritySchemes() error {
+	schemes := resolveJSONPointer(im.root, "/components/securitySchemes")
+	if schemes == nil || schemes.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i != len(schemes.Content); i += 2 {
+		name := schemes.Content[i].Value
+		scheme, err := im.resolve(schemes.Content[i+1])
+		if err != nil {
+			return err
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode, Line: scheme.Line, Column: scheme.Column}
+		describedBy := &yaml.Node{Kind: yaml.MappingNode}
+		settings := &yaml.Node{Kind: yaml.MappingNode}
+		var schemeType string
+		switch t := jsonSchemaKeyword(scheme, "type"); {
+		case t == nil:
+		case t.Value == "http":
+			switch s := strings.ToLower(valueOf(jsonSchemaKeyword(scheme, "scheme"))); s {
+			case "basic":
+				schemeType = SecuritySchemeBasic
+			case "digest":
+				schemeType = SecuritySchemeDigest
+			default:
+				// Other schemes, e.g. "bearer", pass credentials in the Authorization header.
+				schemeType = SecuritySchemePassThrough
+				appendYAMLPair(describedBy, "headers", &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+					yamlString("Authorization"), yamlString(TypeString),
+				}})
+			}
+		case t.Value == "apiKey":
+			in := valueOf(jsonSchemaKeyword(scheme, "in"))
+			key := map[string]string{"header": "headers", "query": "queryParameters"}[in]
+			if key == "" {
+				break
+			}
+			schemeType = SecuritySchemePassThrough
+			appendYAMLPair(describedBy, key, &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+				yamlString(valueOf(jsonSchemaKeyword(scheme, "name"))), yamlString(TypeString),
+			}})
+		case t.Value == "oauth2":
+			schemeType = SecuritySchemeOAuth2
+			im.oauth2Settings(settings, jsonSchemaKeyword(scheme, "flows"))
+			if jsonSchemaKeyword(settings, "accessTokenUri") == nil {
+				schemeType = ""
+			}
+		}
+		if schemeType == "" {
+			im.warn("security scheme is not converted", scheme, stacktrace.WithInfo("name", name))
+			im.securitySchemes[name] = nil
+			continue
+		}
+		appendYAMLPair(node, "type", yamlString(schemeType))
+		if description := jsonSchemaKeyword(scheme, "description"); description != nil {
+			appendYAMLPair(node, FacetDescription, yamlString(description.Value))
+		}
+		if len(describedBy.Content) > 0 {
+			appendYAMLPair(node, "describedBy", describedBy)
+		}
+		if len(settings.Content) > 0 {
+			appendYAMLPair(node, "settings", settings)
+		}
+		node.Content = append(node.Content, im.annotationNodes(scheme)...)
+		ss, err := im.raml.makeSecurityScheme(name, node, im.location)
+		if err != nil {
+			return fmt.Errorf("make security scheme %s: %w", name, err)
+		}
+		im.api.SecuritySchemes.Set(name, ss)
+		im.securitySchemes[name] = ss
+	}
+	return nil
+}
+
+// oauth2Settings converts OAuth 2.0 flows into settings. URIs are taken from the first flow that declares them.
+func (im *OpenAPIImporter) oauth2Settings(settings *yaml.Node, flows *yaml.Node) {
+	if flows == nil {
+		return
+	}
+	var authorizationURI, accessTokenURI string
+	grants := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	scopes := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	seen := make(map[string]struct{})
+	for _, g := range openAPIGrants {
+		flow := jsonSchemaKeyword(flows, g.flow)
+		if flow == nil {
+			continue
+		}
+		grants.Content = append(grants.Content, yamlString(g.grant))
+		if u := jsonSchemaKeyword(flow, "authorizationUrl"); u != nil && authorizationURI == "" {
+			authorizationURI = u.Value
+		}
+		if u := jsonSchemaKeyword(flow, "tokenUrl"); u != nil && accessTokenURI == "" {
+			accessTokenURI = u.Value
+		}
+		if s := jsonSchemaKeyword(flow, "scopes"); s != nil {
+			for i := 0; i < len(s.Content); i += 2 {
+				if _, ok := seen[s.Content[i].Value]; !ok {
+					seen[s.Content[i].Value] = struct{}{}
+					scopes.Content = append(scopes.Content, yamlString(s.Content[i].Value))
+				}
+			}
+		}
+	}
+	if accessTokenURI == "" && authorizationURI != "" {
+		// RAML requires the token URI even for the implicit grant.
+		im.warn("authorization url is used as the access token uri", flows)
+		accessTokenURI = authorizationURI
+	}
+	if authorizationURI != "" {
+		appendYAMLPair(settings, "authorizationUri", yamlString(authorizationURI))
+	}
+	if accessTokenURI != "" {
+		appendYAMLPair(settings, "accessTokenUri", yamlString(accessTokenURI))
+	}
+	if len(grants.Content) > 0 {
+		appendYAMLPair(settings, "authorizationGrants", grants)
+	}
+	if len(scopes.Content) > 0 {
+		appendYAMLPair(settings, "scopes", scopes)
+	}
+}
+
+// securedBy converts security requirements. Requirements that combine several schemes cannot be represented.
+func (im *OpenAPIImporter) securedBy(security *yaml.Node) ([]*SecurityRequirement, error) {
+	if security == nil || security.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+	requirements := make([]*SecurityRequirement, 0, len(security.Content))
+	for _, item := range security.Content {
+		pos := stacktrace.Position{Line: item.Line, Column: item.Column}
+		switch {
+		case len(item.Content) == 0:
+			requirements = append(requirements, &SecurityRequirement{Location: im.location, Position: pos})
+			continue
+		case len(item.Content) > 2:
+			im.warn("security requirement with several schemes is not converted", item)
+			continue
+		}
+		name := item.Content[0].Value
+		ss, ok := im.securitySchemes[name]
+		if !ok {
+			return nil, StacktraceNew("security scheme not found", im.location, WithNodePosition(item),
+				stacktrace.WithInfo("name", name))
+		}
+		if ss == nil {
+			continue
+		}
+		req := &SecurityRequirement{Name: name, Scheme: ss, Location: im.location, Position: pos}
+		if scopes := item.Content[1]; ss.Type == SecuritySchemeOAuth2 && len(scopes.Content) > 0 {
+			values := make([]any, 0, len(scopes.Content))
+			for _, scope := range scopes.Content {
+				values = append(values, scope.Value)
+			}
+			req.Parameters = map[string]any{"scopes": values}
+		}
+		requirements = append(requirements, req)
+	}
+	return requirements, nil
+}
+
+// importPaths converts paths into resources. Resources are nested into resources of the closest paths
+// that are their prefixes, so "/pets/{id}" becomes the "/{id}" resource of the "/pets" resource.
+func (im *OpenAPIImporter) importPaths() error {
+	paths := jsonSchemaKeyword(im.root, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(paths.Content)/2)
+	for i := 0; i != len(paths.Content); i += 2 {
+		keys = append(keys, paths.Content[i].Value)
+	}
+	for _, path := range keys {
+		for _, prefix := range keys {
+			if prefix != "/" && strings.HasPrefix(path, prefix+"/") && len(prefix) > len(im.parents[path]) {
+				im.parents[path] = prefix
+			}
+		}
+	}
+	for i := 0; i != len(paths.Content); i += 2 {
+		path := paths.Content[i].Value
+		item, err := im.resolve(paths.Content[i+1])
+		if err != nil {
+			return err
+		}
+		if err = im.importPath(path, item); err != nil {
+			return fmt.Errorf("import path %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// resource returns the resource of the path creating it and its parents if needed.
+func (im *OpenAPIImporter) resource(path string, node *yaml.Node) *Resource {
+	if res, ok := im.resources[path]; ok {
+		return res
+	}
+	var parent *Resource
+	resources := im.api.Resources
+	uri := path
+	if parentPath, ok := im.parents[path]; ok {
+		parent = im.resource(parentPath, node)
+		resources = parent.Resources
+		uri = strings.TrimPrefix(path, parentPath)
+	}
+	res, _ := im.raml.makeResource(uri, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Line: node.Line,
+		Column: node.Column}, im.location, parent, nil)
+	resources.Set(uri, res)
+	im.resources[path] = res
+	return res
+}
+
+func (im *OpenAPIImporter) importPath(path string, item *yaml.Node) error {
+	res := im.resource(path, item)
+	if summary := jsonSchemaKeyword(item, "summary"); summary != nil {
+		res.DisplayName = summary.Value
+	}
+	if description := jsonSchemaKeyword(item, "description"); description != nil {
+		res.Description = description.Value
+	}
+	if err := im.annotations(item, res.CustomDomainProperties); err != nil {
+		return err
+	}
+	for _, key := range []string{"servers", "trace"} {
+		if n := jsonSchemaKeyword(item, key); n != nil {
+			im.warn(key+" of path is not converted", n)
+		}
+	}
+	for i := 0; i != len(item.Content); i += 2 {
+		name := item.Content[i].Value
+		if _, ok := SetOfMethods[name]; !ok {
+			continue
+		}
+		if err := im.importOperation(path, res, name, item, item.Content[i+1]); err != nil {
+			return fmt.Errorf("import operation %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+//nolint:gocognit,gocyclo,cyclop,funlen // Operation fields are converted in one place.
+func (im *OpenAPIImporter) importOperation(path string, res *Resource, name string, item, op *yaml.Node) error {
+	m, _ := im.raml.makeMethod(name, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagNull, Line: op.Line,
+		Column: op.Column}, im.location, res, nil)
+	hint := ramlTypeName(name + " " + path)
+	if operationID := jsonSchemaKeyword(op, "operationId"); operationID != nil {
+		m.DisplayName = operationID.Value
+		hint = ramlTypeName(operationID.Value)
+	}
+	if summary := jsonSchemaKeyword(op, "summary"); summary != nil {
+		m.DisplayName = summary.Value
+	}
+	if description := jsonSchemaKeyword(op, "description"); description != nil {
+		m.Description = description.Value
+	}
+	if err := im.annotations(op, m.CustomDomainProperties); err != nil {
+		return err
+	}
+	for _, key := range []string{"callbacks", "servers"} {
+		if n := jsonSchemaKeyword(op, key); n != nil {
+			im.warn(key+" of operation are not converted", n)
+		}
+	}
+
+	// Parameters of the operation override parameters of the path with the same name and location.
+	params := orderedmap.New[string, *yaml.Node]()
+	for _, parent := range []*yaml.Node{item, op} {
+		list := jsonSchemaKeyword(parent, "parameters")
+		if list == nil {
+			continue
+		}
+		for _, p := range list.Content {
+			param, err := im.resolve(p)
+			if err != nil {
+				return err
+			}
+			params.Set(valueOf(jsonSchemaKeyword(param, "in"))+":"+valueOf(jsonSchemaKeyword(param, "name")), param)
+		}
+	}
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		param := pair.Value
+		paramName := valueOf(jsonSchemaKeyword(param, "name"))
+		required := valueOf(jsonSchemaKeyword(param, "required")) == "true"
+		var target *orderedmap.OrderedMap[string, Property]
+		switch in := valueOf(jsonSchemaKeyword(param, "in")); in {
+		case "query":
+			target = m.QueryParameters
+		case "header":
+			target = m.Headers
+		case "path":
+			target = im.uriParametersOf(res, paramName)
+			if target == nil {
+				im.warn("path parameter is not used by the path", param, stacktrace.WithInfo("name", paramName))
+				continue
+			}
+			if _, ok := target.Get(paramName); ok {
+				continue
+			}
+			required = true
+		default:
+			im.warn("parameter is not converted", param, stacktrace.WithInfo("name", paramName),
+				stacktrace.WithInfo("in", in))
+			continue
+		}
+		prop, err := im.parameter(paramName, param, required, hint+ramlTypeName(paramName))
+		if err != nil {
+			return fmt.Errorf("import parameter %s: %w", paramName, err)
+		}
+		target.Set(paramName, prop)
+	}
+
+	if requestBody := jsonSchemaKeyword(op, "requestBody"); requestBody != nil {
+		body, err := im.resolve(requestBody)
+		if err != nil {
+			return err
+		}
+		if m.Body, err = im.content(jsonSchemaKeyword(body, "content"), hint+"Request"); err != nil {
+			return fmt.Errorf("import request body: %w", err)
+		}
+	}
+	if responses := jsonSchemaKeyword(op, "responses"); responses != nil {
+		for i := 0; i != len(responses.Content); i += 2 {
+			code := responses.Content[i]
+			if n, err := strconv.Atoi(code.Value); err != nil || n < 100 || n > 599 {
+				im.warn("response is not converted", code, stacktrace.WithInfo("code", code.Value))
+				continue
+			}
+			resp, err := im.response(code, responses.Content[i+1], hint)
+			if err != nil {
+				return fmt.Errorf("import response %s: %w", code.Value, err)
+			}
+			m.Responses.Set(code.Value, resp)
+		}
+	}
+
+	m.SecuredBy = im.api.SecuredBy
+	if security := jsonSchemaKeyword(op, "security"); security != nil {
+		securedBy, err := im.securedBy(security)
+		if err != nil {
+			return err
+		}
+		m.SecuredBy = securedBy
+	}
+	res.Methods.Set(name, m)
+	return nil
+}
+
+// uriParametersOf returns URI parameters of the resource or its parent that declares the parameter in its URI.
+func (im *OpenAPIImporter) uriParametersOf(res *Resource, name string) *orderedmap.OrderedMap[string, Property] {
+	for ; res != nil; res = res.Parent {
+		if strings.Contains(res.URI, "{"+name+"}") {
+			return res.URIParameters
+		}
+	}
+	return nil
+}
+
+// parameter converts the parameter or the header.
+// Parameters described by content use the schema of the first media type.
+func (im *OpenAPIImporter) parameter(name string, param *yaml.Node, required bool, hint string) (Property, error) {
+	schema := jsonSchemaKeyword(param, "schema")
+	if content := jsonSchemaKeyword(param, "content"); schema == nil && content != nil && len(content.Content) > 0 {
+		schema = jsonSchemaKeyword(content.Content[1], "schema")
+	}
+	base, err := im.importSchema(schema, name, hint, TypeString)
+	if err != nil {
+		return Property{}, err
+	}
+	if description := jsonSchemaKeyword(param, "description"); description != nil {
+		desc := description.Value
+		base.Description = &desc
+	}
+	if err = im.examples(param, base); err != nil {
+		return Property{}, err
+	}
+	if err = im.annotations(param, base.CustomDomainProperties); err != nil {
+		return Property{}, err
+	}
+	return Property{Name: name, Base: base, Required: required, raml: im.raml}, nil
+}
+
+// examples converts "example" and "examples" of parameters and media types into examples of the shape.
+func (im *OpenAPIImporter) examples(node *yaml.Node, base *BaseShape) error {
+	if example := jsonSchemaKeyword(node, "example"); example != nil {
+		ex, err := im.schemas.makeExample(example, "")
+		if err != nil {
+			return err
+		}
+		base.Example = ex
+		base.Examples = nil
+		return nil
+	}
+	examples := jsonSchemaKeyword(node, "examples")
+	if examples == nil || examples.Kind != yaml.MappingNode || len(examples.Content) == 0 {
+		return nil
+	}
+	base.Example = nil
+	base.Examples = &Examples{
+		Map:      orderedmap.New[string, *Example](len(examples.Content) / 2),
+		Location: im.location,
+		Position: stacktrace.Position{Line: examples.Line, Column: examples.Column},
+	}
+	for i := 0; i != len(examples.Content); i += 2 {
+		name := examples.Content[i].Value
+		example, err := im.resolve(examples.Content[i+1])
+		if err != nil {
+			return err
+		}
+		value := jsonSchemaKeyword(example, "value")
+		if value == nil {
+			im.warn("example without value is not converted", example, stacktrace.WithInfo("name", name))
+			continue
+		}
+		ex, err := im.schemas.makeExample(value, name)
+		if err != nil {
+			return err
+		}
+		if summary := jsonSchemaKeyword(example, "summary"); summary != nil {
+			ex.DisplayName = summary.Value
+		}
+		if description := jsonSchemaKeyword(example, "description"); description != nil {
+			ex.Description = description.Value
+		}
+		base.Examples.Map.Set(name, ex)
+	}
+	return nil
+}
+
+// content converts media types into bodies.
+func (im *OpenAPIImporter) content(content *yaml.Node, hint string) (*orderedmap.OrderedMap[string, *Body], error) {
+	bodies := orderedmap.New[string, *Body]()
+	if content == nil {
+		return bodies, nil
+	}
+	for i := 0; i != len(content.Content); i += 2 {
+		mediaType, value := content.Content[i], content.Content[i+1]
+		base, err := im.importSchema(jsonSchemaKeyword(value, "schema"), "body", hint, TypeAny)
+		if err != nil {
+			return nil, fmt.Errorf("import body %s: %w", mediaType.Value, err)
+		}
+		if err = im.examples(value, base); err != nil {
+			return nil, err
+		}
+		if err = im.annotations(value, base.CustomDomainProperties); err != nil {
+			return nil, err
+		}
+		if encoding := jsonSchemaKeyword(value, "encoding"); encoding != nil {
+			im.warn("encoding of media type is not converted", encoding)
+		}
+		bodies.Set(mediaType.Value, &Body{
+			MediaType: mediaType.Value,
+			Shape:     base,
+			Location:  im.location,
+			Position:  stacktrace.Position{Line: mediaType.Line, Column: mediaType.Column},
+		})
+	}
+	return bodies, nil
+}
+
+func (im *OpenAPIImporter) response(code *yaml.Node, value *yaml.Node, hint string) (*Response, error) {
+	node, err := im.resolve(value)
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{
+		Code:                   code.Value,
+		Headers:                orderedmap.New[string, Property](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Location:               im.location,
+		Position:               stacktrace.Position{Line: code.Line, Column: code.Column},
+		raml:                   im.raml,
+	}
+	if description := jsonSchemaKeyword(node, "description"); description != nil {
+		resp.Description = description.Value
+	}
+	if err = im.annotations(node, resp.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	if links := jsonSchemaKeyword(node, "links"); links != nil {
+		im.warn("links of response are not converted", links)
+	}
+	if headers := jsonSchemaKeyword(node, "headers"); headers != nil {
+		for i := 0; i != len(headers.Content); i += 2 {
+			name := headers.Content[i].Value
+			header, errHeader := im.resolve(headers.Content[i+1])
+			if errHeader != nil {
+				return nil, errHeader
+			}
+			required := valueOf(jsonSchemaKeyword(header, "required")) == "true"
+			prop, errHeader := im.parameter(name, header, required, hint+"Response"+code.Value+ramlTypeName(name))
+			if errHeader != nil {
+				return nil, fmt.Errorf("import header %s: %w", name, errHeader)
+			}
+			resp.Headers.Set(name, prop)
+		}
+	}
+	if resp.Body, err = im.content(jsonSchemaKeyword(node, "content"), hint+"Response"+code.Value); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// valueOf returns the value of the scalar node or an empty string if the node is missing.
+func valueOf(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.Value
+}
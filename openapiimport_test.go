@@ -0,0 +1,275 @@
+package raml
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIImporter_Import(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantAPI      []string
+		wantTypes    []string
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name: "positive: openapi 3.0 in yaml",
+			content: `openapi: 3.0.3
+info:
+  title: Pets
+  version: v1
+  x-audience: public
+servers:
+  - url: https://{region}.example.com/v1
+    variables:
+      region:
+        enum: [eu, us]
+        default: eu
+  - url: https://example.com
+security:
+  - token: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      x-internal: true
+      parameters:
+        - $ref: '#/components/parameters/limit'
+        - name: session
+          in: cookie
+          schema:
+            type: string
+      responses:
+        '200':
+          description: Pets.
+          headers:
+            X-Total:
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default:
+          description: Error.
+    post:
+      security:
+        - oauth: [write]
+        - {}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+            example:
+              name: Rex
+      responses:
+        '201':
+          description: Created.
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    delete:
+      responses:
+        '204':
+          description: Deleted.
+components:
+  parameters:
+    limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        minimum: 1
+        exclusiveMinimum: true
+  schemas:
+    Pet:
+      type: object
+      x-entity: pet
+      required: [name]
+      properties:
+        name:
+          type: string
+        tag:
+          type: string
+          nullable: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+  securitySchemes:
+    token:
+      type: http
+      scheme: bearer
+    oauth:
+      type: oauth2
+      flows:
+        authorizationCode:
+          authorizationUrl: https://example.com/authorize
+          tokenUrl: https://example.com/token
+          scopes:
+            write: Write access.
+`,
+			wantAPI: []string{
+				"title: Pets\nversion: v1\nbaseUri: https://{region}.example.com/v1\n",
+				"baseUriParameters:\n  region:\n    type: string\n    enum:\n      - eu\n      - us\n    default: eu\n",
+				"protocols: [HTTPS]\n",
+				"(types.audience): public\n",
+				"uses:\n  types: api-types.raml\n",
+				"securitySchemes:\n  token:\n    type: Pass Through\n",
+				"authorizationGrants: [authorization_code]",
+				"securedBy: [token]\n/pets:\n",
+				"  get:\n    displayName: listPets\n    (types.internal): true\n",
+				"      limit:\n        type: integer\n        minimum: 2\n        required: false\n",
+				"        headers:\n          X-Total:\n            type: integer\n            required: false\n",
+				"          application/json:\n            type: array\n            items: types.Pet\n",
+				"securedBy: [{oauth: {scopes: [write]}}, null]",
+				"      application/json:\n        type: types.Pet\n        example:\n          name: Rex\n",
+				"  /{id}:\n    uriParameters:\n      id: integer\n    delete:\n",
+			},
+			wantTypes: []string{
+				"annotationTypes:\n  entity: string\n  audience: string\n  internal: boolean\n",
+				"  Pet:\n    type: object\n    properties:\n      name: string\n      tag:\n        type: string | nil\n        required: false\n",
+				"      owner:\n        type: Owner\n        required: false\n    (entity): pet\n",
+			},
+			wantWarnings: []string{
+				"only the first server is converted",
+				"parameter is not converted",
+				"response is not converted",
+			},
+		},
+		{
+			name: "positive: openapi 3.1 in json",
+			content: `{
+  "openapi": "3.1.0",
+  "info": {"title": "Pets", "version": "1.0"},
+  "paths": {
+    "/pets/{id}/tags": {
+      "get": {
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {
+            "description": "Tags.",
+            "content": {"application/json": {"schema": {"anyOf": [{"type": "string", "minLength": 1}, {"type": "null"}]}}}
+          }
+        }
+      }
+    }
+  }
+}`,
+			wantAPI: []string{
+				"/pets/{id}/tags:\n  uriParameters:\n    id: string\n  get:\n",
+				"application/json: types.GetPetsIdTagsResponse2001 | nil\n",
+			},
+			wantTypes: []string{
+				"types:\n  GetPetsIdTagsResponse2001:\n    type: string\n    minLength: 1\n",
+			},
+		},
+		{
+			name: "positive: openapi without types",
+			content: `openapi: 3.1.0
+info:
+  title: Health
+  version: v1
+paths:
+  /health:
+    get:
+      responses:
+        '204':
+          description: Healthy.
+`,
+			wantAPI: []string{
+				"title: Health\nversion: v1\n/health:\n  get:\n    responses:\n      \"204\":\n",
+			},
+		},
+		{
+			name:    "negative: swagger 2.0",
+			content: "swagger: '2.0'\ninfo:\n  title: Pets\n  version: v1\n",
+			wantErr: true,
+		},
+		{
+			name: "negative: unresolvable reference",
+			content: `openapi: 3.1.0
+info:
+  title: Pets
+  version: v1
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/missing'
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			location := filepath.Join(dir, "api.raml")
+			im := NewOpenAPIImporter(New(context.Background()))
+			api, err := im.Import([]byte(tt.content), location)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Import() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			var apiBuf, typesBuf bytes.Buffer
+			require.NoError(t, NewEncoder(&apiBuf).EncodeAPI(api))
+			// The library is omitted when the document has no types.
+			lib, ok := api.Uses.Get(OpenAPITypesAlias)
+			require.Equal(t, len(tt.wantTypes) > 0, ok)
+			if ok {
+				require.Equal(t, "api-types.raml", lib.Value)
+				require.NoError(t, NewEncoder(&typesBuf).EncodeLibrary(lib.Link))
+			} else {
+				require.NotContains(t, apiBuf.String(), "uses:")
+			}
+			for _, want := range tt.wantAPI {
+				if !strings.Contains(apiBuf.String(), want) {
+					t.Errorf("API does not contain %q:\n%s", want, apiBuf.String())
+				}
+			}
+			for _, want := range tt.wantTypes {
+				if !strings.Contains(typesBuf.String(), want) {
+					t.Errorf("library does not contain %q:\n%s", want, typesBuf.String())
+				}
+			}
+			var warnings []string
+			for _, w := range im.Warnings() {
+				warnings = append(warnings, w.Message)
+			}
+			for _, want := range tt.wantWarnings {
+				if !strings.Contains(strings.Join(warnings, "\n"), want) {
+					t.Errorf("Warnings() = %v, want %q", warnings, want)
+				}
+			}
+
+			// Imported API must be parsed and validated successfully.
+			require.NoError(t, os.WriteFile(location, apiBuf.Bytes(), 0o600))
+			if ok {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, lib.Value), typesBuf.Bytes(), 0o600))
+			}
+			_, err = ParseFromPath(location, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err, apiBuf.String()+typesBuf.String())
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay and Extension documents are merged into the master API document on YAML level.
+// The merged document is decoded afterwards as a regular API located at the path of the master API.
+// Merged nodes keep locations of the documents they are declared in, so that errors and relative includes
+// of the merged content refer to the original documents.
+
+// SetOfOverlayNodes contains a set of nodes that an overlay is allowed to add or override.
+var SetOfOverlayNodes = map[string]struct{}{
+	"title": {}, "displayName": {}, FacetDescription: {}, "documentation": {}, "usage": {},
+	"example": {}, "examples": {}, "annotationTypes": {},
+}
+
+// isOverlayNode returns true if the node can be added or overridden by an overlay.
+func isOverlayNode(key string) bool {
+	if _, ok := SetOfOverlayNodes[key]; ok {
+		return true
+	}
+	return IsCustomDomainExtensionNode(key)
+}
+
+// isOpaqueNode returns true if the value of the node must be replaced as a whole instead of being merged.
+func isOpaqueNode(key string) bool {
+	return key == "example" || IsCustomDomainExtensionNode(key)
+}
+
+// decodeExtension merges the overlay or extension with its master API and decodes the result as API.
+func (r *RAML) decodeExtension(f io.Reader, path string, kind FragmentKind) (*API, error) {
+	root, apiPath, err := r.mergeExtensionDocument(f, path, kind, make(map[string]struct{}))
+	if err != nil {
+		return nil, fmt.Errorf("merge extension document: %w", err)
+	}
+	// Merged content references declarations of the merged API from the locations it is declared in.
+	var aliases []string
+	seen := make(map[string]struct{})
+	for _, location := range r.mergedLocations {
+		if _, ok := seen[location]; !ok {
+			seen[location] = struct{}{}
+			aliases = append(aliases, location)
+		}
+	}
+	api, err := r.decodeAPINode(root, apiPath, aliases...)
+	if err != nil {
+		return nil, fmt.Errorf("decode api node: %w", err)
+	}
+	return api, nil
+}
+
+// mergeExtensionDocument merges the overlay or extension document with the master document it extends.
+// It returns the merged root node and the path of the master API document.
+func (r *RAML) mergeExtensionDocument(
+	f io.Reader,
+	path string,
+	kind FragmentKind,
+	visited map[string]struct{},
+) (*yaml.Node, string, error) {
+	visited[path] = struct{}{}
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, "", StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, "", StacktraceNew("extension document must be map", path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	root := doc.Content[0]
+	extends := findMappingValue(root, "extends")
+	if extends == nil || extends.Kind != yaml.ScalarNode || extends.Value == "" {
+		return nil, "", StacktraceNew("extends is required", path, WithNodePosition(root),
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	masterPath := resolveLocation(path, extends.Value)
+	if _, ok := visited[masterPath]; ok {
+		return nil, "", StacktraceNew("circular extends detected", path, WithNodePosition(extends),
+			stacktrace.WithInfo("extends", extends.Value), stacktrace.WithType(StacktraceTypeParsing))
+	}
+	masterRoot, apiPath, err := r.loadMasterDocument(masterPath, visited)
+	if err != nil {
+		return nil, "", StacktraceNewWrapped("load master document", err, path, WithNodePosition(extends),
+			stacktrace.WithInfo("extends", extends.Value), stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	// Relative library paths in the extension must point to the same files after merging into the master.
+	rebaseUses(root, path, apiPath)
+	r.bindMergedLocation(root, path)
+	m := &extensionMerger{raml: r, kind: kind, location: path, masterLocation: apiPath}
+	if err = m.mergeRoot(masterRoot, root); err != nil {
+		return nil, "", fmt.Errorf("merge root: %w", err)
+	}
+	return masterRoot, apiPath, nil
+}
+
+// loadMasterDocument loads the document extended by overlay or extension.
+// In case the master itself is an overlay or extension, it is merged with its own master first.
+func (r *RAML) loadMasterDocument(path string, visited map[string]struct{}) (*yaml.Node, string, error) {
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, "", StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	head, err := ReadHead(f)
+	if err != nil {
+		return nil, "", StacktraceNewWrapped("read head", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+	kind, err := IdentifyFragment(head)
+	if err != nil {
+		return nil, "", StacktraceNewWrapped("identify fragment", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+	switch kind {
+	case FragmentAPI:
+		var doc yaml.Node
+		if err = yaml.NewDecoder(f).Decode(&doc); err != nil {
+			return nil, "", StacktraceNewWrapped("decode fragment", err, path,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			return nil, "", StacktraceNew("api document must be map", path,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		return doc.Content[0], path, nil
+	case FragmentOverlay, FragmentExtension:
+		return r.mergeExtensionDocument(f, path, kind, visited)
+	default:
+		return nil, "", StacktraceNew("master document must be api, overlay or extension", path,
+			stacktrace.WithInfo("head", head), stacktrace.WithType(StacktraceTypeParsing))
+	}
+}
+
+// rebaseUses rewrites relative library paths of the node declared at the location from
+// to be relative to the location to. References of remote documents are rewritten to absolute URLs.
+func rebaseUses(node *yaml.Node, from string, to string) {
+	fromDir, toDir := filepath.Dir(from), filepath.Dir(to)
+	if fromDir == toDir {
+		return
+	}
+	rebase := func(n *yaml.Node) {
+		if filepath.IsAbs(n.Value) || strings.Contains(n.Value, "://") {
+			return
+		}
+		if isRemoteLocation(from) {
+			n.Value = resolveLocation(from, n.Value)
+			return
+		}
+		rel, err := filepath.Rel(toDir, filepath.Join(fromDir, n.Value))
+		if err != nil {
+			return
+		}
+		n.Value = rel
+	}
+	if uses := findMappingValue(node, "uses"); uses != nil && uses.Kind == yaml.MappingNode {
+		for i := 1; i < len(uses.Content); i += 2 {
+			rebase(uses.Content[i])
+		}
+	}
+}
+
+// bindMergedLocation binds the node and its descendants to the location of the document they are declared in.
+func (r *RAML) bindMergedLocation(node *yaml.Node, location string) {
+	if r.mergedLocations == nil {
+		r.mergedLocations = make(map[*yaml.Node]string)
+	}
+	r.mergedLocations[node] = location
+	for _, c := range node.Content {
+		r.bindMergedLocation(c, location)
+	}
+}
+
+// mergedLocation returns the location of the document the node was merged from or the default location.
+func (r *RAML) mergedLocation(node *yaml.Node, location string) string {
+	if loc, ok := r.mergedLocations[node]; ok {
+		return loc
+	}
+	return location
+}
+
+// includedLocation returns the location of the document included by the node.
+// Relative paths are resolved against the document the node is declared in.
+func (r *RAML) includedLocation(node *yaml.Node, location string) string {
+	return resolveLocation(r.templateLocation(node, location), node.Value)
+}
+
+// extensionMerger applies the RAML merging algorithm of overlay or extension onto the master document.
+type extensionMerger struct {
+	raml     *RAML
+	kind     FragmentKind
+	location string
+	// masterLocation is the location of the master API, relative includes of the master are resolved against it.
+	masterLocation string
+}
+
+func (m *extensionMerger) isOverlay() bool {
+	return m.kind == FragmentOverlay
+}
+
+// mergeRoot merges the root node of the extension into the root node of the master.
+func (m *extensionMerger) mergeRoot(dst *yaml.Node, src *yaml.Node) error {
+	var st *stacktrace.StackTrace
+	for i := 0; i != len(src.Content); i += 2 {
+		key := src.Content[i]
+		value := src.Content[i+1]
+		var err error
+		switch key.Value {
+		case "extends", "usage":
+			// Ignored properties are not merged into the master.
+			continue
+		case "uses":
+			err = m.mergeUses(dst, key, value)
+		default:
+			err = m.mergeProperty(dst, key, value, isOverlayNode(key.Value))
+		}
+		if err != nil {
+			se := StacktraceNewWrapped("merge node", err, m.location, WithNodePosition(key),
+				stacktrace.WithInfo("node", key.Value), stacktrace.WithType(StacktraceTypeParsing))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	if st != nil {
+		return st
+	}
+	return nil
+}
+
+// mergeUses adds libraries used by the extension to the master.
+func (m *extensionMerger) mergeUses(dst *yaml.Node, key *yaml.Node, value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return StacktraceNew("uses must be map", m.location, WithNodePosition(value))
+	}
+	uses := findMappingValue(dst, "uses")
+	if uses == nil || uses.Kind != yaml.MappingNode {
+		dst.Content = append(dst.Content, key, value)
+		return nil
+	}
+	for i := 0; i != len(value.Content); i += 2 {
+		name := value.Content[i]
+		path := value.Content[i+1]
+		existing := findMappingValue(uses, name.Value)
+		if existing == nil {
+			uses.Content = append(uses.Content, name, path)
+			continue
+		}
+		if filepath.Clean(existing.Value) != filepath.Clean(path.Value) {
+			return StacktraceNew("library namespace is already used by master", m.location, WithNodePosition(name),
+				stacktrace.WithInfo("namespace", name.Value), stacktrace.WithInfo("path", existing.Value))
+		}
+	}
+	return nil
+}
+
+// mergeProperty merges the property of the extension into the master map node.
+func (m *extensionMerger) mergeProperty(dst *yaml.Node, key *yaml.Node, value *yaml.Node, allowed bool) error {
+	idx := -1
+	for i := 0; i != len(dst.Content); i += 2 {
+		if dst.Content[i].Value == key.Value {
+			idx = i + 1
+			break
+		}
+	}
+	if idx == -1 {
+		if m.isOverlay() && !allowed {
+			return StacktraceNew("overlay cannot add node", m.location, WithNodePosition(key),
+				stacktrace.WithInfo("node", key.Value))
+		}
+		dst.Content = append(dst.Content, key, value)
+		return nil
+	}
+	target := dst.Content[idx]
+	if isOpaqueNode(key.Value) {
+		dst.Content[idx] = value
+		return nil
+	}
+	// Included content of the master is merged as if it was declared inline.
+	if target.Kind == yaml.ScalarNode && target.Tag == TagInclude &&
+		(value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) {
+		included, err := m.readIncludedNode(target)
+		if err != nil {
+			return fmt.Errorf("read included node: %w", err)
+		}
+		target = included
+		dst.Content[idx] = target
+	}
+	// Null values in master are treated as empty maps, e.g. methods without a body.
+	if target.Kind == yaml.ScalarNode && target.Tag == TagNull && value.Kind == yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: target.Line, Column: target.Column}
+		dst.Content[idx] = target
+	}
+	switch {
+	case target.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+		return m.mergeMap(target, value, allowed)
+	case target.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+		return m.mergeSequence(target, value, allowed)
+	default:
+		if m.isOverlay() && !allowed && !isSameScalar(target, value) {
+			return StacktraceNew("overlay cannot override node", m.location, WithNodePosition(value),
+				stacktrace.WithInfo("node", key.Value))
+		}
+		dst.Content[idx] = value
+		return nil
+	}
+}
+
+// readIncludedNode reads the content included by the master node.
+// Included nodes are bound to the location of the included document.
+func (m *extensionMerger) readIncludedNode(node *yaml.Node) (*yaml.Node, error) {
+	location := m.raml.mergedLocation(node, m.masterLocation)
+	path := resolveLocation(location, node.Value)
+	f, err := m.raml.readRawFile(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("read raw file", err, location, WithNodePosition(node),
+			stacktrace.WithInfo("path", path), stacktrace.WithType(StacktraceTypeLoading))
+	}
+	defer func(f io.ReadCloser) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	var doc yaml.Node
+	if err = yaml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, StacktraceNewWrapped("decode included content", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	if len(doc.Content) == 0 {
+		return nil, StacktraceNew("included content is empty", path, stacktrace.WithType(StacktraceTypeParsing))
+	}
+	included := doc.Content[0]
+	// Libraries of the included fragment are not in scope of the master API.
+	if uses := findMappingValue(included, "uses"); uses != nil {
+		return nil, StacktraceNew("included fragment with uses cannot be merged", path, WithNodePosition(uses),
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	m.raml.bindMergedLocation(included, path)
+	return included, nil
+}
+
+// mergeMap merges the map node of the extension into the master map node.
+func (m *extensionMerger) mergeMap(dst *yaml.Node, src *yaml.Node, allowed bool) error {
+	for i := 0; i != len(src.Content); i += 2 {
+		key := src.Content[i]
+		if err := m.mergeProperty(dst, key, src.Content[i+1], allowed || isOverlayNode(key.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeSequence appends items of the extension sequence that are not present in the master sequence.
+func (m *extensionMerger) mergeSequence(dst *yaml.Node, src *yaml.Node, allowed bool) error {
+	for _, item := range src.Content {
+		found := false
+		if item.Kind == yaml.ScalarNode {
+			for _, existing := range dst.Content {
+				if isSameScalar(existing, item) {
+					found = true
+					break
+				}
+			}
+		}
+		if found {
+			continue
+		}
+		if m.isOverlay() && !allowed {
+			return StacktraceNew("overlay cannot add sequence item", m.location, WithNodePosition(item))
+		}
+		dst.Content = append(dst.Content, item)
+	}
+	return nil
+}
+
+func isSameScalar(a *yaml.Node, b *yaml.Node) bool {
+	return a.Kind == yaml.ScalarNode && b.Kind == yaml.ScalarNode && a.Tag == b.Tag && a.Value == b.Value
+}
@@ -0,0 +1,150 @@
+package raml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_ParseExtension(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/extensions/admin_extension.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+	require.Equal(t, "Public API", api.Title)
+	require.Contains(t, api.Location, "api.raml")
+
+	_, ok = api.Uses.Get("admin")
+	require.True(t, ok)
+
+	users, ok := api.Resources.Get("/users")
+	require.True(t, ok)
+	get, ok := users.Methods.Get("get")
+	require.True(t, ok)
+	// Query parameters of the master are preserved and the ones of the extension are added.
+	require.Equal(t, 2, get.QueryParameters.Len())
+	_, ok = get.QueryParameters.Get("includeDisabled")
+	require.True(t, ok)
+
+	user, ok := users.Resources.Get("/{userId}")
+	require.True(t, ok)
+	_, ok = user.Methods.Get("get")
+	require.True(t, ok)
+	_, ok = user.Methods.Get("delete")
+	require.True(t, ok)
+
+	audit, ok := api.Resources.Get("/audit")
+	require.True(t, ok)
+	get, ok = audit.Methods.Get("get")
+	require.True(t, ok)
+	resp, ok := get.Responses.Get("200")
+	require.True(t, ok)
+	body, ok := resp.Body.Get("application/json")
+	require.True(t, ok)
+	_, ok = body.Shape.Shape.(*ArrayShape)
+	require.True(t, ok)
+	// Merged content keeps the location of the extension it is declared in.
+	require.Contains(t, get.Location, "admin_extension.raml")
+	require.Contains(t, body.Shape.Location, "admin_extension.raml")
+}
+
+func TestAPI_ParseExtensionIncludes(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/extensions/included/ext/extension.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+
+	// Properties of the extension are merged into the type included by the master.
+	user, ok := api.Types.Get("User")
+	require.True(t, ok)
+	obj, ok := user.Shape.(*ObjectShape)
+	require.True(t, ok)
+	for _, name := range []string{"id", "address", "email"} {
+		_, ok = obj.Properties.Get(name)
+		require.True(t, ok, name)
+	}
+	email, _ := obj.Properties.Get("email")
+	require.Contains(t, email.Base.Location, "ext/extension.raml")
+
+	// Includes of the extension are resolved relative to the extension.
+	items, ok := api.Resources.Get("/items")
+	require.True(t, ok)
+	get, ok := items.Methods.Get("get")
+	require.True(t, ok)
+	resp, ok := get.Responses.Get("200")
+	require.True(t, ok)
+	body, ok := resp.Body.Get("application/json")
+	require.True(t, ok)
+	item, ok := body.Shape.Shape.(*ObjectShape)
+	require.True(t, ok)
+	_, ok = item.Properties.Get("sku")
+	require.True(t, ok)
+}
+
+func TestAPI_ParseOverlay(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		check   func(t *testing.T, api *API)
+		wantErr string
+	}{
+		{
+			name: "positive: override descriptions",
+			path: "./fixtures/extensions/overlay_i18n.raml",
+			check: func(t *testing.T, api *API) {
+				require.Equal(t, "Öffentliche API", api.Title)
+				users, ok := api.Resources.Get("/users")
+				require.True(t, ok)
+				require.Equal(t, "Benutzer des Dienstes.", users.Description)
+				get, ok := users.Methods.Get("get")
+				require.True(t, ok)
+				require.Equal(t, "Benutzer auflisten", get.DisplayName)
+			},
+		},
+		{
+			name: "positive: overlay of extension",
+			path: "./fixtures/extensions/overlay_chained.raml",
+			check: func(t *testing.T, api *API) {
+				audit, ok := api.Resources.Get("/audit")
+				require.True(t, ok)
+				require.Equal(t, "Audit log.", audit.Description)
+			},
+		},
+		{
+			name:    "negative: overlay changes behavior",
+			path:    "./fixtures/extensions/overlay_invalid.raml",
+			wantErr: "overlay cannot",
+		},
+		{
+			name: "positive: overlay of included content",
+			path: "./fixtures/extensions/included/overlay.raml",
+			check: func(t *testing.T, api *API) {
+				user, ok := api.Types.Get("User")
+				require.True(t, ok)
+				require.Equal(t, "A user of the service.", *user.Description)
+			},
+		},
+		{
+			name:    "negative: error location in extension",
+			path:    "./fixtures/extensions/extension_invalid.raml",
+			wantErr: "extension_invalid.raml:7:7: invalid status code",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rml, err := ParseFromPath(tt.path, OptWithUnwrap(), OptWithValidate())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseFromPath() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			api, ok := rml.EntryPoint().(*API)
+			require.True(t, ok)
+			tt.check(t, api)
+		})
+	}
+}
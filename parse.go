@@ -2,17 +2,21 @@ package raml
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
 // ReadHead reads, reset file and returns the trimmed first line of a file.
@@ -35,20 +39,51 @@ func ReadHead(f io.ReadSeeker) (string, error) {
 // IdentifyFragment returns the kind of the fragment by its head.
 func IdentifyFragment(head string) (FragmentKind, error) {
 	switch head {
+	case "#%RAML 1.0":
+		return FragmentAPI, nil
 	case "#%RAML 1.0 Library":
 		return FragmentLibrary, nil
 	case "#%RAML 1.0 DataType":
 		return FragmentDataType, nil
 	case "#%RAML 1.0 NamedExample":
 		return FragmentNamedExample, nil
+	case "#%RAML 1.0 Trait":
+		return FragmentTrait, nil
+	case "#%RAML 1.0 ResourceType":
+		return FragmentResourceType, nil
+	case "#%RAML 1.0 SecurityScheme":
+		return FragmentSecurityScheme, nil
+	case "#%RAML 1.0 AnnotationTypeDeclaration":
+		return FragmentAnnotationTypeDeclaration, nil
+	case "#%RAML 1.0 DocumentationItem":
+		return FragmentDocumentationItem, nil
+	case "#%RAML 1.0 Overlay":
+		return FragmentOverlay, nil
+	case "#%RAML 1.0 Extension":
+		return FragmentExtension, nil
 	default:
 		return FragmentUnknown, fmt.Errorf("unknown fragment kind: head: %s", head)
 	}
 }
 
-// ReadRawFile reads a file
+// ReadRawFile reads a file from the OS file system.
 func ReadRawFile(path string) (io.ReadCloser, error) {
-	f, err := openFragmentFile(path)
+	return readRawFile(nil, path)
+}
+
+// readRawFile reads a file from the file system the RAML is parsed from.
+func (r *RAML) readRawFile(path string) (io.ReadCloser, error) {
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	return f, nil
+}
+
+func readRawFile(fsys fs.FS, path string) (io.ReadCloser, error) {
+	f, err := openFragmentFile(fsys, path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("open fragment file", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
@@ -85,10 +120,10 @@ func (r *RAML) decodeDataType(f io.Reader, path string) (*DataType, error) {
 
 	r.PutFragment(path, dt)
 
-	baseDir := filepath.Dir(dt.Location)
+	r.prefetchLibraries(dt.Location, dt.Uses)
 	for pair := dt.Uses.Oldest(); pair != nil; pair = pair.Next() {
 		include := pair.Value
-		sublib, err := r.parseLibrary(filepath.Join(baseDir, include.Value))
+		sublib, err := r.parseLibrary(resolveLocation(dt.Location, include.Value))
 		if err != nil {
 			return nil, StacktraceNewWrapped("parse library", err, dt.Location,
 				stacktrace.WithType(StacktraceTypeParsing))
@@ -100,6 +135,10 @@ func (r *RAML) decodeDataType(f io.Reader, path string) (*DataType, error) {
 }
 
 func CheckFragmentKind(f *os.File, kind FragmentKind) error {
+	return checkFragmentKind(f, kind)
+}
+
+func checkFragmentKind(f fragmentFile, kind FragmentKind) error {
 	// Allow JSON data types.
 	if kind == FragmentDataType && strings.HasSuffix(f.Name(), ".json") {
 		return nil
@@ -134,20 +173,20 @@ func (r *RAML) parseDataType(path string) (*DataType, error) {
 		return dt.(*DataType), nil
 	}
 
-	f, err := openFragmentFile(path)
+	f, err := r.openFragment(path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("open fragment file", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
 	}
 
-	defer func(f *os.File) {
+	defer func(f fragmentFile) {
 		err = f.Close()
 		if err != nil {
 			log.Fatalf("close file error: %v", err)
 		}
 	}(f)
 
-	if err = CheckFragmentKind(f, FragmentDataType); err != nil {
+	if err = checkFragmentKind(f, FragmentDataType); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
 	}
@@ -161,7 +200,45 @@ func (r *RAML) parseDataType(path string) (*DataType, error) {
 	return dt, nil
 }
 
-func openFragmentFile(path string) (*os.File, error) {
+// fragmentFile is an opened fragment file. Name returns the location of the fragment.
+type fragmentFile interface {
+	io.ReadSeekCloser
+	Name() string
+}
+
+// memFragmentFile is a fragment file read into memory from fs.FS or a remote location.
+// Files of fs.FS are read into memory since they are not required to implement io.Seeker.
+type memFragmentFile struct {
+	*bytes.Reader
+	name string
+}
+
+func (f *memFragmentFile) Name() string { return f.name }
+
+func (f *memFragmentFile) Close() error { return nil }
+
+// openFragment opens the fragment file at the local or remote location.
+func (r *RAML) openFragment(location string) (fragmentFile, error) {
+	if isRemoteLocation(location) {
+		return r.openRemoteFragment(location)
+	}
+	return openFragmentFile(r.fsys, location)
+}
+
+// openFragmentFile opens the fragment file from fsys or from the OS file system if fsys is nil.
+// Paths in fsys are slash-separated and relative to its root, a leading slash is ignored.
+func openFragmentFile(fsys fs.FS, path string) (fragmentFile, error) {
+	if fsys != nil {
+		name := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+		if name == "" {
+			name = "."
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		return &memFragmentFile{Reader: bytes.NewReader(data), name: filepath.Clean(path)}, nil
+	}
 	// TODO: Maybe fragments should be loaded against specified base URI.
 	// If base URI is not specified, use current workdir.
 	if !filepath.IsAbs(path) {
@@ -181,35 +258,27 @@ func openFragmentFile(path string) (*os.File, error) {
 func (r *RAML) decodeLibrary(f io.Reader, path string) (*Library, error) {
 	decoder := yaml.NewDecoder(f)
 
-	lib := r.MakeLibrary(path)
-	if err := decoder.Decode(&lib); err != nil {
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err != nil {
 		return nil, StacktraceNewWrapped("decode fragment", err, path,
 			stacktrace.WithType(StacktraceTypeParsing))
 	}
+	return r.decodeLibraryNode(&doc, path)
+}
 
-	var st *stacktrace.StackTrace
+// decodeLibraryNode decodes the library from the given node.
+func (r *RAML) decodeLibraryNode(node *yaml.Node, path string) (*Library, error) {
+	lib := r.MakeLibrary(path)
+	if err := node.Decode(&lib); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
 
 	r.PutFragment(path, lib)
 
 	// Resolve included libraries in a separate stage.
-	baseDir := filepath.Dir(lib.Location)
-	for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
-		include := pair.Value
-
-		sublib, err := r.parseLibrary(filepath.Join(baseDir, include.Value))
-		if err != nil {
-			se := StacktraceNewWrapped("parse uses library", err, path,
-				stacktrace.WithType(StacktraceTypeParsing), stacktrace.WithPosition(&include.Position))
-			if st == nil {
-				st = se
-			} else {
-				st = st.Append(se)
-			}
-		}
-		include.Link = sublib
-	}
-	if st != nil {
-		return nil, st
+	if err := r.parseFragmentUses(lib.Uses, path); err != nil {
+		return nil, err
 	}
 	return lib, nil
 }
@@ -227,20 +296,29 @@ func (r *RAML) parseLibrary(path string) (*Library, error) {
 		return lib.(*Library), nil
 	}
 
-	f, err := openFragmentFile(path)
+	if doc := r.takePrefetched(path); doc != nil {
+		lib, errDecode := r.decodeLibraryNode(doc, path)
+		if errDecode != nil {
+			return nil, StacktraceNewWrapped("decode library", errDecode, path,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		return lib, nil
+	}
+
+	f, err := r.openFragment(path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("open fragment file", err, path,
 			stacktrace.WithType(StacktraceTypeLoading))
 	}
 
-	defer func(f *os.File) {
+	defer func(f fragmentFile) {
 		err = f.Close()
 		if err != nil {
 			log.Fatalf("close file error: %v", err)
 		}
 	}(f)
 
-	if err = CheckFragmentKind(f, FragmentLibrary); err != nil {
+	if err = checkFragmentKind(f, FragmentLibrary); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
 	}
@@ -254,6 +332,336 @@ func (r *RAML) parseLibrary(path string) (*Library, error) {
 	return lib, nil
 }
 
+func (r *RAML) decodeAPI(f io.Reader, path string) (*API, error) {
+	decoder := yaml.NewDecoder(f)
+
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return r.decodeAPINode(&doc, path)
+}
+
+// decodeAPINode decodes the API document from the given node.
+// The API is also registered at the alias locations of documents merged into the node.
+func (r *RAML) decodeAPINode(node *yaml.Node, path string, aliases ...string) (*API, error) {
+	api := r.MakeAPI(path)
+	if err := node.Decode(&api); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, api)
+	for _, alias := range aliases {
+		r.PutFragment(alias, api)
+	}
+
+	// Resolve included libraries in a separate stage.
+	if err := r.parseFragmentUses(api.Uses, path); err != nil {
+		return nil, err
+	}
+	if err := api.unmarshalResources(); err != nil {
+		return nil, StacktraceNewWrapped("unmarshal resources", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return api, nil
+}
+
+// parseFragmentUses parses libraries referenced by "uses" of the fragment at the given location.
+func (r *RAML) parseFragmentUses(uses *orderedmap.OrderedMap[string, *LibraryLink], location string) error {
+	var st *stacktrace.StackTrace
+	r.prefetchLibraries(location, uses)
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		include := pair.Value
+
+		sublib, err := r.parseLibrary(resolveLocation(location, include.Value))
+		if err != nil {
+			se := StacktraceNewWrapped("parse uses library", err, location,
+				stacktrace.WithType(StacktraceTypeParsing), stacktrace.WithPosition(&include.Position))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+		include.Link = sublib
+	}
+	if st != nil {
+		return st
+	}
+	return nil
+}
+
+func (r *RAML) decodeTrait(f io.Reader, path string) (*TraitFragment, error) {
+	decoder := yaml.NewDecoder(f)
+
+	tf := r.MakeTraitFragment(path)
+	if err := decoder.Decode(&tf); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, tf)
+
+	if err := r.parseFragmentUses(tf.Uses, path); err != nil {
+		return nil, fmt.Errorf("parse fragment uses: %w", err)
+	}
+	return tf, nil
+}
+
+func (r *RAML) parseTrait(path string) (*TraitFragment, error) {
+	if tf := r.GetFragment(path); tf != nil {
+		t, ok := tf.(*TraitFragment)
+		if !ok {
+			return nil, StacktraceNew("fragment is not a trait", path, stacktrace.WithType(StacktraceTypeLoading))
+		}
+		return t, nil
+	}
+
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	if err = checkFragmentKind(f, FragmentTrait); err != nil {
+		return nil, StacktraceNewWrapped("check fragment kind", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	tf, err := r.decodeTrait(f, path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("decode trait", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return tf, nil
+}
+
+func (r *RAML) decodeResourceType(f io.Reader, path string) (*ResourceTypeFragment, error) {
+	decoder := yaml.NewDecoder(f)
+
+	rf := r.MakeResourceTypeFragment(path)
+	if err := decoder.Decode(&rf); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, rf)
+
+	if err := r.parseFragmentUses(rf.Uses, path); err != nil {
+		return nil, fmt.Errorf("parse fragment uses: %w", err)
+	}
+	return rf, nil
+}
+
+func (r *RAML) parseResourceType(path string) (*ResourceTypeFragment, error) {
+	if rf := r.GetFragment(path); rf != nil {
+		rt, ok := rf.(*ResourceTypeFragment)
+		if !ok {
+			return nil, StacktraceNew("fragment is not a resource type", path,
+				stacktrace.WithType(StacktraceTypeLoading))
+		}
+		return rt, nil
+	}
+
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	if err = checkFragmentKind(f, FragmentResourceType); err != nil {
+		return nil, StacktraceNewWrapped("check fragment kind", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	rf, err := r.decodeResourceType(f, path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("decode resource type", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return rf, nil
+}
+
+func (r *RAML) decodeSecurityScheme(f io.Reader, path string) (*SecuritySchemeFragment, error) {
+	decoder := yaml.NewDecoder(f)
+
+	sf := r.MakeSecuritySchemeFragment(path)
+	if err := decoder.Decode(&sf); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, sf)
+
+	if err := r.parseFragmentUses(sf.Uses, path); err != nil {
+		return nil, fmt.Errorf("parse fragment uses: %w", err)
+	}
+	return sf, nil
+}
+
+func (r *RAML) parseSecurityScheme(path string) (*SecuritySchemeFragment, error) {
+	if sf := r.GetFragment(path); sf != nil {
+		ss, ok := sf.(*SecuritySchemeFragment)
+		if !ok {
+			return nil, StacktraceNew("fragment is not a security scheme", path,
+				stacktrace.WithType(StacktraceTypeLoading))
+		}
+		return ss, nil
+	}
+
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	if err = checkFragmentKind(f, FragmentSecurityScheme); err != nil {
+		return nil, StacktraceNewWrapped("check fragment kind", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	sf, err := r.decodeSecurityScheme(f, path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("decode security scheme", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return sf, nil
+}
+
+func (r *RAML) decodeAnnotationTypeDeclaration(f io.Reader, path string) (*AnnotationTypeDeclaration, error) {
+	decoder := yaml.NewDecoder(f)
+
+	atd := r.MakeAnnotationTypeDeclaration(path)
+	if err := decoder.Decode(&atd); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, atd)
+
+	if err := r.parseFragmentUses(atd.Uses, path); err != nil {
+		return nil, fmt.Errorf("parse fragment uses: %w", err)
+	}
+	return atd, nil
+}
+
+func (r *RAML) parseAnnotationTypeDeclaration(path string) (*AnnotationTypeDeclaration, error) {
+	if frag := r.GetFragment(path); frag != nil {
+		atd, ok := frag.(*AnnotationTypeDeclaration)
+		if !ok {
+			return nil, StacktraceNew("fragment is not an annotation type declaration", path,
+				stacktrace.WithType(StacktraceTypeLoading))
+		}
+		return atd, nil
+	}
+
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	if err = checkFragmentKind(f, FragmentAnnotationTypeDeclaration); err != nil {
+		return nil, StacktraceNewWrapped("check fragment kind", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	atd, err := r.decodeAnnotationTypeDeclaration(f, path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("decode annotation type declaration", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return atd, nil
+}
+
+func (r *RAML) decodeDocumentationItem(f io.Reader, path string) (*DocumentationItem, error) {
+	decoder := yaml.NewDecoder(f)
+
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, StacktraceNewWrapped("decode fragment", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	if len(doc.Content) == 0 {
+		return nil, StacktraceNew("empty document", path, stacktrace.WithType(StacktraceTypeParsing))
+	}
+	di, err := r.makeDocumentationItem(doc.Content[0], path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("make documentation item", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+
+	r.PutFragment(path, di)
+
+	return di, nil
+}
+
+func (r *RAML) parseDocumentationItem(path string) (*DocumentationItem, error) {
+	if frag := r.GetFragment(path); frag != nil {
+		di, ok := frag.(*DocumentationItem)
+		if !ok {
+			return nil, StacktraceNew("fragment is not a documentation item", path,
+				stacktrace.WithType(StacktraceTypeLoading))
+		}
+		return di, nil
+	}
+
+	f, err := r.openFragment(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("open fragment file", err, path,
+			stacktrace.WithType(StacktraceTypeLoading))
+	}
+
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+
+	if err = checkFragmentKind(f, FragmentDocumentationItem); err != nil {
+		return nil, StacktraceNewWrapped("check fragment kind", err, path,
+			stacktrace.WithType(StacktraceTypeReading))
+	}
+
+	di, err := r.decodeDocumentationItem(f, path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("decode documentation item", err, path,
+			stacktrace.WithType(StacktraceTypeParsing))
+	}
+	return di, nil
+}
+
 func (r *RAML) decodeNamedExample(f io.Reader, path string) (*NamedExample, error) {
 	decoder := yaml.NewDecoder(f)
 
@@ -277,19 +685,19 @@ func (r *RAML) parseNamedExample(path string) (*NamedExample, error) {
 		return lib.(*NamedExample), nil
 	}
 
-	f, err := openFragmentFile(path)
+	f, err := r.openFragment(path)
 	if err != nil {
 		return nil, fmt.Errorf("open fragment file: %w", err)
 	}
 
-	defer func(f *os.File) {
+	defer func(f fragmentFile) {
 		err = f.Close()
 		if err != nil {
 			log.Fatalf("close file error: %v", err)
 		}
 	}(f)
 
-	if err = CheckFragmentKind(f, FragmentNamedExample); err != nil {
+	if err = checkFragmentKind(f, FragmentNamedExample); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
 	}
@@ -307,18 +715,18 @@ func (r *RAML) ParseFromPath(path string, opts ...ParseOpt) error {
 	// Library paths must be normalized to simplify dependent libraries resolution.
 	// Convert rel to abs relative to current workdir if necessary.
 
-	pOpts := &parserOptions{}
-	for _, opt := range opts {
-		opt.Apply(pOpts)
-	}
+	pOpts := newParserOptions(opts...)
+	r.fsys = pOpts.fsys
+	r.remote = pOpts.remote
+	r.workers = pOpts.workers
 
-	f, err := openFragmentFile(path)
+	f, err := r.openFragment(path)
 	if err != nil {
 		return StacktraceNewWrapped("open fragment file", err, path,
 			stacktrace.WithType(StacktraceTypeReading))
 	}
 
-	defer func(f *os.File) {
+	defer func(f fragmentFile) {
 		err = f.Close()
 		if err != nil {
 			log.Fatalf("close file error: %v", err)
@@ -329,10 +737,10 @@ func (r *RAML) ParseFromPath(path string, opts ...ParseOpt) error {
 }
 
 func (r *RAML) ParseFromString(content string, fileName string, baseDir string, opts ...ParseOpt) error {
-	pOpts := &parserOptions{}
-	for _, opt := range opts {
-		opt.Apply(pOpts)
-	}
+	pOpts := newParserOptions(opts...)
+	r.fsys = pOpts.fsys
+	r.remote = pOpts.remote
+	r.workers = pOpts.workers
 
 	f := strings.NewReader(content)
 
@@ -375,6 +783,55 @@ func (r *RAML) parseFragment(f io.ReadSeeker, fragmentPath string, pOpts *parser
 				stacktrace.WithType(StacktraceTypeParsing))
 		}
 		r.SetEntryPoint(ne)
+	case FragmentAPI:
+		api, errDecode := r.decodeAPI(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse api", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(api)
+	case FragmentOverlay, FragmentExtension:
+		api, errDecode := r.decodeExtension(f, fragmentPath, frag)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse extension", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(api)
+	case FragmentTrait:
+		tf, errDecode := r.decodeTrait(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse trait", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(tf)
+	case FragmentResourceType:
+		rf, errDecode := r.decodeResourceType(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse resource type", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(rf)
+	case FragmentSecurityScheme:
+		sf, errDecode := r.decodeSecurityScheme(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse security scheme", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(sf)
+	case FragmentAnnotationTypeDeclaration:
+		atd, errDecode := r.decodeAnnotationTypeDeclaration(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse annotation type declaration", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(atd)
+	case FragmentDocumentationItem:
+		di, errDecode := r.decodeDocumentationItem(f, fragmentPath)
+		if errDecode != nil {
+			return StacktraceNewWrapped("parse documentation item", errDecode, fragmentPath,
+				stacktrace.WithType(StacktraceTypeParsing))
+		}
+		r.SetEntryPoint(di)
 	default:
 		return StacktraceNew("unknown fragment kind", fragmentPath,
 			stacktrace.WithInfo("head", head), stacktrace.WithType(StacktraceTypeParsing))
@@ -437,7 +894,9 @@ func ParseFromStringCtx(
 
 func ParseFromString(content string, fileName string, baseDir string, opts ...ParseOpt) (*RAML, error) {
 	// TODO: Probably needs to be a bit more flexible. Maybe baseDir must be defined as parser option?
-	if !filepath.IsAbs(baseDir) {
+	pOpts := newParserOptions(opts...)
+	// Paths of fs.FS are relative to its root.
+	if pOpts.fsys == nil && !filepath.IsAbs(baseDir) {
 		return nil, fmt.Errorf("baseDir must be an absolute path")
 	}
 	return ParseFromStringCtx(context.Background(), content, fileName, baseDir, opts...)
@@ -446,6 +905,17 @@ func ParseFromString(content string, fileName string, baseDir string, opts ...Pa
 type parserOptions struct {
 	withUnwrapOpt   bool
 	withValidateOpt bool
+	fsys            fs.FS
+	remote          *HTTPResolver
+	workers         int
+}
+
+func newParserOptions(opts ...ParseOpt) *parserOptions {
+	pOpts := &parserOptions{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt.Apply(pOpts)
+	}
+	return pOpts
 }
 
 type ParseOpt interface {
@@ -471,3 +941,41 @@ func (parseOptWithValidate) Apply(opt *parserOptions) {
 func OptWithValidate() ParseOpt {
 	return parseOptWithValidate{}
 }
+
+type parseOptWithFS struct{ fsys fs.FS }
+
+func (o parseOptWithFS) Apply(opt *parserOptions) {
+	opt.fsys = o.fsys
+}
+
+// OptWithFS makes the parser read the entry point, includes and libraries from fsys instead of the OS file system,
+// e.g. from embed.FS, fstest.MapFS or zip.Reader. Paths are slash-separated and relative to the root of fsys,
+// so ParseFromString accepts a relative base directory with this option.
+func OptWithFS(fsys fs.FS) ParseOpt {
+	return parseOptWithFS{fsys}
+}
+
+type parseOptWithHTTPResolver struct{ res *HTTPResolver }
+
+func (o parseOptWithHTTPResolver) Apply(opt *parserOptions) {
+	opt.remote = o.res
+}
+
+// OptWithHTTPResolver enables includes and libraries referenced by http(s) URLs and fetches them with res.
+// Relative references in remote fragments are resolved relative to the URLs of the fragments.
+// Without this option, remote references fail to resolve.
+func OptWithHTTPResolver(res *HTTPResolver) ParseOpt {
+	return parseOptWithHTTPResolver{res}
+}
+
+type parseOptWithWorkers struct{ n int }
+
+func (o parseOptWithWorkers) Apply(opt *parserOptions) {
+	opt.workers = o.n
+}
+
+// OptWithWorkers sets the maximum number of libraries that are read and decoded concurrently.
+// Defaults to GOMAXPROCS, values less than 2 make the parser load libraries sequentially.
+func OptWithWorkers(n int) ParseOpt {
+	return parseOptWithWorkers{n}
+}
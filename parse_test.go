@@ -5,11 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/require"
@@ -59,6 +64,10 @@ func Test_ParseFixturesIntegration(t *testing.T) {
 			name: "named_example.raml",
 			path: "./fixtures/named_example.raml",
 		},
+		{
+			name: "json_schema.raml",
+			path: "./fixtures/json_schema.raml",
+		},
 		{
 			name: "other_lib.raml",
 			path: "./fixtures/other_lib.raml",
@@ -71,6 +80,46 @@ func Test_ParseFixturesIntegration(t *testing.T) {
 			name: "common.raml",
 			path: "./fixtures/common.raml",
 		},
+		{
+			name: "api.raml",
+			path: "./fixtures/api.raml",
+		},
+		{
+			name: "api_crud.raml",
+			path: "./fixtures/api_crud.raml",
+		},
+		{
+			name: "api_traits.raml",
+			path: "./fixtures/api_traits.raml",
+		},
+		{
+			name: "traits_lib.raml",
+			path: "./fixtures/traits_lib.raml",
+		},
+		{
+			name: "api_http.raml",
+			path: "./fixtures/api_http.raml",
+		},
+		{
+			name: "api_security.raml",
+			path: "./fixtures/api_security.raml",
+		},
+		{
+			name: "fragments_api.raml",
+			path: "./fixtures/fragments/api.raml",
+		},
+		{
+			name: "extensions_admin_extension.raml",
+			path: "./fixtures/extensions/admin_extension.raml",
+		},
+		{
+			name: "extensions_overlay_i18n.raml",
+			path: "./fixtures/extensions/overlay_i18n.raml",
+		},
+		{
+			name: "extensions_overlay_chained.raml",
+			path: "./fixtures/extensions/overlay_chained.raml",
+		},
 	}
 
 	for _, tt := range validTests {
@@ -125,6 +174,15 @@ func Test_ParseFixturesIntegration(t *testing.T) {
 					writeToDiskHelper(t, struct{name string; typeName string; outSchema *JSONSchemaRAML}{name: tt.name, typeName: typeName, outSchema: outSchema})
 					require.NoError(t, err, "Failed to convert data type shape in %s: %v", tt.path, err)
 					convertedCount++
+				case *API:
+					for pair := f.Types.Oldest(); pair != nil; pair = pair.Next() {
+						s := pair.Value
+						typeName := pair.Key
+						outSchema, err := conv.Convert(s.Shape)
+						writeToDiskHelper(t, struct{name string; typeName string; outSchema *JSONSchemaRAML}{name: tt.name, typeName: typeName, outSchema: outSchema})
+						require.NoError(t, err, "Failed to convert type shape in %s: %v", tt.path, err)
+						convertedCount++
+					}
 				}
 			}
 			t.Logf("Successfully converted %d shapes to JSON Schema for %s", convertedCount, tt.name)
@@ -148,6 +206,10 @@ func Test_ParseFixturesIntegration(t *testing.T) {
 			name: "invalid_decode.raml",
 			path: "./fixtures/invalid_decode.raml",
 		},
+		{
+			name: "extensions_overlay_invalid.raml",
+			path: "./fixtures/extensions/overlay_invalid.raml",
+		},
 		{
 			name: "library_invalid.raml",
 			path: "./fixtures/library_invalid.raml",
@@ -156,10 +218,18 @@ func Test_ParseFixturesIntegration(t *testing.T) {
 			name: "library_invalid_decode.raml",
 			path: "./fixtures/library_invalid_decode.raml",
 		},
+		{
+			name: "library_invalid_discriminator.raml",
+			path: "./fixtures/library_invalid_discriminator.raml",
+		},
 		{
 			name: "library_invalid_dot_import.raml",
 			path: "./fixtures/library_invalid_dot_import.raml",
 		},
+		{
+			name: "library_invalid_json_example.raml",
+			path: "./fixtures/library_invalid_json_example.raml",
+		},
 		{
 			name: "library_invalid_inheritance.raml",
 			path: "./fixtures/library_invalid_inheritance.raml",
@@ -302,8 +372,42 @@ func TestIdentifyFragment(t *testing.T) {
 			args: args{
 				head: "#%RAML 1.0 ResourceType",
 			},
-			want:    FragmentUnknown,
-			wantErr: true,
+			want: FragmentResourceType,
+		},
+		{
+			name: "positive: identify trait",
+			args: args{
+				head: "#%RAML 1.0 Trait",
+			},
+			want: FragmentTrait,
+		},
+		{
+			name: "positive: identify security scheme",
+			args: args{
+				head: "#%RAML 1.0 SecurityScheme",
+			},
+			want: FragmentSecurityScheme,
+		},
+		{
+			name: "positive: identify annotation type declaration",
+			args: args{
+				head: "#%RAML 1.0 AnnotationTypeDeclaration",
+			},
+			want: FragmentAnnotationTypeDeclaration,
+		},
+		{
+			name: "positive: identify documentation item",
+			args: args{
+				head: "#%RAML 1.0 DocumentationItem",
+			},
+			want: FragmentDocumentationItem,
+		},
+		{
+			name: "positive: identify api",
+			args: args{
+				head: "#%RAML 1.0",
+			},
+			want: FragmentAPI,
 		},
 		{
 			name: "negative: identify named example",
@@ -1421,6 +1525,149 @@ func TestParseFromString(t *testing.T) {
 	}
 }
 
+func TestOptWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/api.raml": {Data: []byte(`#%RAML 1.0
+title: Test
+uses:
+  lib: ../libs/lib.raml
+types:
+  Pet: !include types/pet.raml
+/pets:
+  get:
+    responses:
+      200:
+        body:
+          application/json:
+            type: lib.Pets
+            example: !include examples/pets.json
+`)},
+		"api/types/pet.raml": {Data: []byte(`#%RAML 1.0 DataType
+type: object
+properties:
+  name: string
+`)},
+		"api/examples/pets.json": {Data: []byte(`[{"name": "Rex"}]`)},
+		"libs/lib.raml": {Data: []byte(`#%RAML 1.0 Library
+types:
+  Pets:
+    type: array
+    items:
+      properties:
+        name: string
+`)},
+	}
+	type args struct {
+		path string
+		fsys fs.FS
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "positive: fragments are read from fs",
+			args: args{
+				path: "api/api.raml",
+				fsys: fsys,
+			},
+		},
+		{
+			name: "negative: file does not exist in fs",
+			args: args{
+				path: "api/missing.raml",
+				fsys: fsys,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative: included file does not exist in fs",
+			args: args{
+				path: "api/api.raml",
+				fsys: fstest.MapFS{"api/api.raml": fsys["api/api.raml"]},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFromPath(tt.args.path, OptWithFS(tt.args.fsys), OptWithValidate())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFromPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			require.NotNil(t, got.EntryPoint())
+			content, err := fs.ReadFile(tt.args.fsys, tt.args.path)
+			require.NoError(t, err)
+			_, err = ParseFromString(string(content), "api.raml", "api", OptWithFS(tt.args.fsys), OptWithValidate())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOptWithWorkers(t *testing.T) {
+	shapeKeys := func(r *RAML) []string {
+		var keys []string
+		for _, s := range r.GetShapes() {
+			keys = append(keys, fmt.Sprintf("%d %s %s", s.ID, s.Name, s.Location))
+		}
+		// Order of shapes depends on the order of fragments in the cache.
+		sort.Strings(keys)
+		return keys
+	}
+	sequential, err := ParseFromPath("./fixtures/library.raml", OptWithWorkers(1), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{
+			name:    "positive: default number of workers",
+			workers: runtime.GOMAXPROCS(0),
+		},
+		{
+			name:    "positive: more workers than libraries",
+			workers: 64,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFromPath("./fixtures/library.raml", OptWithWorkers(tt.workers), OptWithUnwrap(),
+				OptWithValidate())
+			require.NoError(t, err)
+			require.Equal(t, shapeKeys(sequential), shapeKeys(got), "shapes must not depend on the number of workers")
+			require.Empty(t, got.prefetched)
+
+			// Parsed RAML may be read concurrently, run with -race to detect data races.
+			lib := got.EntryPoint().(*Library)
+			want := make(map[string]string)
+			for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+				want[pair.Key] = fmt.Sprint(pair.Value.Validate(map[string]any{}))
+			}
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+						if res := fmt.Sprint(pair.Value.Validate(map[string]any{})); res != want[pair.Key] {
+							t.Errorf("Validate() of %s = %s, want %s", pair.Key, res, want[pair.Key])
+						}
+						if got.GetFragment(pair.Value.Location) == nil {
+							t.Errorf("GetFragment() of %s = nil", pair.Value.Location)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
 func mustAbs(path string) string {
 	abs, err := filepath.Abs(path)
 	if err != nil {
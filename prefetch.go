@@ -0,0 +1,114 @@
+package raml
+
+import (
+	"log"
+	"sync"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// prefetchLibraries loads the libraries referenced by uses and the libraries used by them with a bounded pool
+// of workers, so that files are read and decoded concurrently. Libraries are built from the loaded documents
+// sequentially by parseLibrary to keep shape IDs deterministic.
+// Libraries that fail to load are skipped, parseLibrary reports their errors.
+func (r *RAML) prefetchLibraries(location string, uses *orderedmap.OrderedMap[string, *LibraryLink]) {
+	if r.workers < 2 || uses.Len() == 0 {
+		return
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[string]struct{})
+		sem  = make(chan struct{}, r.workers)
+	)
+	var schedule func(path string)
+	load := func(path string) {
+		defer wg.Done()
+		sem <- struct{}{}
+		doc, refs := r.loadLibraryDocument(path)
+		<-sem
+		if doc == nil {
+			return
+		}
+		r.mu.Lock()
+		if r.prefetched == nil {
+			r.prefetched = make(map[string]*yaml.Node)
+		}
+		r.prefetched[path] = doc
+		r.mu.Unlock()
+		for _, ref := range refs {
+			schedule(ref)
+		}
+	}
+	schedule = func(path string) {
+		mu.Lock()
+		_, ok := seen[path]
+		seen[path] = struct{}{}
+		mu.Unlock()
+		if ok || r.isLoaded(path) {
+			return
+		}
+		wg.Add(1)
+		go load(path)
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		schedule(resolveLocation(location, pair.Value.Value))
+	}
+	wg.Wait()
+}
+
+// isLoaded reports whether the fragment at the location is parsed or prefetched.
+func (r *RAML) isLoaded(location string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.fragmentsCache[location]; ok {
+		return true
+	}
+	_, ok := r.prefetched[location]
+	return ok
+}
+
+// takePrefetched returns the prefetched document of the library and removes it from the store.
+// Returns nil if the library was not prefetched.
+func (r *RAML) takePrefetched(location string) *yaml.Node {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.prefetched[location]
+	if !ok {
+		return nil
+	}
+	delete(r.prefetched, location)
+	return doc
+}
+
+// loadLibraryDocument reads and decodes the library at the location and returns its document
+// with locations of the libraries it uses. Returns nil document if the library cannot be loaded.
+func (r *RAML) loadLibraryDocument(location string) (*yaml.Node, []string) {
+	f, err := r.openFragment(location)
+	if err != nil {
+		return nil, nil
+	}
+	defer func(f fragmentFile) {
+		err = f.Close()
+		if err != nil {
+			log.Fatalf("close file error: %v", err)
+		}
+	}(f)
+	if err = checkFragmentKind(f, FragmentLibrary); err != nil {
+		return nil, nil
+	}
+	var doc yaml.Node
+	if err = yaml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, nil
+	}
+	var refs []string
+	if len(doc.Content) > 0 {
+		if uses := findMappingValue(doc.Content[0], "uses"); uses != nil && uses.Kind == yaml.MappingNode {
+			for i := 1; i < len(uses.Content); i += 2 {
+				refs = append(refs, resolveLocation(location, uses.Content[i].Value))
+			}
+		}
+	}
+	return &doc, refs
+}
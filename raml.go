@@ -4,18 +4,26 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"io/fs"
 	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
 )
 
 type HookKey string
 
 // RAML is a store for all fragments and shapes.
-// WARNING: Not thread-safe
+// The store is safe for concurrent reads once parsing is finished, so one parsed RAML may back many concurrent
+// validators. Parsing, unwrapping and modification of shapes must not run concurrently with other calls.
 type RAML struct {
-	fragmentsCache          map[string]Fragment // Library, NamedExample, DataType
+	// mu guards fragments, types, annotations and shapes of the store.
+	mu sync.RWMutex
+
+	fragmentsCache          map[string]Fragment // API, Library, NamedExample, DataType
 	fragmentTypes           map[string]map[string]*BaseShape
 	fragmentAnnotationTypes map[string]map[string]*BaseShape
-	// entryPoint is a Library, NamedExample or DataType fragment that is used as an entry point for the resolution.
+	// entryPoint is an API, Library, NamedExample or DataType fragment that is used as an entry point for the resolution.
 	entryPoint Fragment
 	// basePath   string
 
@@ -25,18 +33,32 @@ type RAML struct {
 	shapes []*BaseShape
 	// Temporary storage for unresolved shapes.
 	unresolvedShapes list.List
+	// templateLocations binds nodes copied from resource types and traits to locations of their declarations.
+	templateLocations map[*yaml.Node]string
+	// mergedLocations binds nodes merged from overlays, extensions and included master content to locations of
+	// the documents they are declared in, since the merged document is decoded at the location of the master API.
+	mergedLocations map[*yaml.Node]string
 
 	// idCounter is a counter for generating unique IDs per raml
 	idCounter int64
 	// ctx is a context of the RAML, for future use.
 	ctx context.Context
+	// fsys is the file system fragments are read from. Nil for the OS file system.
+	fsys fs.FS
+	// remote is the resolver of remote fragments. Nil if remote fragments are disabled.
+	remote *HTTPResolver
+	// workers is the maximum number of libraries that are loaded concurrently.
+	workers int
+	// prefetched contains decoded documents of libraries loaded ahead of parsing by locations.
+	prefetched map[string]*yaml.Node
 }
 
 type HookFunc func(ctx context.Context, r *RAML, params ...any) error
 
 func (r *RAML) getHooks(key HookKey) []HookFunc {
+	// Hooks are read during validation, so the context must not be modified here.
 	if r.ctx == nil {
-		r.ctx = context.Background()
+		return []HookFunc{}
 	}
 	hooks, ok := r.ctx.Value(key).([]HookFunc)
 	if !ok {
@@ -120,12 +142,16 @@ func (r *RAML) GetLocation() string {
 
 // GetAllAnnotationsPtr returns all annotations as pointers.
 func (r *RAML) GetAllAnnotationsPtr() []*DomainExtension {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var annotations []*DomainExtension
 	return append(annotations, r.domainExtensions...)
 }
 
 // GetAllAnnotations returns all annotations.
 func (r *RAML) GetAllAnnotations() []DomainExtension {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var annotations []DomainExtension
 	for _, de := range r.domainExtensions {
 		annotations = append(annotations, *de)
@@ -146,20 +172,28 @@ func New(ctx context.Context) *RAML {
 
 // Shapes returns all shapes.
 func (r *RAML) GetShapes() []*BaseShape {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.shapes
 }
 
 func (r *RAML) PutShape(shape *BaseShape) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.shapes = append(r.shapes, shape)
 }
 
 // GetFragmentTypePtrs returns fragment shapes as pointers.
 func (r *RAML) GetFragmentTypePtrs(location string) map[string]*BaseShape {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.fragmentTypes[location]
 }
 
 // GetTypeFromFragmentPtr returns a shape from a fragment as a pointer.
 func (r *RAML) GetTypeFromFragmentPtr(location string, typeName string) (*BaseShape, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	loc, ok := r.fragmentTypes[location]
 	if !ok {
 		return nil, fmt.Errorf("location %s not found", location)
@@ -169,6 +203,8 @@ func (r *RAML) GetTypeFromFragmentPtr(location string, typeName string) (*BaseSh
 
 // PutTypeIntoFragment puts a shape into a fragment.
 func (r *RAML) PutTypeIntoFragment(name string, location string, shape *BaseShape) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	loc, ok := r.fragmentTypes[location]
 	if !ok {
 		loc = make(map[string]*BaseShape)
@@ -179,6 +215,8 @@ func (r *RAML) PutTypeIntoFragment(name string, location string, shape *BaseShap
 
 // GetTypeFromFragmentPtr returns a shape from a fragment.
 func (r *RAML) GetAnnotationTypeFromFragmentPtr(location string, typeName string) (*BaseShape, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	loc, ok := r.fragmentAnnotationTypes[location]
 	if !ok {
 		return nil, fmt.Errorf("location %s not found", location)
@@ -188,6 +226,8 @@ func (r *RAML) GetAnnotationTypeFromFragmentPtr(location string, typeName string
 
 // PutTypeIntoFragment puts a shape into a fragment.
 func (r *RAML) PutAnnotationTypeIntoFragment(name string, location string, shape *BaseShape) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	loc, ok := r.fragmentAnnotationTypes[location]
 	if !ok {
 		loc = make(map[string]*BaseShape)
@@ -198,11 +238,15 @@ func (r *RAML) PutAnnotationTypeIntoFragment(name string, location string, shape
 
 // GetFragment returns a fragment.
 func (r *RAML) GetFragment(location string) Fragment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.fragmentsCache[location]
 }
 
 // PutFragment puts a fragment.
 func (r *RAML) PutFragment(location string, fragment Fragment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if _, ok := r.fragmentsCache[location]; !ok {
 		r.fragmentsCache[location] = fragment
 	}
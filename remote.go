@@ -0,0 +1,272 @@
+package raml
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRemoteTimeout is the default timeout of requests for remote fragments.
+const DefaultRemoteTimeout = 30 * time.Second
+
+// ErrOffline is returned when a remote fragment is requested in offline mode and is not cached.
+var ErrOffline = errors.New("remote fragment is not cached and offline mode is enabled")
+
+type HTTPResolverOptions struct {
+	client       *http.Client
+	timeout      time.Duration
+	timeoutSet   bool
+	cacheDir     string
+	allowedHosts []string
+	offline      bool
+}
+
+type HTTPResolverOpt interface {
+	apply(*HTTPResolverOptions)
+}
+
+type optHTTPClient struct{ client *http.Client }
+
+func (o optHTTPClient) apply(opts *HTTPResolverOptions) { opts.client = o.client }
+
+// WithHTTPClient makes the resolver send requests with a copy of the given client.
+// The timeout of the client is preserved unless WithTimeout is set, redirects are checked
+// against the allowed hosts before the CheckRedirect of the client.
+func WithHTTPClient(client *http.Client) HTTPResolverOpt {
+	return optHTTPClient{client}
+}
+
+type optTimeout struct{ timeout time.Duration }
+
+func (o optTimeout) apply(opts *HTTPResolverOptions) { opts.timeout, opts.timeoutSet = o.timeout, true }
+
+// WithTimeout sets the timeout of a single request. DefaultRemoteTimeout is used by default.
+func WithTimeout(timeout time.Duration) HTTPResolverOpt {
+	return optTimeout{timeout}
+}
+
+type optCacheDir struct{ dir string }
+
+func (o optCacheDir) apply(opts *HTTPResolverOptions) { opts.cacheDir = o.dir }
+
+// WithCacheDir enables the on-disk cache of remote fragments in dir.
+// Cached fragments are revalidated with their ETag, so unchanged fragments are not downloaded again.
+func WithCacheDir(dir string) HTTPResolverOpt {
+	return optCacheDir{dir}
+}
+
+type optAllowedHosts struct{ hosts []string }
+
+func (o optAllowedHosts) apply(opts *HTTPResolverOptions) {
+	opts.allowedHosts = append(opts.allowedHosts, o.hosts...)
+}
+
+// WithAllowedHosts restricts remote fragments to the given hosts. A host may include a port, e.g. "localhost:8080",
+// otherwise any port of the host is allowed. By default, fragments may be fetched from any host.
+func WithAllowedHosts(hosts ...string) HTTPResolverOpt {
+	return optAllowedHosts{hosts}
+}
+
+type optOffline struct{}
+
+func (o optOffline) apply(opts *HTTPResolverOptions) { opts.offline = true }
+
+// WithOffline makes the resolver serve remote fragments only from the cache.
+// Fragments that are not cached fail with ErrOffline.
+func WithOffline() HTTPResolverOpt {
+	return optOffline{}
+}
+
+// HTTPResolver fetches remote fragments referenced by http(s) URLs in includes and uses.
+type HTTPResolver struct {
+	opts   HTTPResolverOptions
+	client *http.Client
+}
+
+// NewHTTPResolver creates a resolver of remote fragments.
+func NewHTTPResolver(opts ...HTTPResolverOpt) *HTTPResolver {
+	res := &HTTPResolver{}
+	for _, opt := range opts {
+		opt.apply(&res.opts)
+	}
+	client := http.Client{Timeout: DefaultRemoteTimeout}
+	if res.opts.client != nil {
+		// The client is copied so that the client of the caller is not modified.
+		client = *res.opts.client
+	}
+	if res.opts.timeoutSet {
+		client.Timeout = res.opts.timeout
+	}
+	// Redirects must not lead out of the allowed hosts.
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := res.checkHost(req.URL); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	res.client = &client
+	return res
+}
+
+// Fetch returns the content of the remote fragment located at rawURL.
+func (res *HTTPResolver) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err = res.checkHost(u); err != nil {
+		return nil, err
+	}
+	cached, etag := res.readCache(rawURL)
+	if res.opts.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("%s: %w", rawURL, ErrOffline)
+		}
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	if cached != nil && etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := res.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("get %s: unexpected status %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if err = res.writeCache(rawURL, data, resp.Header.Get("ETag")); err != nil {
+		return nil, fmt.Errorf("write cache: %w", err)
+	}
+	return data, nil
+}
+
+func (res *HTTPResolver) checkHost(u *url.URL) error {
+	if len(res.opts.allowedHosts) == 0 {
+		return nil
+	}
+	for _, host := range res.opts.allowedHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", u.Host)
+}
+
+// cachePath returns the path of the cached fragment. The ETag of the fragment is stored next to it.
+func (res *HTTPResolver) cachePath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(res.opts.cacheDir, hex.EncodeToString(sum[:]))
+}
+
+// readCache returns the cached content and ETag of the fragment. Content is nil if the fragment is not cached.
+func (res *HTTPResolver) readCache(rawURL string) ([]byte, string) {
+	if res.opts.cacheDir == "" {
+		return nil, ""
+	}
+	p := res.cachePath(rawURL)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, ""
+	}
+	etag, err := os.ReadFile(p + ".etag")
+	if err != nil {
+		return data, ""
+	}
+	return data, string(etag)
+}
+
+func (res *HTTPResolver) writeCache(rawURL string, data []byte, etag string) error {
+	if res.opts.cacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(res.opts.cacheDir, 0o750); err != nil {
+		return err
+	}
+	p := res.cachePath(rawURL)
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		return err
+	}
+	if etag == "" {
+		// Stale ETag of the previous content must not be used for revalidation.
+		if err := os.Remove(p + ".etag"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(p+".etag", []byte(etag), 0o600)
+}
+
+// isRemoteLocation reports whether the location is an http(s) URL.
+func isRemoteLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// resolveLocation resolves the reference of an include or a library relative to the location
+// of the referencing fragment. References in remote fragments are resolved relative to their URLs.
+func resolveLocation(location string, ref string) string {
+	if isRemoteLocation(ref) {
+		return ref
+	}
+	if isRemoteLocation(location) {
+		base, err := url.Parse(location)
+		if err != nil {
+			return ref
+		}
+		rel, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return ref
+		}
+		return base.ResolveReference(rel).String()
+	}
+	return filepath.Join(filepath.Dir(location), ref)
+}
+
+// openRemoteFragment fetches the remote fragment with the resolver of the RAML.
+func (r *RAML) openRemoteFragment(location string) (fragmentFile, error) {
+	if r.remote == nil {
+		return nil, fmt.Errorf("remote fragment %s: remote resolution is disabled, use OptWithHTTPResolver", location)
+	}
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	data, err := r.remote.Fetch(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return &memFragmentFile{Reader: bytes.NewReader(data), name: location}, nil
+}
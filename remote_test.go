@@ -0,0 +1,236 @@
+package raml
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newRemoteServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	files := map[string]string{
+		"/libs/lib.raml": `#%RAML 1.0 Library
+uses:
+  common: common/common.raml
+types:
+  Pet: !include types/pet.raml
+  Pets:
+    type: array
+    items: Pet
+`,
+		"/libs/common/common.raml": `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    minLength: 1
+`,
+		"/libs/types/pet.raml": `#%RAML 1.0 DataType
+uses:
+  common: ../common/common.raml
+type: object
+properties:
+  name: common.Name
+example: !include ../examples/pet.json
+`,
+		"/libs/examples/pet.json": `{"name": "Rex"}`,
+	}
+	var notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow.raml" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		content, ok := files[req.URL.Path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		etag := `"` + req.URL.Path + `"`
+		if req.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &notModified
+}
+
+func TestOptWithHTTPResolver(t *testing.T) {
+	srv, _ := newRemoteServer(t)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	content := `#%RAML 1.0
+title: Test
+uses:
+  lib: ` + srv.URL + `/libs/lib.raml
+/pets:
+  get:
+    responses:
+      200:
+        body:
+          application/json: lib.Pets
+`
+	tests := []struct {
+		name    string
+		opts    []ParseOpt
+		wantErr bool
+	}{
+		{
+			name: "positive: remote libraries and includes",
+			opts: []ParseOpt{OptWithHTTPResolver(NewHTTPResolver())},
+		},
+		{
+			name: "positive: host is allowed",
+			opts: []ParseOpt{OptWithHTTPResolver(NewHTTPResolver(WithAllowedHosts(u.Hostname())))},
+		},
+		{
+			name:    "negative: remote resolution is disabled",
+			wantErr: true,
+		},
+		{
+			name:    "negative: host is not allowed",
+			opts:    []ParseOpt{OptWithHTTPResolver(NewHTTPResolver(WithAllowedHosts("example.com")))},
+			wantErr: true,
+		},
+		{
+			name:    "negative: offline without cache",
+			opts:    []ParseOpt{OptWithHTTPResolver(NewHTTPResolver(WithOffline()))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ParseOpt{OptWithValidate()}, tt.opts...)
+			got, err := ParseFromString(content, "api.raml", t.TempDir(), opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			frag := got.GetFragment(srv.URL + "/libs/common/common.raml")
+			require.NotNil(t, frag, "library used by remote fragment must be resolved relative to its URL")
+		})
+	}
+}
+
+func TestHTTPResolver_Fetch(t *testing.T) {
+	srv, notModified := newRemoteServer(t)
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	res := NewHTTPResolver(WithCacheDir(cacheDir))
+	data, err := res.Fetch(ctx, srv.URL+"/libs/examples/pet.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"name": "Rex"}`, string(data))
+	require.Equal(t, int32(0), notModified.Load())
+
+	// Cached fragment is revalidated with its ETag.
+	data, err = res.Fetch(ctx, srv.URL+"/libs/examples/pet.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"name": "Rex"}`, string(data))
+	require.Equal(t, int32(1), notModified.Load())
+
+	offline := NewHTTPResolver(WithCacheDir(cacheDir), WithOffline())
+	data, err = offline.Fetch(ctx, srv.URL+"/libs/examples/pet.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"name": "Rex"}`, string(data))
+	_, err = offline.Fetch(ctx, srv.URL+"/libs/lib.raml")
+	require.True(t, errors.Is(err, ErrOffline), "got %v", err)
+
+	_, err = res.Fetch(ctx, srv.URL+"/missing.raml")
+	require.Error(t, err)
+
+	_, err = NewHTTPResolver(WithTimeout(50*time.Millisecond)).Fetch(ctx, srv.URL+"/slow.raml")
+	require.Error(t, err)
+
+	_, err = res.Fetch(ctx, "ftp://example.com/lib.raml")
+	require.Error(t, err)
+}
+
+func TestHTTPResolver_CustomClient(t *testing.T) {
+	srv, _ := newRemoteServer(t)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, srv.URL+req.URL.Path, http.StatusFound)
+	}))
+	t.Cleanup(redirect.Close)
+	ctx := context.Background()
+
+	var redirects atomic.Int32
+	client := &http.Client{
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			redirects.Add(1)
+			return nil
+		},
+	}
+
+	// Redirect to the allowed host is checked by the client as well.
+	res := NewHTTPResolver(WithHTTPClient(client), WithAllowedHosts(u.Host, redirect.Listener.Addr().String()))
+	data, err := res.Fetch(ctx, redirect.URL+"/libs/examples/pet.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"name": "Rex"}`, string(data))
+	require.Equal(t, int32(1), redirects.Load())
+
+	// Redirect out of the allowed hosts is rejected before the client is asked.
+	res = NewHTTPResolver(WithHTTPClient(client), WithAllowedHosts(redirect.Listener.Addr().String()))
+	_, err = res.Fetch(ctx, redirect.URL+"/libs/examples/pet.json")
+	require.ErrorContains(t, err, "is not allowed")
+	require.Equal(t, int32(1), redirects.Load())
+
+	// Timeout is applied to the copy of the client.
+	_, err = NewHTTPResolver(WithHTTPClient(client), WithTimeout(50*time.Millisecond)).Fetch(ctx, srv.URL+"/slow.raml")
+	require.Error(t, err)
+	require.Zero(t, client.Timeout)
+}
+
+func Test_resolveLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		ref      string
+		want     string
+	}{
+		{
+			name:     "local reference",
+			location: "/api/api.raml",
+			ref:      "types/pet.raml",
+			want:     "/api/types/pet.raml",
+		},
+		{
+			name:     "remote reference in local fragment",
+			location: "/api/api.raml",
+			ref:      "https://example.com/lib.raml",
+			want:     "https://example.com/lib.raml",
+		},
+		{
+			name:     "relative reference in remote fragment",
+			location: "https://example.com/libs/lib.raml",
+			ref:      "../types/pet.raml",
+			want:     "https://example.com/types/pet.raml",
+		},
+		{
+			name:     "absolute reference in remote fragment",
+			location: "https://example.com/libs/lib.raml",
+			ref:      "http://other.com/pet.raml",
+			want:     "http://other.com/pet.raml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveLocation(tt.location, tt.ref); got != tt.want {
+				t.Errorf("resolveLocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Supported output formats.
+const (
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+	FormatJUnit      = "junit"
+	FormatCheckstyle = "checkstyle"
+)
+
+const (
+	toolName           = "raml"
+	toolInformationURI = "https://github.com/acronis/go-raml"
+	sarifVersion       = "2.1.0"
+	sarifSchema        = "https://json.schemastore.org/sarif-2.1.0.json"
+	checkstyleVersion  = "4.3"
+)
+
+// Write writes problems of the results in the format.
+func Write(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, allProblems(results))
+	case FormatSARIF:
+		return writeJSON(w, newSARIFLog(allProblems(results)))
+	case FormatJUnit:
+		return writeXML(w, newJUnitTestSuites(results))
+	case FormatCheckstyle:
+		return writeXML(w, newCheckstyle(allProblems(results)))
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+func allProblems(results []Result) []Problem {
+	problems := []Problem{}
+	for _, res := range results {
+		problems = append(problems, res.Problems...)
+	}
+	return problems
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write xml header: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode xml: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("write xml: %w", err)
+	}
+	return nil
+}
+
+// sarifLog is a SARIF 2.1.0 log with the subset of properties used by code scanning services.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// newSARIFLog returns a log with a rule for each type of problems.
+func newSARIFLog(problems []Problem) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolInformationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := make(map[string]struct{})
+	for _, p := range problems {
+		if _, ok := rules[p.Type]; !ok {
+			rules[p.Type] = struct{}{}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               p.Type,
+				ShortDescription: sarifMessage{Text: p.Type + " error"},
+			})
+		}
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: fileURI(p.File)}}
+		if p.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: p.Line, StartColumn: p.Column}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    p.Type,
+			Level:     sarifLevel(p.Severity),
+			Message:   sarifMessage{Text: p.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+	return sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}
+}
+
+// fileURI returns the URI reference of the file. Relative paths remain relative, so that code scanning services
+// resolve them against the root of the repository.
+func fileURI(file string) string {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return file
+	}
+	uri := filepath.ToSlash(file)
+	if filepath.IsAbs(file) {
+		if !strings.HasPrefix(uri, "/") {
+			// Absolute Windows paths start with a volume name.
+			uri = "/" + uri
+		}
+		return "file://" + uri
+	}
+	return uri
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info":
+		return "note"
+	}
+	return "error"
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// newJUnitTestSuites returns a test suite with a test case for each checked file. A test case fails with all
+// problems found for the file.
+func newJUnitTestSuites(results []Result) junitTestSuites {
+	suite := junitTestSuite{Name: toolName, Cases: []junitTestCase{}}
+	for _, res := range results {
+		tc := junitTestCase{Name: res.File, ClassName: toolName}
+		if len(res.Problems) > 0 {
+			lines := make([]string, len(res.Problems))
+			for i, p := range res.Problems {
+				lines[i] = fmt.Sprintf("%s:%d:%d: %s: %s", p.File, p.Line, p.Column, p.Type, p.Message)
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("problems found: %d", len(res.Problems)),
+				Type:    res.Problems[0].Type,
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+	}
+	return junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+}
+
+type checkstyle struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// newCheckstyle returns problems grouped by files in the order of their first problems.
+func newCheckstyle(problems []Problem) checkstyle {
+	cs := checkstyle{Version: checkstyleVersion}
+	index := make(map[string]int)
+	for _, p := range problems {
+		i, ok := index[p.File]
+		if !ok {
+			i = len(cs.Files)
+			index[p.File] = i
+			cs.Files = append(cs.Files, checkstyleFile{Name: p.File})
+		}
+		cs.Files[i].Errors = append(cs.Files[i].Errors, checkstyleError{
+			Line:     p.Line,
+			Column:   p.Column,
+			Severity: p.Severity,
+			Message:  p.Message,
+			Source:   toolName + "." + p.Type,
+		})
+	}
+	return cs
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testResults = []Result{
+	{File: "api.raml", Problems: []Problem{
+		{File: "lib.raml", Line: 3, Column: 8, Severity: SeverityError, Type: "resolving",
+			Message: "resolve shapes: reference not found", Chain: []string{"resolve shapes", "reference not found"}},
+		{File: "api.raml", Severity: "warning", Type: "parsing", Message: "unexpected key",
+			Chain: []string{"unexpected key"}},
+	}},
+	{File: "valid.raml"},
+}
+
+func TestWrite_json(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, testResults))
+	var got []Problem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, testResults[0].Problems, got)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatJSON, []Result{{File: "valid.raml"}}))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestWrite_sarif(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatSARIF, testResults))
+	var got sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, sarifVersion, got.Version)
+	require.Len(t, got.Runs, 1)
+	run := got.Runs[0]
+	require.Equal(t, []sarifRule{
+		{ID: "resolving", ShortDescription: sarifMessage{Text: "resolving error"}},
+		{ID: "parsing", ShortDescription: sarifMessage{Text: "parsing error"}},
+	}, run.Tool.Driver.Rules)
+	require.Equal(t, []sarifResult{
+		{RuleID: "resolving", Level: "error", Message: sarifMessage{Text: "resolve shapes: reference not found"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "lib.raml"},
+				Region:           &sarifRegion{StartLine: 3, StartColumn: 8},
+			}}}},
+		{RuleID: "parsing", Level: "warning", Message: sarifMessage{Text: "unexpected key"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "api.raml"},
+			}}}},
+	}, run.Results)
+}
+
+func TestWrite_junit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJUnit, testResults))
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, 2, got.Tests)
+	require.Equal(t, 1, got.Failures)
+	require.Len(t, got.Suites, 1)
+	cases := got.Suites[0].Cases
+	require.Len(t, cases, 2)
+	require.Equal(t, "api.raml", cases[0].Name)
+	require.Equal(t, &junitFailure{
+		Message: "problems found: 2",
+		Type:    "resolving",
+		Text:    "lib.raml:3:8: resolving: resolve shapes: reference not found\napi.raml:0:0: parsing: unexpected key",
+	}, cases[0].Failure)
+	require.Equal(t, "valid.raml", cases[1].Name)
+	require.Nil(t, cases[1].Failure)
+}
+
+func TestWrite_checkstyle(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCheckstyle, testResults))
+	var got checkstyle
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	got.XMLName = xml.Name{}
+	require.Equal(t, checkstyle{Version: checkstyleVersion, Files: []checkstyleFile{
+		{Name: "lib.raml", Errors: []checkstyleError{{Line: 3, Column: 8, Severity: SeverityError,
+			Message: "resolve shapes: reference not found", Source: "raml.resolving"}}},
+		{Name: "api.raml", Errors: []checkstyleError{{Severity: "warning", Message: "unexpected key",
+			Source: "raml.parsing"}}},
+	}}, got)
+}
+
+func TestWrite_unsupported(t *testing.T) {
+	require.Error(t, Write(&bytes.Buffer{}, "yaml", testResults))
+}
+
+func TestFileURI(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"api/lib.raml", "api/lib.raml"},
+		{"/srv/api/lib.raml", "file:///srv/api/lib.raml"},
+		{"https://example.com/lib.raml", "https://example.com/lib.raml"},
+	}
+	for _, tt := range tests {
+		if got := fileURI(tt.file); got != tt.want {
+			t.Errorf("fileURI(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package report converts errors of the parser to flat problems and writes them in machine-readable formats
+// for CI systems: JSON, SARIF, JUnit and Checkstyle.
+package report
+
+import (
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// SeverityError is the severity of problems which stack traces do not set the severity.
+const SeverityError = "error"
+
+// TypeUnknown is the type of problems which stack traces do not set the type.
+const TypeUnknown = "unknown"
+
+// Problem is a single problem found in a file.
+type Problem struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	// Type is the type of the innermost stack trace that sets it, e.g. "parsing" or "validating".
+	Type string `json:"type"`
+	// Message is the chain of messages joined with ": ".
+	Message string `json:"message"`
+	// Chain contains messages of stack traces from the outermost to the innermost one.
+	Chain []string `json:"chain"`
+}
+
+// Result is the result of checking a file. Problems may be found in other files the file refers to.
+type Result struct {
+	File     string
+	Problems []Problem
+}
+
+// Problems returns a problem for each leaf of the stack trace of the error. The file, the position and the type
+// of a problem are taken from the innermost stack traces that set them. Errors without a stack trace and
+// stack traces without a location are reported in the fallback file.
+func Problems(err error, fallback string) []Problem {
+	if err == nil {
+		return nil
+	}
+	st, ok := stacktrace.Unwrap(err)
+	if !ok {
+		return []Problem{{
+			File:     fallback,
+			Severity: SeverityError,
+			Type:     TypeUnknown,
+			Message:  err.Error(),
+			Chain:    []string{err.Error()},
+		}}
+	}
+	c := &collector{}
+	c.walk(st, Problem{File: fallback, Severity: SeverityError, Type: TypeUnknown})
+	return c.problems
+}
+
+type collector struct {
+	problems []Problem
+}
+
+// walk walks the stack trace and adds a problem for each leaf. The problem is accumulated on the path from the root.
+// Stack traces in the list are appended siblings of the stack trace, so they share only the parent path.
+func (c *collector) walk(st *stacktrace.StackTrace, parent Problem) {
+	p := parent
+	if st.Location != nil && string(*st.Location) != "" && string(*st.Location) != p.File {
+		p.File = string(*st.Location)
+		// The position of the outer location does not belong to the new one.
+		p.Line, p.Column = 0, 0
+	}
+	if st.Position != nil {
+		p.Line, p.Column = st.Position.Line, st.Position.Column
+	}
+	if st.Type != nil && *st.Type != "" {
+		p.Type = string(*st.Type)
+	}
+	if st.Severity != nil && *st.Severity != "" {
+		p.Severity = string(*st.Severity)
+	}
+	if msg := st.MessageWithInfo(); msg != "" {
+		// Copy the chain since branches share the prefix.
+		p.Chain = append(p.Chain[:len(p.Chain):len(p.Chain)], msg)
+	}
+	if st.Wrapped != nil {
+		c.walk(st.Wrapped, p)
+	} else {
+		c.add(p)
+	}
+	for _, item := range st.List {
+		c.walk(item, parent)
+	}
+}
+
+// add adds the problem unless the same error at the same position is already added, since the parser may report
+// an error in a shared fragment with different outer chains.
+func (c *collector) add(p Problem) {
+	p.Message = strings.Join(p.Chain, ": ")
+	for _, existing := range c.problems {
+		if existing.File == p.File && existing.Line == p.Line && existing.Column == p.Column &&
+			lastMessage(existing.Chain) == lastMessage(p.Chain) {
+			return
+		}
+	}
+	c.problems = append(c.problems, p)
+}
+
+func lastMessage(chain []string) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	return chain[len(chain)-1]
+}
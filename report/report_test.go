@@ -0,0 +1,73 @@
+package report
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblems(t *testing.T) {
+	validating := stacktrace.WithType("validating")
+	tests := []struct {
+		name string
+		err  error
+		want []Problem
+	}{
+		{
+			name: "positive: nil error",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "positive: error without stack trace",
+			err:  errors.New("context canceled"),
+			want: []Problem{{File: "api.raml", Severity: SeverityError, Type: TypeUnknown,
+				Message: "context canceled", Chain: []string{"context canceled"}}},
+		},
+		{
+			name: "positive: wrapped stack trace in another location",
+			err: stacktrace.NewWrapped("resolve shapes",
+				stacktrace.New("reference not found", stacktrace.WithLocation("lib.raml"),
+					stacktrace.WithPosition(stacktrace.NewPosition(3, 8)), stacktrace.WithInfo("name", "Bar")),
+				stacktrace.WithLocation("api.raml"), stacktrace.WithPosition(stacktrace.NewPosition(1, 1)),
+				stacktrace.WithType("parsing")),
+			want: []Problem{{File: "lib.raml", Line: 3, Column: 8, Severity: SeverityError, Type: "parsing",
+				Message: "resolve shapes: reference not found: name: Bar",
+				Chain:   []string{"resolve shapes", "reference not found: name: Bar"}}},
+		},
+		{
+			name: "positive: appended stack traces share the parent chain",
+			err: stacktrace.NewWrapped("validate shapes",
+				stacktrace.New("invalid example", stacktrace.WithLocation("api.raml"),
+					stacktrace.WithPosition(stacktrace.NewPosition(5, 7)), validating).
+					Append(stacktrace.New("invalid default", stacktrace.WithLocation("api.raml"),
+						stacktrace.WithPosition(stacktrace.NewPosition(9, 7)), validating,
+						stacktrace.WithSeverity("warning"))),
+				stacktrace.WithLocation("api.raml"), stacktrace.WithType("parsing")),
+			want: []Problem{
+				{File: "api.raml", Line: 5, Column: 7, Severity: SeverityError, Type: "validating",
+					Message: "validate shapes: invalid example", Chain: []string{"validate shapes", "invalid example"}},
+				{File: "api.raml", Line: 9, Column: 7, Severity: "warning", Type: "validating",
+					Message: "validate shapes: invalid default", Chain: []string{"validate shapes", "invalid default"}},
+			},
+		},
+		{
+			name: "positive: duplicates with different outer chains",
+			err: stacktrace.New("resolve shapes", stacktrace.WithLocation("api.raml")).
+				Wrap(stacktrace.New("not found", stacktrace.WithLocation("lib.raml"),
+					stacktrace.WithPosition(stacktrace.NewPosition(3, 8)))).
+				Append(stacktrace.New("not found", stacktrace.WithLocation("lib.raml"),
+					stacktrace.WithPosition(stacktrace.NewPosition(3, 8)))),
+			want: []Problem{{File: "lib.raml", Line: 3, Column: 8, Severity: SeverityError, Type: TypeUnknown,
+				Message: "resolve shapes: not found", Chain: []string{"resolve shapes", "not found"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Problems(tt.err, "api.raml")
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SetOfMethods contains a set of HTTP methods allowed in a resource.
+var SetOfMethods = map[string]struct{}{
+	"get": {}, "patch": {}, "put": {}, "post": {}, "delete": {}, "head": {}, "options": {},
+}
+
+// Resource is a RAML resource identified by its relative URI (e.g. "/users").
+type Resource struct {
+	URI         string
+	DisplayName string
+	Description string
+	// Type is the name of the applied resource type.
+	Type          string
+	URIParameters *orderedmap.OrderedMap[string, Property]
+	Methods       *orderedmap.OrderedMap[string, *Method]
+	Resources     *orderedmap.OrderedMap[string, *Resource]
+	// SecuredBy contains security requirements declared by the resource for its methods.
+	SecuredBy []*SecurityRequirement
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Parent *Resource
+
+	Location string
+	stacktrace.Position
+	raml *RAML
+}
+
+// FullURI returns the URI of the resource relative to the base URI of the API.
+func (res *Resource) FullURI() string {
+	if res.Parent == nil {
+		return res.URI
+	}
+	return res.Parent.FullURI() + res.URI
+}
+
+// Method is an HTTP method of a resource.
+type Method struct {
+	Name            string
+	DisplayName     string
+	Description     string
+	QueryParameters *orderedmap.OrderedMap[string, Property]
+	QueryString     *BaseShape
+	Headers         *orderedmap.OrderedMap[string, Property]
+	Body            *orderedmap.OrderedMap[string, *Body]
+	Responses       *orderedmap.OrderedMap[string, *Response]
+	Protocols       []string
+	// Is contains names of traits applied to the method, including traits applied to the resource.
+	Is []string
+	// SecuredBy contains effective security requirements of the method,
+	// inherited from the resource or the root if the method does not declare any.
+	SecuredBy []*SecurityRequirement
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Resource *Resource
+
+	Location string
+	stacktrace.Position
+	raml *RAML
+}
+
+// Response is a response of a method for a specific HTTP status code.
+type Response struct {
+	Code        string
+	Description string
+	Headers     *orderedmap.OrderedMap[string, Property]
+	Body        *orderedmap.OrderedMap[string, *Body]
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Location string
+	stacktrace.Position
+	raml *RAML
+}
+
+// Body is a request or response body for a specific media type.
+type Body struct {
+	MediaType string
+	Shape     *BaseShape
+
+	Location string
+	stacktrace.Position
+}
+
+// shapes returns shapes of the resource itself and its methods, not including nested resources.
+func (res *Resource) shapes() []*BaseShape {
+	var shapes []*BaseShape
+	for pair := res.URIParameters.Oldest(); pair != nil; pair = pair.Next() {
+		shapes = append(shapes, pair.Value.Base)
+	}
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		shapes = append(shapes, pair.Value.shapes()...)
+	}
+	return shapes
+}
+
+// shapes returns shapes of the method parameters, bodies and responses.
+func (m *Method) shapes() []*BaseShape {
+	var shapes []*BaseShape
+	if m.QueryString != nil {
+		shapes = append(shapes, m.QueryString)
+	}
+	for pair := m.QueryParameters.Oldest(); pair != nil; pair = pair.Next() {
+		shapes = append(shapes, pair.Value.Base)
+	}
+	for pair := m.Headers.Oldest(); pair != nil; pair = pair.Next() {
+		shapes = append(shapes, pair.Value.Base)
+	}
+	for pair := m.Body.Oldest(); pair != nil; pair = pair.Next() {
+		shapes = append(shapes, pair.Value.Shape)
+	}
+	for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		for hPair := pair.Value.Headers.Oldest(); hPair != nil; hPair = hPair.Next() {
+			shapes = append(shapes, hPair.Value.Base)
+		}
+		for bPair := pair.Value.Body.Oldest(); bPair != nil; bPair = bPair.Next() {
+			shapes = append(shapes, bPair.Value.Shape)
+		}
+	}
+	return shapes
+}
+
+// IsResourceNode returns true if the node name is a relative URI of a nested resource.
+func IsResourceNode(name string) bool {
+	return strings.HasPrefix(name, "/")
+}
+
+// makeResource creates a resource from the given value node.
+// defaultMediaTypes are applied to bodies that do not declare media types explicitly.
+func (r *RAML) makeResource(
+	uri string,
+	value *yaml.Node,
+	location string,
+	parent *Resource,
+	defaultMediaTypes []string,
+) (*Resource, error) {
+	location = r.mergedLocation(value, location)
+	res := &Resource{
+		URI:                    uri,
+		DisplayName:            uri,
+		URIParameters:          orderedmap.New[string, Property](0),
+		Methods:                orderedmap.New[string, *Method](0),
+		Resources:              orderedmap.New[string, *Resource](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Parent:                 parent,
+		Location:               location,
+		Position:               stacktrace.Position{Line: value.Line, Column: value.Column},
+		raml:                   r,
+	}
+	// Resources may be declared with an empty value, e.g. "/users:".
+	if value.Tag == TagNull {
+		return res, nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("resource must be map", location, WithNodePosition(value))
+	}
+
+	expanded, err := r.expandResource(value, location, res.FullURI())
+	if err != nil {
+		return nil, StacktraceNewWrapped("expand resource", err, location, WithNodePosition(value))
+	}
+	res.Type = expanded.Type
+
+	for i := 0; i != len(expanded.Node.Content); i += 2 {
+		node := expanded.Node.Content[i]
+		valueNode := expanded.Node.Content[i+1]
+		if err = res.decodeValueNode(node, valueNode, defaultMediaTypes); err != nil {
+			return nil, err
+		}
+	}
+	for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+		pair.Value.Is = expanded.Traits[pair.Key]
+	}
+	return res, nil
+}
+
+func (res *Resource) decodeValueNode(node, valueNode *yaml.Node, defaultMediaTypes []string) error {
+	switch node.Value {
+	case "displayName":
+		if err := valueNode.Decode(&res.DisplayName); err != nil {
+			return StacktraceNewWrapped("decode displayName", err, res.Location, WithNodePosition(valueNode))
+		}
+	case FacetDescription:
+		if err := valueNode.Decode(&res.Description); err != nil {
+			return StacktraceNewWrapped("decode description", err, res.Location, WithNodePosition(valueNode))
+		}
+	case "uriParameters":
+		params, err := res.raml.makeParameters(valueNode, res.Location)
+		if err != nil {
+			return fmt.Errorf("make uri parameters: %w", err)
+		}
+		res.URIParameters = params
+	case "securedBy":
+		reqs, err := res.raml.decodeSecuredBy(valueNode, res.Location)
+		if err != nil {
+			return fmt.Errorf("decode securedBy: %w", err)
+		}
+		res.SecuredBy = reqs
+	default:
+		if _, ok := SetOfMethods[node.Value]; ok {
+			method, err := res.raml.makeMethod(node.Value, valueNode, res.Location, res, defaultMediaTypes)
+			if err != nil {
+				return StacktraceNewWrapped("make method", err, res.Location, WithNodePosition(node),
+					stacktrace.WithInfo("method", node.Value))
+			}
+			res.Methods.Set(method.Name, method)
+		} else if IsResourceNode(node.Value) {
+			nested, err := res.raml.makeResource(node.Value, valueNode, res.Location, res, defaultMediaTypes)
+			if err != nil {
+				return StacktraceNewWrapped("make resource", err, res.Location, WithNodePosition(node),
+					stacktrace.WithInfo("resource", node.Value))
+			}
+			res.Resources.Set(nested.URI, nested)
+		} else if IsCustomDomainExtensionNode(node.Value) {
+			name, de, err := res.raml.unmarshalCustomDomainExtension(res.Location, node, valueNode)
+			if err != nil {
+				return StacktraceNewWrapped("unmarshal custom domain extension", err, res.Location,
+					WithNodePosition(valueNode))
+			}
+			res.CustomDomainProperties.Set(name, de)
+		}
+	}
+	return nil
+}
+
+// makeMethod creates a method from the given value node.
+func (r *RAML) makeMethod(
+	name string,
+	value *yaml.Node,
+	location string,
+	res *Resource,
+	defaultMediaTypes []string,
+) (*Method, error) {
+	location = r.mergedLocation(value, location)
+	m := &Method{
+		Name:                   name,
+		DisplayName:            name,
+		QueryParameters:        orderedmap.New[string, Property](0),
+		Headers:                orderedmap.New[string, Property](0),
+		Body:                   orderedmap.New[string, *Body](0),
+		Responses:              orderedmap.New[string, *Response](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Resource:               res,
+		Location:               location,
+		Position:               stacktrace.Position{Line: value.Line, Column: value.Column},
+		raml:                   r,
+	}
+	if value.Tag == TagNull {
+		return m, nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("method must be map", location, WithNodePosition(value))
+	}
+
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		if err := m.decodeValueNode(node, valueNode, defaultMediaTypes); err != nil {
+			return nil, err
+		}
+	}
+	if m.QueryString != nil && m.QueryParameters.Len() > 0 {
+		return nil, StacktraceNew("queryString and queryParameters cannot be defined together", location,
+			WithNodePosition(value))
+	}
+	return m, nil
+}
+
+func (m *Method) decodeValueNode(node, valueNode *yaml.Node, defaultMediaTypes []string) error {
+	switch node.Value {
+	case "displayName":
+		if err := valueNode.Decode(&m.DisplayName); err != nil {
+			return StacktraceNewWrapped("decode displayName", err, m.Location, WithNodePosition(valueNode))
+		}
+	case FacetDescription:
+		if err := valueNode.Decode(&m.Description); err != nil {
+			return StacktraceNewWrapped("decode description", err, m.Location, WithNodePosition(valueNode))
+		}
+	case "queryParameters":
+		params, err := m.raml.makeParameters(valueNode, m.Location)
+		if err != nil {
+			return fmt.Errorf("make query parameters: %w", err)
+		}
+		m.QueryParameters = params
+	case "queryString":
+		shape, err := m.raml.makeNewShapeYAML(valueNode, "queryString", m.raml.templateLocation(valueNode, m.Location))
+		if err != nil {
+			return StacktraceNewWrapped("make query string shape", err, m.Location, WithNodePosition(valueNode))
+		}
+		m.QueryString = shape
+	case "headers":
+		params, err := m.raml.makeParameters(valueNode, m.Location)
+		if err != nil {
+			return fmt.Errorf("make headers: %w", err)
+		}
+		m.Headers = params
+	case "body":
+		body, err := m.raml.makeBody(valueNode, m.Location, defaultMediaTypes)
+		if err != nil {
+			return fmt.Errorf("make body: %w", err)
+		}
+		m.Body = body
+	case "responses":
+		if err := m.unmarshalResponses(valueNode, defaultMediaTypes); err != nil {
+			return fmt.Errorf("unmarshal responses: %w", err)
+		}
+	case "protocols":
+		protocols, err := decodeProtocols(valueNode, m.Location)
+		if err != nil {
+			return fmt.Errorf("decode protocols: %w", err)
+		}
+		m.Protocols = protocols
+	case "securedBy":
+		reqs, err := m.raml.decodeSecuredBy(valueNode, m.Location)
+		if err != nil {
+			return fmt.Errorf("decode securedBy: %w", err)
+		}
+		m.SecuredBy = reqs
+	default:
+		if IsCustomDomainExtensionNode(node.Value) {
+			name, de, err := m.raml.unmarshalCustomDomainExtension(m.Location, node, valueNode)
+			if err != nil {
+				return StacktraceNewWrapped("unmarshal custom domain extension", err, m.Location,
+					WithNodePosition(valueNode))
+			}
+			m.CustomDomainProperties.Set(name, de)
+		}
+	}
+	return nil
+}
+
+func (m *Method) unmarshalResponses(valueNode *yaml.Node, defaultMediaTypes []string) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return StacktraceNew("responses must be map", m.Location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		codeNode := valueNode.Content[j]
+		data := valueNode.Content[j+1]
+		resp, err := m.raml.makeResponse(codeNode, data, m.Location, defaultMediaTypes)
+		if err != nil {
+			return StacktraceNewWrapped("make response", err, m.Location, WithNodePosition(codeNode),
+				stacktrace.WithInfo("code", codeNode.Value))
+		}
+		m.Responses.Set(resp.Code, resp)
+	}
+	return nil
+}
+
+// makeResponse creates a response from the given status code and value nodes.
+func (r *RAML) makeResponse(
+	codeNode *yaml.Node,
+	value *yaml.Node,
+	location string,
+	defaultMediaTypes []string,
+) (*Response, error) {
+	location = r.mergedLocation(value, location)
+	code, err := strconv.Atoi(codeNode.Value)
+	if err != nil || code < 100 || code > 599 {
+		return nil, StacktraceNew("invalid status code", location, WithNodePosition(codeNode),
+			stacktrace.WithInfo("code", codeNode.Value))
+	}
+	resp := &Response{
+		Code:                   codeNode.Value,
+		Headers:                orderedmap.New[string, Property](0),
+		Body:                   orderedmap.New[string, *Body](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Location:               location,
+		Position:               stacktrace.Position{Line: codeNode.Line, Column: codeNode.Column},
+		raml:                   r,
+	}
+	if value.Tag == TagNull {
+		return resp, nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("response must be map", location, WithNodePosition(value))
+	}
+
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		switch node.Value {
+		case FacetDescription:
+			if err := valueNode.Decode(&resp.Description); err != nil {
+				return nil, StacktraceNewWrapped("decode description", err, location, WithNodePosition(valueNode))
+			}
+		case "headers":
+			params, err := r.makeParameters(valueNode, location)
+			if err != nil {
+				return nil, fmt.Errorf("make headers: %w", err)
+			}
+			resp.Headers = params
+		case "body":
+			body, err := r.makeBody(valueNode, location, defaultMediaTypes)
+			if err != nil {
+				return nil, fmt.Errorf("make body: %w", err)
+			}
+			resp.Body = body
+		default:
+			if IsCustomDomainExtensionNode(node.Value) {
+				name, de, err := r.unmarshalCustomDomainExtension(location, node, valueNode)
+				if err != nil {
+					return nil, StacktraceNewWrapped("unmarshal custom domain extension", err, location,
+						WithNodePosition(valueNode))
+				}
+				resp.CustomDomainProperties.Set(name, de)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// isMediaTypeMap returns true if the body node declares types per media type
+// (e.g. "application/json: User") rather than a single type declaration.
+func isMediaTypeMap(valueNode *yaml.Node) bool {
+	if valueNode.Kind != yaml.MappingNode || len(valueNode.Content) == 0 {
+		return false
+	}
+	return strings.Contains(valueNode.Content[0].Value, "/")
+}
+
+// makeBodyShape creates a body shape from the given value node.
+// Bodies without a type declaration default to "any" according to the specification.
+func (r *RAML) makeBodyShape(valueNode *yaml.Node, location string) (*BaseShape, error) {
+	location = r.templateLocation(valueNode, location)
+	if valueNode.Tag == TagNull {
+		base, _, err := r.MakeNewShape("body", TypeAny, location,
+			stacktrace.Position{Line: valueNode.Line, Column: valueNode.Column})
+		if err != nil {
+			return nil, StacktraceNewWrapped("make shape", err, location, WithNodePosition(valueNode))
+		}
+		return base, nil
+	}
+	shape, err := r.makeNewShapeYAML(valueNode, "body", location)
+	if err != nil {
+		return nil, StacktraceNewWrapped("make shape", err, location, WithNodePosition(valueNode))
+	}
+	return shape, nil
+}
+
+// makeBody creates bodies keyed by media type from the given value node.
+// If the node is a type declaration, the declaration is applied to every default media type.
+func (r *RAML) makeBody(
+	valueNode *yaml.Node,
+	location string,
+	defaultMediaTypes []string,
+) (*orderedmap.OrderedMap[string, *Body], error) {
+	bodies := orderedmap.New[string, *Body](0)
+	if !isMediaTypeMap(valueNode) {
+		if len(defaultMediaTypes) == 0 {
+			return nil, StacktraceNew("body media type is not specified and no default media type is declared",
+				location, WithNodePosition(valueNode))
+		}
+		shape, err := r.makeBodyShape(valueNode, location)
+		if err != nil {
+			return nil, fmt.Errorf("make body shape: %w", err)
+		}
+		for _, mediaType := range defaultMediaTypes {
+			bodies.Set(mediaType, &Body{
+				MediaType: mediaType,
+				Shape:     shape,
+				Location:  location,
+				Position:  stacktrace.Position{Line: valueNode.Line, Column: valueNode.Column},
+			})
+		}
+		return bodies, nil
+	}
+
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		mediaTypeNode := valueNode.Content[j]
+		data := valueNode.Content[j+1]
+		if !strings.Contains(mediaTypeNode.Value, "/") {
+			return nil, StacktraceNew("invalid media type", location, WithNodePosition(mediaTypeNode),
+				stacktrace.WithInfo("media type", mediaTypeNode.Value))
+		}
+		shape, err := r.makeBodyShape(data, location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make body shape", err, r.templateLocation(data, location),
+				WithNodePosition(data),
+				stacktrace.WithInfo("media type", mediaTypeNode.Value))
+		}
+		bodies.Set(mediaTypeNode.Value, &Body{
+			MediaType: mediaTypeNode.Value,
+			Shape:     shape,
+			Location:  location,
+			Position:  stacktrace.Position{Line: mediaTypeNode.Line, Column: mediaTypeNode.Column},
+		})
+	}
+	return bodies, nil
+}
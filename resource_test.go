@@ -0,0 +1,208 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResource_FullURI(t *testing.T) {
+	root := &Resource{URI: "/users"}
+	nested := &Resource{URI: "/{id}", Parent: root}
+	require.Equal(t, "/users", root.FullURI())
+	require.Equal(t, "/users/{id}", nested.FullURI())
+}
+
+func TestAPI_ParseResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		want    func(t *testing.T, got *API)
+	}{
+		{
+			name: "positive: nested resources and methods",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  get:
+    queryParameters:
+      limit?: integer
+    headers:
+      X-Request-ID: string
+    responses:
+      200:
+        description: OK
+        headers:
+          X-Total-Count: integer
+        body: object[]
+  /{id}:
+    uriParameters:
+      id: integer
+    put:
+      body:
+        application/json:
+          properties:
+            name: string
+        text/plain:
+mediaType: application/json
+`,
+			want: func(t *testing.T, got *API) {
+				users, ok := got.Resources.Get("/users")
+				require.True(t, ok)
+				require.Equal(t, "/users", users.DisplayName)
+				get, ok := users.Methods.Get("get")
+				require.True(t, ok)
+				limit, ok := get.QueryParameters.Get("limit")
+				require.True(t, ok)
+				require.False(t, limit.Required)
+				_, ok = get.Headers.Get("X-Request-ID")
+				require.True(t, ok)
+				resp, ok := get.Responses.Get("200")
+				require.True(t, ok)
+				require.Equal(t, "OK", resp.Description)
+				_, ok = resp.Headers.Get("X-Total-Count")
+				require.True(t, ok)
+				body, ok := resp.Body.Get("application/json")
+				require.True(t, ok)
+				require.IsType(t, &ArrayShape{}, body.Shape.Shape)
+
+				item, ok := users.Resources.Get("/{id}")
+				require.True(t, ok)
+				require.Equal(t, "/users/{id}", item.FullURI())
+				_, ok = item.URIParameters.Get("id")
+				require.True(t, ok)
+				put, ok := item.Methods.Get("put")
+				require.True(t, ok)
+				require.Equal(t, item, put.Resource)
+				body, ok = put.Body.Get("application/json")
+				require.True(t, ok)
+				require.IsType(t, &ObjectShape{}, body.Shape.Shape)
+				body, ok = put.Body.Get("text/plain")
+				require.True(t, ok)
+				require.IsType(t, &AnyShape{}, body.Shape.Shape)
+			},
+		},
+		{
+			name: "positive: query string",
+			content: `#%RAML 1.0
+title: Sample API
+/search:
+  get:
+    queryString:
+      properties:
+        q: string
+`,
+			want: func(t *testing.T, got *API) {
+				search, ok := got.Resources.Get("/search")
+				require.True(t, ok)
+				get, ok := search.Methods.Get("get")
+				require.True(t, ok)
+				require.NotNil(t, get.QueryString)
+				require.IsType(t, &ObjectShape{}, get.QueryString.Shape)
+			},
+		},
+		{
+			name: "negative: body without default media type",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  post:
+    body: object
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid status code",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  get:
+    responses:
+      OK:
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: query string with query parameters",
+			content: `#%RAML 1.0
+title: Sample API
+/search:
+  get:
+    queryString: object
+    queryParameters:
+      q: string
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: resource must be map",
+			content: `#%RAML 1.0
+title: Sample API
+/users: value
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: method must be map",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  get: value
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid method protocol",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  get:
+    protocols: [FTP]
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid uri parameter",
+			content: `#%RAML 1.0
+title: Sample API
+/users/{id}:
+  uriParameters:
+    id:
+      type: integer
+      minimum: 10
+      maximum: 1
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: unresolved body type",
+			content: `#%RAML 1.0
+title: Sample API
+mediaType: application/json
+/users:
+  get:
+    responses:
+      200:
+        body: Unknown
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFromStringCtx(context.Background(), tt.content, "api.raml", "",
+				OptWithUnwrap(), OptWithValidate())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want != nil {
+				api, ok := got.EntryPoint().(*API)
+				require.True(t, ok)
+				tt.want(t, api)
+			}
+		})
+	}
+}
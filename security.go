@@ -0,0 +1,531 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Security scheme types defined by the specification.
+// Custom security schemes must use a type prefixed with "x-".
+const (
+	SecuritySchemeOAuth1      = "OAuth 1.0"
+	SecuritySchemeOAuth2      = "OAuth 2.0"
+	SecuritySchemeBasic       = "Basic Authentication"
+	SecuritySchemeDigest      = "Digest Authentication"
+	SecuritySchemePassThrough = "Pass Through"
+)
+
+// SetOfSecuritySchemeTypes contains a set of security scheme types defined by the specification.
+var SetOfSecuritySchemeTypes = map[string]struct{}{
+	SecuritySchemeOAuth1: {}, SecuritySchemeOAuth2: {}, SecuritySchemeBasic: {}, SecuritySchemeDigest: {},
+	SecuritySchemePassThrough: {},
+}
+
+// SetOfOAuth2Grants contains a set of OAuth 2.0 authorization grants defined by the specification.
+var SetOfOAuth2Grants = map[string]struct{}{
+	"authorization_code": {}, "password": {}, "client_credentials": {}, "implicit": {},
+}
+
+// IsCustomSecuritySchemeType returns true if the security scheme type is a custom one.
+func IsCustomSecuritySchemeType(schemeType string) bool {
+	return strings.HasPrefix(schemeType, "x-")
+}
+
+// SecurityScheme is a declaration of a security scheme.
+type SecurityScheme struct {
+	Name        string
+	Type        string
+	DisplayName string
+	Description string
+	DescribedBy *SecuritySchemePart
+	// Settings contains raw settings of the security scheme.
+	// Typed settings are available in OAuth1 and OAuth2 for the corresponding types.
+	Settings map[string]any
+	OAuth1   *OAuth1Settings
+	OAuth2   *OAuth2Settings
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	// Node is the raw definition of the security scheme.
+	Node *yaml.Node
+
+	Location string
+	stacktrace.Position
+}
+
+// SecuritySchemePart describes the request and responses of methods secured by the security scheme.
+type SecuritySchemePart struct {
+	Headers         *orderedmap.OrderedMap[string, Property]
+	QueryParameters *orderedmap.OrderedMap[string, Property]
+	QueryString     *BaseShape
+	Responses       *orderedmap.OrderedMap[string, *Response]
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Location string
+	stacktrace.Position
+}
+
+// OAuth1Settings contains settings of the OAuth 1.0 security scheme.
+type OAuth1Settings struct {
+	RequestTokenURI     string   `yaml:"requestTokenUri"`
+	AuthorizationURI    string   `yaml:"authorizationUri"`
+	TokenCredentialsURI string   `yaml:"tokenCredentialsUri"`
+	Signatures          []string `yaml:"signatures"`
+}
+
+// OAuth2Settings contains settings of the OAuth 2.0 security scheme.
+type OAuth2Settings struct {
+	AuthorizationURI    string   `yaml:"authorizationUri"`
+	AccessTokenURI      string   `yaml:"accessTokenUri"`
+	AuthorizationGrants []string `yaml:"authorizationGrants"`
+	Scopes              []string `yaml:"scopes"`
+}
+
+// SecurityRequirement is a security scheme applied to a method by "securedBy".
+// Scheme is nil for the "null" requirement which means that the method may be called without authentication.
+type SecurityRequirement struct {
+	Name       string
+	Scheme     *SecurityScheme
+	Parameters map[string]any
+
+	Location string
+	stacktrace.Position
+}
+
+// IsAnonymous returns true if the requirement allows calls without authentication.
+func (req *SecurityRequirement) IsAnonymous() bool {
+	return req.Name == ""
+}
+
+type ReferenceSecuritySchemeGetter interface {
+	GetReferenceSecurityScheme(refName string) (*SecurityScheme, error)
+}
+
+// makeSecurityScheme creates a security scheme from the given value node.
+func (r *RAML) makeSecurityScheme(name string, value *yaml.Node, location string) (*SecurityScheme, error) {
+	location = r.mergedLocation(value, location)
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("security scheme must be map", location, WithNodePosition(value),
+			stacktrace.WithInfo("name", name))
+	}
+	ss := &SecurityScheme{
+		Name:                   name,
+		DisplayName:            name,
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Node:                   value,
+		Location:               location,
+		Position:               stacktrace.Position{Line: value.Line, Column: value.Column},
+	}
+	var settingsNode *yaml.Node
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		switch node.Value {
+		case "type":
+			if err := valueNode.Decode(&ss.Type); err != nil {
+				return nil, StacktraceNewWrapped("decode type", err, location, WithNodePosition(valueNode))
+			}
+		case "displayName":
+			if err := valueNode.Decode(&ss.DisplayName); err != nil {
+				return nil, StacktraceNewWrapped("decode displayName", err, location, WithNodePosition(valueNode))
+			}
+		case FacetDescription:
+			if err := valueNode.Decode(&ss.Description); err != nil {
+				return nil, StacktraceNewWrapped("decode description", err, location, WithNodePosition(valueNode))
+			}
+		case "describedBy":
+			part, err := r.makeSecuritySchemePart(valueNode, location)
+			if err != nil {
+				return nil, fmt.Errorf("make security scheme part: %w", err)
+			}
+			ss.DescribedBy = part
+		case "settings":
+			settingsNode = valueNode
+		default:
+			if IsCustomDomainExtensionNode(node.Value) {
+				name, de, err := r.unmarshalCustomDomainExtension(location, node, valueNode)
+				if err != nil {
+					return nil, StacktraceNewWrapped("unmarshal custom domain extension", err, location,
+						WithNodePosition(valueNode))
+				}
+				ss.CustomDomainProperties.Set(name, de)
+			}
+		}
+	}
+	if ss.Type == "" {
+		return nil, StacktraceNew("security scheme type is required", location, WithNodePosition(value),
+			stacktrace.WithInfo("name", name))
+	}
+	if _, ok := SetOfSecuritySchemeTypes[ss.Type]; !ok && !IsCustomSecuritySchemeType(ss.Type) {
+		return nil, StacktraceNew("unknown security scheme type", location, WithNodePosition(value),
+			stacktrace.WithInfo("name", name), stacktrace.WithInfo("type", ss.Type))
+	}
+	if ss.DescribedBy == nil {
+		ss.DescribedBy = r.makeEmptySecuritySchemePart(value, location)
+	}
+	if err := ss.decodeSettings(settingsNode); err != nil {
+		return nil, fmt.Errorf("decode settings: %w", err)
+	}
+	return ss, nil
+}
+
+func (r *RAML) makeEmptySecuritySchemePart(value *yaml.Node, location string) *SecuritySchemePart {
+	return &SecuritySchemePart{
+		Headers:                orderedmap.New[string, Property](0),
+		QueryParameters:        orderedmap.New[string, Property](0),
+		Responses:              orderedmap.New[string, *Response](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Location:               location,
+		Position:               stacktrace.Position{Line: value.Line, Column: value.Column},
+	}
+}
+
+// makeSecuritySchemePart creates a "describedBy" part of the security scheme from the given value node.
+func (r *RAML) makeSecuritySchemePart(value *yaml.Node, location string) (*SecuritySchemePart, error) {
+	part := r.makeEmptySecuritySchemePart(value, location)
+	if value.Tag == TagNull {
+		return part, nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("describedBy must be map", location, WithNodePosition(value))
+	}
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		switch node.Value {
+		case "headers":
+			params, err := r.makeParameters(valueNode, location)
+			if err != nil {
+				return nil, fmt.Errorf("make headers: %w", err)
+			}
+			part.Headers = params
+		case "queryParameters":
+			params, err := r.makeParameters(valueNode, location)
+			if err != nil {
+				return nil, fmt.Errorf("make query parameters: %w", err)
+			}
+			part.QueryParameters = params
+		case "queryString":
+			shape, err := r.makeNewShapeYAML(valueNode, "queryString", location)
+			if err != nil {
+				return nil, StacktraceNewWrapped("make query string shape", err, location, WithNodePosition(valueNode))
+			}
+			part.QueryString = shape
+		case "responses":
+			if valueNode.Kind != yaml.MappingNode {
+				return nil, StacktraceNew("responses must be map", location, WithNodePosition(valueNode))
+			}
+			for j := 0; j != len(valueNode.Content); j += 2 {
+				resp, err := r.makeResponse(valueNode.Content[j], valueNode.Content[j+1], location, nil)
+				if err != nil {
+					return nil, StacktraceNewWrapped("make response", err, location,
+						WithNodePosition(valueNode.Content[j]))
+				}
+				part.Responses.Set(resp.Code, resp)
+			}
+		default:
+			if IsCustomDomainExtensionNode(node.Value) {
+				name, de, err := r.unmarshalCustomDomainExtension(location, node, valueNode)
+				if err != nil {
+					return nil, StacktraceNewWrapped("unmarshal custom domain extension", err, location,
+						WithNodePosition(valueNode))
+				}
+				part.CustomDomainProperties.Set(name, de)
+			}
+		}
+	}
+	if part.QueryString != nil && part.QueryParameters.Len() > 0 {
+		return nil, StacktraceNew("queryString and queryParameters cannot be defined together", location,
+			WithNodePosition(value))
+	}
+	return part, nil
+}
+
+// decodeSettings decodes settings of the security scheme according to its type.
+func (ss *SecurityScheme) decodeSettings(value *yaml.Node) error {
+	ss.Settings = make(map[string]any)
+	if value != nil && value.Tag != TagNull {
+		if value.Kind != yaml.MappingNode {
+			return StacktraceNew("settings must be map", ss.Location, WithNodePosition(value))
+		}
+		if err := value.Decode(&ss.Settings); err != nil {
+			return StacktraceNewWrapped("decode settings", err, ss.Location, WithNodePosition(value))
+		}
+	}
+	pos := &ss.Position
+	if value != nil {
+		pos = &stacktrace.Position{Line: value.Line, Column: value.Column}
+	}
+	switch ss.Type {
+	case SecuritySchemeOAuth1:
+		s := &OAuth1Settings{}
+		if value != nil && value.Tag != TagNull {
+			if err := value.Decode(s); err != nil {
+				return StacktraceNewWrapped("decode oauth 1.0 settings", err, ss.Location, WithNodePosition(value))
+			}
+		}
+		if s.RequestTokenURI == "" || s.AuthorizationURI == "" || s.TokenCredentialsURI == "" {
+			return StacktraceNew("oauth 1.0 settings require requestTokenUri, authorizationUri and tokenCredentialsUri",
+				ss.Location, stacktrace.WithPosition(pos), stacktrace.WithInfo("name", ss.Name))
+		}
+		ss.OAuth1 = s
+	case SecuritySchemeOAuth2:
+		s := &OAuth2Settings{}
+		if value != nil && value.Tag != TagNull {
+			if err := value.Decode(s); err != nil {
+				return StacktraceNewWrapped("decode oauth 2.0 settings", err, ss.Location, WithNodePosition(value))
+			}
+		}
+		if s.AccessTokenURI == "" {
+			return StacktraceNew("oauth 2.0 settings require accessTokenUri", ss.Location,
+				stacktrace.WithPosition(pos), stacktrace.WithInfo("name", ss.Name))
+		}
+		if len(s.AuthorizationGrants) == 0 {
+			return StacktraceNew("oauth 2.0 settings require authorizationGrants", ss.Location,
+				stacktrace.WithPosition(pos), stacktrace.WithInfo("name", ss.Name))
+		}
+		for _, grant := range s.AuthorizationGrants {
+			// Custom grants must be absolute URIs.
+			if _, ok := SetOfOAuth2Grants[grant]; !ok && !strings.Contains(grant, ":") {
+				return StacktraceNew("invalid authorization grant", ss.Location, stacktrace.WithPosition(pos),
+					stacktrace.WithInfo("name", ss.Name), stacktrace.WithInfo("grant", grant))
+			}
+			if (grant == "authorization_code" || grant == "implicit") && s.AuthorizationURI == "" {
+				return StacktraceNew("oauth 2.0 settings require authorizationUri for the grant", ss.Location,
+					stacktrace.WithPosition(pos), stacktrace.WithInfo("name", ss.Name),
+					stacktrace.WithInfo("grant", grant))
+			}
+		}
+		ss.OAuth2 = s
+	}
+	return nil
+}
+
+// makeSecuritySchemes creates security scheme declarations from the given value node.
+func (r *RAML) makeSecuritySchemes(
+	valueNode *yaml.Node,
+	location string,
+) (*orderedmap.OrderedMap[string, *SecurityScheme], error) {
+	schemes := orderedmap.New[string, *SecurityScheme](0)
+	if valueNode.Tag == TagNull {
+		return schemes, nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("security schemes must be map", location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		if data.Tag == TagInclude {
+			frag, err := r.parseSecurityScheme(r.includedLocation(data, location))
+			if err != nil {
+				return nil, StacktraceNewWrapped("parse security scheme", err, location, WithNodePosition(data),
+					stacktrace.WithInfo("name", name))
+			}
+			ss := *frag.SecurityScheme
+			ss.Name = name
+			if ss.DisplayName == "" {
+				ss.DisplayName = name
+			}
+			schemes.Set(name, &ss)
+			continue
+		}
+		ss, err := r.makeSecurityScheme(name, data, location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make security scheme", err, location, WithNodePosition(data))
+		}
+		schemes.Set(name, ss)
+	}
+	return schemes, nil
+}
+
+// GetReferencedSecurityScheme returns a security scheme referenced from the fragment at the given location.
+func (r *RAML) GetReferencedSecurityScheme(refName string, location string) (*SecurityScheme, error) {
+	frag, ok := r.GetFragment(location).(ReferenceSecuritySchemeGetter)
+	if !ok {
+		return nil, fmt.Errorf("fragment not found")
+	}
+	ref, err := frag.GetReferenceSecurityScheme(refName)
+	if err != nil {
+		return nil, fmt.Errorf("get reference security scheme: %s: %w", refName, err)
+	}
+	return ref, nil
+}
+
+// decodeSecuredBy decodes security requirements from the "securedBy" node.
+// Schemes are resolved separately since libraries may not be parsed yet.
+func (r *RAML) decodeSecuredBy(valueNode *yaml.Node, location string) ([]*SecurityRequirement, error) {
+	location = r.templateLocation(valueNode, location)
+	if valueNode.Kind != yaml.SequenceNode {
+		return nil, StacktraceNew("securedBy must be sequence", location, WithNodePosition(valueNode))
+	}
+	reqs := make([]*SecurityRequirement, 0, len(valueNode.Content))
+	for _, item := range valueNode.Content {
+		req := &SecurityRequirement{
+			Location: r.templateLocation(item, location),
+			Position: stacktrace.Position{Line: item.Line, Column: item.Column},
+		}
+		switch {
+		case item.Tag == TagNull:
+			// Null requirement allows anonymous access.
+		case item.Kind == yaml.ScalarNode:
+			req.Name = item.Value
+		case item.Kind == yaml.MappingNode && len(item.Content) == 2:
+			req.Name = item.Content[0].Value
+			params := item.Content[1]
+			if params.Tag != TagNull {
+				if params.Kind != yaml.MappingNode {
+					return nil, StacktraceNew("security scheme parameters must be map", location,
+						WithNodePosition(params), stacktrace.WithInfo("name", req.Name))
+				}
+				if err := params.Decode(&req.Parameters); err != nil {
+					return nil, StacktraceNewWrapped("decode security scheme parameters", err, location,
+						WithNodePosition(params), stacktrace.WithInfo("name", req.Name))
+				}
+			}
+		default:
+			return nil, StacktraceNew("invalid security requirement", location, WithNodePosition(item))
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// resolveSecurityRequirements resolves security schemes of the requirements in-place.
+func (r *RAML) resolveSecurityRequirements(reqs []*SecurityRequirement) error {
+	for _, req := range reqs {
+		if req.IsAnonymous() || req.Scheme != nil {
+			continue
+		}
+		scheme, err := r.GetReferencedSecurityScheme(req.Name, req.Location)
+		if err != nil {
+			return StacktraceNewWrapped("get referenced security scheme", err, req.Location,
+				stacktrace.WithPosition(&req.Position), stacktrace.WithInfo("name", req.Name))
+		}
+		if scheme.Type == SecuritySchemeOAuth2 {
+			if err = checkOAuth2Scopes(scheme, req); err != nil {
+				return err
+			}
+		}
+		req.Scheme = scheme
+	}
+	return nil
+}
+
+// checkOAuth2Scopes checks that scopes requested by the requirement are declared by the OAuth 2.0 scheme.
+func checkOAuth2Scopes(scheme *SecurityScheme, req *SecurityRequirement) error {
+	scopes, ok := req.Parameters["scopes"]
+	if !ok || len(scheme.OAuth2.Scopes) == 0 {
+		return nil
+	}
+	list, ok := scopes.([]any)
+	if !ok {
+		return StacktraceNew("scopes must be sequence", req.Location, stacktrace.WithPosition(&req.Position),
+			stacktrace.WithInfo("name", req.Name))
+	}
+	declared := make(map[string]struct{}, len(scheme.OAuth2.Scopes))
+	for _, scope := range scheme.OAuth2.Scopes {
+		declared[scope] = struct{}{}
+	}
+	for _, scope := range list {
+		if _, ok := declared[fmt.Sprint(scope)]; !ok {
+			return StacktraceNew("scope is not declared by security scheme", req.Location,
+				stacktrace.WithPosition(&req.Position), stacktrace.WithInfo("name", req.Name),
+				stacktrace.WithInfo("scope", scope))
+		}
+	}
+	return nil
+}
+
+// applySecuredBy resolves the effective security requirements of methods of the resources and their nested resources.
+// Requirements of the method take precedence over requirements of the resource, which take precedence over
+// requirements of the root. Security schemes "describedBy" is merged into the secured methods like a trait.
+// Requirements are alternatives, so parameters of a scheme are required only if it is the only alternative.
+func (r *RAML) applySecuredBy(
+	resources *orderedmap.OrderedMap[string, *Resource],
+	rootSecuredBy []*SecurityRequirement,
+) error {
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		res := pair.Value
+		if err := r.resolveSecurityRequirements(res.SecuredBy); err != nil {
+			return fmt.Errorf("resolve resource security requirements: %w", err)
+		}
+		for mPair := res.Methods.Oldest(); mPair != nil; mPair = mPair.Next() {
+			m := mPair.Value
+			if m.SecuredBy == nil {
+				m.SecuredBy = res.SecuredBy
+			}
+			if m.SecuredBy == nil {
+				m.SecuredBy = rootSecuredBy
+			}
+			if err := r.resolveSecurityRequirements(m.SecuredBy); err != nil {
+				return StacktraceNewWrapped("resolve method security requirements", err, m.Location,
+					stacktrace.WithPosition(&m.Position), stacktrace.WithInfo("method", m.Name))
+			}
+			required := len(m.SecuredBy) == 1
+			for _, req := range m.SecuredBy {
+				if req.Scheme != nil {
+					m.mergeSecuritySchemePart(req.Scheme.DescribedBy, required)
+				}
+			}
+		}
+		if err := r.applySecuredBy(res.Resources, rootSecuredBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeSecuritySchemePart merges the "describedBy" part of the security scheme into the method.
+// Declarations of the method take precedence. Unless required, headers and query parameters are merged as optional
+// and query string is not merged, since it would apply to requests secured by other schemes.
+func (m *Method) mergeSecuritySchemePart(part *SecuritySchemePart, required bool) {
+	mergeParameters(m.Headers, part.Headers, required)
+	if m.QueryString == nil {
+		mergeParameters(m.QueryParameters, part.QueryParameters, required)
+	}
+	if required && m.QueryString == nil && m.QueryParameters.Len() == 0 {
+		m.QueryString = part.QueryString
+	}
+	for pair := part.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		src := pair.Value
+		resp, ok := m.Responses.Get(pair.Key)
+		if !ok {
+			resp = &Response{
+				Code:                   src.Code,
+				Description:            src.Description,
+				Headers:                orderedmap.New[string, Property](src.Headers.Len()),
+				Body:                   orderedmap.New[string, *Body](src.Body.Len()),
+				CustomDomainProperties: src.CustomDomainProperties,
+				Location:               src.Location,
+				Position:               src.Position,
+				raml:                   src.raml,
+			}
+			m.Responses.Set(pair.Key, resp)
+		}
+		mergeParameters(resp.Headers, src.Headers, true)
+		for bPair := src.Body.Oldest(); bPair != nil; bPair = bPair.Next() {
+			if _, ok = resp.Body.Get(bPair.Key); !ok {
+				b := *bPair.Value
+				resp.Body.Set(bPair.Key, &b)
+			}
+		}
+	}
+}
+
+// mergeParameters adds parameters of src that are not declared in dst.
+// Unless required, the parameters are added as optional.
+func mergeParameters(dst, src *orderedmap.OrderedMap[string, Property], required bool) {
+	for pair := src.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := dst.Get(pair.Key); !ok {
+			p := pair.Value
+			p.Required = p.Required && required
+			dst.Set(pair.Key, p)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package raml
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAPI_ParseSecuritySchemes(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_security.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+
+	oauth2, ok := api.SecuritySchemes.Get("oauth_2_0")
+	require.True(t, ok)
+	require.Equal(t, SecuritySchemeOAuth2, oauth2.Type)
+	require.NotNil(t, oauth2.OAuth2)
+	require.Equal(t, "https://example.com/oauth2/token", oauth2.OAuth2.AccessTokenURI)
+	require.Equal(t, []string{"authorization_code", "client_credentials"}, oauth2.OAuth2.AuthorizationGrants)
+	require.Equal(t, []string{"read", "write"}, oauth2.OAuth2.Scopes)
+	_, ok = oauth2.DescribedBy.Headers.Get("Authorization")
+	require.True(t, ok)
+
+	oauth1, ok := api.SecuritySchemes.Get("oauth_1_0")
+	require.True(t, ok)
+	require.NotNil(t, oauth1.OAuth1)
+	require.Equal(t, []string{"HMAC-SHA1"}, oauth1.OAuth1.Signatures)
+
+	users, ok := api.Resources.Get("/users")
+	require.True(t, ok)
+
+	// Root securedBy is applied to methods that do not declare requirements.
+	get, ok := users.Methods.Get("get")
+	require.True(t, ok)
+	require.Len(t, get.SecuredBy, 1)
+	require.Same(t, oauth2, get.SecuredBy[0].Scheme)
+	require.Equal(t, []any{"read"}, get.SecuredBy[0].Parameters["scopes"])
+	_, ok = get.Headers.Get("Authorization")
+	require.True(t, ok)
+	resp, ok := get.Responses.Get("401")
+	require.True(t, ok)
+	require.Equal(t, "Token is invalid or expired.", resp.Description)
+
+	// Declarations of the method take precedence over describedBy.
+	post, ok := users.Methods.Get("post")
+	require.True(t, ok)
+	require.Len(t, post.SecuredBy, 2)
+	require.Equal(t, "basic", post.SecuredBy[1].Name)
+	resp, ok = post.Responses.Get("401")
+	require.True(t, ok)
+	require.Equal(t, "Custom unauthorized response.", resp.Description)
+
+	// Resource securedBy is applied to its methods, null allows anonymous access.
+	user, ok := users.Resources.Get("/{userId}")
+	require.True(t, ok)
+	get, ok = user.Methods.Get("get")
+	require.True(t, ok)
+	require.Len(t, get.SecuredBy, 2)
+	require.Equal(t, SecuritySchemeDigest, get.SecuredBy[0].Scheme.Type)
+	require.True(t, get.SecuredBy[1].IsAnonymous())
+	require.Nil(t, get.SecuredBy[1].Scheme)
+
+	admin, ok := api.Resources.Get("/admin")
+	require.True(t, ok)
+	get, ok = admin.Methods.Get("get")
+	require.True(t, ok)
+	require.Equal(t, "x-custom", get.SecuredBy[0].Scheme.Type)
+	require.Equal(t, map[string]any{"realm": "internal"}, get.SecuredBy[0].Scheme.Settings)
+	_, ok = get.Headers.Get("X-Custom-Token")
+	require.True(t, ok)
+
+	// securedBy of a trait is applied to the method.
+	del, ok := admin.Methods.Get("delete")
+	require.True(t, ok)
+	require.Equal(t, "api_key", del.SecuredBy[0].Name)
+	_, ok = del.QueryParameters.Get("apiKey")
+	require.True(t, ok)
+}
+
+func TestAPI_ApplySecuredBy(t *testing.T) {
+	const schemes = `#%RAML 1.0
+title: Secured API
+securitySchemes:
+  token:
+    type: Pass Through
+    describedBy:
+      headers:
+        X-Token: string
+  api_key:
+    type: Pass Through
+    describedBy:
+      queryParameters:
+        apiKey: string
+`
+	tests := []struct {
+		name         string
+		securedBy    string
+		wantRequired map[string]bool
+	}{
+		{
+			name:         "positive: single scheme",
+			securedBy:    "[token]",
+			wantRequired: map[string]bool{"X-Token": true},
+		},
+		{
+			name:         "positive: scheme or anonymous",
+			securedBy:    "[null, token]",
+			wantRequired: map[string]bool{"X-Token": false},
+		},
+		{
+			name:         "positive: two schemes",
+			securedBy:    "[token, api_key]",
+			wantRequired: map[string]bool{"X-Token": false, "apiKey": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := schemes + "/users:\n  get:\n    securedBy: " + tt.securedBy + "\n"
+			rml, err := ParseFromStringCtx(context.Background(), content, "api.raml", "",
+				OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+			api, ok := rml.EntryPoint().(*API)
+			require.True(t, ok)
+			users, _ := api.Resources.Get("/users")
+			get, ok := users.Methods.Get("get")
+			require.True(t, ok)
+			got := make(map[string]bool)
+			for pair := get.Headers.Oldest(); pair != nil; pair = pair.Next() {
+				got[pair.Key] = pair.Value.Required
+			}
+			for pair := get.QueryParameters.Oldest(); pair != nil; pair = pair.Next() {
+				got[pair.Key] = pair.Value.Required
+			}
+			require.Equal(t, tt.wantRequired, got)
+		})
+	}
+}
+
+func TestRAML_makeSecurityScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{
+			name:  "positive: pass through",
+			value: "type: Pass Through",
+		},
+		{
+			name:  "positive: custom",
+			value: "type: x-custom\nsettings:\n  anything: true",
+		},
+		{
+			name:    "negative: type is missing",
+			value:   "description: No type",
+			wantErr: "security scheme type is required",
+		},
+		{
+			name:    "negative: unknown type",
+			value:   "type: Kerberos",
+			wantErr: "unknown security scheme type",
+		},
+		{
+			name:    "negative: oauth 2.0 without access token uri",
+			value:   "type: OAuth 2.0\nsettings:\n  authorizationGrants: [password]",
+			wantErr: "oauth 2.0 settings require accessTokenUri",
+		},
+		{
+			name: "negative: oauth 2.0 authorization code without authorization uri",
+			value: "type: OAuth 2.0\nsettings:\n  accessTokenUri: https://example.com/token\n" +
+				"  authorizationGrants: [authorization_code]",
+			wantErr: "oauth 2.0 settings require authorizationUri for the grant",
+		},
+		{
+			name:    "negative: oauth 1.0 without settings",
+			value:   "type: OAuth 1.0",
+			wantErr: "oauth 1.0 settings require",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tt.value), &doc))
+			r := New(context.Background())
+			_, err := r.makeSecurityScheme("scheme", doc.Content[0], "scheme.raml")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("makeSecurityScheme() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"sync/atomic"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -68,7 +67,7 @@ type BaseShape struct {
 
 	// Controlled by UnwrapShape
 	unwrapped bool
-	// NOTE: Not thread safe and should be used only in one method simultaneously.
+	// Deprecated: Not used anymore. Traversals track visited shapes per call.
 	ShapeVisited bool
 
 	raml *RAML
@@ -124,16 +123,22 @@ func (s *BaseShape) Validate(v interface{}) error {
 const HookBeforeBaseShapeInherit = "BaseShape.Inherit"
 
 func (s *BaseShape) Inherit(sourceBase *BaseShape) (*BaseShape, error) {
+	return s.inheritFrom(sourceBase, make(map[*BaseShape]struct{}))
+}
+
+// inheritFrom merges the source shape into the shape. Visited contains source shapes on the current inheritance path.
+func (s *BaseShape) inheritFrom(sourceBase *BaseShape, visited map[*BaseShape]struct{}) (*BaseShape, error) {
 	if err := s.callRAMLHooks(HookBeforeBaseShapeInherit, sourceBase); err != nil {
 		return nil, err
 	}
 
 	// Avoid recursion caused by inheritance chain
-	if sourceBase.ShapeVisited {
+	if _, ok := visited[sourceBase]; ok {
 		// NOTE: We do not mark any recursions here. External code must handle this case.
 		return sourceBase, nil
 	}
-	sourceBase.ShapeVisited = true
+	visited[sourceBase] = struct{}{}
+	defer delete(visited, sourceBase)
 
 	source := sourceBase.Shape
 	target := s.Shape
@@ -164,24 +169,32 @@ func (s *BaseShape) Inherit(sourceBase *BaseShape) (*BaseShape, error) {
 
 	switch {
 	case isSourceUnion && !isTargetUnion:
-		return s.inheritUnionSource(sourceUnion)
+		return s.inheritUnionSource(sourceUnion, visited)
 
 	case isTargetUnion && !isSourceUnion:
-		return s.inheritUnionTarget(targetUnion)
+		return s.inheritUnionTarget(targetUnion, visited)
 	}
 	// Homogenous types produce same type
-	_, err := target.inherit(source)
+	_, err := inheritShape(target, source, visited)
 	if err != nil {
 		return nil, StacktraceNewWrapped("merge shapes", err, target.Base().Location,
 			stacktrace.WithPosition(&target.Base().Position))
 	}
-	sourceBase.ShapeVisited = false
 	return s, nil
 }
 
+// inheritShape merges the source shape into the target shape.
+// Shapes with nested shapes share the visited sources of the current inheritance path.
+func inheritShape(target Shape, source Shape, visited map[*BaseShape]struct{}) (Shape, error) {
+	if t, ok := target.(nestedShapeInheritor); ok {
+		return t.inheritNested(source, visited)
+	}
+	return target.inherit(source)
+}
+
 const HookBeforeBaseShapeInheritUnionSource = "BaseShape.inheritUnionSource"
 
-func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, error) {
+func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape, visited map[*BaseShape]struct{}) (*BaseShape, error) {
 	if err := s.callRAMLHooks(HookBeforeBaseShapeInheritUnionSource, sourceUnion); err != nil {
 		return nil, err
 	}
@@ -198,7 +211,7 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 			tc.ID = s.raml.generateShapeID()
 			// TODO: Probably all copied shapes must change IDs since these are actually new shapes.
 			// tc.ID = generateShapeID()
-			is, err := tc.Inherit(source)
+			is, err := tc.inheritFrom(source, visited)
 			if err != nil {
 				se := StacktraceNewWrapped("merge shapes", err, s.Location,
 					stacktrace.WithPosition(&s.Position))
@@ -238,14 +251,14 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 
 const HookBeforeBaseShapeInheritUnionTarget = "BaseShape.inheritUnionTarget"
 
-func (s *BaseShape) inheritUnionTarget(targetUnion *UnionShape) (*BaseShape, error) {
+func (s *BaseShape) inheritUnionTarget(targetUnion *UnionShape, visited map[*BaseShape]struct{}) (*BaseShape, error) {
 	if err := s.callRAMLHooks(HookBeforeBaseShapeInheritUnionTarget, targetUnion); err != nil {
 		return nil, err
 	}
 	var st *stacktrace.StackTrace
 	for _, item := range targetUnion.AnyOf {
 		// Merge will raise an error in case any of union members has incompatible type
-		_, err := item.Inherit(s)
+		_, err := item.inheritFrom(s, visited)
 		if err != nil {
 			se := StacktraceNewWrapped("merge shapes", err, targetUnion.Base().Location,
 				stacktrace.WithPosition(&targetUnion.Base().Position))
@@ -431,6 +444,11 @@ type ShapeInheritor interface {
 	inherit(source Shape) (Shape, error)
 }
 
+// nestedShapeInheritor is implemented by shapes that inherit nested shapes, e.g. array items or properties.
+type nestedShapeInheritor interface {
+	inheritNested(source Shape, visited map[*BaseShape]struct{}) (Shape, error)
+}
+
 // ShapeCloner is the interface that provide clone implementation for a RAML shape.
 type ShapeCloner interface {
 	clone(base *BaseShape, clonedMap map[int64]*BaseShape) Shape
@@ -545,7 +563,13 @@ func (r *RAML) MakeJSONShape(base *BaseShape, rawSchema string) (*JSONShape, err
 			stacktrace.WithPosition(&base.Position))
 	}
 
-	return &JSONShape{BaseShape: base, Raw: rawSchema, Schema: schema}, nil
+	validator, err := newJSONSchemaValidatorFromRaw(rawSchema)
+	if err != nil {
+		return nil, StacktraceNewWrapped("compile json schema", err, base.Location,
+			stacktrace.WithPosition(&base.Position))
+	}
+
+	return &JSONShape{BaseShape: base, Raw: rawSchema, Schema: schema, validator: validator}, nil
 }
 
 const HookBeforeRAMLMakeConcreteShapeYAML = "before:RAML.makeConcreteShapeYAML"
@@ -671,8 +695,7 @@ func (r *RAML) makeShapeType(
 				return shapeType, s, nil
 			}
 		case TagInclude:
-			baseDir := filepath.Dir(location)
-			dt, errParse := r.parseDataType(filepath.Join(baseDir, shapeTypeNode.Value))
+			dt, errParse := r.parseDataType(r.includedLocation(shapeTypeNode, location))
 			if errParse != nil {
 				return "", nil, StacktraceNewWrapped("parse data", errParse, location,
 					WithNodePosition(shapeTypeNode))
@@ -730,6 +753,7 @@ func (r *RAML) makeNewShapeYAML(v *yaml.Node, name string, location string) (*Ba
 	if err := r.callHooks(HookBeforeRAMLMakeNewShapeYAML, v); err != nil {
 		return nil, err
 	}
+	location = r.mergedLocation(v, location)
 
 	base := r.MakeBaseShape(name, location, stacktrace.Position{Line: v.Line, Column: v.Column})
 
@@ -774,8 +798,7 @@ func (s *BaseShape) decodeExamples(valueNode *yaml.Node) error {
 			WithNodePosition(valueNode))
 	}
 	if valueNode.Kind == yaml.ScalarNode && valueNode.Tag == "!include" {
-		baseDir := filepath.Dir(s.Location)
-		n, err := s.raml.parseNamedExample(filepath.Join(baseDir, valueNode.Value))
+		n, err := s.raml.parseNamedExample(s.raml.includedLocation(valueNode, s.Location))
 		if err != nil {
 			return StacktraceNewWrapped("parse named example", err, s.Location,
 				WithNodePosition(valueNode))
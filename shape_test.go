@@ -543,7 +543,7 @@ func TestBaseShape_inheritUnionSource(t *testing.T) {
 			if tt.prepare != nil {
 				tt.prepare(s)
 			}
-			got, err := s.inheritUnionSource(tt.args.sourceUnion)
+			got, err := s.inheritUnionSource(tt.args.sourceUnion, make(map[*BaseShape]struct{}))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("inheritUnionSource() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -727,7 +727,7 @@ func TestBaseShape_inheritUnionTarget(t *testing.T) {
 			if tt.prepare != nil {
 				tt.prepare(s)
 			}
-			got, err := s.inheritUnionTarget(tt.args.targetUnion)
+			got, err := s.inheritUnionTarget(tt.args.targetUnion, make(map[*BaseShape]struct{}))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("inheritUnionTarget() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,761 @@
+package raml
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceType is a declaration of a resource type. Its definition is kept as a raw YAML node
+// because parameters can only be substituted when the resource type is applied to a resource.
+type ResourceType struct {
+	Name  string
+	Usage string
+	Node  *yaml.Node
+
+	Location string
+	stacktrace.Position
+}
+
+// Trait is a declaration of a trait. Its definition is kept as a raw YAML node
+// because parameters can only be substituted when the trait is applied to a method.
+type Trait struct {
+	Name  string
+	Usage string
+	Node  *yaml.Node
+
+	Location string
+	stacktrace.Position
+}
+
+type ReferenceResourceTypeGetter interface {
+	GetReferenceResourceType(refName string) (*ResourceType, error)
+}
+
+type ReferenceTraitGetter interface {
+	GetReferenceTrait(refName string) (*Trait, error)
+}
+
+// Reserved parameters that are available in resource types and traits.
+const (
+	ParamResourcePath     = "resourcePath"
+	ParamResourcePathName = "resourcePathName"
+	ParamMethodName       = "methodName"
+)
+
+// makeTemplateNode returns a raw definition node of a resource type or a trait and its usage.
+func makeTemplateNode(name string, data *yaml.Node, location string) (*yaml.Node, string, error) {
+	node := data
+	switch {
+	case data.Tag == TagNull:
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: data.Line, Column: data.Column}
+	case data.Kind != yaml.MappingNode:
+		return nil, "", StacktraceNew("declaration must be map", location, WithNodePosition(data),
+			stacktrace.WithInfo("name", name))
+	}
+	var usage string
+	if usageNode := findMappingValue(node, "usage"); usageNode != nil {
+		if err := usageNode.Decode(&usage); err != nil {
+			return nil, "", StacktraceNewWrapped("decode usage", err, location, WithNodePosition(usageNode),
+				stacktrace.WithInfo("name", name))
+		}
+	}
+	return node, usage, nil
+}
+
+// makeResourceTypes creates resource type declarations from the given value node.
+func (r *RAML) makeResourceTypes(
+	valueNode *yaml.Node,
+	location string,
+) (*orderedmap.OrderedMap[string, *ResourceType], error) {
+	resourceTypes := orderedmap.New[string, *ResourceType](0)
+	if valueNode.Tag == TagNull {
+		return resourceTypes, nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("resource types must be map", location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		if data.Tag == TagInclude {
+			frag, err := r.parseResourceType(r.includedLocation(data, location))
+			if err != nil {
+				return nil, StacktraceNewWrapped("parse resource type", err, location, WithNodePosition(data),
+					stacktrace.WithInfo("name", name))
+			}
+			rt := *frag.ResourceType
+			rt.Name = name
+			resourceTypes.Set(name, &rt)
+			continue
+		}
+		dataLocation := r.mergedLocation(data, location)
+		node, usage, err := makeTemplateNode(name, data, dataLocation)
+		if err != nil {
+			return nil, fmt.Errorf("make template node: %w", err)
+		}
+		resourceTypes.Set(name, &ResourceType{
+			Name:     name,
+			Usage:    usage,
+			Node:     node,
+			Location: dataLocation,
+			Position: stacktrace.Position{Line: data.Line, Column: data.Column},
+		})
+	}
+	return resourceTypes, nil
+}
+
+// makeTraits creates trait declarations from the given value node.
+func (r *RAML) makeTraits(valueNode *yaml.Node, location string) (*orderedmap.OrderedMap[string, *Trait], error) {
+	traits := orderedmap.New[string, *Trait](0)
+	if valueNode.Tag == TagNull {
+		return traits, nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return nil, StacktraceNew("traits must be map", location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		if data.Tag == TagInclude {
+			frag, err := r.parseTrait(r.includedLocation(data, location))
+			if err != nil {
+				return nil, StacktraceNewWrapped("parse trait", err, location, WithNodePosition(data),
+					stacktrace.WithInfo("name", name))
+			}
+			trait := *frag.Trait
+			trait.Name = name
+			traits.Set(name, &trait)
+			continue
+		}
+		dataLocation := r.mergedLocation(data, location)
+		node, usage, err := makeTemplateNode(name, data, dataLocation)
+		if err != nil {
+			return nil, fmt.Errorf("make template node: %w", err)
+		}
+		traits.Set(name, &Trait{
+			Name:     name,
+			Usage:    usage,
+			Node:     node,
+			Location: dataLocation,
+			Position: stacktrace.Position{Line: data.Line, Column: data.Column},
+		})
+	}
+	return traits, nil
+}
+
+// GetReferencedResourceType returns a resource type referenced from the fragment at the given location.
+func (r *RAML) GetReferencedResourceType(refName string, location string) (*ResourceType, error) {
+	frag, ok := r.GetFragment(location).(ReferenceResourceTypeGetter)
+	if !ok {
+		return nil, fmt.Errorf("fragment not found")
+	}
+	ref, err := frag.GetReferenceResourceType(refName)
+	if err != nil {
+		return nil, fmt.Errorf("get reference resource type: %s: %w", refName, err)
+	}
+	return ref, nil
+}
+
+// GetReferencedTrait returns a trait referenced from the fragment at the given location.
+func (r *RAML) GetReferencedTrait(refName string, location string) (*Trait, error) {
+	frag, ok := r.GetFragment(location).(ReferenceTraitGetter)
+	if !ok {
+		return nil, fmt.Errorf("fragment not found")
+	}
+	ref, err := frag.GetReferenceTrait(refName)
+	if err != nil {
+		return nil, fmt.Errorf("get reference trait: %s: %w", refName, err)
+	}
+	return ref, nil
+}
+
+// findMappingValue returns a value node of the mapping node by key or nil if key is not found.
+func findMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeMappingKey removes the key from the mapping node and returns its value node or nil if key is not found.
+func removeMappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// templateRef is a reference to a resource type or a trait with its parameters.
+type templateRef struct {
+	Name   string
+	Params map[string]*yaml.Node
+	Node   *yaml.Node
+	// Location is the location of the fragment where the reference is declared.
+	Location string
+}
+
+// decodeTemplateRef decodes a reference in the form of "name" or "{name: {param: value}}".
+func decodeTemplateRef(node *yaml.Node, location string) (templateRef, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value == "" {
+			return templateRef{}, StacktraceNew("reference name is empty", location, WithNodePosition(node))
+		}
+		return templateRef{Name: node.Value, Node: node, Location: location}, nil
+	case yaml.MappingNode:
+		if len(node.Content) != 2 {
+			return templateRef{}, StacktraceNew("reference must contain exactly one name", location,
+				WithNodePosition(node))
+		}
+		ref := templateRef{
+			Name:     node.Content[0].Value,
+			Params:   make(map[string]*yaml.Node),
+			Node:     node,
+			Location: location,
+		}
+		params := node.Content[1]
+		if params.Tag == TagNull {
+			return ref, nil
+		}
+		if params.Kind != yaml.MappingNode {
+			return templateRef{}, StacktraceNew("reference parameters must be map", location,
+				WithNodePosition(params))
+		}
+		for j := 0; j != len(params.Content); j += 2 {
+			ref.Params[params.Content[j].Value] = params.Content[j+1]
+		}
+		return ref, nil
+	default:
+		return templateRef{}, StacktraceNew("reference must be string or map", location, WithNodePosition(node))
+	}
+}
+
+// decodeTemplateRefs decodes the "is" node, which is a sequence of references.
+func decodeTemplateRefs(node *yaml.Node, location string) ([]templateRef, error) {
+	if node.Tag == TagNull {
+		return nil, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, StacktraceNew("is must be sequence", location, WithNodePosition(node))
+	}
+	refs := make([]templateRef, 0, len(node.Content))
+	for _, item := range node.Content {
+		ref, err := decodeTemplateRef(item, location)
+		if err != nil {
+			return nil, fmt.Errorf("decode template ref: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+var templateParamRegexp = regexp.MustCompile(`<<\s*([^\s|>]+)\s*((?:\|\s*![A-Za-z]+\s*)*)>>`)
+
+// templateParams holds parameter values used for substitution in resource types and traits.
+type templateParams struct {
+	values map[string]*yaml.Node
+	// refParams contains names of parameters provided by the reference, as opposed to reserved parameters.
+	refParams map[string]struct{}
+	// location is the location of the template declaration.
+	location string
+	// refLocation is the location of the reference that provides parameter values.
+	refLocation string
+}
+
+// makeTemplateParams creates parameters from the reference parameters and reserved parameters.
+func makeTemplateParams(ref templateRef, reserved map[string]string) templateParams {
+	values := make(map[string]*yaml.Node, len(ref.Params)+len(reserved))
+	refParams := make(map[string]struct{}, len(ref.Params))
+	for k, v := range ref.Params {
+		values[k] = v
+		refParams[k] = struct{}{}
+	}
+	for k, v := range reserved {
+		values[k] = &yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: v}
+	}
+	return templateParams{values: values, refParams: refParams, refLocation: ref.Location}
+}
+
+// substituteString replaces all parameters in the string. Returns the node of the parameter
+// if the whole string is a single parameter without transformations, so non-scalar values can be used.
+// Also reports whether any of the substituted parameters was provided by the reference.
+func (p templateParams) substituteString(s string, node *yaml.Node) (string, *yaml.Node, bool, error) {
+	matches := templateParamRegexp.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil, false, nil
+	}
+	var b strings.Builder
+	last := 0
+	usesRefParams := false
+	for _, m := range matches {
+		name := s[m[2]:m[3]]
+		value, ok := p.values[name]
+		if !ok {
+			return "", nil, false, StacktraceNew("parameter is not provided", p.location, WithNodePosition(node),
+				stacktrace.WithInfo("parameter", name))
+		}
+		if _, ok = p.refParams[name]; ok {
+			usesRefParams = true
+		}
+		funcs := s[m[4]:m[5]]
+		if m[0] == 0 && m[1] == len(s) && funcs == "" && value.Kind != yaml.ScalarNode {
+			return "", value, usesRefParams, nil
+		}
+		if value.Kind != yaml.ScalarNode {
+			return "", nil, false, StacktraceNew("parameter value must be scalar", p.location,
+				WithNodePosition(node), stacktrace.WithInfo("parameter", name))
+		}
+		transformed, err := applyTransformers(value.Value, funcs)
+		if err != nil {
+			return "", nil, false, StacktraceNewWrapped("apply transformers", err, p.location,
+				WithNodePosition(node), stacktrace.WithInfo("parameter", name))
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(transformed)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil, usesRefParams, nil
+}
+
+// copyTemplateNode deeply copies the template node substituting parameters in keys and values.
+// Copied nodes keep positions of the template and are bound to the template location,
+// so type references are resolved in scope of the fragment that declares the template.
+// Scalars that contain values of parameters provided by the reference are bound to the reference location instead,
+// because such values (e.g. type names) belong to scope of the fragment that applies the template.
+func (r *RAML) copyTemplateNode(node *yaml.Node, params templateParams) (*yaml.Node, error) {
+	c := *node
+	c.Content = nil
+	location := params.location
+	if node.Kind == yaml.ScalarNode {
+		value, replacement, usesRefParams, err := params.substituteString(node.Value, node)
+		if err != nil {
+			return nil, err
+		}
+		if replacement != nil {
+			return r.copyTemplateNode(replacement, templateParams{location: params.refLocation})
+		}
+		if usesRefParams && params.refLocation != "" {
+			location = params.refLocation
+		}
+		if value != node.Value {
+			c.Value = value
+			// Substituted values are plain strings unless the original tag was explicit.
+			if c.Tag == "" || c.Tag == TagStr {
+				c.Style = 0
+				var resolved yaml.Node
+				if err = yaml.Unmarshal([]byte(value), &resolved); err == nil && len(resolved.Content) == 1 &&
+					resolved.Content[0].Kind == yaml.ScalarNode {
+					c.Tag = resolved.Content[0].Tag
+				} else {
+					c.Tag = TagStr
+				}
+			}
+		}
+	}
+	if len(node.Content) > 0 {
+		c.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			cc, err := r.copyTemplateNode(child, params)
+			if err != nil {
+				return nil, err
+			}
+			c.Content[i] = cc
+		}
+	}
+	if r.templateLocations == nil {
+		r.templateLocations = make(map[*yaml.Node]string)
+	}
+	r.templateLocations[&c] = location
+	return &c, nil
+}
+
+// templateLocation returns the location of the template the node was copied from, the location of the document
+// the node was merged from or the default location.
+// Type declarations in the form of a map are bound to the location of their "type" node.
+func (r *RAML) templateLocation(node *yaml.Node, location string) string {
+	if typeNode := findMappingValue(node, "type"); typeNode != nil {
+		if loc, ok := r.templateLocations[typeNode]; ok {
+			return loc
+		}
+	}
+	if loc, ok := r.templateLocations[node]; ok {
+		return loc
+	}
+	return r.mergedLocation(node, location)
+}
+
+// applyTransformers applies transformer functions like "| !singularize | !uppercase" to the value.
+func applyTransformers(value string, funcs string) (string, error) {
+	for _, f := range strings.Split(funcs, "|") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		switch f {
+		case "!singularize":
+			value = singularize(value)
+		case "!pluralize":
+			value = pluralize(value)
+		case "!uppercase":
+			value = strings.ToUpper(value)
+		case "!lowercase":
+			value = strings.ToLower(value)
+		case "!lowercamelcase":
+			value = joinWords(splitWords(value), "", false, true)
+		case "!uppercamelcase":
+			value = joinWords(splitWords(value), "", true, true)
+		case "!lowerunderscorecase":
+			value = strings.ToLower(strings.Join(splitWords(value), "_"))
+		case "!upperunderscorecase":
+			value = strings.ToUpper(strings.Join(splitWords(value), "_"))
+		case "!lowerhyphencase":
+			value = strings.ToLower(strings.Join(splitWords(value), "-"))
+		case "!upperhyphencase":
+			value = strings.ToUpper(strings.Join(splitWords(value), "-"))
+		default:
+			return "", fmt.Errorf("unknown transformer function: %s", f)
+		}
+	}
+	return value, nil
+}
+
+// splitWords splits the value into words by non-alphanumeric characters and camel case boundaries.
+func splitWords(value string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(value)
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(c) && len(current) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(current))
+				current = nil
+			}
+		}
+		current = append(current, c)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+func joinWords(words []string, sep string, upperFirst bool, capitalize bool) string {
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if capitalize && (i > 0 || upperFirst) && w != "" {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		words[i] = w
+	}
+	return strings.Join(words, sep)
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+// singularize converts an English noun to its singular form using common suffix rules.
+func singularize(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(value) > 3:
+		return value[:len(value)-3] + matchCase(value[len(value)-3:], "y")
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"):
+		return value[:len(value)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return value
+	case strings.HasSuffix(lower, "s") && len(value) > 1:
+		return value[:len(value)-1]
+	}
+	return value
+}
+
+// pluralize converts an English noun to its plural form using common suffix rules.
+func pluralize(value string) string {
+	lower := strings.ToLower(value)
+	n := len(lower)
+	switch {
+	case n == 0:
+		return value
+	case strings.HasSuffix(lower, "y") && n > 1 && !isVowel(lower[n-2]):
+		return value[:n-1] + matchCase(value[n-1:], "ies")
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return value + matchCase(value[n-1:], "es")
+	}
+	return value + matchCase(value[n-1:], "s")
+}
+
+// matchCase returns the suffix in upper case if the reference is upper case.
+func matchCase(reference string, suffix string) string {
+	if strings.ToUpper(reference) == reference && strings.ToLower(reference) != reference {
+		return strings.ToUpper(suffix)
+	}
+	return suffix
+}
+
+// resourcePathName returns the rightmost path segment of the resource path that is not a URI parameter.
+func resourcePathName(resourcePath string) string {
+	segments := strings.Split(resourcePath, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		s := segments[i]
+		if s != "" && !strings.Contains(s, "{") {
+			return s
+		}
+	}
+	return ""
+}
+
+// mergeTemplateNode merges the src mapping node into the dst mapping node in-place.
+// Values of dst take precedence; mappings are merged recursively.
+// Optional keys of src (e.g. "get?") are merged only into existing keys of dst and dropped otherwise,
+// unless keepOptional is set, in which case they are added as is.
+func mergeTemplateNode(dst, src *yaml.Node, keepOptional bool) {
+	for i := 0; i != len(src.Content); i += 2 {
+		key := src.Content[i]
+		value := src.Content[i+1]
+		name, optional := key.Value, false
+		if _, ok := SetOfMethods[strings.TrimSuffix(name, "?")]; ok && strings.HasSuffix(name, "?") {
+			name, optional = strings.TrimSuffix(name, "?"), true
+		}
+		existing := findMappingValue(dst, name)
+		if existing == nil && optional {
+			existing = findMappingValue(dst, key.Value)
+			if existing == nil {
+				if keepOptional {
+					dst.Content = append(dst.Content, key, value)
+				}
+				continue
+			}
+		}
+		if existing == nil {
+			dst.Content = append(dst.Content, key, value)
+			continue
+		}
+		if existing.Tag == TagNull && value.Kind == yaml.MappingNode {
+			*existing = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: existing.Line, Column: existing.Column}
+		}
+		if existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeTemplateNode(existing, value, keepOptional)
+		}
+	}
+}
+
+// expandResourceType returns the resource type node with substituted parameters,
+// applied parent resource types and traits.
+func (r *RAML) expandResourceType(
+	ref templateRef,
+	location string,
+	resourcePath string,
+	visited map[*ResourceType]struct{},
+) (*yaml.Node, error) {
+	rt, err := r.GetReferencedResourceType(ref.Name, location)
+	if err != nil {
+		return nil, StacktraceNewWrapped("get referenced resource type", err, location, WithNodePosition(ref.Node))
+	}
+	if _, ok := visited[rt]; ok {
+		return nil, StacktraceNew("resource type has cyclic inheritance", location, WithNodePosition(ref.Node),
+			stacktrace.WithInfo("resource type", ref.Name))
+	}
+	visited[rt] = struct{}{}
+	defer delete(visited, rt)
+
+	params := makeTemplateParams(ref, map[string]string{
+		ParamResourcePath:     resourcePath,
+		ParamResourcePathName: resourcePathName(resourcePath),
+	})
+	params.location = rt.Location
+	node, err := r.copyTemplateNode(rt.Node, params)
+	if err != nil {
+		return nil, StacktraceNewWrapped("substitute parameters", err, rt.Location,
+			stacktrace.WithPosition(&rt.Position), stacktrace.WithInfo("resource type", rt.Name))
+	}
+	removeMappingKey(node, "usage")
+
+	var parent *yaml.Node
+	if parentNode := removeMappingKey(node, "type"); parentNode != nil {
+		parentRef, errRef := decodeTemplateRef(parentNode, rt.Location)
+		if errRef != nil {
+			return nil, fmt.Errorf("decode resource type ref: %w", errRef)
+		}
+		parent, err = r.expandResourceType(parentRef, rt.Location, resourcePath, visited)
+		if err != nil {
+			return nil, StacktraceNewWrapped("expand parent resource type", err, rt.Location,
+				stacktrace.WithPosition(&rt.Position), stacktrace.WithInfo("resource type", rt.Name))
+		}
+		addTemplateMethods(node, parent)
+	}
+
+	// Traits are applied in scope of the resource type since their names are resolved from its location.
+	if _, err = r.applyResourceTraits(node, rt.Location, resourcePath); err != nil {
+		return nil, StacktraceNewWrapped("apply traits", err, rt.Location,
+			stacktrace.WithPosition(&rt.Position), stacktrace.WithInfo("resource type", rt.Name))
+	}
+	if parent != nil {
+		mergeTemplateNode(node, parent, true)
+	}
+	return node, nil
+}
+
+// expandTrait returns the trait node with substituted parameters.
+func (r *RAML) expandTrait(ref templateRef, location string, resourcePath string, methodName string) (*yaml.Node, error) {
+	trait, err := r.GetReferencedTrait(ref.Name, location)
+	if err != nil {
+		return nil, StacktraceNewWrapped("get referenced trait", err, location, WithNodePosition(ref.Node))
+	}
+	params := makeTemplateParams(ref, map[string]string{
+		ParamResourcePath:     resourcePath,
+		ParamResourcePathName: resourcePathName(resourcePath),
+		ParamMethodName:       methodName,
+	})
+	params.location = trait.Location
+	node, err := r.copyTemplateNode(trait.Node, params)
+	if err != nil {
+		return nil, StacktraceNewWrapped("substitute parameters", err, trait.Location,
+			stacktrace.WithPosition(&trait.Position), stacktrace.WithInfo("trait", trait.Name))
+	}
+	removeMappingKey(node, "usage")
+	return node, nil
+}
+
+// applyResourceTraits applies traits referenced by the resource and its methods to every method in-place.
+// Traits of the method take precedence over traits of the resource, earlier traits take precedence over later ones.
+// Returns names of the applied traits per method.
+func (r *RAML) applyResourceTraits(node *yaml.Node, location string, resourcePath string) (map[string][]string, error) {
+	var resourceRefs []templateRef
+	if isNode := removeMappingKey(node, "is"); isNode != nil {
+		refs, err := decodeTemplateRefs(isNode, location)
+		if err != nil {
+			return nil, fmt.Errorf("decode resource traits: %w", err)
+		}
+		resourceRefs = refs
+	}
+	applied := make(map[string][]string)
+	for i := 0; i != len(node.Content); i += 2 {
+		key := node.Content[i]
+		methodName := strings.TrimSuffix(key.Value, "?")
+		if _, ok := SetOfMethods[methodName]; !ok {
+			continue
+		}
+		methodNode := node.Content[i+1]
+		if methodNode.Tag == TagNull {
+			methodNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: methodNode.Line, Column: methodNode.Column}
+			node.Content[i+1] = methodNode
+		}
+		if methodNode.Kind != yaml.MappingNode {
+			continue
+		}
+		var refs []templateRef
+		if isNode := removeMappingKey(methodNode, "is"); isNode != nil {
+			methodRefs, err := decodeTemplateRefs(isNode, location)
+			if err != nil {
+				return nil, fmt.Errorf("decode method traits: %w", err)
+			}
+			refs = methodRefs
+		}
+		refs = append(refs, resourceRefs...)
+		for _, ref := range refs {
+			traitNode, err := r.expandTrait(ref, location, resourcePath, methodName)
+			if err != nil {
+				return nil, StacktraceNewWrapped("expand trait", err, location, WithNodePosition(ref.Node),
+					stacktrace.WithInfo("method", methodName), stacktrace.WithInfo("trait", ref.Name))
+			}
+			mergeTemplateNode(methodNode, traitNode, false)
+			applied[key.Value] = append(applied[key.Value], ref.Name)
+		}
+	}
+	return applied, nil
+}
+
+// addTemplateMethods adds empty methods to the dst mapping node for required methods of the src resource type node
+// that dst does not declare, so that traits of the resource are applied to methods inherited from the resource type.
+func addTemplateMethods(dst, src *yaml.Node) {
+	for i := 0; i != len(src.Content); i += 2 {
+		key := src.Content[i]
+		if _, ok := SetOfMethods[key.Value]; !ok || findMappingValue(dst, key.Value) != nil {
+			continue
+		}
+		value := src.Content[i+1]
+		dst.Content = append(dst.Content, key,
+			&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column})
+	}
+}
+
+// expandedResource is a resource node with the applied resource type and traits.
+type expandedResource struct {
+	Node   *yaml.Node
+	Type   string
+	Traits map[string][]string
+}
+
+// expandResource applies the resource type and traits to the resource node in-place.
+// Nested resources are not affected since they are expanded separately when they are decoded.
+func (r *RAML) expandResource(node *yaml.Node, location string, resourcePath string) (expandedResource, error) {
+	nested := make([]*yaml.Node, 0)
+	for i := 0; i < len(node.Content); {
+		if IsResourceNode(node.Content[i].Value) {
+			nested = append(nested, node.Content[i], node.Content[i+1])
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			continue
+		}
+		i += 2
+	}
+
+	var res expandedResource
+	var rtNode *yaml.Node
+	if typeNode := removeMappingKey(node, "type"); typeNode != nil {
+		ref, errRef := decodeTemplateRef(typeNode, location)
+		if errRef != nil {
+			return expandedResource{}, fmt.Errorf("decode resource type ref: %w", errRef)
+		}
+		var errExpand error
+		rtNode, errExpand = r.expandResourceType(ref, location, resourcePath, make(map[*ResourceType]struct{}))
+		if errExpand != nil {
+			return expandedResource{}, StacktraceNewWrapped("expand resource type", errExpand, location,
+				WithNodePosition(typeNode), stacktrace.WithInfo("resource type", ref.Name))
+		}
+		addTemplateMethods(node, rtNode)
+		res.Type = ref.Name
+	}
+
+	// Traits are applied before the resource type is merged, since they take precedence over it.
+	traits, err := r.applyResourceTraits(node, location, resourcePath)
+	if err != nil {
+		return expandedResource{}, fmt.Errorf("apply traits: %w", err)
+	}
+	res.Node, res.Traits = node, traits
+	if rtNode != nil {
+		mergeTemplateNode(node, rtNode, false)
+	}
+	node.Content = append(node.Content, nested...)
+	return res, nil
+}
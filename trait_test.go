@@ -0,0 +1,321 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_applyTransformers(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		funcs   string
+		want    string
+		wantErr bool
+	}{
+		{name: "singularize", value: "users", funcs: "| !singularize", want: "user"},
+		{name: "singularize ies", value: "categories", funcs: "| !singularize", want: "category"},
+		{name: "singularize es", value: "boxes", funcs: "| !singularize", want: "box"},
+		{name: "pluralize", value: "user", funcs: "| !pluralize", want: "users"},
+		{name: "pluralize y", value: "category", funcs: "| !pluralize", want: "categories"},
+		{name: "pluralize ch", value: "batch", funcs: "| !pluralize", want: "batches"},
+		{name: "uppercase", value: "userId", funcs: "| !uppercase", want: "USERID"},
+		{name: "lowercase", value: "UserId", funcs: "| !lowercase", want: "userid"},
+		{name: "lowercamelcase", value: "user-id", funcs: "| !lowercamelcase", want: "userId"},
+		{name: "uppercamelcase", value: "user_id", funcs: "| !uppercamelcase", want: "UserId"},
+		{name: "lowerunderscorecase", value: "userId", funcs: "| !lowerunderscorecase", want: "user_id"},
+		{name: "upperunderscorecase", value: "userId", funcs: "| !upperunderscorecase", want: "USER_ID"},
+		{name: "lowerhyphencase", value: "UserID", funcs: "| !lowerhyphencase", want: "user-id"},
+		{name: "upperhyphencase", value: "userId", funcs: "| !upperhyphencase", want: "USER-ID"},
+		{name: "chain", value: "users", funcs: "| !singularize | !uppercamelcase", want: "User"},
+		{name: "unknown function", value: "users", funcs: "| !unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyTransformers(tt.value, tt.funcs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("applyTransformers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_resourcePathName(t *testing.T) {
+	require.Equal(t, "users", resourcePathName("/users"))
+	require.Equal(t, "users", resourcePathName("/users/{id}"))
+	require.Equal(t, "groups", resourcePathName("/users/{id}/groups"))
+	require.Equal(t, "", resourcePathName("/{id}"))
+}
+
+func TestAPI_ApplyResourceTypesAndTraits(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		want    func(t *testing.T, got *API)
+	}{
+		{
+			name: "positive: resource type with parameters and optional methods",
+			content: `#%RAML 1.0
+title: Sample API
+mediaType: application/json
+resourceTypes:
+  base:
+    get?:
+      description: Base <<resourcePathName>>
+    delete?:
+  collection:
+    type: base
+    get:
+      responses:
+        200:
+          body: <<itemType>>[]
+    post?:
+      body: <<itemType>>
+types:
+  User:
+    properties:
+      name: string
+/users:
+  type: { collection: { itemType: User } }
+  get:
+    description: Own description
+`,
+			want: func(t *testing.T, got *API) {
+				users, ok := got.Resources.Get("/users")
+				require.True(t, ok)
+				require.Equal(t, "collection", users.Type)
+				require.Equal(t, 1, users.Methods.Len())
+				get, ok := users.Methods.Get("get")
+				require.True(t, ok)
+				require.Equal(t, "Own description", get.Description)
+				resp, ok := get.Responses.Get("200")
+				require.True(t, ok)
+				body, ok := resp.Body.Get("application/json")
+				require.True(t, ok)
+				require.IsType(t, &ArrayShape{}, body.Shape.Shape)
+			},
+		},
+		{
+			name: "positive: traits precedence",
+			content: `#%RAML 1.0
+title: Sample API
+traits:
+  first:
+    description: first
+    headers:
+      X-First: string
+  second:
+    description: second
+    headers:
+      X-Second: string
+  named:
+    displayName: <<methodName | !uppercase>> <<resourcePath>>
+/users:
+  is: [second]
+  get:
+    is: [first, named]
+  /{id}:
+    put:
+      is: [first]
+      description: own
+`,
+			want: func(t *testing.T, got *API) {
+				users, _ := got.Resources.Get("/users")
+				get, ok := users.Methods.Get("get")
+				require.True(t, ok)
+				require.Equal(t, []string{"first", "named", "second"}, get.Is)
+				require.Equal(t, "first", get.Description)
+				require.Equal(t, "GET /users", get.DisplayName)
+				_, ok = get.Headers.Get("X-First")
+				require.True(t, ok)
+				_, ok = get.Headers.Get("X-Second")
+				require.True(t, ok)
+
+				item, _ := users.Resources.Get("/{id}")
+				put, ok := item.Methods.Get("put")
+				require.True(t, ok)
+				require.Equal(t, "own", put.Description)
+				_, ok = put.Headers.Get("X-Second")
+				require.False(t, ok)
+			},
+		},
+		{
+			name: "positive: resource traits apply to methods of resource type",
+			content: `#%RAML 1.0
+title: Sample API
+traits:
+  secured:
+    description: secured
+    headers:
+      Authorization: string
+  paged:
+    queryParameters:
+      limit?: integer
+resourceTypes:
+  base:
+    delete:
+  collection:
+    type: base
+    is: [paged]
+    get:
+      description: collection
+    post?:
+/users:
+  type: collection
+  is: [secured]
+`,
+			want: func(t *testing.T, got *API) {
+				users, _ := got.Resources.Get("/users")
+				require.Equal(t, 2, users.Methods.Len())
+				get, ok := users.Methods.Get("get")
+				require.True(t, ok)
+				require.Equal(t, []string{"secured"}, get.Is)
+				require.Equal(t, "secured", get.Description)
+				_, ok = get.Headers.Get("Authorization")
+				require.True(t, ok)
+				_, ok = get.QueryParameters.Get("limit")
+				require.True(t, ok)
+				del, ok := users.Methods.Get("delete")
+				require.True(t, ok)
+				_, ok = del.Headers.Get("Authorization")
+				require.True(t, ok)
+				_, ok = del.QueryParameters.Get("limit")
+				require.True(t, ok)
+			},
+		},
+		{
+			name: "negative: missing parameter",
+			content: `#%RAML 1.0
+title: Sample API
+traits:
+  paged:
+    queryParameters:
+      limit:
+        type: integer
+        maximum: <<max>>
+/users:
+  get:
+    is: [paged]
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: unknown trait",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  get:
+    is: [unknown]
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: unknown resource type",
+			content: `#%RAML 1.0
+title: Sample API
+/users:
+  type: unknown
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: cyclic resource types",
+			content: `#%RAML 1.0
+title: Sample API
+resourceTypes:
+  a:
+    type: b
+  b:
+    type: a
+/users:
+  type: a
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: is must be sequence",
+			content: `#%RAML 1.0
+title: Sample API
+traits:
+  t:
+/users:
+  get:
+    is: t
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative: invalid shape from trait",
+			content: `#%RAML 1.0
+title: Sample API
+traits:
+  t:
+    queryParameters:
+      q:
+        type: string
+        minLength: <<min>>
+        maxLength: 1
+/users:
+  get:
+    is: [t: {min: 5}]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFromStringCtx(context.Background(), tt.content, "api.raml", "",
+				OptWithUnwrap(), OptWithValidate())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want != nil {
+				api, ok := got.EntryPoint().(*API)
+				require.True(t, ok)
+				tt.want(t, api)
+			}
+		})
+	}
+}
+
+func TestAPI_ApplyLibraryTraits(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/api_traits.raml", OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api, ok := rml.EntryPoint().(*API)
+	require.True(t, ok)
+
+	books, ok := api.Resources.Get("/books")
+	require.True(t, ok)
+	require.Equal(t, "lib.collection", books.Type)
+
+	get, ok := books.Methods.Get("get")
+	require.True(t, ok)
+	require.Equal(t, "List Books", get.DisplayName)
+	require.Equal(t, []string{"lib.paged", "secured"}, get.Is)
+	limit, ok := get.QueryParameters.Get("limit")
+	require.True(t, ok)
+	limitShape, ok := limit.Base.Shape.(*IntegerShape)
+	require.True(t, ok)
+	require.Equal(t, int64(100), limitShape.Maximum.Int64())
+	auth, ok := get.Headers.Get("Authorization")
+	require.True(t, ok)
+	require.Equal(t, "Token for get /books", *auth.Base.Description)
+	// Error is declared in the library and must be resolved in its scope.
+	resp, ok := get.Responses.Get("404")
+	require.True(t, ok)
+	body, ok := resp.Body.Get("application/json")
+	require.True(t, ok)
+	require.Contains(t, body.Shape.Location, "traits_lib.raml")
+
+	post, ok := books.Methods.Get("post")
+	require.True(t, ok)
+	require.Equal(t, "Create book", post.DisplayName)
+	_, ok = books.Methods.Get("delete")
+	require.False(t, ok)
+}
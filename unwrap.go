@@ -9,14 +9,15 @@ import (
 
 func (r *RAML) unwrapTypes(
 	types *orderedmap.OrderedMap[string, *BaseShape],
-	f *Library,
+	f Fragment,
 	isAnnotationType bool,
 ) *stacktrace.StackTrace {
+	location := f.GetLocation()
 	var st *stacktrace.StackTrace
 	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
 		base := pair.Value
 		if base == nil {
-			se := StacktraceNew("shape is nil", f.Location,
+			se := StacktraceNew("shape is nil", location,
 				stacktrace.WithType(StacktraceTypeUnwrapping))
 			if st == nil {
 				st = se
@@ -27,7 +28,7 @@ func (r *RAML) unwrapTypes(
 		}
 		us, err := r.UnwrapShape(base)
 		if err != nil {
-			se := StacktraceNewWrapped("unwrap shape", err, f.Location,
+			se := StacktraceNewWrapped("unwrap shape", err, location,
 				stacktrace.WithType(StacktraceTypeUnwrapping), stacktrace.WithPosition(&base.Position))
 			if st == nil {
 				st = se
@@ -38,9 +39,9 @@ func (r *RAML) unwrapTypes(
 		}
 		types.Set(pair.Key, us)
 		if isAnnotationType {
-			r.PutAnnotationTypeIntoFragment(us.Name, f.Location, base)
+			r.PutAnnotationTypeIntoFragment(us.Name, location, base)
 		} else {
-			r.PutTypeIntoFragment(us.Name, f.Location, base)
+			r.PutTypeIntoFragment(us.Name, location, base)
 		}
 	}
 	return st
@@ -56,6 +57,182 @@ func (r *RAML) unwrapLibrary(f *Library) *stacktrace.StackTrace {
 			st = st.Append(se)
 		}
 	}
+	if se = r.unwrapSecuritySchemes(f.SecuritySchemes); se != nil {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	return st
+}
+
+// unwrapParameters unwraps named parameters in-place.
+func (r *RAML) unwrapParameters(params *orderedmap.OrderedMap[string, Property], location string) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value
+		us, err := r.UnwrapShape(prop.Base)
+		if err != nil {
+			se := StacktraceNewWrapped("unwrap parameter", err, location,
+				stacktrace.WithType(StacktraceTypeUnwrapping), stacktrace.WithPosition(&prop.Base.Position),
+				stacktrace.WithInfo("parameter", pair.Key))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+			continue
+		}
+		prop.Base = us
+		params.Set(pair.Key, prop)
+	}
+	return st
+}
+
+func (r *RAML) unwrapAPI(f *API) *stacktrace.StackTrace {
+	st := r.unwrapTypes(f.AnnotationTypes, f, true)
+	if se := r.unwrapTypes(f.Types, f, false); se != nil {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	if se := r.unwrapParameters(f.BaseURIParameters, f.Location); se != nil {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	if se := r.unwrapResources(f.Resources); se != nil {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	if se := r.unwrapSecuritySchemes(f.SecuritySchemes); se != nil {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	return st
+}
+
+// unwrapSecuritySchemes unwraps shapes of security schemes "describedBy" in-place.
+func (r *RAML) unwrapSecuritySchemes(schemes *orderedmap.OrderedMap[string, *SecurityScheme]) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	for pair := schemes.Oldest(); pair != nil; pair = pair.Next() {
+		part := pair.Value.DescribedBy
+		ses := []*stacktrace.StackTrace{
+			r.unwrapParameters(part.Headers, part.Location),
+			r.unwrapParameters(part.QueryParameters, part.Location),
+		}
+		if part.QueryString != nil {
+			us, err := r.UnwrapShape(part.QueryString)
+			if err != nil {
+				ses = append(ses, StacktraceNewWrapped("unwrap query string", err, part.Location,
+					stacktrace.WithType(StacktraceTypeUnwrapping), stacktrace.WithPosition(&part.QueryString.Position)))
+			} else {
+				part.QueryString = us
+			}
+		}
+		for rPair := part.Responses.Oldest(); rPair != nil; rPair = rPair.Next() {
+			ses = append(ses, r.unwrapParameters(rPair.Value.Headers, rPair.Value.Location), r.unwrapBody(rPair.Value.Body))
+		}
+		for _, se := range ses {
+			if se == nil {
+				continue
+			}
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	return st
+}
+
+// unwrapBody unwraps body shapes in-place.
+func (r *RAML) unwrapBody(body *orderedmap.OrderedMap[string, *Body]) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	for pair := body.Oldest(); pair != nil; pair = pair.Next() {
+		b := pair.Value
+		us, err := r.UnwrapShape(b.Shape)
+		if err != nil {
+			se := StacktraceNewWrapped("unwrap body", err, b.Location,
+				stacktrace.WithType(StacktraceTypeUnwrapping), stacktrace.WithPosition(&b.Position),
+				stacktrace.WithInfo("media type", pair.Key))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+			continue
+		}
+		b.Shape = us
+	}
+	return st
+}
+
+func (r *RAML) unwrapMethod(m *Method) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	if m.QueryString != nil {
+		us, err := r.UnwrapShape(m.QueryString)
+		if err != nil {
+			st = StacktraceNewWrapped("unwrap query string", err, m.Location,
+				stacktrace.WithType(StacktraceTypeUnwrapping), stacktrace.WithPosition(&m.QueryString.Position))
+		} else {
+			m.QueryString = us
+		}
+	}
+	ses := []*stacktrace.StackTrace{
+		r.unwrapParameters(m.QueryParameters, m.Location),
+		r.unwrapParameters(m.Headers, m.Location),
+		r.unwrapBody(m.Body),
+	}
+	for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		ses = append(ses, r.unwrapParameters(pair.Value.Headers, pair.Value.Location), r.unwrapBody(pair.Value.Body))
+	}
+	for _, se := range ses {
+		if se == nil {
+			continue
+		}
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	return st
+}
+
+// unwrapResources unwraps shapes of resources and their nested resources in-place.
+func (r *RAML) unwrapResources(resources *orderedmap.OrderedMap[string, *Resource]) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		res := pair.Value
+		ses := []*stacktrace.StackTrace{r.unwrapParameters(res.URIParameters, res.Location)}
+		for mPair := res.Methods.Oldest(); mPair != nil; mPair = mPair.Next() {
+			ses = append(ses, r.unwrapMethod(mPair.Value))
+		}
+		ses = append(ses, r.unwrapResources(res.Resources))
+		for _, se := range ses {
+			if se == nil {
+				continue
+			}
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
 	return st
 }
 
@@ -96,6 +273,24 @@ func (r *RAML) unwrapFragments() *stacktrace.StackTrace {
 					st = st.Append(se)
 				}
 			}
+		case *AnnotationTypeDeclaration:
+			se := r.unwrapDataType(&f.DataType)
+			if se != nil {
+				if st == nil {
+					st = se
+				} else {
+					st = st.Append(se)
+				}
+			}
+		case *API:
+			se := r.unwrapAPI(f)
+			if se != nil {
+				if st == nil {
+					st = se
+				} else {
+					st = st.Append(se)
+				}
+			}
 		}
 	}
 	return st
@@ -163,6 +358,49 @@ func (r *RAML) markShapeRecursions() error {
 			if _, err := r.FindAndMarkRecursion(f.Shape); err != nil {
 				return err
 			}
+		case *AnnotationTypeDeclaration:
+			if _, err := r.FindAndMarkRecursion(f.Shape); err != nil {
+				return err
+			}
+		case *API:
+			if err := r.markAPIRecursions(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// markAPIRecursions marks recursive shapes in the API document.
+func (r *RAML) markAPIRecursions(f *API) error {
+	for pair := f.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+		if _, err := r.FindAndMarkRecursion(pair.Value); err != nil {
+			return err
+		}
+	}
+	for pair := f.Types.Oldest(); pair != nil; pair = pair.Next() {
+		if _, err := r.FindAndMarkRecursion(pair.Value); err != nil {
+			return err
+		}
+	}
+	for pair := f.BaseURIParameters.Oldest(); pair != nil; pair = pair.Next() {
+		if _, err := r.FindAndMarkRecursion(pair.Value.Base); err != nil {
+			return err
+		}
+	}
+	return r.markResourceRecursions(f.Resources)
+}
+
+// markResourceRecursions marks recursive shapes in resources and their nested resources.
+func (r *RAML) markResourceRecursions(resources *orderedmap.OrderedMap[string, *Resource]) error {
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		for _, shape := range pair.Value.shapes() {
+			if _, err := r.FindAndMarkRecursion(shape); err != nil {
+				return err
+			}
+		}
+		if err := r.markResourceRecursions(pair.Value.Resources); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -172,6 +410,11 @@ const HookBeforeFindAndMarkRecursion HookKey = "RAML.FindAndMarkRecursion"
 
 // FindAndMarkRecursion finds recursive shapes and replaces them with RecursiveShape.
 func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
+	return r.findAndMarkRecursion(base, make(map[*BaseShape]struct{}))
+}
+
+// findAndMarkRecursion finds recursive shapes of the base. Visited contains shapes on the current path.
+func (r *RAML) findAndMarkRecursion(base *BaseShape, visited map[*BaseShape]struct{}) (*BaseShape, error) {
 	if err := r.callHooks(HookBeforeFindAndMarkRecursion, base); err != nil {
 		return nil, err
 	}
@@ -179,21 +422,21 @@ func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
 		return nil, fmt.Errorf("shape is not unwrapped")
 	}
 
-	if base.ShapeVisited {
+	if _, ok := visited[base]; ok {
 		s := r.MakeRecursiveShape(base)
 		s.unwrapped = true
 		return s, nil
 	}
-	base.ShapeVisited = true
+	visited[base] = struct{}{}
 
 	var err error
 	switch t := base.Shape.(type) {
 	case *ArrayShape:
-		err = r.findAndMarkRecursionInArrayShape(t)
+		err = r.findAndMarkRecursionInArrayShape(t, visited)
 	case *ObjectShape:
-		err = r.findAndMarkRecursionInObjectShape(t)
+		err = r.findAndMarkRecursionInObjectShape(t, visited)
 	case *UnionShape:
-		err = r.findAndMarkRecursionInUnionShape(t)
+		err = r.findAndMarkRecursionInUnionShape(t, visited)
 	}
 	if err != nil {
 		return nil, err
@@ -203,8 +446,8 @@ func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
 	// for trait that points to the same type that defines this trait.
 	// This is OK because traits cannot have nested traits and
 	// cannot be used as a source for inheritance.
-	base.ShapeVisited = false
-	err = r.findAndMarkRecursionInCustomShapeFacetDefinitions(base)
+	delete(visited, base)
+	err = r.findAndMarkRecursionInCustomShapeFacetDefinitions(base, visited)
 	if err != nil {
 		return nil, err
 	}
@@ -212,10 +455,12 @@ func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
 	return nil, ErrNil
 }
 
-func (r *RAML) findAndMarkRecursionInCustomShapeFacetDefinitions(base *BaseShape) error {
+func (r *RAML) findAndMarkRecursionInCustomShapeFacetDefinitions(
+	base *BaseShape, visited map[*BaseShape]struct{},
+) error {
 	for pair := base.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
 		prop := pair.Value
-		rs, err := r.FindAndMarkRecursion(prop.Base)
+		rs, err := r.findAndMarkRecursion(prop.Base, visited)
 		if err != nil {
 			return fmt.Errorf("find and mark recursion: %w", err)
 		}
@@ -227,9 +472,9 @@ func (r *RAML) findAndMarkRecursionInCustomShapeFacetDefinitions(base *BaseShape
 	return nil
 }
 
-func (r *RAML) findAndMarkRecursionInArrayShape(t *ArrayShape) error {
+func (r *RAML) findAndMarkRecursionInArrayShape(t *ArrayShape, visited map[*BaseShape]struct{}) error {
 	if t.Items != nil {
-		rs, err := r.FindAndMarkRecursion(t.Items)
+		rs, err := r.findAndMarkRecursion(t.Items, visited)
 		if err != nil {
 			return fmt.Errorf("find and mark recursion: %w", err)
 		}
@@ -240,11 +485,11 @@ func (r *RAML) findAndMarkRecursionInArrayShape(t *ArrayShape) error {
 	return nil
 }
 
-func (r *RAML) findAndMarkRecursionInObjectShape(t *ObjectShape) error {
+func (r *RAML) findAndMarkRecursionInObjectShape(t *ObjectShape, visited map[*BaseShape]struct{}) error {
 	if t.Properties != nil {
 		for pair := t.Properties.Oldest(); pair != nil; pair = pair.Next() {
 			prop := pair.Value
-			rs, err := r.FindAndMarkRecursion(prop.Base)
+			rs, err := r.findAndMarkRecursion(prop.Base, visited)
 			if err != nil {
 				return fmt.Errorf("find and mark recursion: %w", err)
 			}
@@ -257,7 +502,7 @@ func (r *RAML) findAndMarkRecursionInObjectShape(t *ObjectShape) error {
 	if t.PatternProperties != nil {
 		for pair := t.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
 			prop := pair.Value
-			rs, err := r.FindAndMarkRecursion(prop.Base)
+			rs, err := r.findAndMarkRecursion(prop.Base, visited)
 			if err != nil {
 				return fmt.Errorf("find and mark recursion: %w", err)
 			}
@@ -270,9 +515,9 @@ func (r *RAML) findAndMarkRecursionInObjectShape(t *ObjectShape) error {
 	return nil
 }
 
-func (r *RAML) findAndMarkRecursionInUnionShape(t *UnionShape) error {
+func (r *RAML) findAndMarkRecursionInUnionShape(t *UnionShape, visited map[*BaseShape]struct{}) error {
 	for i, item := range t.AnyOf {
-		rs, err := r.FindAndMarkRecursion(item)
+		rs, err := r.findAndMarkRecursion(item, visited)
 		if err != nil {
 			return fmt.Errorf("find and mark recursion: %w", err)
 		}
@@ -129,6 +129,176 @@ func (r *RAML) validateDataType(f *DataType, unwrapCache map[int64]*BaseShape) *
 	return nil
 }
 
+// validateShape unwraps the shape if necessary and checks it.
+// Options are appended to the resulting stacktrace to identify the shape owner.
+func (r *RAML) validateShape(
+	base *BaseShape,
+	unwrapCache map[int64]*BaseShape,
+	opts ...stacktrace.Option,
+) *stacktrace.StackTrace {
+	shape, se := r.unwrapShape(base, unwrapCache)
+	if se != nil {
+		return se
+	}
+	if err := shape.Check(); err != nil {
+		return StacktraceNewWrapped("check shape", err, shape.Location,
+			append([]stacktrace.Option{
+				stacktrace.WithPosition(&shape.Position),
+				stacktrace.WithType(StacktraceTypeValidating),
+			}, opts...)...)
+	}
+	if err := r.validateShapeCommons(shape); err != nil {
+		return StacktraceNewWrapped("validate shape commons", err, shape.Location,
+			append([]stacktrace.Option{
+				stacktrace.WithPosition(&shape.Position),
+				stacktrace.WithType(StacktraceTypeValidating),
+			}, opts...)...)
+	}
+	return nil
+}
+
+const HookBeforeValidateParameters HookKey = "RAML.validateParameters"
+
+func (r *RAML) validateParameters(
+	params *orderedmap.OrderedMap[string, Property],
+	unwrapCache map[int64]*BaseShape,
+) *stacktrace.StackTrace {
+	if err := r.callHooks(HookBeforeValidateParameters, params, unwrapCache); err != nil {
+		return StacktraceNewWrapped("handle step", err, r.GetLocation())
+	}
+	var st *stacktrace.StackTrace
+	for pair := params.Oldest(); pair != nil; pair = pair.Next() {
+		se := r.validateShape(pair.Value.Base, unwrapCache, stacktrace.WithInfo("parameter", pair.Key))
+		if se != nil {
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	return st
+}
+
+func (r *RAML) validateBody(
+	body *orderedmap.OrderedMap[string, *Body],
+	unwrapCache map[int64]*BaseShape,
+) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	for pair := body.Oldest(); pair != nil; pair = pair.Next() {
+		se := r.validateShape(pair.Value.Shape, unwrapCache, stacktrace.WithInfo("media type", pair.Key))
+		if se != nil {
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	return st
+}
+
+const HookBeforeValidateMethod HookKey = "RAML.validateMethod"
+
+func (r *RAML) validateMethod(m *Method, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+	if err := r.callHooks(HookBeforeValidateMethod, m, unwrapCache); err != nil {
+		return StacktraceNewWrapped("handle step", err, m.Location)
+	}
+	var ses []*stacktrace.StackTrace
+	if m.QueryString != nil {
+		ses = append(ses, r.validateShape(m.QueryString, unwrapCache, stacktrace.WithInfo("node", "queryString")))
+	}
+	ses = append(ses,
+		r.validateParameters(m.QueryParameters, unwrapCache),
+		r.validateParameters(m.Headers, unwrapCache),
+		r.validateBody(m.Body, unwrapCache),
+	)
+	for pair := m.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		ses = append(ses,
+			r.validateParameters(pair.Value.Headers, unwrapCache),
+			r.validateBody(pair.Value.Body, unwrapCache),
+		)
+	}
+	var st *stacktrace.StackTrace
+	for _, se := range ses {
+		if se == nil {
+			continue
+		}
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	if st != nil {
+		return StacktraceNewWrapped("validate method", st, m.Location,
+			stacktrace.WithPosition(&m.Position),
+			stacktrace.WithType(StacktraceTypeValidating),
+			stacktrace.WithInfo("method", m.Name))
+	}
+	return nil
+}
+
+const HookBeforeValidateResources HookKey = "RAML.validateResources"
+
+func (r *RAML) validateResources(
+	resources *orderedmap.OrderedMap[string, *Resource],
+	unwrapCache map[int64]*BaseShape,
+) *stacktrace.StackTrace {
+	if err := r.callHooks(HookBeforeValidateResources, resources, unwrapCache); err != nil {
+		return StacktraceNewWrapped("handle step", err, r.GetLocation())
+	}
+	var st *stacktrace.StackTrace
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		res := pair.Value
+		ses := []*stacktrace.StackTrace{r.validateParameters(res.URIParameters, unwrapCache)}
+		for mPair := res.Methods.Oldest(); mPair != nil; mPair = mPair.Next() {
+			ses = append(ses, r.validateMethod(mPair.Value, unwrapCache))
+		}
+		ses = append(ses, r.validateResources(res.Resources, unwrapCache))
+		for _, se := range ses {
+			if se == nil {
+				continue
+			}
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		}
+	}
+	return st
+}
+
+const HookBeforeValidateAPI HookKey = "RAML.validateAPI"
+
+func (r *RAML) validateAPI(f *API, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+	if err := r.callHooks(HookBeforeValidateAPI, f, unwrapCache); err != nil {
+		return StacktraceNewWrapped("handle step", err, f.Location)
+	}
+	var st *stacktrace.StackTrace
+	if f.Title == "" {
+		st = StacktraceNew("title is required", f.Location,
+			stacktrace.WithType(StacktraceTypeValidating))
+	}
+	for _, se := range []*stacktrace.StackTrace{
+		r.validateTypes(f.AnnotationTypes, unwrapCache),
+		r.validateTypes(f.Types, unwrapCache),
+		r.validateParameters(f.BaseURIParameters, unwrapCache),
+		r.validateResources(f.Resources, unwrapCache),
+	} {
+		if se == nil {
+			continue
+		}
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	return st
+}
+
 const HookBeforeValidateFragments HookKey = "RAML.validateFragments"
 
 func (r *RAML) validateFragments(unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
@@ -154,6 +324,22 @@ func (r *RAML) validateFragments(unwrapCache map[int64]*BaseShape) *stacktrace.S
 					st = st.Append(err)
 				}
 			}
+		case *AnnotationTypeDeclaration:
+			if err := r.validateDataType(&f.DataType, unwrapCache); err != nil {
+				if st == nil {
+					st = err
+				} else {
+					st = st.Append(err)
+				}
+			}
+		case *API:
+			if err := r.validateAPI(f, unwrapCache); err != nil {
+				if st == nil {
+					st = err
+				} else {
+					st = st.Append(err)
+				}
+			}
 		}
 	}
 	return st